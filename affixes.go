@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bufio"
+
+	"fmt"
+
+	"sort"
+
+	"strings"
+
+	"github.com/jdkato/prose/v2"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+
+	"golang.org/x/text/encoding"
+)
+
+// Word-building affix checked by the morphology report
+
+type affix struct {
+	Text string
+
+	Prefix bool
+}
+
+// Productive affixes of Mandarin word formation
+
+var productiveAffixes = []affix{
+
+	{Text: "老", Prefix: true},
+
+	{Text: "小", Prefix: true},
+
+	{Text: "子"},
+
+	{Text: "儿"},
+
+	{Text: "化"},
+
+	{Text: "性"},
+
+	{Text: "者"},
+}
+
+// Words formed with one affix, with per-word token counts
+
+type affixUsage struct {
+	Affix affix
+
+	Words map[string]int
+
+	Stems map[string]string // Word → stem
+
+}
+
+// Collects the words built with each productive affix from the segmented tokens. An affix the segmenter
+
+// left as a token of its own (the word is missing from the dictionary) is joined to its neighboring word.
+
+func analyzeAffixes(tokens []prose.Token) []affixUsage {
+
+	usages := make([]affixUsage, len(productiveAffixes))
+
+	for i, a := range productiveAffixes {
+
+		usages[i] = affixUsage{Affix: a, Words: make(map[string]int), Stems: make(map[string]string)}
+
+	}
+
+	isWord := func(i int) bool {
+
+		return i >= 0 && i < len(tokens) && classifier.IsChineseText(tokens[i].Text)
+
+	}
+
+	for i, tok := range tokens {
+
+		if !isWord(i) {
+
+			continue
+
+		}
+
+		word := tok.Text
+
+		for k, a := range productiveAffixes {
+
+			var stem string
+
+			switch {
+
+			case word == a.Text && a.Prefix && isWord(i+1):
+
+				stem = tokens[i+1].Text
+
+				word = a.Text + stem
+
+			case word == a.Text && !a.Prefix && isWord(i-1):
+
+				stem = tokens[i-1].Text
+
+				word = stem + a.Text
+
+			case len([]rune(word)) < 2:
+
+				continue
+
+			case a.Prefix && strings.HasPrefix(word, a.Text):
+
+				stem = strings.TrimPrefix(word, a.Text)
+
+			case !a.Prefix && strings.HasSuffix(word, a.Text):
+
+				stem = strings.TrimSuffix(word, a.Text)
+
+			default:
+
+				continue
+
+			}
+
+			usages[k].Words[word]++
+
+			usages[k].Stems[word] = stem
+
+			word = tok.Text
+
+		}
+
+	}
+
+	return usages
+
+}
+
+// Productivity in the narrow sense (Baayen's P): words seen once divided by all tokens with the affix
+
+func (u affixUsage) productivity() (tokens, hapax int, p float64) {
+
+	for _, count := range u.Words {
+
+		tokens += count
+
+		if count == 1 {
+
+			hapax++
+
+		}
+
+	}
+
+	if tokens > 0 {
+
+		p = float64(hapax) / float64(tokens)
+
+	}
+
+	return tokens, hapax, p
+
+}
+
+// Writes ChineseMorphology.txt: per affix the stems it attaches to and how often, most productive affix first
+
+func writeMorphologyReport(path string, usages []affixUsage, enc encoding.Encoding) error {
+
+	file, err := createOutputFile(path, enc)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to create morphology report: %v", err)
+
+	}
+
+	defer file.Close()
+
+	sorted := append([]affixUsage{}, usages...)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+
+		_, _, pi := sorted[i].productivity()
+
+		_, _, pj := sorted[j].productivity()
+
+		if len(sorted[i].Words) != len(sorted[j].Words) {
+
+			return len(sorted[i].Words) > len(sorted[j].Words)
+
+		}
+
+		return pi > pj
+
+	})
+
+	writer := bufio.NewWriter(file)
+
+	for _, usage := range sorted {
+
+		tokens, hapax, p := usage.productivity()
+
+		position, label := "suffix", "-"+usage.Affix.Text
+
+		if usage.Affix.Prefix {
+
+			position, label = "prefix", usage.Affix.Text+"-"
+
+		}
+
+		fmt.Fprintf(writer, "# %s (%s): %d stems, %d tokens, %d hapax, productivity %.3f\n", label, position, len(usage.Words), tokens, hapax, p)
+
+		for _, entry := range classifier.RankByFrequency(usage.Words) {
+
+			fmt.Fprintf(writer, "%s\t%s\t%d\n", entry.Item, usage.Stems[entry.Item], entry.Frequency)
+
+		}
+
+		writer.WriteString("\n")
+
+	}
+
+	return writer.Flush()
+
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/jdkato/prose/v2"
+)
+
+// Token located in the original text, with the untokenized text (usually whitespace) preceding it
+
+type alignedToken struct {
+	Gap string
+
+	Token prose.Token
+}
+
+// Line of the original text split into aligned tokens
+
+type alignedLine struct {
+	Tokens []alignedToken
+
+	Trailing string
+}
+
+// Maps the token stream back onto the original lines so outputs can reproduce the text in situ
+
+func alignTokens(lines []string, tokens []prose.Token) []alignedLine {
+
+	aligned := make([]alignedLine, len(lines))
+
+	lineIndex, cursor := 0, 0
+
+	for _, tok := range tokens {
+
+		for i := lineIndex; i < len(lines); i++ {
+
+			start := 0
+
+			if i == lineIndex {
+
+				start = cursor
+
+			}
+
+			offset := strings.Index(lines[i][start:], tok.Text)
+
+			if offset < 0 {
+
+				continue
+
+			}
+
+			// Close the lines skipped over before placing the token
+
+			if i != lineIndex {
+
+				aligned[lineIndex].Trailing = lines[lineIndex][cursor:]
+
+				for skipped := lineIndex + 1; skipped < i; skipped++ {
+
+					aligned[skipped].Trailing = lines[skipped]
+
+				}
+
+				lineIndex, cursor = i, 0
+
+				start = 0
+
+			}
+
+			aligned[i].Tokens = append(aligned[i].Tokens, alignedToken{Gap: lines[i][start : start+offset], Token: tok})
+
+			cursor = start + offset + len(tok.Text)
+
+			break
+
+		}
+
+	}
+
+	if lineIndex < len(lines) {
+
+		aligned[lineIndex].Trailing = lines[lineIndex][cursor:]
+
+	}
+
+	for i := lineIndex + 1; i < len(lines); i++ {
+
+		aligned[i].Trailing = lines[i]
+
+	}
+
+	return aligned
+
+}
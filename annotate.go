@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bufio"
+
+	"fmt"
+
+	"github.com/jdkato/prose/v2"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+
+	"golang.org/x/text/encoding"
+)
+
+// Inline marker labels for the categories worth annotating in place
+
+var annotationLabels = map[string]string{
+
+	"ChineseAbbreviations": "缩略语",
+
+	"ChineseFinanceTerms": "金融术语",
+
+	"ChineseITTerms": "IT术语",
+
+	"ChineseIdioms": "成语",
+
+	"ChineseLegalTerms": "法律术语",
+
+	"ChineseMedicalTerms": "医学术语",
+
+	"ChineseSlang": "俚语",
+}
+
+// Returns the inline label for a token, if it belongs to an annotated category
+
+func annotationLabel(tok prose.Token, c *classifier.Classifier) (string, bool) {
+
+	if !classifier.IsChineseText(tok.Text) {
+
+		if classifier.IsAbbreviation(tok.Text) {
+
+			return annotationLabels["ChineseAbbreviations"], true
+
+		}
+
+		return "", false
+
+	}
+
+	for _, category := range c.TokenCategories(tok) {
+
+		if label, ok := annotationLabels[category]; ok {
+
+			return label, true
+
+		}
+
+	}
+
+	if classifier.IsAbbreviation(tok.Text) {
+
+		return annotationLabels["ChineseAbbreviations"], true
+
+	}
+
+	return "", false
+
+}
+
+// Writes the original text with 【label:item】 markers around annotated items, keeping the line structure intact
+
+func writeAnnotatedCopy(path string, lines []alignedLine, c *classifier.Classifier, enc encoding.Encoding) error {
+
+	file, err := createOutputFile(path, enc)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to create annotated copy: %v", err)
+
+	}
+
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+
+	for _, line := range lines {
+
+		for _, aligned := range line.Tokens {
+
+			writer.WriteString(aligned.Gap)
+
+			if label, ok := annotationLabel(aligned.Token, c); ok {
+
+				fmt.Fprintf(writer, "【%s:%s】", label, aligned.Token.Text)
+
+			} else {
+
+				writer.WriteString(aligned.Token.Text)
+
+			}
+
+		}
+
+		writer.WriteString(line.Trailing + "\n")
+
+	}
+
+	return writer.Flush()
+
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bufio"
+
+	"fmt"
+
+	"strconv"
+
+	"strings"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+
+	"golang.org/x/text/encoding"
+)
+
+// Frequency bands of characters by their rank in the embedded character table; characters ranked past the
+
+// last band, or not in the table, are rare
+
+var frequencyBands = []struct {
+	Name string
+
+	Rank int // Lowest rank in the band
+}{
+
+	{"top-500", 500},
+
+	{"top-1500", 1500},
+
+	{"top-3000", 3000},
+}
+
+// Band of characters outside every frequency band
+
+const rareBand = "rare"
+
+// Characters listed per band in the distribution summary
+
+const bandReportExamples = 10
+
+// Frequency band of a single character, such as top-500 for 的
+
+func characterBand(characters map[rune]characterInfo, character string) string {
+
+	runes := []rune(character)
+
+	if len(runes) != 1 {
+
+		return rareBand
+
+	}
+
+	info, ok := characters[runes[0]]
+
+	if !ok {
+
+		return rareBand
+
+	}
+
+	for _, band := range frequencyBands {
+
+		if info.Rank <= band.Rank {
+
+			return band.Name
+
+		}
+
+	}
+
+	return rareBand
+
+}
+
+// Writes ChineseFrequencyBands.txt: how many distinct characters and occurrences of the text fall in each
+
+// frequency band, from the most common characters to the rare ones
+
+func writeBandReport(path string, ranked []classifier.ItemFrequency, characters map[rune]characterInfo, enc encoding.Encoding) error {
+
+	distinct := make(map[string]int)
+
+	occurrences := make(map[string]int)
+
+	examples := make(map[string][]string)
+
+	total := 0
+
+	for _, entry := range ranked {
+
+		band := characterBand(characters, entry.Item)
+
+		distinct[band]++
+
+		occurrences[band] += entry.Frequency
+
+		total += entry.Frequency
+
+		if len(examples[band]) < bandReportExamples {
+
+			examples[band] = append(examples[band], entry.Item)
+
+		}
+
+	}
+
+	file, err := createOutputFile(path, enc)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to create frequency band report: %v", err)
+
+	}
+
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+
+	var rows [][]string
+
+	names := []string{}
+
+	for _, band := range frequencyBands {
+
+		names = append(names, band.Name)
+
+	}
+
+	for _, band := range append(names, rareBand) {
+
+		share := 0.0
+
+		if total > 0 {
+
+			share = float64(occurrences[band]) * 100 / float64(total)
+
+		}
+
+		rows = append(rows, []string{band, strconv.Itoa(distinct[band]), strconv.Itoa(occurrences[band]), strconv.FormatFloat(share, 'f', 1, 64) + "%", strings.Join(examples[band], " ")})
+
+	}
+
+	writeTable(writer, []string{"band", "characters", "occurrences", "share", "most frequent"}, rows, []bool{false, true, true, true, false})
+
+	return writer.Flush()
+
+}
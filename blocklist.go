@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bufio"
+
+	"fmt"
+
+	"sort"
+
+	"strconv"
+
+	"golang.org/x/text/encoding"
+)
+
+// Writes ChineseSuppressed.txt: the blocked items of the --blocklist found in the text and the occurrences
+
+// dropped from the outputs, most frequent first
+
+func writeSuppressedReport(path string, suppressed map[string]int, enc encoding.Encoding) error {
+
+	var items []string
+
+	total := 0
+
+	for item, count := range suppressed {
+
+		items = append(items, item)
+
+		total += count
+
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+
+		if suppressed[items[i]] != suppressed[items[j]] {
+
+			return suppressed[items[i]] > suppressed[items[j]]
+
+		}
+
+		return items[i] < items[j]
+
+	})
+
+	file, err := createOutputFile(path, enc)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to create suppressed item report: %v", err)
+
+	}
+
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+
+	var rows [][]string
+
+	for _, item := range items {
+
+		rows = append(rows, []string{item, strconv.Itoa(suppressed[item])})
+
+	}
+
+	writeTable(writer, []string{"blocked item", "occurrences"}, rows, []bool{false, true})
+
+	fmt.Fprintf(writer, "\nSuppressed occurrences: %d\n", total)
+
+	return writer.Flush()
+
+}
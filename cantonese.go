@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bufio"
+
+	"fmt"
+
+	"sort"
+
+	"strconv"
+
+	"github.com/jdkato/prose/v2"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+
+	"golang.org/x/text/encoding"
+)
+
+// Writes ChineseCantonese.txt: the words of the Cantonese lexicon found in the text with their Jyutping,
+// occurrences and glosses, most frequent first, and how much of the text is written in them
+
+func writeCantoneseReport(path string, tokens []prose.Token, enc encoding.Encoding) error {
+
+	counts := make(map[string]int)
+
+	words := 0
+
+	for _, token := range tokens {
+
+		if !classifier.IsChineseText(token.Text) {
+
+			continue
+
+		}
+
+		words++
+
+		if _, ok := classifier.LookupCantonese(token.Text); ok {
+
+			counts[token.Text]++
+
+		}
+
+	}
+
+	var found []string
+
+	total := 0
+
+	for word, count := range counts {
+
+		found = append(found, word)
+
+		total += count
+
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+
+		if counts[found[i]] != counts[found[j]] {
+
+			return counts[found[i]] > counts[found[j]]
+
+		}
+
+		return found[i] < found[j]
+
+	})
+
+	file, err := createOutputFile(path, enc)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to create Cantonese report: %v", err)
+
+	}
+
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+
+	var rows [][]string
+
+	for _, word := range found {
+
+		entry, _ := classifier.LookupCantonese(word)
+
+		rows = append(rows, []string{word, entry.Jyutping, strconv.Itoa(counts[word]), entry.Gloss})
+
+	}
+
+	writeTable(writer, []string{"word", "jyutping", "occurrences", "meaning"}, rows, []bool{false, false, true, false})
+
+	share := 0.0
+
+	if words > 0 {
+
+		share = float64(total) * 100 / float64(words)
+
+	}
+
+	fmt.Fprintf(writer, "\nCantonese words: %d of %d words (%.1f%%)\n", total, words, share)
+
+	return writer.Flush()
+
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bufio"
+
+	_ "embed"
+
+	"fmt"
+
+	"strings"
+
+	"unicode/utf8"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+)
+
+//go:embed dict/characters.txt
+
+var characterData string
+
+func init() {
+
+	classifier.RegisterResource("characters.txt", characterData)
+
+}
+
+// Frequency rank and pinyin readings of a character
+
+type characterInfo struct {
+	Rank int // 1 for the most frequent character
+
+	Readings []string // Numbered pinyin, most common reading first
+
+}
+
+// Loads the embedded character table, keyed by character
+
+func loadCharacterTable() (map[rune]characterInfo, error) {
+
+	table := make(map[rune]characterInfo)
+
+	scanner := bufio.NewScanner(strings.NewReader(classifier.ResourceText("characters.txt")))
+
+	rank := 0
+
+	for scanner.Scan() {
+
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+
+			continue
+
+		}
+
+		fields := strings.Fields(line)
+
+		if len(fields) != 2 || utf8.RuneCountInString(fields[0]) != 1 {
+
+			return nil, fmt.Errorf("invalid character table line %q", line)
+
+		}
+
+		r, _ := utf8.DecodeRuneInString(fields[0])
+
+		rank++
+
+		table[r] = characterInfo{Rank: rank, Readings: strings.Split(fields[1], "/")}
+
+	}
+
+	return table, scanner.Err()
+
+}
+
+// Tone marks for each vowel, indexed by tone number 1-4
+
+var toneMarkedVowels = map[rune][]rune{
+
+	'a': {'ā', 'á', 'ǎ', 'à'},
+
+	'e': {'ē', 'é', 'ě', 'è'},
+
+	'i': {'ī', 'í', 'ǐ', 'ì'},
+
+	'o': {'ō', 'ó', 'ǒ', 'ò'},
+
+	'u': {'ū', 'ú', 'ǔ', 'ù'},
+
+	'ü': {'ǖ', 'ǘ', 'ǚ', 'ǜ'},
+}
+
+// Converts numbered pinyin such as "lv4" or "zhong1" to tone-marked pinyin ("lǜ", "zhōng")
+
+func toneMarks(numbered string) string {
+
+	if numbered == "" {
+
+		return numbered
+
+	}
+
+	syllable := strings.ReplaceAll(numbered, "v", "ü")
+
+	last := syllable[len(syllable)-1]
+
+	if last < '0' || last > '5' {
+
+		return syllable
+
+	}
+
+	tone := int(last - '0')
+
+	syllable = syllable[:len(syllable)-1]
+
+	if tone == 5 || tone == 0 {
+
+		return syllable
+
+	}
+
+	runes := []rune(syllable)
+
+	// a and e always take the mark, o takes it in "ou", otherwise the last vowel does
+
+	target := -1
+
+	for i, r := range runes {
+
+		if r == 'a' || r == 'e' {
+
+			target = i
+
+			break
+
+		}
+
+	}
+
+	if target < 0 {
+
+		if i := strings.Index(syllable, "ou"); i >= 0 {
+
+			target = utf8.RuneCountInString(syllable[:i])
+
+		}
+
+	}
+
+	if target < 0 {
+
+		for i := len(runes) - 1; i >= 0; i-- {
+
+			if _, ok := toneMarkedVowels[runes[i]]; ok {
+
+				target = i
+
+				break
+
+			}
+
+		}
+
+	}
+
+	if target < 0 {
+
+		return syllable
+
+	}
+
+	runes[target] = toneMarkedVowels[runes[target]][tone-1]
+
+	return string(runes)
+
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bufio"
+
+	"fmt"
+
+	"regexp"
+
+	"sort"
+
+	"strconv"
+
+	"strings"
+
+	"unicode/utf8"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+
+	"golang.org/x/text/encoding"
+)
+
+// Chat line such as "张三: 你好" or "[12:01] 张三：你好", with an optional bracketed timestamp
+
+var chatLinePattern = regexp.MustCompile(`^\s*(?:\[[^\]]*\]\s*)?([^:：\[\]]{1,32}?)\s*[:：]\s*(.*)$`)
+
+// How many of each speaker's most frequent words the chat report lists
+
+const chatTopWords = 20
+
+// One message of a chat transcript
+
+type chatMessage struct {
+	Speaker string
+
+	Text string
+}
+
+// Splits a transcript into messages; lines without a speaker continue the previous message
+
+func parseChatLog(lines []string) []chatMessage {
+
+	var messages []chatMessage
+
+	for _, line := range lines {
+
+		if match := chatLinePattern.FindStringSubmatch(line); match != nil {
+
+			messages = append(messages, chatMessage{Speaker: strings.TrimSpace(match[1]), Text: match[2]})
+
+			continue
+
+		}
+
+		if len(messages) > 0 && strings.TrimSpace(line) != "" {
+
+			messages[len(messages)-1].Text += "\n" + line
+
+		}
+
+	}
+
+	return messages
+
+}
+
+// Message texts without the speaker names, so names do not end up in the global categories
+
+func chatMessageLines(messages []chatMessage) []string {
+
+	var lines []string
+
+	for _, message := range messages {
+
+		lines = append(lines, strings.Split(message.Text, "\n")...)
+
+	}
+
+	return lines
+
+}
+
+// Writes ChineseChatReport.txt: per speaker the message statistics, most frequent words and slang,
+
+// speakers with the most messages first
+
+func writeChatReport(path string, messages []chatMessage, c *classifier.Classifier, enc encoding.Encoding) error {
+
+	bySpeaker := make(map[string][]string)
+
+	var speakers []string
+
+	for _, message := range messages {
+
+		if _, ok := bySpeaker[message.Speaker]; !ok {
+
+			speakers = append(speakers, message.Speaker)
+
+		}
+
+		bySpeaker[message.Speaker] = append(bySpeaker[message.Speaker], message.Text)
+
+	}
+
+	sort.SliceStable(speakers, func(i, j int) bool {
+
+		return len(bySpeaker[speakers[i]]) > len(bySpeaker[speakers[j]])
+
+	})
+
+	file, err := createOutputFile(path, enc)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to create chat report: %v", err)
+
+	}
+
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+
+	fmt.Fprintf(writer, "# %d messages from %d speakers\n\n", len(messages), len(speakers))
+
+	for _, speaker := range speakers {
+
+		texts := bySpeaker[speaker]
+
+		characters := 0
+
+		for _, text := range texts {
+
+			characters += utf8.RuneCountInString(text)
+
+		}
+
+		result, err := c.Classify(strings.Join(texts, " "))
+
+		if err != nil {
+
+			return err
+
+		}
+
+		words := make(map[string]int)
+
+		for _, category := range vocabularyCategories {
+
+			for _, entry := range result.Ranked[category] {
+
+				if containsChinese(entry.Item) {
+
+					words[entry.Item] = entry.Frequency
+
+				}
+
+			}
+
+		}
+
+		slang := 0
+
+		for _, entry := range result.Ranked["ChineseSlang"] {
+
+			slang += entry.Frequency
+
+		}
+
+		fmt.Fprintf(writer, "# %s: %d messages, %d characters, %.1f characters per message, %d distinct words, %d slang uses\n",
+
+			speaker, len(texts), characters, float64(characters)/float64(len(texts)), len(words), slang)
+
+		var rows [][]string
+
+		for i, entry := range classifier.RankByFrequency(words) {
+
+			if i >= chatTopWords {
+
+				break
+
+			}
+
+			rows = append(rows, []string{entry.Item, strconv.Itoa(entry.Frequency)})
+
+		}
+
+		writeTable(writer, nil, rows, []bool{false, true})
+
+		if len(result.Ranked["ChineseSlang"]) > 0 {
+
+			writer.WriteString("slang:")
+
+			for _, entry := range result.Ranked["ChineseSlang"] {
+
+				fmt.Fprintf(writer, " %s (%d)", entry.Item, entry.Frequency)
+
+			}
+
+			writer.WriteString("\n")
+
+		}
+
+		writer.WriteString("\n")
+
+	}
+
+	return writer.Flush()
+
+}
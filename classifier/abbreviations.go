@@ -0,0 +1,126 @@
+package classifier
+
+import (
+	"regexp"
+
+	"sort"
+
+	"strings"
+
+	"unicode"
+
+	"unicode/utf8"
+)
+
+// Common Chinese abbreviations (缩略语) that cannot be recovered from POS tags alone
+
+var chineseAbbreviations = []string{
+
+	"高铁", "动车", "环保", "三农", "两会", "人大", "政协", "央行", "央视", "央企",
+
+	"国企", "民企", "外企", "高校", "高考", "中考", "考研", "公交", "北大", "清华",
+
+	"人大代表", "政协委员", "交警", "城管", "医保", "社保", "低保", "个税", "房贷", "车贷",
+
+	"网购", "网银", "网民", "彩电", "冰箱", "空调", "体检", "科研", "扶贫", "维稳",
+
+	"反腐", "双减", "四化", "五讲四美", "一带一路", "港澳", "港澳台", "沪深", "京津冀", "长三角",
+
+	"珠三角", "亚运", "奥运", "世博", "世卫", "安理会", "世贸", "欧盟", "北约", "东盟",
+
+	"上合", "金砖", "发改委", "国资委", "证监会", "卫健委", "社科院", "中科院", "工程院", "中小学",
+
+	"职教", "高职", "新冠", "非典", "彩票", "劳模", "知青", "外贸", "家电", "影视",
+
+	"三个代表", "四个全面", "四个自信", "四个意识", "两个维护", "两个确立", "两个一百年", "三严三实", "八荣八耻", "四项基本原则",
+
+	"三个有利于", "五位一体", "三去一降一补", "三好学生", "四有新人", "五讲四美三热爱", "两岸三通", "三来一补", "两学一做", "三大纪律",
+}
+
+// Latin acronyms such as GDP, AI, 5G written inside Chinese text
+
+var latinAcronymPattern = regexp.MustCompile(`(?:[0-9][A-Z]|[A-Z][A-Z0-9&])[A-Z0-9&]{0,6}`)
+
+// Extracts Chinese abbreviations and embedded Latin acronyms from raw text
+
+func extractAbbreviations(text string) []string {
+
+	var abbreviations []string
+
+	// Match dictionary entries longest first so 人大代表 is not also counted as 人大
+
+	entries := append([]string(nil), chineseAbbreviations...)
+
+	sort.Slice(entries, func(i, j int) bool {
+
+		return len([]rune(entries[i])) > len([]rune(entries[j]))
+
+	})
+
+	for _, entry := range entries {
+
+		count := strings.Count(text, entry)
+
+		for i := 0; i < count; i++ {
+
+			abbreviations = append(abbreviations, entry)
+
+		}
+
+		text = strings.ReplaceAll(text, entry, " ")
+
+	}
+
+	for _, loc := range latinAcronymPattern.FindAllStringIndex(text, -1) {
+
+		if isEmbeddedInChinese(text, loc[0], loc[1]) {
+
+			abbreviations = append(abbreviations, text[loc[0]:loc[1]])
+
+		}
+
+	}
+
+	return abbreviations
+
+}
+
+// IsAbbreviation reports whether a whole token is a known Chinese abbreviation or a Latin acronym
+
+func IsAbbreviation(text string) bool {
+
+	if matchesPhraseList(text, chineseAbbreviations) {
+
+		return true
+
+	}
+
+	return text != "" && latinAcronymPattern.FindString(text) == text
+
+}
+
+// Checks whether the span is a standalone Latin word directly adjacent to Chinese characters
+
+func isEmbeddedInChinese(text string, start, end int) bool {
+
+	// Only the neighbouring runes matter; at either end of the text they decode as utf8.RuneError
+
+	prev, _ := utf8.DecodeLastRuneInString(text[:start])
+
+	next, _ := utf8.DecodeRuneInString(text[end:])
+
+	if (unicode.IsLetter(prev) || unicode.IsDigit(prev)) && !unicode.Is(unicode.Han, prev) {
+
+		return false
+
+	}
+
+	if (unicode.IsLetter(next) || unicode.IsDigit(next)) && !unicode.Is(unicode.Han, next) {
+
+		return false
+
+	}
+
+	return unicode.Is(unicode.Han, prev) || unicode.Is(unicode.Han, next)
+
+}
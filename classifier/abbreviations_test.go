@@ -0,0 +1,49 @@
+package classifier
+
+import (
+	"slices"
+
+	"testing"
+)
+
+func TestExtractAbbreviations(t *testing.T) {
+
+	tests := []struct {
+		text string
+
+		want []string
+	}{
+		{"他有两个好朋友，十有八九会来", nil},
+
+		{"三个好学生都会来，两人有事。", nil},
+
+		{"四化建设和三个代表重要思想", []string{"三个代表", "四化"}},
+
+		{"学习五讲四美三热爱", []string{"五讲四美三热爱"}},
+
+		{"两会期间讨论了高铁", []string{"两会", "高铁"}},
+
+		{"今年GDP增长，5G网络普及", []string{"5G", "GDP"}},
+
+		{"GDP", nil},
+
+		{"他说 GDP grew", nil},
+
+		{"买了iPhoneXS手机", nil},
+	}
+
+	for _, test := range tests {
+
+		got := extractAbbreviations(test.text)
+
+		slices.Sort(got)
+
+		if !slices.Equal(got, test.want) {
+
+			t.Errorf("extractAbbreviations(%q) = %q, want %q", test.text, got, test.want)
+
+		}
+
+	}
+
+}
@@ -0,0 +1,51 @@
+package classifier
+
+import (
+	"strings"
+)
+
+// WithBlocklist drops junk tokens (OCR debris, boilerplate, watermark words) from every category. Unlike a
+
+// filter, the occurrences it drops are counted in Result.Suppressed, so a run can show what it left out.
+
+func WithBlocklist(words ...string) Option {
+
+	return func(c *config) error {
+
+		for _, word := range words {
+
+			if word = strings.TrimSpace(word); word != "" {
+
+				c.blocklist = append(c.blocklist, word)
+
+			}
+
+		}
+
+		return nil
+
+	}
+
+}
+
+// Counts the occurrences of blocked items dropped from each category and returns, per item, those of the
+
+// category it occurred most in, which for a word is its number of occurrences in the text
+
+func suppressedOccurrences(dropped map[string]map[string]int) map[string]int {
+
+	suppressed := make(map[string]int)
+
+	for _, items := range dropped {
+
+		for item, count := range items {
+
+			suppressed[item] = max(suppressed[item], count)
+
+		}
+
+	}
+
+	return suppressed
+
+}
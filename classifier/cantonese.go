@@ -0,0 +1,145 @@
+package classifier
+
+import (
+	_ "embed"
+
+	"fmt"
+
+	"strings"
+
+	"sync"
+)
+
+//go:embed dict/cantonese.txt
+
+var cantoneseLexicon string
+
+//go:embed dict/cantonese_slang.txt
+
+var cantoneseSlangLexicon string
+
+// CantoneseWord is an entry of the Cantonese lexicon
+
+type CantoneseWord struct {
+	Word string
+
+	Tag string // jieba tag, e.g. "u" for the particle 嘅
+
+	Jyutping string // One syllable per character, separated by spaces
+
+	Gloss string // English meaning, with the Mandarin word for it in parentheses
+}
+
+// Entries of the Cantonese lexicon, parsed once
+
+var (
+	cantoneseOnce sync.Once
+
+	cantoneseWords map[string]CantoneseWord
+)
+
+// Parses the embedded Cantonese lexicon on first use
+
+func cantoneseEntries() map[string]CantoneseWord {
+
+	cantoneseOnce.Do(func() {
+
+		cantoneseWords = make(map[string]CantoneseWord)
+
+		for _, line := range strings.Split(ResourceText("cantonese.txt"), "\n") {
+
+			fields := strings.Split(strings.TrimSpace(line), "\t")
+
+			if fields[0] == "" || strings.HasPrefix(fields[0], "#") {
+
+				continue
+
+			}
+
+			entry := CantoneseWord{Word: fields[0]}
+
+			if len(fields) > 1 {
+
+				entry.Tag = fields[1]
+
+			}
+
+			if len(fields) > 2 {
+
+				entry.Jyutping = fields[2]
+
+			}
+
+			if len(fields) > 3 {
+
+				entry.Gloss = fields[3]
+
+			}
+
+			cantoneseWords[entry.Word] = entry
+
+		}
+
+	})
+
+	return cantoneseWords
+
+}
+
+// LookupCantonese returns the lexicon entry of a distinctly Cantonese word such as 嘅 or 睇
+
+func LookupCantonese(word string) (CantoneseWord, bool) {
+
+	entry, ok := cantoneseEntries()[word]
+
+	return entry, ok
+
+}
+
+// CantoneseWords lists the entries of the Cantonese lexicon
+
+func CantoneseWords() []CantoneseWord {
+
+	var words []CantoneseWord
+
+	for _, entry := range cantoneseEntries() {
+
+		words = append(words, entry)
+
+	}
+
+	return words
+
+}
+
+// CantoneseSlang returns the terms of the embedded Cantonese slang lexicon (dict/cantonese_slang.txt)
+
+func CantoneseSlang() []SlangTerm {
+
+	terms, err := ParseSlang(strings.NewReader(ResourceText("cantonese_slang.txt")))
+
+	if err != nil {
+
+		panic(fmt.Sprintf("invalid embedded Cantonese slang lexicon: %v", err))
+
+	}
+
+	return terms
+
+}
+
+// WithCantonese analyzes written Cantonese, such as the posts of Hong Kong forums: the words of the Cantonese
+// lexicon (嘅, 唔, 咁, 靓) join the dictionary with their tags, so they are segmented and categorized as words,
+// and Cantonese slang (食花生, 巴打) joins the slang lexicon
+
+func WithCantonese() Option {
+
+	return func(c *config) error {
+
+		c.cantonese = true
+
+		return nil
+
+	}
+
+}
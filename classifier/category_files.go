@@ -0,0 +1,133 @@
+package classifier
+
+import (
+	"fmt"
+
+	"os"
+
+	"regexp"
+
+	"slices"
+
+	"sort"
+
+	"strings"
+)
+
+// Word list categories name their output files, so they are a letter followed by letters and digits
+
+var categoryNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*$`)
+
+// WithCategoryFiles adds a category of its own for the words of each word list file, keyed by category name,
+
+// such as "MedicalTerms": "medical.txt". Word lists have the WithDictionary format; like the terms of domain
+
+// dictionaries, their words are kept whole by segmentation and their categories belong to the domains stage.
+
+func WithCategoryFiles(files map[string]string) Option {
+
+	return func(c *config) error {
+
+		for category, path := range files {
+
+			if !categoryNamePattern.MatchString(category) {
+
+				return fmt.Errorf("invalid category name %q: use a letter followed by letters and digits", category)
+
+			}
+
+			if c.categoryFiles == nil {
+
+				c.categoryFiles = make(map[string]string)
+
+			}
+
+			c.categoryFiles[category] = path
+
+		}
+
+		return nil
+
+	}
+
+}
+
+// ParseCategoryFiles parses a comma-separated list of word list categories such as
+
+// "MedicalTerms=medical.txt,Brands=brands.txt"
+
+func ParseCategoryFiles(value string) (map[string]string, error) {
+
+	files := make(map[string]string)
+
+	for _, pair := range strings.Split(value, ",") {
+
+		if strings.TrimSpace(pair) == "" {
+
+			continue
+
+		}
+
+		category, path, ok := strings.Cut(pair, "=")
+
+		if !ok || strings.TrimSpace(path) == "" {
+
+			return nil, fmt.Errorf("invalid word list category %q (expected category=file)", strings.TrimSpace(pair))
+
+		}
+
+		files[strings.TrimSpace(category)] = strings.TrimSpace(path)
+
+	}
+
+	return files, nil
+
+}
+
+// Merges word list files into the segmentation dictionary, adding each one's terms to the domain categories
+
+// under its own category, which must be new
+
+func loadCategoryFiles(dict *dictionary, files map[string]string, domainTerms map[string]map[string]bool) error {
+
+	var categories []string
+
+	for category := range files {
+
+		categories = append(categories, category)
+
+	}
+
+	sort.Strings(categories)
+
+	for _, category := range categories {
+
+		if _, ok := domainTerms[category]; ok || slices.Contains(builtinCategories, category) {
+
+			return fmt.Errorf("word list category %q is already a category", category)
+
+		}
+
+		data, err := os.ReadFile(files[category])
+
+		if err != nil {
+
+			return fmt.Errorf("failed to read word list for %s: %v", category, err)
+
+		}
+
+		terms, err := loadTerms(dict, string(data), files[category])
+
+		if err != nil {
+
+			return fmt.Errorf("invalid word list %s: %v", files[category], err)
+
+		}
+
+		domainTerms[category] = terms
+
+	}
+
+	return nil
+
+}
@@ -0,0 +1,1502 @@
+// Package classifier segments Chinese text and sorts its words into linguistic categories
+
+// (nouns, verbs, idioms, slang, abbreviations, domain terms, ...) with their frequencies.
+
+//
+
+// The exported API (New, the With* options, Classifier, Result and ItemFrequency) follows
+
+// semantic versioning: within a major version only additive changes are made, so embedding
+
+// applications do not break on upgrades. New behavior is added as new options.
+
+//
+
+// A Classifier is immutable once New returns: dictionaries and the tagging model are loaded
+
+// once, and Classify keeps all per-call state local. One instance can therefore be shared by
+
+// any number of goroutines, e.g. the handlers of an HTTP server, without reloading anything.
+
+package classifier
+
+import (
+	"fmt"
+
+	"io"
+
+	"os"
+
+	"slices"
+
+	"sort"
+
+	"strconv"
+
+	"strings"
+
+	"sync"
+
+	"unicode"
+
+	"unicode/utf8"
+
+	"github.com/jdkato/prose/v2"
+)
+
+// Version is the semantic version of the classifier API
+
+const Version = "1.0.0"
+
+// Built-in categories, in addition to one category per enabled domain dictionary
+
+var builtinCategories = []string{
+
+	"ChineseCharacters", "ChineseAbbreviations", "ChineseAdjectives", "ChineseAdverbs",
+
+	"ChineseCommonPhrases", "ChineseFunctionWords", "ChineseIdioms", "ChineseNouns", "ChineseNounPhrases",
+
+	"ChineseSlang", "ChineseVerbPhrases", "ChineseVerbs", "ChineseOtherExpressions",
+
+	"ChinesePersons", "ChinesePlaces", "ChineseOrganizations", "ChineseMeasureWords", "ChineseLoanwords",
+
+	"ChineseNumbersDates", "ChineseOnomatopoeia", "ChineseProverbs", "ChineseXiehouyu",
+
+	"ChineseDialogue", "ChinesePoliteness",
+}
+
+// Tokenizer splits text into POS-tagged tokens before dictionary segmentation.
+
+// A Tokenizer passed to WithTokenizer must be safe for concurrent use if the Classifier is shared.
+
+type Tokenizer func(text string) ([]prose.Token, error)
+
+// Filter decides whether an item is kept in a category; returning false drops it
+
+type Filter func(category, item string) bool
+
+// Option configures a Classifier
+
+type Option func(*config) error
+
+// Settings collected from the options before the Classifier is built
+
+type config struct {
+	tokenizer Tokenizer
+
+	dictionaries []io.Reader
+
+	dictionaryFiles []string
+
+	domains []string
+
+	categoryFiles map[string]string // Word list category → file
+
+	idioms []string
+
+	slang []SlangTerm
+
+	categories []string
+
+	filters []Filter
+
+	stages []string
+
+	minConfidence float64
+
+	minLengths map[string]int // Category → fewest characters an item needs
+
+	segmentation string
+
+	segmenter Segmenter
+
+	tagCategories map[string]string
+
+	hmm bool
+
+	strictDictionary bool
+
+	lemmas bool
+
+	erhua string
+
+	blocklist []string
+
+	hooks Hooks
+
+	phraseGrammar string
+
+	scanMode string
+
+	cantonese bool
+}
+
+// WithTokenizer replaces the default prose tokenizer
+
+func WithTokenizer(tokenizer Tokenizer) Option {
+
+	return func(c *config) error {
+
+		if tokenizer == nil {
+
+			return fmt.Errorf("tokenizer must not be nil")
+
+		}
+
+		c.tokenizer = tokenizer
+
+		return nil
+
+	}
+
+}
+
+// WithDictionary adds words to the segmentation dictionary, one "word [frequency] [tag]" per line.
+
+// A word without a frequency gets one just high enough to be kept whole. User dictionaries take precedence
+
+// over domain dictionaries and the built-in resources: their frequency and tag replace those defined there,
+
+// and conflicting tags are listed by DictionaryConflicts.
+
+func WithDictionary(r io.Reader) Option {
+
+	return func(c *config) error {
+
+		c.dictionaries = append(c.dictionaries, r)
+
+		return nil
+
+	}
+
+}
+
+// WithDictionaryFiles adds user dictionary files in the WithDictionary format, e.g. product names or
+
+// terms that would otherwise be split into single characters; later files override earlier ones
+
+func WithDictionaryFiles(paths ...string) Option {
+
+	return func(c *config) error {
+
+		c.dictionaryFiles = append(c.dictionaryFiles, paths...)
+
+		return nil
+
+	}
+
+}
+
+// WithDomains enables bundled domain dictionaries (see AvailableDomains), each adding its own category
+
+func WithDomains(domains ...string) Option {
+
+	return func(c *config) error {
+
+		parsed, err := ParseDomains(strings.Join(domains, ","))
+
+		if err != nil {
+
+			return err
+
+		}
+
+		c.domains = append(c.domains, parsed...)
+
+		return nil
+
+	}
+
+}
+
+// WithIdioms replaces the embedded idiom dictionary (see DefaultIdioms)
+
+func WithIdioms(idioms ...string) Option {
+
+	return func(c *config) error {
+
+		c.idioms = append([]string{}, idioms...)
+
+		return nil
+
+	}
+
+}
+
+// WithSlang replaces the embedded slang lexicon with plain terms (see WithSlangLexicon)
+
+func WithSlang(slang ...string) Option {
+
+	return func(c *config) error {
+
+		c.slang = []SlangTerm{}
+
+		for _, term := range slang {
+
+			c.slang = append(c.slang, SlangTerm{Term: term})
+
+		}
+
+		return nil
+
+	}
+
+}
+
+// WithCategories restricts results to the given categories
+
+func WithCategories(categories ...string) Option {
+
+	return func(c *config) error {
+
+		c.categories = append(c.categories, categories...)
+
+		return nil
+
+	}
+
+}
+
+// WithFilter drops items the filter rejects; several filters must all accept an item
+
+func WithFilter(filter Filter) Option {
+
+	return func(c *config) error {
+
+		if filter == nil {
+
+			return fmt.Errorf("filter must not be nil")
+
+		}
+
+		c.filters = append(c.filters, filter)
+
+		return nil
+
+	}
+
+}
+
+// WithMinLengths drops items with fewer characters than the minimum of their category, such as 2 for
+
+// ChineseNouns to drop the single-character nouns segmentation leaves behind. Categories without a minimum,
+
+// such as ChineseCharacters, are unaffected.
+
+func WithMinLengths(lengths map[string]int) Option {
+
+	return func(c *config) error {
+
+		if c.minLengths == nil {
+
+			c.minLengths = make(map[string]int)
+
+		}
+
+		for category, length := range lengths {
+
+			if length < 1 {
+
+				return fmt.Errorf("minimum length of %s must be at least 1, got %d", category, length)
+
+			}
+
+			c.minLengths[category] = length
+
+		}
+
+		return nil
+
+	}
+
+}
+
+// ParseMinLengths parses comma-separated "category=length" pairs, such as "ChineseNouns=2,ChineseVerbs=2"
+
+func ParseMinLengths(value string) (map[string]int, error) {
+
+	lengths := make(map[string]int)
+
+	for _, pair := range strings.Split(value, ",") {
+
+		if strings.TrimSpace(pair) == "" {
+
+			continue
+
+		}
+
+		category, length, ok := strings.Cut(pair, "=")
+
+		parsed, err := strconv.Atoi(strings.TrimSpace(length))
+
+		if !ok || err != nil {
+
+			return nil, fmt.Errorf("invalid minimum length %q (expected category=length)", strings.TrimSpace(pair))
+
+		}
+
+		lengths[strings.TrimSpace(category)] = parsed
+
+	}
+
+	return lengths, nil
+
+}
+
+// WithStages runs only the given pipeline stages (see Stages and ProfileStages); by default all stages run.
+
+// Without the tagger stage, words are tagged from the dictionary alone unless WithTokenizer is given.
+
+func WithStages(names ...string) Option {
+
+	return func(c *config) error {
+
+		parsed, err := ParseStages(strings.Join(names, ","))
+
+		if err != nil {
+
+			return err
+
+		}
+
+		c.stages = parsed
+
+		return nil
+
+	}
+
+}
+
+// Classifier segments and categorizes Chinese text
+
+type Classifier struct {
+	tokenizer Tokenizer
+
+	dict *dictionary
+
+	domainTerms map[string]map[string]bool // Domain category → terms
+
+	domainCategories []string // Sorted keys of domainTerms
+
+	idioms idiomSet
+
+	proverbs sayingSet
+
+	xiehouyu sayingSet
+
+	slang map[string]SlangTerm // Lower-cased term → entry
+
+	categories []string
+
+	filters []Filter
+
+	stages map[string]bool
+
+	presegmented bool // The tokenizer already splits Chinese into dictionary words
+
+	minConfidence float64
+
+	minLengths map[string]int // Category → fewest characters an item needs
+
+	segmenter Segmenter
+
+	tagCategories map[string]string // POS tag → category
+
+	lemmas bool // Count words under their dictionary forms
+
+	erhua string // Treatment of erhua words: "merge", "keep" or "both"; empty leaves them as segmented
+
+	blocklist map[string]bool // Items dropped from every category, with their occurrences counted
+
+	hooks Hooks // Progress and item callbacks of embedding applications
+
+	phraseRules []phraseRule // Chunking grammar of the phrases stage
+
+	scanMode string // How overlapping idiom and slang matches are resolved: "greedy", "longest" or "all"
+
+	slangTerms idiomSet // Chinese slang terms, scanned in the text like idioms
+
+}
+
+// New loads the dictionaries and builds a Classifier from the options
+
+func New(opts ...Option) (*Classifier, error) {
+
+	cfg := config{
+
+		stages: stageNames(),
+
+		segmentation: "jieba",
+	}
+
+	for _, opt := range opts {
+
+		if err := opt(&cfg); err != nil {
+
+			return nil, fmt.Errorf("invalid classifier option: %v", err)
+
+		}
+
+	}
+
+	if err := cfg.checkStrictDictionary(); err != nil {
+
+		return nil, err
+
+	}
+
+	if cfg.hmm && cfg.segmenter != nil {
+
+		return nil, fmt.Errorf("HMM unknown-word recognition applies to the built-in segmenters only")
+
+	}
+
+	enabled := make(map[string]bool)
+
+	for _, name := range cfg.stages {
+
+		enabled[name] = true
+
+	}
+
+	presegmented := false
+
+	if cfg.tokenizer == nil {
+
+		if enabled["tagger"] {
+
+			cfg.tokenizer = newTaggerTokenizer()
+
+			// In strict-dictionary mode the tagger's own segmentation is redone with the dictionaries
+
+			presegmented = taggerSegments && !cfg.strictDictionary
+
+		} else {
+
+			cfg.tokenizer = plainTokenizer
+
+		}
+
+	}
+
+	dict, err := newDictionary()
+
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to load segmentation dictionary: %v", err)
+
+	}
+
+	// Domain dictionaries improve segmentation of specialized terms and get their own categories
+
+	domainTerms, err := loadDomains(dict, cfg.domains)
+
+	if err != nil {
+
+		return nil, err
+
+	}
+
+	if err := loadCategoryFiles(dict, cfg.categoryFiles, domainTerms); err != nil {
+
+		return nil, err
+
+	}
+
+	for i, r := range cfg.dictionaries {
+
+		if err := dict.load(r, fmt.Sprintf("dictionary %d", i+1), userLayer); err != nil {
+
+			return nil, fmt.Errorf("failed to load dictionary: %v", err)
+
+		}
+
+	}
+
+	for _, path := range cfg.dictionaryFiles {
+
+		if err := loadDictionaryFile(dict, path); err != nil {
+
+			return nil, err
+
+		}
+
+	}
+
+	if cfg.segmenter == nil {
+
+		cfg.segmenter = builtinSegmenters[cfg.segmentation](dict)
+
+		if cfg.hmm {
+
+			cfg.segmenter = hmmSegmenter{base: cfg.segmenter, dict: dict}
+
+		}
+
+	}
+
+	tagCategories := make(map[string]string)
+
+	for tag, category := range defaultTagCategories {
+
+		tagCategories[tag] = category
+
+	}
+
+	for tag, category := range cfg.tagCategories {
+
+		if !slices.Contains(builtinCategories, category) {
+
+			return nil, fmt.Errorf("unknown category %q for tag %q", category, tag)
+
+		}
+
+		tagCategories[tag] = category
+
+	}
+
+	// Idioms join the dictionary so the segmenter keeps them whole
+
+	if cfg.idioms == nil {
+
+		cfg.idioms = DefaultIdioms()
+
+	}
+
+	for _, idiom := range cfg.idioms {
+
+		frequency, ok := defaultIdioms()[idiom]
+
+		if !ok {
+
+			frequency = dict.suggestFrequency(idiom)
+
+		}
+
+		dict.mergeWord(idiom, frequency, "i", "idioms.txt")
+
+	}
+
+	// Entity names join the dictionary so the segmenter keeps them whole and their tags identify them
+
+	if enabled["entities"] {
+
+		loadEntities(dict)
+
+	}
+
+	// Loanwords of the lexicon join the dictionary so transliterations are not split into their syllables
+
+	if enabled["loanwords"] {
+
+		for word := range loanwordEntries() {
+
+			dict.mergeWord(word, dict.suggestFrequency(word), "n", "loanwords.txt")
+
+		}
+
+	}
+
+	// So do onomatopoeia, tagged as such
+
+	if enabled["onomatopoeia"] {
+
+		for word := range onomatopoeiaEntries() {
+
+			dict.mergeWord(word, dict.suggestFrequency(word), "o", "onomatopoeia.txt")
+
+		}
+
+	}
+
+	// Slang terms join the dictionary too, so new internet slang is not split apart
+
+	if cfg.slang == nil {
+
+		cfg.slang = DefaultSlang()
+
+	}
+
+	// Written Cantonese adds its words and slang
+
+	if cfg.cantonese {
+
+		for _, entry := range cantoneseEntries() {
+
+			dict.mergeWord(entry.Word, dict.suggestFrequency(entry.Word), entry.Tag, "cantonese.txt")
+
+		}
+
+		cfg.slang = append(cfg.slang, CantoneseSlang()...)
+
+	}
+
+	slang := make(map[string]SlangTerm)
+
+	var chineseSlang []string
+
+	for _, entry := range cfg.slang {
+
+		slang[strings.ToLower(entry.Term)] = entry
+
+		dict.mergeWord(entry.Term, dict.suggestFrequency(entry.Term), "l", "slang.txt")
+
+		if IsChineseText(entry.Term) && !strings.ContainsAny(entry.Term, " -") && utf8.RuneCountInString(entry.Term) > 1 {
+
+			chineseSlang = append(chineseSlang, entry.Term)
+
+		}
+
+	}
+
+	if cfg.scanMode == "" {
+
+		cfg.scanMode = "greedy"
+
+	}
+
+	categories := append([]string{}, builtinCategories...)
+
+	for category := range domainTerms {
+
+		categories = append(categories, category)
+
+	}
+
+	for category := range cfg.minLengths {
+
+		if !slices.Contains(categories, category) {
+
+			return nil, fmt.Errorf("unknown category %q for minimum length", category)
+
+		}
+
+	}
+
+	if len(cfg.categories) > 0 {
+
+		known := make(map[string]bool)
+
+		for _, category := range categories {
+
+			known[category] = true
+
+		}
+
+		for _, category := range cfg.categories {
+
+			if !known[category] {
+
+				return nil, fmt.Errorf("unknown category %q", category)
+
+			}
+
+			if stage := categoryStage(category); !enabled[stage] {
+
+				return nil, fmt.Errorf("category %q needs the %s stage", category, stage)
+
+			}
+
+		}
+
+		categories = append([]string{}, cfg.categories...)
+
+	} else {
+
+		var kept []string
+
+		for _, category := range categories {
+
+			if enabled[categoryStage(category)] {
+
+				kept = append(kept, category)
+
+			}
+
+		}
+
+		categories = kept
+
+	}
+
+	sort.Strings(categories)
+
+	var domainCategories []string
+
+	for category := range domainTerms {
+
+		domainCategories = append(domainCategories, category)
+
+	}
+
+	sort.Strings(domainCategories)
+
+	if cfg.phraseGrammar == "" {
+
+		cfg.phraseGrammar = ResourceText("phrase_grammar.txt")
+
+	}
+
+	phraseRules, err := parsePhraseGrammar(cfg.phraseGrammar)
+
+	if err != nil {
+
+		return nil, fmt.Errorf("invalid phrase grammar: %v", err)
+
+	}
+
+	var blocklist map[string]bool
+
+	for _, word := range cfg.blocklist {
+
+		if blocklist == nil {
+
+			blocklist = make(map[string]bool)
+
+		}
+
+		blocklist[word] = true
+
+	}
+
+	return &Classifier{
+
+		tokenizer: cfg.tokenizer,
+
+		dict: dict,
+
+		domainTerms: domainTerms,
+
+		domainCategories: domainCategories,
+
+		idioms: newIdiomSet(cfg.idioms),
+
+		proverbs: newSayingSet("proverbs.txt", false),
+
+		xiehouyu: newSayingSet("xiehouyu.txt", true),
+
+		slang: slang,
+
+		categories: categories,
+
+		filters: cfg.filters,
+
+		stages: enabled,
+
+		presegmented: presegmented,
+
+		minConfidence: cfg.minConfidence,
+
+		minLengths: cfg.minLengths,
+
+		segmenter: cfg.segmenter,
+
+		tagCategories: tagCategories,
+
+		lemmas: cfg.lemmas,
+
+		erhua: cfg.erhua,
+
+		blocklist: blocklist,
+
+		hooks: cfg.hooks,
+
+		phraseRules: phraseRules,
+
+		scanMode: cfg.scanMode,
+
+		slangTerms: newIdiomSet(chineseSlang),
+	}, nil
+
+}
+
+// Tokenizer of the tagger stage. Builds with the gojieba tag replace it with jieba, which also segments
+
+// Chinese itself (taggerSegments), so its words are not split again with the built-in dictionary.
+
+var (
+	newTaggerTokenizer = newProseTokenizer
+
+	taggerSegments = false
+)
+
+// Loads one user dictionary file
+
+func loadDictionaryFile(dict *dictionary, path string) error {
+
+	file, err := os.Open(path)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to open dictionary: %v", err)
+
+	}
+
+	defer file.Close()
+
+	if err := dict.load(file, path, userLayer); err != nil {
+
+		return fmt.Errorf("failed to load dictionary %s: %v", path, err)
+
+	}
+
+	return nil
+
+}
+
+// Default tokenizer backed by the prose NLP library. The model is loaded once, on first use, and only
+
+// read while tagging; sentence segmentation and entity extraction are skipped as nothing uses them.
+
+func newProseTokenizer() Tokenizer {
+
+	var once sync.Once
+
+	var model *prose.Model
+
+	return func(text string) ([]prose.Token, error) {
+
+		once.Do(func() {
+
+			model = prose.ModelFromData("cwClassifier")
+
+		})
+
+		doc, err := prose.NewDocument(text, prose.UsingModel(model), prose.WithSegmentation(false), prose.WithExtraction(false))
+
+		if err != nil {
+
+			return nil, fmt.Errorf("error creating Prose document: %v", err)
+
+		}
+
+		return doc.Tokens(), nil
+
+	}
+
+}
+
+// Tokenizer used without the tagger stage: whitespace-separated runs, tagged later from the dictionary
+
+func plainTokenizer(text string) ([]prose.Token, error) {
+
+	var tokens []prose.Token
+
+	for _, run := range splitHanRuns(text) {
+
+		tokens = append(tokens, prose.Token{Text: run})
+
+	}
+
+	return tokens, nil
+
+}
+
+// Segment splits text into words with the segmenter alone, without POS tagging. Runs of non-Chinese
+
+// text are kept whole; whitespace is dropped. If a custom segmenter fails, the dictionary segmentation is used.
+
+func (c *Classifier) Segment(text string) []string {
+
+	runs := splitHanRuns(text)
+
+	var han []string
+
+	for _, run := range runs {
+
+		if unicode.Is(unicode.Han, []rune(run)[0]) {
+
+			han = append(han, run)
+
+		}
+
+	}
+
+	segmented, err := segmentRuns(c.segmenter, han)
+
+	if err != nil {
+
+		segmented, _ = dagSegmenter{c.dict}.Segment(han)
+
+	}
+
+	var words []string
+
+	for _, run := range runs {
+
+		if unicode.Is(unicode.Han, []rune(run)[0]) {
+
+			words = append(words, segmented[0]...)
+
+			segmented = segmented[1:]
+
+		} else {
+
+			words = append(words, run)
+
+		}
+
+	}
+
+	return words
+
+}
+
+// Decompose splits a multi-character word into its most probable dictionary morphemes, down to single
+
+// characters where no longer sub-word fits. Words of one character have no decomposition.
+
+func (c *Classifier) Decompose(word string) []string {
+
+	if len([]rune(word)) < 2 || !IsChineseText(word) {
+
+		return nil
+
+	}
+
+	return c.dict.decompose(word)
+
+}
+
+// Stages lists the enabled pipeline stages in the order they run
+
+func (c *Classifier) Stages() []string {
+
+	var names []string
+
+	for _, stage := range stages {
+
+		if c.stages[stage.Name] {
+
+			names = append(names, stage.Name)
+
+		}
+
+	}
+
+	return names
+
+}
+
+// DictionaryConflicts lists the words that dictionaries of different sources tag differently, in the order
+
+// they were loaded, with the definition that precedence kept
+
+func (c *Classifier) DictionaryConflicts() []DictionaryConflict {
+
+	return append([]DictionaryConflict{}, c.dict.conflicts...)
+
+}
+
+// Categories lists the categories this Classifier reports, sorted by name
+
+func (c *Classifier) Categories() []string {
+
+	return append([]string{}, c.categories...)
+
+}
+
+// Result of classifying one text
+
+type Result struct {
+	Tokens []prose.Token // Segmented tokens in text order
+
+	Items map[string][]string // Category → item occurrences in text order
+
+	Ranked map[string][]ItemFrequency // Category → distinct items, most frequent first
+
+	Sentences []Sentence // Sentences in text order with their token ranges
+
+	Forms map[string]map[string]map[string]int // With WithLemmas or WithErhua, category → item → surface form → occurrences, for items written in other forms than their own
+
+	Quotes []Quote // With the dialogue stage, quoted utterances in text order
+
+	Suppressed map[string]int // With WithBlocklist, blocked item → occurrences dropped
+
+}
+
+// Classify splits the text into sentences, segments them and sorts their Chinese words into categories.
+
+// Multi-token items such as phrases and names never cross a sentence boundary.
+
+func (c *Classifier) Classify(text string) (*Result, error) {
+
+	sentenceTexts := SplitSentences(text)
+
+	rawTokens, lengths, err := c.tokenizeSentences(sentenceTexts)
+
+	if err != nil {
+
+		return nil, err
+
+	}
+
+	// Split runs of Chinese characters into dictionary words, resolving ambiguous segmentations
+
+	tokens := rawTokens
+
+	if !c.presegmented {
+
+		tokens, err = segmentTokens(rawTokens, c.dict, c.segmenter)
+
+		if err != nil {
+
+			return nil, err
+
+		}
+
+	}
+
+	sentences := sentenceSpans(sentenceTexts, lengths, tokens)
+
+	if c.hooks.OnSentence != nil {
+
+		for _, sentence := range sentences {
+
+			c.hooks.OnSentence(sentence)
+
+		}
+
+	}
+
+	items := make(map[string][]string)
+
+	forms := make(map[string]map[string]map[string]int)
+
+	// Numbers, dates and onomatopoeia span several tokens; those tokens are kept out of the word categories
+
+	claimed := make([]bool, len(tokens))
+
+	claim := func(sentence Sentence, covered []bool) {
+
+		for k, ok := range covered {
+
+			claimed[sentence.Start+k] = claimed[sentence.Start+k] || ok
+
+		}
+
+	}
+
+	if c.stages["numbers"] {
+
+		for _, sentence := range sentences {
+
+			expressions, covered := recognizeNumbers(tokens[sentence.Start:sentence.End])
+
+			items["ChineseNumbersDates"] = append(items["ChineseNumbersDates"], expressions...)
+
+			claim(sentence, covered)
+
+		}
+
+	}
+
+	if c.stages["onomatopoeia"] {
+
+		for _, sentence := range sentences {
+
+			words, covered := recognizeOnomatopoeia(tokens[sentence.Start:sentence.End])
+
+			items["ChineseOnomatopoeia"] = append(items["ChineseOnomatopoeia"], words...)
+
+			claim(sentence, covered)
+
+		}
+
+	}
+
+	// Extracting and categorizing tokens
+
+	for i := 0; i < len(tokens); i++ {
+
+		tok := tokens[i]
+
+		text := tok.Text
+
+		if IsChineseText(text) && claimed[i] {
+
+			if c.stages["characters"] {
+
+				items["ChineseCharacters"] = append(items["ChineseCharacters"], extractChineseCharacters(text)...)
+
+			}
+
+		} else if IsChineseText(text) {
+
+			// Words are counted under their dictionary form, which may span several tokens
+
+			span := lemmaSpan{Lemma: text, Surface: text, Length: 1}
+
+			if c.lemmas {
+
+				span = c.lemmaAt(tokens, i)
+
+			} else if c.erhua != "" {
+
+				if erhua, ok := c.erhuaAt(tokens, i); ok {
+
+					span = erhua
+
+				}
+
+			}
+
+			// Extract individual characters
+
+			if c.stages["characters"] {
+
+				for _, spanned := range tokens[i : i+span.Length] {
+
+					items["ChineseCharacters"] = append(items["ChineseCharacters"], extractChineseCharacters(spanned.Text)...)
+
+				}
+
+			}
+
+			for _, lemma := range []string{span.Lemma, span.Also} {
+
+				if lemma == "" {
+
+					continue
+
+				}
+
+				word := tok
+
+				word.Text = lemma
+
+				for _, category := range c.TokenCategories(word) {
+
+					items[category] = append(items[category], lemma)
+
+					if forms[category] == nil {
+
+						forms[category] = make(map[string]map[string]int)
+
+					}
+
+					if forms[category][lemma] == nil {
+
+						forms[category][lemma] = make(map[string]int)
+
+					}
+
+					forms[category][lemma][span.Surface]++
+
+				}
+
+			}
+
+			i += span.Length - 1
+
+		} else if c.stages["slang"] {
+
+			// Latin and digit slang such as yyds or 996; the token may still carry full-width punctuation
+
+			for _, word := range strings.FieldsFunc(text, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+
+				if c.slang[strings.ToLower(word)].Term != "" {
+
+					items["ChineseSlang"] = append(items["ChineseSlang"], word)
+
+				}
+
+			}
+
+		}
+
+	}
+
+	// Names may span several tokens, such as a place before an organization suffix
+
+	if c.stages["entities"] {
+
+		for _, sentence := range sentences {
+
+			for category, names := range c.recognizeEntities(tokens[sentence.Start:sentence.End]) {
+
+				items[category] = append(items[category], names...)
+
+			}
+
+		}
+
+	}
+
+	// Measure words count only after a numeral or demonstrative, which may be a separate token
+
+	if c.stages["measure-words"] {
+
+		for _, sentence := range sentences {
+
+			items["ChineseMeasureWords"] = append(items["ChineseMeasureWords"], recognizeMeasureWords(tokens[sentence.Start:sentence.End])...)
+
+		}
+
+	}
+
+	// Idioms are matched in the raw text, since one may span several tokens
+
+	if c.stages["idioms"] {
+
+		items["ChineseIdioms"] = c.idioms.find(text, c.scanMode)
+
+	}
+
+	// So is Chinese slang, by the same scanning mode; Latin and digit slang such as yyds comes from the tokens
+
+	if c.stages["slang"] {
+
+		var tokenSlang []string
+
+		for _, item := range items["ChineseSlang"] {
+
+			if !c.slangTerms.idioms[item] {
+
+				tokenSlang = append(tokenSlang, item)
+
+			}
+
+		}
+
+		items["ChineseSlang"] = append(c.slangTerms.find(text, c.scanMode), tokenSlang...)
+
+	}
+
+	// Politeness markers such as 麻烦您 span tokens too
+
+	if c.stages["politeness"] {
+
+		items["ChinesePoliteness"] = findPolitenessMarkers(text)
+
+	}
+
+	// Proverbs and xiehouyu are longer still, and punctuation often separates their parts
+
+	if c.stages["sayings"] {
+
+		items["ChineseProverbs"] = c.proverbs.find(text)
+
+		items["ChineseXiehouyu"] = c.xiehouyu.find(text)
+
+	}
+
+	// Extract abbreviations and acronyms from the raw text, since they often span token boundaries
+
+	if c.stages["abbreviations"] {
+
+		items["ChineseAbbreviations"] = extractAbbreviations(text)
+
+	}
+
+	// Extract phrases sentence by sentence
+
+	if c.stages["phrases"] {
+
+		for _, sentence := range sentences {
+
+			for category, phrases := range extractPhrases(tokens[sentence.Start:sentence.End], c.phraseRules) {
+
+				items[category] = append(items[category], phrases...)
+
+			}
+
+		}
+
+	}
+
+	result := &Result{Tokens: tokens, Items: make(map[string][]string), Ranked: make(map[string][]ItemFrequency), Sentences: sentences}
+
+	// Quoted speech may span several sentences, so it is found in the raw text
+
+	if c.stages["dialogue"] {
+
+		result.Quotes = ExtractQuotes(text)
+
+		for _, quote := range result.Quotes {
+
+			items["ChineseDialogue"] = append(items["ChineseDialogue"], quote.Text)
+
+		}
+
+	}
+
+	if c.lemmas || c.erhua != "" {
+
+		result.Forms = make(map[string]map[string]map[string]int)
+
+	}
+
+	dropped := make(map[string]map[string]int)
+
+	for _, category := range c.categories {
+
+		for _, item := range items[category] {
+
+			if c.blocklist[item] {
+
+				if dropped[category] == nil {
+
+					dropped[category] = make(map[string]int)
+
+				}
+
+				dropped[category][item]++
+
+				continue
+
+			}
+
+			if c.keep(category, item) {
+
+				result.Items[category] = append(result.Items[category], item)
+
+				if c.hooks.OnItem != nil {
+
+					c.hooks.OnItem(category, item)
+
+				}
+
+			}
+
+		}
+
+		result.Ranked[category] = RankByFrequency(CountFrequencies(result.Items[category]))
+
+		if c.hooks.OnCategoryDone != nil {
+
+			c.hooks.OnCategoryDone(category, result.Ranked[category])
+
+		}
+
+		// Only items also written in other forms have a breakdown
+
+		for item, surfaces := range forms[category] {
+
+			if _, ok := surfaces[item]; (len(surfaces) > 1 || !ok) && result.Forms != nil && !c.blocklist[item] && c.keep(category, item) {
+
+				if result.Forms[category] == nil {
+
+					result.Forms[category] = make(map[string]map[string]int)
+
+				}
+
+				result.Forms[category][item] = surfaces
+
+			}
+
+		}
+
+	}
+
+	if c.blocklist != nil {
+
+		result.Suppressed = suppressedOccurrences(dropped)
+
+	}
+
+	return result, nil
+
+}
+
+// Applies the minimum confidence and the configured filters to one item
+
+func (c *Classifier) keep(category, item string) bool {
+
+	if c.minConfidence > 0 && c.Confidence(category, item) < c.minConfidence {
+
+		return false
+
+	}
+
+	if utf8.RuneCountInString(item) < c.minLengths[category] {
+
+		return false
+
+	}
+
+	for _, filter := range c.filters {
+
+		if !filter(category, item) {
+
+			return false
+
+		}
+
+	}
+
+	return true
+
+}
+
+// TokenCategories returns the categories a Chinese token belongs to, most specific first.
+
+// A token no enabled stage categorizes falls back to ChineseOtherExpressions.
+
+func (c *Classifier) TokenCategories(tok prose.Token) []string {
+
+	var categories []string
+
+	text := tok.Text
+
+	if c.stages["domains"] {
+
+		for _, category := range c.domainCategories {
+
+			if c.domainTerms[category][text] {
+
+				categories = append(categories, category)
+
+			}
+
+		}
+
+	}
+
+	if _, ok := functionWordTypes[text]; ok && c.stages["function-words"] {
+
+		categories = append(categories, "ChineseFunctionWords")
+
+	}
+
+	if c.stages["idioms"] && c.idioms.idioms[text] {
+
+		categories = append(categories, "ChineseIdioms")
+
+	}
+
+	if c.stages["slang"] && c.slang[strings.ToLower(text)].Term != "" {
+
+		categories = append(categories, "ChineseSlang")
+
+	}
+
+	if c.stages["loanwords"] && c.isLoanword(text) {
+
+		categories = append(categories, "ChineseLoanwords")
+
+	}
+
+	if !c.stages["pos"] {
+
+		if len(categories) == 0 {
+
+			categories = append(categories, "ChineseOtherExpressions")
+
+		}
+
+		return categories
+
+	}
+
+	// Function words, idioms and slang already have a category and need no fallback
+
+	if category := c.tagCategory(text, tok.Tag); category != "ChineseOtherExpressions" || len(categories) == 0 {
+
+		categories = append(categories, category)
+
+	}
+
+	return categories
+
+}
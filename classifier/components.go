@@ -0,0 +1,135 @@
+package classifier
+
+import (
+	_ "embed"
+
+	"strings"
+
+	"sync"
+)
+
+//go:embed dict/components.txt
+
+var componentTable string
+
+// Ideographic description characters, which describe how the components of a character are arranged
+
+const ideographicDescriptions = "⿰⿱⿲⿳⿴⿵⿶⿷⿸⿹⿺⿻"
+
+// Decomposition of each character of the component table, parsed once
+
+var (
+	componentsOnce sync.Once
+
+	decompositions map[rune]string
+)
+
+// Parses the embedded component table on first use
+
+func componentEntries() map[rune]string {
+
+	componentsOnce.Do(func() {
+
+		decompositions = make(map[rune]string)
+
+		for _, line := range strings.Split(ResourceText("components.txt"), "\n") {
+
+			character, sequence, ok := strings.Cut(strings.TrimSpace(line), "\t")
+
+			runes := []rune(character)
+
+			if !ok || len(runes) != 1 || sequence == "" {
+
+				continue
+
+			}
+
+			decompositions[runes[0]] = sequence
+
+		}
+
+	})
+
+	return decompositions
+
+}
+
+// Decompose returns the ideographic description sequence of a character one level deep, such as ⿱相心 for 想;
+
+// characters not composed of others are not found
+
+func Decompose(character rune) (string, bool) {
+
+	sequence, ok := componentEntries()[character]
+
+	return sequence, ok
+
+}
+
+// Components returns the direct components of a character in writing order, such as 相 and 心 for 想, or nil
+
+// when it is not composed of others
+
+func Components(character rune) []string {
+
+	sequence, ok := Decompose(character)
+
+	if !ok {
+
+		return nil
+
+	}
+
+	var components []string
+
+	for _, r := range sequence {
+
+		if !strings.ContainsRune(ideographicDescriptions, r) {
+
+			components = append(components, string(r))
+
+		}
+
+	}
+
+	return components
+
+}
+
+// AllComponents returns the components of a character at every level, such as 相, 木, 目 and 心 for 想, each
+
+// once and in writing order
+
+func AllComponents(character rune) []string {
+
+	var components []string
+
+	seen := make(map[string]bool)
+
+	var walk func(r rune)
+
+	walk = func(r rune) {
+
+		for _, component := range Components(r) {
+
+			if seen[component] {
+
+				continue
+
+			}
+
+			seen[component] = true
+
+			components = append(components, component)
+
+			walk([]rune(component)[0])
+
+		}
+
+	}
+
+	walk(character)
+
+	return components
+
+}
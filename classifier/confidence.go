@@ -0,0 +1,130 @@
+package classifier
+
+import (
+	"fmt"
+
+	"strings"
+)
+
+// Confidence of each way an item can be categorized, from exact lexicon matches down to fallbacks
+
+const (
+	confidenceLexicon = 1.0 // Listed in an idiom, slang, abbreviation, function word, measure word, loanword or domain lexicon
+
+	confidenceDictionaryTag = 0.9 // POS from the tag of a segmentation dictionary entry
+
+	confidenceTagger = 0.6 // POS guessed by the statistical tagger for a word without a dictionary tag
+
+	confidencePattern = 0.7 // Abbreviation, acronym or loanword matched by pattern only
+
+	confidenceChunk = 0.6 // Phrase chunked from POS tags
+
+	confidenceFallback = 0.3 // Uncategorized word in ChineseOtherExpressions
+
+)
+
+// WithMinConfidence drops items whose confidence (see Confidence) is below min
+
+func WithMinConfidence(min float64) Option {
+
+	return func(c *config) error {
+
+		if min < 0 || min > 1 {
+
+			return fmt.Errorf("minimum confidence must be between 0 and 1, got %g", min)
+
+		}
+
+		c.minConfidence = min
+
+		return nil
+
+	}
+
+}
+
+// Confidence scores how reliably an item belongs to a category, between 0 and 1, from how it was matched:
+
+// lexicon entries score highest, dictionary tags above tagger guesses, and fallbacks lowest
+
+func (c *Classifier) Confidence(category, item string) float64 {
+
+	switch category {
+
+	case "ChineseCharacters", "ChineseFunctionWords", "ChineseIdioms", "ChineseSlang", "ChineseMeasureWords", "ChineseProverbs", "ChineseXiehouyu", "ChineseDialogue", "ChinesePoliteness":
+
+		return confidenceLexicon
+
+	case "ChineseAbbreviations":
+
+		if matchesPhraseList(item, chineseAbbreviations) {
+
+			return confidenceLexicon
+
+		}
+
+		return confidencePattern
+
+	case "ChinesePersons", "ChinesePlaces", "ChineseOrganizations":
+
+		if entityTagCategories[c.dict.entries[item].Tag] == category {
+
+			return confidenceLexicon
+
+		}
+
+		return confidencePattern
+
+	case "ChineseLoanwords":
+
+		if _, ok := loanwordEntries()[item]; ok {
+
+			return confidenceLexicon
+
+		}
+
+		return confidencePattern
+
+	case "ChineseOnomatopoeia":
+
+		if inOnomatopoeiaLexicon(item) {
+
+			return confidenceLexicon
+
+		}
+
+		return confidencePattern
+
+	case "ChineseNumbersDates":
+
+		return confidencePattern
+
+	case "ChineseCommonPhrases", "ChineseNounPhrases", "ChineseVerbPhrases":
+
+		return confidenceChunk
+
+	case "ChineseOtherExpressions":
+
+		return confidenceFallback
+
+	case "ChineseNouns", "ChineseVerbs", "ChineseAdjectives", "ChineseAdverbs":
+
+		if entry, ok := c.dict.entries[item]; ok && jiebaToPennTags[entry.Tag] != "" {
+
+			return confidenceDictionaryTag
+
+		}
+
+		return confidenceTagger
+
+	}
+
+	if strings.HasPrefix(category, "Chinese") && strings.HasSuffix(category, "Terms") {
+
+		return confidenceLexicon
+
+	}
+
+	return confidenceTagger
+
+}
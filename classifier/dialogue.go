@@ -0,0 +1,162 @@
+package classifier
+
+import (
+	"strings"
+
+	"unicode"
+
+	"unicode/utf8"
+)
+
+// Quote is an utterance in quotation marks, with the speaker the narration around it names
+
+type Quote struct {
+	Text string
+
+	Speaker string // Empty when the narration names no speaker
+
+}
+
+// Closing mark of each opening quotation mark
+
+var quotationMarks = map[rune]rune{'“': '”', '「': '」', '『': '』'}
+
+// Verbs that introduce speech, in simplified and traditional script, longest first so 问道 is cut before 道
+
+var speechVerbs = []string{"回答道", "回答说", "说道", "问道", "答道", "喊道", "叫道", "笑道", "骂道", "叹道", "嚷道", "說道", "問道", "罵道", "嘆道", "回答", "说", "道", "问", "答", "喊", "叫", "嚷", "骂", "叹", "說", "問", "罵", "嘆"}
+
+// Words that follow the speaker before the speech verb, such as the manner in 老王笑着说 or the listener
+
+// in 老王对小李说
+
+var speakerEnds = []string{"笑着", "哭着", "大声", "小声", "低声", "轻声", "高声", "大聲", "小聲", "低聲", "輕聲", "高聲", "连忙", "連忙", "趕緊", "赶紧", "急忙", "一边", "突然", "忽然", "终于", "接着", "冷冷", "慢慢", "忙", "又", "也", "就", "便", "还", "却", "对", "向", "跟", "朝", "不"}
+
+// Marks that end the narration clause naming a speaker
+
+const clauseEnds = "。！？；，…!?;,\n"
+
+// ExtractQuotes finds the utterances in quotation marks (“…”, 「…」, 『…』) in text order. The speaker is taken
+
+// from the narration just before the quote (老王说：“…”) or, failing that, just after it (“…”老王说。).
+
+func ExtractQuotes(text string) []Quote {
+
+	var quotes []Quote
+
+	runes := []rune(text)
+
+	narrated := 0 // Start of the narration since the last quote
+
+	for i := 0; i < len(runes); i++ {
+
+		closing, ok := quotationMarks[runes[i]]
+
+		if !ok {
+
+			continue
+
+		}
+
+		end := i + 1
+
+		for end < len(runes) && runes[end] != closing {
+
+			end++
+
+		}
+
+		if end == len(runes) {
+
+			break
+
+		}
+
+		// A quote interrupted by narration (“好啊，”小李说) keeps no trailing comma
+
+		utterance := strings.TrimRight(strings.TrimSpace(string(runes[i+1:end])), "，,")
+
+		if utterance == "" {
+
+			i = end
+
+			continue
+
+		}
+
+		before := string(runes[narrated:i])
+
+		if cut := strings.LastIndexAny(strings.TrimRight(before, "：:，, "), clauseEnds); cut >= 0 {
+
+			before = before[cut:]
+
+		}
+
+		speaker := speakerOf(before)
+
+		if speaker == "" {
+
+			after := string(runes[end+1:])
+
+			if cut := strings.IndexFunc(after, func(r rune) bool { return strings.ContainsRune(clauseEnds, r) || quotationMarks[r] != 0 }); cut >= 0 {
+
+				after = after[:cut]
+
+			}
+
+			speaker = speakerOf(after)
+
+		}
+
+		quotes = append(quotes, Quote{Text: utterance, Speaker: speaker})
+
+		i = end
+
+		narrated = end + 1
+
+	}
+
+	return quotes
+
+}
+
+// Speaker named by a narration clause ending with a speech verb, such as 老王 of 老王笑着对小李说
+
+func speakerOf(clause string) string {
+
+	clause = strings.Trim(clause, "：:，, \t"+clauseEnds)
+
+	for _, verb := range speechVerbs {
+
+		if !strings.HasSuffix(clause, verb) {
+
+			continue
+
+		}
+
+		speaker := strings.TrimSuffix(clause, verb)
+
+		for _, word := range speakerEnds {
+
+			if cut := strings.Index(speaker, word); cut >= 0 {
+
+				speaker = speaker[:cut]
+
+			}
+
+		}
+
+		speaker = strings.TrimSpace(speaker)
+
+		if n := utf8.RuneCountInString(speaker); n == 0 || n > 6 || strings.IndexFunc(speaker, unicode.IsPunct) >= 0 {
+
+			return ""
+
+		}
+
+		return speaker
+
+	}
+
+	return ""
+
+}
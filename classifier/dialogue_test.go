@@ -0,0 +1,43 @@
+package classifier
+
+import (
+	"slices"
+
+	"testing"
+)
+
+func TestExtractQuotes(t *testing.T) {
+
+	tests := []struct {
+		text string
+
+		want []Quote
+	}{
+		{"老王说：“今天天气真好。”", []Quote{{"今天天气真好。", "老王"}}},
+
+		{"“你去哪儿？”小李问道。", []Quote{{"你去哪儿？", "小李"}}},
+
+		{"老王笑着说：“走吧。”", []Quote{{"走吧。", "老王"}}},
+
+		{"老王对小李说：「明天见。」", []Quote{{"明天见。", "老王"}}},
+
+		{"第一章 开始\n老王说：“你好。”", []Quote{{"你好。", "老王"}}},
+
+		{"他写下『再见』两个字。", []Quote{{"再见", ""}}},
+
+		{"老王说：“好。”小李说：“行。”", []Quote{{"好。", "老王"}, {"行。", "小李"}}},
+
+		{"没有引号的句子。", nil},
+	}
+
+	for _, test := range tests {
+
+		if got := ExtractQuotes(test.text); !slices.Equal(got, test.want) {
+
+			t.Errorf("ExtractQuotes(%q) = %v, want %v", test.text, got, test.want)
+
+		}
+
+	}
+
+}
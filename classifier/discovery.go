@@ -0,0 +1,252 @@
+package classifier
+
+import (
+	"math"
+
+	"sort"
+
+	"unicode"
+)
+
+// Limits of new-word discovery: candidate lengths in characters, and the least evidence a candidate needs
+
+const (
+	discoveryMinLength = 2
+
+	discoveryMaxLength = 4
+
+	discoveryMinCohesion = 1.0 // Natural-log PMI of the weakest split
+
+	discoveryMinEntropy = 0.5 // Natural-log entropy of the less varied side
+
+)
+
+// WordCandidate is a proposed out-of-dictionary word with the statistics it was found by
+
+type WordCandidate struct {
+	Word string
+
+	Frequency int
+
+	Cohesion float64 // Pointwise mutual information of the word's weakest split into two parts
+
+	LeftEntropy float64 // Entropy of the characters before the word
+
+	RightEntropy float64 // Entropy of the characters after the word
+
+	Score float64 // Cohesion × the smaller entropy
+
+}
+
+// Known reports whether a word is in the segmentation dictionaries
+
+func (c *Classifier) Known(word string) bool {
+
+	_, ok := c.dict.entries[word]
+
+	return ok
+
+}
+
+// Tag returns the part-of-speech tag the segmentation dictionaries give a word, e.g. "v" for 说
+
+func (c *Classifier) Tag(word string) (string, bool) {
+
+	entry, ok := c.dict.entries[word]
+
+	return entry.Tag, ok
+
+}
+
+// DiscoverWords proposes words missing from the dictionaries, unsupervised: character n-grams that occur
+
+// at least minFrequency times, hold together (high mutual information between their parts) and appear
+
+// in varied contexts (high boundary entropy on both sides). Candidates are ordered by score.
+
+func (c *Classifier) DiscoverWords(text string, minFrequency int) []WordCandidate {
+
+	var runs [][]rune
+
+	total := 0
+
+	for _, run := range splitHanRuns(text) {
+
+		runes := []rune(run)
+
+		if unicode.Is(unicode.Han, runes[0]) {
+
+			runs = append(runs, runes)
+
+			total += len(runes)
+
+		}
+
+	}
+
+	counts := make(map[string]int)
+
+	left := make(map[string]map[string]int)
+
+	right := make(map[string]map[string]int)
+
+	// Run boundaries are counted apart: punctuation frees the context, so each one is a distinct neighbor
+
+	leftBoundaries := make(map[string]int)
+
+	rightBoundaries := make(map[string]int)
+
+	addNeighbor := func(neighbors map[string]map[string]int, gram, neighbor string) {
+
+		if neighbors[gram] == nil {
+
+			neighbors[gram] = make(map[string]int)
+
+		}
+
+		neighbors[gram][neighbor]++
+
+	}
+
+	for _, runes := range runs {
+
+		for i := range runes {
+
+			for n := 1; n <= discoveryMaxLength && i+n <= len(runes); n++ {
+
+				gram := string(runes[i : i+n])
+
+				counts[gram]++
+
+				if n < discoveryMinLength {
+
+					continue
+
+				}
+
+				if i > 0 {
+
+					addNeighbor(left, gram, string(runes[i-1]))
+
+				} else {
+
+					leftBoundaries[gram]++
+
+				}
+
+				if i+n < len(runes) {
+
+					addNeighbor(right, gram, string(runes[i+n]))
+
+				} else {
+
+					rightBoundaries[gram]++
+
+				}
+
+			}
+
+		}
+
+	}
+
+	probability := func(gram string) float64 {
+
+		return float64(counts[gram]) / float64(total)
+
+	}
+
+	var candidates []WordCandidate
+
+	for gram, count := range counts {
+
+		runes := []rune(gram)
+
+		if len(runes) < discoveryMinLength || count < minFrequency || c.Known(gram) {
+
+			continue
+
+		}
+
+		cohesion := math.Inf(1)
+
+		for split := 1; split < len(runes); split++ {
+
+			pmi := math.Log(probability(gram) / (probability(string(runes[:split])) * probability(string(runes[split:]))))
+
+			cohesion = math.Min(cohesion, pmi)
+
+		}
+
+		leftEntropy, rightEntropy := entropy(left[gram], leftBoundaries[gram]), entropy(right[gram], rightBoundaries[gram])
+
+		if cohesion < discoveryMinCohesion || math.Min(leftEntropy, rightEntropy) < discoveryMinEntropy {
+
+			continue
+
+		}
+
+		candidates = append(candidates, WordCandidate{
+
+			Word: gram,
+
+			Frequency: count,
+
+			Cohesion: cohesion,
+
+			LeftEntropy: leftEntropy,
+
+			RightEntropy: rightEntropy,
+
+			Score: cohesion * math.Min(leftEntropy, rightEntropy),
+		})
+
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+
+		if candidates[i].Score != candidates[j].Score {
+
+			return candidates[i].Score > candidates[j].Score
+
+		}
+
+		return candidates[i].Word < candidates[j].Word
+
+	})
+
+	return candidates
+
+}
+
+// Shannon entropy of a neighbor distribution, each of the boundaries being a neighbor seen once
+
+func entropy(neighbors map[string]int, boundaries int) float64 {
+
+	total := boundaries
+
+	for _, count := range neighbors {
+
+		total += count
+
+	}
+
+	if total == 0 {
+
+		return 0
+
+	}
+
+	h := float64(boundaries) / float64(total) * math.Log(float64(total))
+
+	for _, count := range neighbors {
+
+		p := float64(count) / float64(total)
+
+		h -= p * math.Log(p)
+
+	}
+
+	return h
+
+}
@@ -0,0 +1,160 @@
+package classifier
+
+import (
+	"bufio"
+
+	"embed"
+
+	"fmt"
+
+	"sort"
+
+	"strconv"
+
+	"strings"
+)
+
+//go:embed dict/domains/*.txt
+
+var domainDictionaries embed.FS
+
+// Output category for the terms of each optional domain dictionary
+
+var domainCategories = map[string]string{
+
+	"finance": "ChineseFinanceTerms",
+
+	"it": "ChineseITTerms",
+
+	"legal": "ChineseLegalTerms",
+
+	"medical": "ChineseMedicalTerms",
+}
+
+// ParseDomains parses a comma-separated list such as "medical,it" into validated domain names
+
+func ParseDomains(value string) ([]string, error) {
+
+	var domains []string
+
+	for _, name := range strings.Split(value, ",") {
+
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		if name == "" {
+
+			continue
+
+		}
+
+		if _, ok := domainCategories[name]; !ok {
+
+			return nil, fmt.Errorf("unknown domain %q (available: %s)", name, strings.Join(AvailableDomains(), ", "))
+
+		}
+
+		domains = append(domains, name)
+
+	}
+
+	return domains, nil
+
+}
+
+// AvailableDomains lists the names of the bundled domain dictionaries
+
+func AvailableDomains() []string {
+
+	var names []string
+
+	for name := range domainCategories {
+
+		names = append(names, name)
+
+	}
+
+	sort.Strings(names)
+
+	return names
+
+}
+
+// Merges the selected domain dictionaries into the segmentation dictionary, overriding the built-in definitions of
+
+// their words, and returns each domain category's terms
+
+func loadDomains(dict *dictionary, domains []string) (map[string]map[string]bool, error) {
+
+	domainTerms := make(map[string]map[string]bool)
+
+	for _, name := range domains {
+
+		terms, err := loadTerms(dict, ResourceText("domains/"+name+".txt"), "domains/"+name+".txt")
+
+		if err != nil {
+
+			return nil, fmt.Errorf("invalid %s domain dictionary: %v", name, err)
+
+		}
+
+		domainTerms[domainCategories[name]] = terms
+
+	}
+
+	return domainTerms, nil
+
+}
+
+// Merges a term list of "word [frequency] [POS tag]" lines into the segmentation dictionary at the domain
+
+// layer and returns its terms
+
+func loadTerms(dict *dictionary, data, source string) (map[string]bool, error) {
+
+	terms := make(map[string]bool)
+
+	scanner := bufio.NewScanner(strings.NewReader(data))
+
+	for scanner.Scan() {
+
+		fields := strings.Fields(scanner.Text())
+
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+
+			continue
+
+		}
+
+		frequency := 1
+
+		if len(fields) > 1 {
+
+			var err error
+
+			frequency, err = strconv.Atoi(fields[1])
+
+			if err != nil {
+
+				return nil, fmt.Errorf("invalid frequency for %q: %v", fields[0], err)
+
+			}
+
+		}
+
+		tag := ""
+
+		if len(fields) > 2 {
+
+			tag = fields[2]
+
+		}
+
+		dict.define(fields[0], frequency, tag, source, domainLayer)
+
+		terms[fields[0]] = true
+
+	}
+
+	return terms, nil
+
+}
@@ -0,0 +1,253 @@
+package classifier
+
+import (
+	_ "embed"
+
+	"slices"
+
+	"strconv"
+
+	"strings"
+
+	"unicode"
+
+	"github.com/jdkato/prose/v2"
+)
+
+//go:embed dict/entities.txt
+
+var entityDictionary string
+
+// Categories of the entity dictionary tags
+
+var entityTagCategories = map[string]string{"nr": "ChinesePersons", "ns": "ChinesePlaces", "nt": "ChineseOrganizations"}
+
+// Common surnames that start personal names
+
+const chineseSurnames = "王李张刘陈杨黄赵吴周徐孙马朱胡郭何高林罗郑梁谢宋唐许韩冯邓曹彭曾肖田董袁潘于蒋蔡余杜叶程苏魏吕丁任沈姚卢姜崔钟谭陆汪范金石廖贾夏韦付方白邹孟熊秦邱江尹薛闫段雷侯龙史陶黎贺顾毛郝龚邵万钱严覃武戴莫孔向汤"
+
+// Titles and forms of address that follow a personal name
+
+var personTitles = []string{
+
+	"先生", "女士", "小姐", "夫人", "同志", "老师", "教授", "博士", "院士", "医生", "大夫",
+
+	"总统", "主席", "总理", "部长", "省长", "市长", "局长", "校长", "主任", "书记", "经理", "将军",
+}
+
+// Single-character suffixes that turn the word before them into a place name, as in 重庆市
+
+const placeSuffixes = "省市县区镇乡村州岛"
+
+// Words that end organization names, as in 腾讯公司
+
+var organizationSuffixes = []string{
+
+	"公司", "有限公司", "集团", "大学", "学院", "中学", "小学", "银行", "医院", "委员会", "研究所", "研究院",
+
+	"协会", "学会", "基金会", "政府", "法院", "检察院", "电视台", "出版社", "报社",
+}
+
+// Most words before an organization suffix that join the name
+
+const maxOrganizationPrefix = 3
+
+// Dictionary tags of words that may be part of a name before a place or organization suffix
+
+var nameTags = map[string]bool{"ns": true, "nz": true, "nt": true, "nr": true, "j": true, "n": true}
+
+// IsSurname reports whether a character is a common Chinese surname
+
+func IsSurname(r rune) bool {
+
+	return strings.ContainsRune(chineseSurnames, r)
+
+}
+
+// Merges the embedded entity dictionary. Words the dictionary already has keep a specific tag, so common
+
+// words the entity list mistags are not taken for names; a generic built-in noun tag gives way to the name tag.
+
+func loadEntities(dict *dictionary) {
+
+	for _, line := range strings.Split(ResourceText("entities.txt"), "\n") {
+
+		fields := strings.Fields(line)
+
+		if len(fields) != 3 || strings.HasPrefix(fields[0], "#") {
+
+			continue
+
+		}
+
+		frequency, err := strconv.Atoi(fields[1])
+
+		if err != nil {
+
+			continue
+
+		}
+
+		if existing, ok := dict.entries[fields[0]]; ok && existing.Tag == "n" && existing.layer == builtinLayer {
+
+			dict.addWord(fields[0], dictEntry{Frequency: max(existing.Frequency, frequency), Tag: fields[2], Source: "entities.txt"})
+
+			continue
+
+		}
+
+		dict.mergeWord(fields[0], frequency, fields[2], "entities.txt")
+
+	}
+
+}
+
+// Finds person, place and organization names among the tokens: dictionary names, words before a place or
+
+// organization suffix, and surnames or names before a title such as 先生
+
+func (c *Classifier) recognizeEntities(tokens []prose.Token) map[string][]string {
+
+	entities := make(map[string][]string)
+
+	for i, tok := range tokens {
+
+		text := tok.Text
+
+		if !IsChineseText(text) {
+
+			continue
+
+		}
+
+		if category, ok := entityTagCategories[c.dict.entries[text].Tag]; ok {
+
+			entities[category] = append(entities[category], text)
+
+			continue
+
+		}
+
+		switch {
+
+		case slices.Contains(organizationSuffixes, text):
+
+			if prefix := c.namePrefix(tokens, i, maxOrganizationPrefix); prefix != "" {
+
+				entities["ChineseOrganizations"] = append(entities["ChineseOrganizations"], prefix+text)
+
+			}
+
+		case len([]rune(text)) == 1 && strings.Contains(placeSuffixes, text):
+
+			if prefix := c.namePrefix(tokens, i, 1); prefix != "" {
+
+				entities["ChinesePlaces"] = append(entities["ChinesePlaces"], prefix+text)
+
+			}
+
+		case slices.Contains(personTitles, text):
+
+			if name := c.nameBeforeTitle(tokens, i); name != "" {
+
+				entities["ChinesePersons"] = append(entities["ChinesePersons"], name)
+
+			}
+
+		}
+
+	}
+
+	return entities
+
+}
+
+// Joins up to max words before tokens[i] that can be part of a name: words of two or more characters that
+
+// the dictionary lacks or tags as nouns or names
+
+func (c *Classifier) namePrefix(tokens []prose.Token, i, max int) string {
+
+	prefix := ""
+
+	for j := i - 1; j >= 0 && j >= i-max; j-- {
+
+		text := tokens[j].Text
+
+		entry, known := c.dict.entries[text]
+
+		if !IsChineseText(text) || len([]rune(text)) < 2 || known && !nameTags[entry.Tag] {
+
+			break
+
+		}
+
+		if _, ok := functionWordTypes[text]; ok {
+
+			break
+
+		}
+
+		prefix = text + prefix
+
+	}
+
+	return prefix
+
+}
+
+// Finds the name before a title at tokens[i]: a two- or three-character word starting with a surname, or a
+
+// surname followed by up to two single characters (张三先生). A bare surname keeps the title (王先生).
+
+func (c *Classifier) nameBeforeTitle(tokens []prose.Token, i int) string {
+
+	if i == 0 {
+
+		return ""
+
+	}
+
+	previous := []rune(tokens[i-1].Text)
+
+	if len(previous) >= 2 && len(previous) <= 3 && IsSurname(previous[0]) {
+
+		if entry, known := c.dict.entries[string(previous)]; !known || entry.Tag == "nr" {
+
+			return string(previous)
+
+		}
+
+	}
+
+	name := ""
+
+	for j := i - 1; j >= 0 && j >= i-3; j-- {
+
+		runes := []rune(tokens[j].Text)
+
+		if len(runes) != 1 || !unicode.Is(unicode.Han, runes[0]) {
+
+			return ""
+
+		}
+
+		name = tokens[j].Text + name
+
+		if IsSurname(runes[0]) {
+
+			if j == i-1 {
+
+				return name + tokens[i].Text
+
+			}
+
+			return name
+
+		}
+
+	}
+
+	return ""
+
+}
@@ -0,0 +1,118 @@
+package classifier
+
+import (
+	"fmt"
+
+	"slices"
+
+	"strings"
+
+	"github.com/jdkato/prose/v2"
+)
+
+// Treatments of erhua words such as 花儿: counted as the word without 儿, as a word of their own, or as both
+
+var erhuaModes = []string{"merge", "keep", "both"}
+
+// Words where 儿 means child or son rather than marking erhua, so 女儿 is never counted as 女
+
+var nonErhuaWords = map[string]bool{
+
+	"儿女": true, "女儿": true, "婴儿": true, "幼儿": true, "孤儿": true, "胎儿": true, "患儿": true, "少儿": true,
+
+	"男儿": true, "健儿": true, "宠儿": true, "育儿": true, "托儿": true, "弃儿": true, "孙儿": true, "侄儿": true,
+
+	"新生儿": true, "混血儿": true, "幸运儿": true, "低能儿": true,
+}
+
+// WithErhua sets how erhua words (花儿, 玩儿), whether segmented whole or as the word and a separate 儿, are
+
+// counted: "merge" counts 花儿 as 花, "keep" as the word 花儿, and "both" as 花儿 and as 花. Without it erhua
+
+// words are counted as segmented, which may leave a stray 儿.
+
+func WithErhua(mode string) Option {
+
+	return func(c *config) error {
+
+		mode = strings.ToLower(strings.TrimSpace(mode))
+
+		if !slices.Contains(erhuaModes, mode) {
+
+			return fmt.Errorf("unknown erhua mode %q (available: %s)", mode, strings.Join(erhuaModes, ", "))
+
+		}
+
+		c.erhua = mode
+
+		return nil
+
+	}
+
+}
+
+// Finds an erhua word starting at token i, written whole (花儿) or split (花 儿), and counts it as the erhua
+
+// mode says; without a mode it is merged
+
+func (c *Classifier) erhuaAt(tokens []prose.Token, i int) (lemmaSpan, bool) {
+
+	text := tokens[i].Text
+
+	runes := []rune(text)
+
+	var word, stem string
+
+	length := 1
+
+	// 儿 of a word such as 婴儿 that segmentation split off is joined back, but not merged
+
+	if i+1 < len(tokens) && tokens[i+1].Text == "儿" && nonErhuaWords[text+"儿"] {
+
+		return lemmaSpan{Lemma: text + "儿", Surface: text + "儿", Length: 2}, true
+
+	}
+
+	if i+1 < len(tokens) && tokens[i+1].Text == "儿" && !nonErhuaWords[text+"儿"] {
+
+		word, stem, length = text+"儿", text, 2
+
+	} else if len(runes) > 1 && runes[len(runes)-1] == '儿' && !nonErhuaWords[text] {
+
+		if _, ok := c.dict.entries[string(runes[:len(runes)-1])]; !ok {
+
+			return lemmaSpan{}, false
+
+		}
+
+		word, stem = text, string(runes[:len(runes)-1])
+
+	} else {
+
+		return lemmaSpan{}, false
+
+	}
+
+	switch c.erhua {
+
+	case "keep":
+
+		return lemmaSpan{Lemma: word, Surface: word, Length: length}, true
+
+	case "both":
+
+		return lemmaSpan{Lemma: word, Surface: word, Length: length, Also: stem}, true
+
+	}
+
+	return lemmaSpan{Lemma: stem, Surface: word, Length: length}, true
+
+}
+
+// ErhuaModes lists the erhua treatments WithErhua accepts
+
+func ErhuaModes() []string {
+
+	return append([]string{}, erhuaModes...)
+
+}
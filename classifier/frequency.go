@@ -0,0 +1,77 @@
+package classifier
+
+import (
+	"sort"
+)
+
+// ItemFrequency is an item paired with its number of occurrences
+
+type ItemFrequency struct {
+	Item string
+
+	Frequency int
+}
+
+// CountFrequencies counts appearances of items and stores them in a frequency map
+
+func CountFrequencies(content []string) map[string]int {
+
+	counts := make(map[string]int)
+
+	for _, item := range content {
+
+		capitalizedItem := CapitalizePhrase(item)
+
+		counts[capitalizedItem]++
+
+	}
+
+	return counts
+
+}
+
+// RankByFrequency converts a frequency map to a slice of items and frequencies, most frequent first.
+
+// Ties are ordered by item so that repeated runs give identical output.
+
+func RankByFrequency(counts map[string]int) []ItemFrequency {
+
+	var items []ItemFrequency
+
+	for item, freq := range counts {
+
+		items = append(items, ItemFrequency{Item: item, Frequency: freq})
+
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+
+		if items[i].Frequency != items[j].Frequency {
+
+			return items[i].Frequency > items[j].Frequency
+
+		}
+
+		return items[i].Item < items[j].Item
+
+	})
+
+	return items
+
+}
+
+// SortByFrequency converts a frequency map to a sorted slice (only items, sorted by frequency)
+
+func SortByFrequency(counts map[string]int) []string {
+
+	var sortedItems []string
+
+	for _, entry := range RankByFrequency(counts) {
+
+		sortedItems = append(sortedItems, entry.Item)
+
+	}
+
+	return sortedItems
+
+}
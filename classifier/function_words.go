@@ -0,0 +1,80 @@
+package classifier
+
+// Function words (虚词) and particles by type; they carry grammar rather than content and are key style indicators
+
+var functionWordTypes = map[string]string{}
+
+func init() {
+
+	types := map[string][]string{
+
+		"preposition": {
+
+			"在", "从", "对", "把", "被", "给", "向", "往", "比", "为", "为了", "关于", "对于", "通过",
+
+			"根据", "按照", "由", "自", "自从", "除了", "随着", "沿着", "朝", "离", "以", "于", "将", "让",
+
+			"喺", // Cantonese 在
+		},
+
+		"conjunction": {
+
+			"和", "与", "及", "以及", "而", "而且", "并且", "或", "或者", "还是", "但", "但是", "可是",
+
+			"然而", "因为", "所以", "因此", "如果", "虽然", "即使", "不但", "不仅", "只要", "只有",
+
+			"无论", "不管", "既然", "于是", "那么", "否则", "尽管", "并",
+		},
+
+		"aspect particle": {"了", "着", "过", "咗"},
+
+		"structural particle": {"的", "地", "得", "之", "所", "嘅"},
+
+		"modal particle": {"吗", "呢", "吧", "啊", "呀", "嘛", "啦", "哦", "哇", "么", "罢了", "而已"},
+
+		// Cantonese sentence-final particles
+
+		"cantonese particle": {"㗎", "喎", "咋", "啩", "囉", "嘞", "喇", "噃"},
+	}
+
+	for kind, words := range types {
+
+		for _, word := range words {
+
+			functionWordTypes[word] = kind
+
+		}
+
+	}
+
+}
+
+// WithFunctionWordStopwords treats the words of the stopword list (stopwords.txt, the function words unless
+// overridden) as stopwords: they are still counted in ChineseFunctionWords and ChineseCharacters but dropped
+// from every other category
+
+func WithFunctionWordStopwords() Option {
+
+	return WithFilter(func(category, item string) bool {
+
+		if category == "ChineseFunctionWords" || category == "ChineseCharacters" {
+
+			return true
+
+		}
+
+		return !stopwordSet()[item]
+
+	})
+
+}
+
+// FunctionWordType returns the type of a function word, e.g. "preposition" or "aspect particle"
+
+func FunctionWordType(word string) (string, bool) {
+
+	kind, ok := functionWordTypes[word]
+
+	return kind, ok
+
+}
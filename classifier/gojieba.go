@@ -0,0 +1,93 @@
+//go:build gojieba
+
+// Build with "go build -tags gojieba" to segment and tag with jieba (cppjieba, needs cgo and a C++ compiler)
+
+// instead of the prose tagger and the built-in dictionary. It is not the default: gojieba reads its
+
+// dictionaries from its module source directory at run time, so a tagged binary only runs where it was built.
+
+package classifier
+
+import (
+	"strings"
+
+	"sync"
+
+	"github.com/jdkato/prose/v2"
+
+	"github.com/yanyiwu/gojieba"
+)
+
+func init() {
+
+	newTaggerTokenizer = newJiebaTokenizer
+
+	taggerSegments = true
+
+}
+
+// Tokenizer backed by gojieba. Its dictionaries load once, on first use; afterwards jieba only reads them,
+
+// so the tokenizer is safe for concurrent use.
+
+func newJiebaTokenizer() Tokenizer {
+
+	var once sync.Once
+
+	var jieba *gojieba.Jieba
+
+	return func(text string) ([]prose.Token, error) {
+
+		once.Do(func() {
+
+			jieba = gojieba.NewJieba()
+
+		})
+
+		var tokens []prose.Token
+
+		for _, tagged := range jieba.Tag(text) {
+
+			word, tag := tagged, ""
+
+			if i := strings.LastIndex(tagged, "/"); i > 0 {
+
+				word, tag = tagged[:i], tagged[i+1:]
+
+			}
+
+			if strings.TrimSpace(word) == "" {
+
+				continue
+
+			}
+
+			tokens = append(tokens, prose.Token{Text: word, Tag: jiebaPennTag(word, tag)})
+
+		}
+
+		return tokens, nil
+
+	}
+
+}
+
+// Maps a jieba tag to the Penn tag the categories use; punctuation and unknown tags follow segmentTokens
+
+func jiebaPennTag(word, tag string) string {
+
+	if isPunctuation(word) {
+
+		return "."
+
+	}
+
+	if penn, ok := jiebaToPennTags[tag]; ok {
+
+		return penn
+
+	}
+
+	return "FW"
+
+}
@@ -0,0 +1,326 @@
+package classifier
+
+import (
+	_ "embed"
+
+	"fmt"
+
+	"math"
+
+	"strconv"
+
+	"strings"
+
+	"sync"
+)
+
+//go:embed dict/hmm_emit.txt
+
+var hmmEmissions string
+
+// States of the unknown-word HMM: begin, middle and end of a word, and single-character word
+
+const (
+	hmmBegin = iota
+
+	hmmMiddle
+
+	hmmEnd
+
+	hmmSingle
+)
+
+// Log probability standing in for impossible starts, transitions and emissions
+
+const hmmImpossible = -3.14e100
+
+// Start and transition log probabilities of jieba's finalseg model
+
+var (
+	hmmStart = [4]float64{-0.26268660809250016, hmmImpossible, hmmImpossible, -1.4652633398537678}
+
+	hmmTransitions = [4][4]float64{
+
+		hmmBegin: {hmmImpossible, -0.916290731874155, -0.510825623765990, hmmImpossible},
+
+		hmmMiddle: {hmmImpossible, -1.2603623820268226, -0.33344856811948514, hmmImpossible},
+
+		hmmEnd: {-0.5897149736854513, hmmImpossible, hmmImpossible, -0.8085250474669937},
+
+		hmmSingle: {-0.7211965654669841, hmmImpossible, hmmImpossible, -0.6658631448798212},
+	}
+)
+
+// Emission log probabilities per character, parsed once
+
+var (
+	hmmOnce sync.Once
+
+	hmmEmit map[rune][4]float64
+)
+
+// Parses the embedded emission table on first use
+
+func emissions() map[rune][4]float64 {
+
+	hmmOnce.Do(func() {
+
+		hmmEmit = make(map[rune][4]float64)
+
+		for _, line := range strings.Split(ResourceText("hmm_emit.txt"), "\n") {
+
+			fields := strings.Split(line, "\t")
+
+			if len(fields) != 5 || strings.HasPrefix(line, "#") {
+
+				continue
+
+			}
+
+			var probabilities [4]float64
+
+			for state, field := range fields[1:] {
+
+				probabilities[state] = hmmImpossible
+
+				if value, err := strconv.ParseFloat(field, 64); err == nil {
+
+					probabilities[state] = value
+
+				}
+
+			}
+
+			hmmEmit[[]rune(fields[0])[0]] = probabilities
+
+		}
+
+	})
+
+	return hmmEmit
+
+}
+
+// WithHMM enables or disables HMM recognition of unknown words: runs of single characters the dictionary
+
+// segmentation leaves are regrouped into words by jieba's BMES model, as in names such as 王小明.
+
+// Off by default; the result is deterministic either way.
+
+func WithHMM(enabled bool) Option {
+
+	return func(c *config) error {
+
+		c.hmm = enabled
+
+		return nil
+
+	}
+
+}
+
+// WithStrictDictionary segments with the dictionaries alone: no HMM, no external or tagger segmentation.
+
+// Use it for reproducible experiments, where only the dictionary files determine the words.
+
+func WithStrictDictionary() Option {
+
+	return func(c *config) error {
+
+		c.strictDictionary = true
+
+		return nil
+
+	}
+
+}
+
+// Checks that the segmentation options agree with strict-dictionary mode
+
+func (cfg *config) checkStrictDictionary() error {
+
+	if !cfg.strictDictionary {
+
+		return nil
+
+	}
+
+	if cfg.hmm {
+
+		return fmt.Errorf("HMM unknown-word recognition cannot be used in strict-dictionary mode")
+
+	}
+
+	if cfg.segmenter != nil {
+
+		return fmt.Errorf("custom segmenters cannot be used in strict-dictionary mode")
+
+	}
+
+	return nil
+
+}
+
+// Segments with a dictionary segmenter, then regroups runs of single characters the dictionary does not know
+
+// as a word into words with the HMM
+
+type hmmSegmenter struct {
+	base Segmenter
+
+	dict *dictionary
+}
+
+func (s hmmSegmenter) Segment(runs []string) ([][]string, error) {
+
+	segmented, err := s.base.Segment(runs)
+
+	if err != nil {
+
+		return nil, err
+
+	}
+
+	for i, words := range segmented {
+
+		var regrouped []string
+
+		var singles []rune
+
+		flush := func() {
+
+			if _, known := s.dict.entries[string(singles)]; len(singles) > 1 && !known {
+
+				regrouped = append(regrouped, hmmCut(singles)...)
+
+			} else {
+
+				for _, r := range singles {
+
+					regrouped = append(regrouped, string(r))
+
+				}
+
+			}
+
+			singles = nil
+
+		}
+
+		for _, word := range words {
+
+			if runes := []rune(word); len(runes) == 1 {
+
+				singles = append(singles, runes[0])
+
+				continue
+
+			}
+
+			flush()
+
+			regrouped = append(regrouped, word)
+
+		}
+
+		flush()
+
+		segmented[i] = regrouped
+
+	}
+
+	return segmented, nil
+
+}
+
+// Splits characters into words along the most probable BMES state sequence (Viterbi)
+
+func hmmCut(runes []rune) []string {
+
+	emit := emissions()
+
+	emission := func(r rune, state int) float64 {
+
+		if probabilities, ok := emit[r]; ok {
+
+			return probabilities[state]
+
+		}
+
+		return hmmImpossible
+
+	}
+
+	n := len(runes)
+
+	scores := make([][4]float64, n)
+
+	previous := make([][4]int, n)
+
+	for state := range 4 {
+
+		scores[0][state] = hmmStart[state] + emission(runes[0], state)
+
+	}
+
+	for t := 1; t < n; t++ {
+
+		for state := range 4 {
+
+			scores[t][state] = math.Inf(-1)
+
+			for from := range 4 {
+
+				if score := scores[t-1][from] + hmmTransitions[from][state]; score > scores[t][state] {
+
+					scores[t][state], previous[t][state] = score, from
+
+				}
+
+			}
+
+			scores[t][state] += emission(runes[t], state)
+
+		}
+
+	}
+
+	// A word must end the run
+
+	state := hmmEnd
+
+	if scores[n-1][hmmSingle] > scores[n-1][hmmEnd] {
+
+		state = hmmSingle
+
+	}
+
+	states := make([]int, n)
+
+	for t := n - 1; t >= 0; t-- {
+
+		states[t] = state
+
+		state = previous[t][state]
+
+	}
+
+	var words []string
+
+	start := 0
+
+	for t, state := range states {
+
+		if state == hmmEnd || state == hmmSingle || t == n-1 {
+
+			words = append(words, string(runes[start:t+1]))
+
+			start = t + 1
+
+		}
+
+	}
+
+	return words
+
+}
@@ -0,0 +1,34 @@
+package classifier
+
+// Hooks are called by Classify at fixed points of its work: OnSentence for every sentence once the whole text is
+
+// segmented, then, category by category in the order of Categories, OnItem for each item kept in the category
+
+// followed by OnCategoryDone. An embedding application can so stream sentences and finished categories into its
+
+// own UI, but the hooks do not report progress while the text is segmented. Any hook may be nil. The hooks of a
+
+// shared Classifier are called from every goroutine classifying with it and must then be safe for concurrent use.
+
+type Hooks struct {
+	OnSentence func(sentence Sentence) // Each sentence in text order, after the whole text is segmented and before any item
+
+	OnItem func(category, item string) // Each item kept in the category, including repetitions, in the order the stages found it
+
+	OnCategoryDone func(category string, ranked []ItemFrequency) // The category's items ranked, most frequent first, after its last OnItem
+
+}
+
+// WithHooks sets the callbacks Classify reports its sentences and items to
+
+func WithHooks(hooks Hooks) Option {
+
+	return func(c *config) error {
+
+		c.hooks = hooks
+
+		return nil
+
+	}
+
+}
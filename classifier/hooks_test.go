@@ -0,0 +1,86 @@
+package classifier
+
+import (
+	"fmt"
+
+	"slices"
+
+	"testing"
+)
+
+// Hooks fire as documented: every sentence first, then each category's items followed by its ranking
+
+func TestHooksOrder(t *testing.T) {
+
+	var calls []string
+
+	hooks := Hooks{
+
+		OnSentence: func(sentence Sentence) {
+
+			calls = append(calls, fmt.Sprintf("sentence %d %s", sentence.ID, sentence.Text))
+
+		},
+
+		OnItem: func(category, item string) {
+
+			calls = append(calls, "item "+category+" "+item)
+
+		},
+
+		OnCategoryDone: func(category string, ranked []ItemFrequency) {
+
+			calls = append(calls, fmt.Sprintf("done %s %d", category, len(ranked)))
+
+		},
+	}
+
+	c, err := New(WithHooks(hooks))
+
+	if err != nil {
+
+		t.Fatal(err)
+
+	}
+
+	result, err := c.Classify("老王坐高铁去北京。小李在车站等他。老王说：“好久不见。”")
+
+	if err != nil {
+
+		t.Fatal(err)
+
+	}
+
+	var want []string
+
+	for _, sentence := range result.Sentences {
+
+		want = append(want, fmt.Sprintf("sentence %d %s", sentence.ID, sentence.Text))
+
+	}
+
+	for _, category := range c.Categories() {
+
+		for _, item := range result.Items[category] {
+
+			want = append(want, "item "+category+" "+item)
+
+		}
+
+		want = append(want, fmt.Sprintf("done %s %d", category, len(result.Ranked[category])))
+
+	}
+
+	if len(result.Sentences) < 3 || len(result.Items) == 0 {
+
+		t.Fatalf("the text gave %d sentences and %d categories with items", len(result.Sentences), len(result.Items))
+
+	}
+
+	if !slices.Equal(calls, want) {
+
+		t.Errorf("hook calls\n%q\nwant\n%q", calls, want)
+
+	}
+
+}
@@ -0,0 +1,152 @@
+package classifier
+
+import (
+	_ "embed"
+
+	"strconv"
+
+	"strings"
+
+	"sync"
+
+	"unicode"
+)
+
+//go:embed dict/idioms.txt
+
+var idiomDictionary string
+
+// Idioms of the embedded dictionary with their frequencies, parsed once
+
+var (
+	idiomsOnce sync.Once
+
+	bundledIdioms map[string]int
+)
+
+// Parses the embedded idiom dictionary on first use
+
+func defaultIdioms() map[string]int {
+
+	idiomsOnce.Do(func() {
+
+		bundledIdioms = make(map[string]int)
+
+		for _, line := range strings.Split(ResourceText("idioms.txt"), "\n") {
+
+			fields := strings.Fields(line)
+
+			if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+
+				continue
+
+			}
+
+			frequency := 1
+
+			if len(fields) > 1 {
+
+				if parsed, err := strconv.Atoi(fields[1]); err == nil {
+
+					frequency = parsed
+
+				}
+
+			}
+
+			bundledIdioms[fields[0]] = frequency
+
+		}
+
+	})
+
+	return bundledIdioms
+
+}
+
+// DefaultIdioms lists the idioms of the embedded dictionary
+
+func DefaultIdioms() []string {
+
+	var idioms []string
+
+	for idiom := range defaultIdioms() {
+
+		idioms = append(idioms, idiom)
+
+	}
+
+	return idioms
+
+}
+
+// Set of idioms with the length of the longest, for matching in running text
+
+type idiomSet struct {
+	idioms map[string]bool
+
+	maxLen int
+}
+
+// Builds the set from a list of idioms
+
+func newIdiomSet(idioms []string) idiomSet {
+
+	set := idiomSet{idioms: make(map[string]bool)}
+
+	for _, idiom := range idioms {
+
+		set.idioms[idiom] = true
+
+		set.maxLen = max(set.maxLen, len([]rune(idiom)))
+
+	}
+
+	return set
+
+}
+
+// Finds the entries of the set in the text independently of segmentation, so entries split across tokens
+// are still found; overlapping matches are resolved by the scanning mode
+
+func (s idiomSet) find(text, mode string) []string {
+
+	var found []string
+
+	for _, run := range splitHanRuns(text) {
+
+		runes := []rune(run)
+
+		if !unicode.Is(unicode.Han, runes[0]) {
+
+			continue
+
+		}
+
+		var matches []scanMatch
+
+		for i := range runes {
+
+			for n := min(s.maxLen, len(runes)-i); n >= 2; n-- {
+
+				if s.idioms[string(runes[i:i+n])] {
+
+					matches = append(matches, scanMatch{Start: i, End: i + n})
+
+				}
+
+			}
+
+		}
+
+		for _, match := range resolveMatches(matches, mode) {
+
+			found = append(found, string(runes[match.Start:match.End]))
+
+		}
+
+	}
+
+	return found
+
+}
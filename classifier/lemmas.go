@@ -0,0 +1,126 @@
+package classifier
+
+import (
+	"github.com/jdkato/prose/v2"
+)
+
+// Aspect particles, recorded with the verb before them as one of its forms
+
+var aspectParticles = map[string]bool{"了": true, "过": true, "着": true}
+
+// WithLemmas counts inflected and variant forms under their dictionary form: reduplicated verbs and
+
+// adjectives (看看, 看一看, 研究研究, 干干净净) count once as 看, 研究 or 干净, erhua words (花儿) as WithErhua
+
+// says (as the word without 儿 unless set), and a verb followed by an aspect particle (看了) is recorded as a
+
+// form of the verb.
+
+// Result.Forms keeps the surface forms each item was written in.
+
+func WithLemmas() Option {
+
+	return func(c *config) error {
+
+		c.lemmas = true
+
+		return nil
+
+	}
+
+}
+
+// Dictionary form of the word written at a token, the way it was written and the number of tokens it spans
+
+type lemmaSpan struct {
+	Lemma string
+
+	Surface string
+
+	Length int
+
+	Also string // Another item the word counts as, such as 花 for 花儿 in erhua mode "both"
+
+}
+
+// Finds the dictionary form of the word starting at token i. Reduplications and erhua split over several
+
+// tokens by segmentation are joined again.
+
+func (c *Classifier) lemmaAt(tokens []prose.Token, i int) lemmaSpan {
+
+	text := tokens[i].Text
+
+	next := func(k int) string {
+
+		if i+k < len(tokens) {
+
+			return tokens[i+k].Text
+
+		}
+
+		return ""
+
+	}
+
+	category := c.tagCategory(text, tokens[i].Tag)
+
+	verb := category == "ChineseVerbs"
+
+	runes := []rune(text)
+
+	// 看一看, 看了看
+
+	if verb && len(runes) == 1 && (next(1) == "一" || next(1) == "了") && next(2) == text {
+
+		return lemmaSpan{Lemma: text, Surface: text + next(1) + text, Length: 3}
+
+	}
+
+	// 干 干净 净
+
+	if pair := []rune(next(1)); len(runes) == 1 && len(pair) == 2 && pair[0] == runes[0] && next(2) == string(pair[1]) {
+
+		return lemmaSpan{Lemma: next(1), Surface: text + next(1) + next(2), Length: 3}
+
+	}
+
+	// 看看, 研究研究, 红红
+
+	if next(1) == text && (verb || len(runes) == 1 && category == "ChineseAdjectives") {
+
+		return lemmaSpan{Lemma: text, Surface: text + text, Length: 2}
+
+	}
+
+	// 花 儿, 花儿
+
+	if span, ok := c.erhuaAt(tokens, i); ok {
+
+		return span
+
+	}
+
+	// 高高兴兴 kept whole
+
+	if len(runes) == 4 && runes[0] == runes[1] && runes[2] == runes[3] && runes[0] != runes[2] {
+
+		if _, ok := c.dict.entries[string([]rune{runes[0], runes[2]})]; ok {
+
+			return lemmaSpan{Lemma: string([]rune{runes[0], runes[2]}), Surface: text, Length: 1}
+
+		}
+
+	}
+
+	// 看了, 去过, 等着
+
+	if verb && aspectParticles[next(1)] {
+
+		return lemmaSpan{Lemma: text, Surface: text + next(1), Length: 1}
+
+	}
+
+	return lemmaSpan{Lemma: text, Surface: text, Length: 1}
+
+}
@@ -0,0 +1,146 @@
+package classifier
+
+import (
+	_ "embed"
+
+	"strings"
+
+	"sync"
+)
+
+//go:embed dict/loanwords.txt
+
+var loanwordLexicon string
+
+// Loanword is an entry of the loanword lexicon
+
+type Loanword struct {
+	Word string
+
+	Language string // ISO 639 code of the source language, e.g. "en"
+
+	Original string // Word it was borrowed from, e.g. "coffee"
+
+}
+
+// Entries of the loanword lexicon, parsed once
+
+var (
+	loanwordsOnce sync.Once
+
+	loanwords map[string]Loanword
+)
+
+// Characters used mostly for their sound in transliterations, such as 斯, 尔 and 克. A longer word written
+
+// only with them reads as a transliteration (奥特曼, 巴塞罗那) rather than a native compound.
+
+const transliterationCharacters = "阿埃艾爱安奥澳巴芭拜班邦贝比彼毕宾波伯博布查达戴丹道德迪蒂丁顿多厄恩尔法菲芬弗伏福盖戈格古圭哈海汉赫亨胡霍基吉加贾杰捷金卡凯坎康考科克肯库夸拉莱赖兰朗劳勒雷蕾里利莉丽列林琳隆卢鲁路伦罗洛马玛迈麦曼芒梅门蒙米密莫默姆穆纳娜奈南内尼妮纽诺欧帕潘佩彭皮普奇齐恰乔切萨塞桑瑟森沙莎圣施史舒斯丝索塔泰坦汤特提廷图托瓦万旺威维韦温沃乌西希锡夏谢辛休雅亚扬耶伊因尤约泽扎詹朱兹卓佐"
+
+// Characters made for transliterations that hardly occur in native words, so one is enough (咖喱, 嘌呤)
+
+const phoneticCharacters = "咖啡啤喱嘌呤噻吩嗪呋唑喹啉嘧啶吲哚"
+
+// Parses the embedded loanword lexicon on first use
+
+func loanwordEntries() map[string]Loanword {
+
+	loanwordsOnce.Do(func() {
+
+		loanwords = make(map[string]Loanword)
+
+		for _, line := range strings.Split(ResourceText("loanwords.txt"), "\n") {
+
+			fields := strings.Split(strings.TrimSpace(line), "\t")
+
+			if fields[0] == "" || strings.HasPrefix(fields[0], "#") {
+
+				continue
+
+			}
+
+			entry := Loanword{Word: fields[0]}
+
+			if len(fields) > 1 {
+
+				entry.Language = fields[1]
+
+			}
+
+			if len(fields) > 2 {
+
+				entry.Original = fields[2]
+
+			}
+
+			loanwords[entry.Word] = entry
+
+		}
+
+	})
+
+	return loanwords
+
+}
+
+// LookupLoanword returns the lexicon entry of a loanword
+
+func LookupLoanword(word string) (Loanword, bool) {
+
+	entry, ok := loanwordEntries()[word]
+
+	return entry, ok
+
+}
+
+// Reports whether a word is a loanword, from the lexicon or from the characters it is written with.
+
+// Names of people, places and organizations are left to their own categories.
+
+func (c *Classifier) isLoanword(word string) bool {
+
+	if _, ok := loanwordEntries()[word]; ok {
+
+		return true
+
+	}
+
+	if entityTagCategories[c.dict.entries[word].Tag] != "" {
+
+		return false
+
+	}
+
+	runes := []rune(word)
+
+	if len(runes) < 2 {
+
+		return false
+
+	}
+
+	if strings.ContainsAny(word, phoneticCharacters) {
+
+		return true
+
+	}
+
+	if len(runes) < 3 {
+
+		return false
+
+	}
+
+	for _, r := range runes {
+
+		if !strings.ContainsRune(transliterationCharacters, r) {
+
+			return false
+
+		}
+
+	}
+
+	return true
+
+}
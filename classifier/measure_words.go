@@ -0,0 +1,215 @@
+package classifier
+
+import (
+	"strings"
+
+	"unicode"
+
+	"github.com/jdkato/prose/v2"
+)
+
+// Measure words (量词) that stand between a numeral or demonstrative and a noun, as in 三本书 or 这张桌子
+
+var measureWords = map[string]bool{}
+
+// Characters that count or point at what a measure word measures: numerals, 几, 半, 每 and demonstratives
+
+const quantifierCharacters = "零〇一二两三四五六七八九十百千万亿几半每这那哪某"
+
+// How many tokens after a measure word are searched for the noun it measures, past modifiers as in 一件漂亮的衣服
+
+const measuredNounWindow = 4
+
+// Dictionary and tagger tags of the nouns a measure word pairs with
+
+var nounTags = map[string]bool{"n": true, "nr": true, "ns": true, "nt": true, "nz": true, "ng": true, "j": true, "NN": true, "NR": true}
+
+func init() {
+
+	for _, word := range strings.Fields(`
+		个 只 条 张 本 件 位 名 头 匹 棵 株 朵 片 块 支 枝 根 把 辆 架 艘 座 所 栋 幢 间 家 台 部 封 首 篇 道 颗 粒 枚
+		双 对 副 套 群 批 串 堆 束 杯 碗 瓶 盒 袋 包 箱 桶 盘 壶 层 种 类 份 场 项 门 幅 顶 扇 盏 面 口 尾 笔 则 节 段 句
+		次 遍 趟 顿 阵 番 回 下`) {
+		measureWords[word] = true
+
+	}
+
+}
+
+// IsMeasureWord reports whether a word is a measure word such as 个, 本 or 条
+
+func IsMeasureWord(word string) bool {
+
+	return measureWords[word]
+
+}
+
+// Returns true when every character of text counts or points, as in 三, 二十 or 这; digits count too
+
+func isQuantifier(text string) bool {
+
+	if text == "" {
+
+		return false
+
+	}
+
+	for _, r := range text {
+
+		if !unicode.IsDigit(r) && !strings.ContainsRune(quantifierCharacters, r) {
+
+			return false
+
+		}
+
+	}
+
+	return true
+
+}
+
+// Measure word used by tokens[i] and the rest of its token. After a numeral or demonstrative token the
+
+// measure word starts the token, which may run on into the noun (一 个人); otherwise it ends a token that
+
+// segmentation kept together with its numeral (一个, 这张). A measure word on its own, such as the surname in
+
+// 张三, does not count.
+
+func splitMeasureWord(tokens []prose.Token, i int) (string, string) {
+
+	runes := []rune(tokens[i].Text)
+
+	if len(runes) == 0 {
+
+		return "", ""
+
+	}
+
+	if measureWords[string(runes[0])] && i > 0 && isQuantifier(tokens[i-1].Text) {
+
+		return string(runes[0]), string(runes[1:])
+
+	}
+
+	if len(runes) >= 2 && measureWords[string(runes[len(runes)-1])] && isQuantifier(string(runes[:len(runes)-1])) {
+
+		return string(runes[len(runes)-1]), ""
+
+	}
+
+	return "", ""
+
+}
+
+// Finds the measure words among the tokens of one sentence
+
+func recognizeMeasureWords(tokens []prose.Token) []string {
+
+	var words []string
+
+	for i := range tokens {
+
+		if word, _ := splitMeasureWord(tokens, i); word != "" {
+
+			words = append(words, word)
+
+		}
+
+	}
+
+	return words
+
+}
+
+// Noun measured by the measure word at tokens[i]: the rest of its token, or else the first noun within the
+
+// next few Chinese tokens
+
+func (c *Classifier) measuredNoun(tokens []prose.Token, i int) string {
+
+	if _, rest := splitMeasureWord(tokens, i); rest != "" {
+
+		return rest
+
+	}
+
+	for j := i + 1; j < len(tokens) && j <= i+measuredNounWindow; j++ {
+
+		text := tokens[j].Text
+
+		if word, _ := splitMeasureWord(tokens, j); word != "" || !IsChineseText(text) {
+
+			return ""
+
+		}
+
+		tag := tokens[j].Tag
+
+		if entry, ok := c.dict.entries[text]; ok && entry.Tag != "" {
+
+			tag = entry.Tag
+
+		}
+
+		if nounTags[tag] {
+
+			return text
+
+		}
+
+	}
+
+	return ""
+
+}
+
+// MeasureWordPairs returns, for each measure word of a result, the nouns it measures, most frequent first.
+
+// Nouns are looked up within the measure word's sentence.
+
+func (c *Classifier) MeasureWordPairs(result *Result) map[string][]ItemFrequency {
+
+	counts := make(map[string]map[string]int)
+
+	for _, sentence := range result.Sentences {
+
+		tokens := result.Tokens[sentence.Start:sentence.End]
+
+		for i := range tokens {
+
+			word, _ := splitMeasureWord(tokens, i)
+
+			if word == "" {
+
+				continue
+
+			}
+
+			if noun := c.measuredNoun(tokens, i); noun != "" {
+
+				if counts[word] == nil {
+
+					counts[word] = make(map[string]int)
+
+				}
+
+				counts[word][noun]++
+
+			}
+
+		}
+
+	}
+
+	pairs := make(map[string][]ItemFrequency)
+
+	for word, nouns := range counts {
+
+		pairs[word] = RankByFrequency(nouns)
+
+	}
+
+	return pairs
+
+}
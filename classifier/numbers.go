@@ -0,0 +1,391 @@
+package classifier
+
+import (
+	"fmt"
+
+	"regexp"
+
+	"strconv"
+
+	"strings"
+
+	"unicode"
+
+	"unicode/utf8"
+
+	"github.com/jdkato/prose/v2"
+)
+
+// Digits and numeral characters, including the financial forms (壹, 贰, ...)
+
+const numeralCharacters = "0123456789０１２３４５６７８９〇零一二两三四五六七八九十百千万亿壹贰叁肆伍陆柒捌玖拾佰仟"
+
+// Characters joining digits within one token, as in 2.5, 3:30, 50% or 2024-03-05
+
+const numberPunctuation = ".．:：%％/-"
+
+// Units that follow a number in dates, times, ages, amounts and percentages (三月, 十点半, 百分之三十)
+
+var numberUnits = map[string]bool{
+
+	"年": true, "月": true, "日": true, "号": true, "点": true, "点钟": true, "时": true, "小时": true, "分": true,
+
+	"分钟": true, "秒": true, "秒钟": true, "刻": true, "半": true, "世纪": true, "年代": true, "岁": true, "度": true,
+
+	"元": true, "块": true, "角": true, "万元": true, "亿元": true, "美元": true, "欧元": true, "公里": true,
+
+	"米": true, "厘米": true, "公斤": true, "千克": true, "吨": true, "之": true,
+}
+
+// Words that start a number expression: ordinals (第三), weekdays (星期三) and times of day (上午十点)
+
+var numberPrefixes = map[string]bool{
+
+	"第": true, "星期": true, "礼拜": true, "周": true, "公元": true, "农历": true, "上午": true, "下午": true,
+
+	"中午": true, "晚上": true, "早上": true, "凌晨": true, "傍晚": true, "夜里": true,
+}
+
+// Words written with numerals that are rarely numbers on their own, such as the adverb 十分
+
+var nonNumberWords = map[string]bool{"十分": true, "万分": true, "一点": true, "一时": true, "一分": true, "万一": true, "千万": true, "一一": true}
+
+// Reports whether every character of a token belongs to a number, with at least one numeral
+
+func isNumberToken(text string) bool {
+
+	numeral := false
+
+	for _, r := range text {
+
+		switch {
+
+		case strings.ContainsRune(numeralCharacters, r):
+
+			numeral = true
+
+		case strings.ContainsRune(numberPunctuation, r), strings.ContainsRune("年月日号点时分秒刻半第周", r):
+
+		default:
+
+			return false
+
+		}
+
+	}
+
+	return numeral
+
+}
+
+// Finds numbers, dates and times such as 三百二十一, 二〇二四年三月五日, 3月5日 and 下午3:30, which segmentation
+
+// splits into many tokens, and marks the tokens they cover
+
+func recognizeNumbers(tokens []prose.Token) ([]string, []bool) {
+
+	var expressions []string
+
+	covered := make([]bool, len(tokens))
+
+	start, numeral := 0, false
+
+	// Latin tokens may still carry the full-width punctuation around them, as in 50%。 or ，2010
+
+	texts := make([]string, len(tokens))
+
+	for i, tok := range tokens {
+
+		texts[i] = strings.TrimFunc(tok.Text, func(r rune) bool { return unicode.IsPunct(r) && !strings.ContainsRune(numberPunctuation, r) })
+
+	}
+
+	// Ends the run of tokens before end, dropping prefixes and 之 left at its end
+
+	flush := func(end int) {
+
+		for end > start && (numberPrefixes[texts[end-1]] || texts[end-1] == "之") {
+
+			end--
+
+		}
+
+		if numeral && end > start {
+
+			text := ""
+
+			units := false
+
+			for _, word := range texts[start:end] {
+
+				text += word
+
+				units = units || !isNumeralWord(word)
+
+			}
+
+			// A lone numeral such as 一 in 一个 is left to the measure word, and 十分 to the adverbs
+
+			if (units || utf8.RuneCountInString(text) > 1) && !(end-start == 1 && nonNumberWords[text]) {
+
+				expressions = append(expressions, text)
+
+				for i := start; i < end; i++ {
+
+					covered[i] = true
+
+				}
+
+			}
+
+		}
+
+		numeral = false
+
+	}
+
+	for i, text := range texts {
+
+		switch {
+
+		case isNumberToken(text):
+
+			numeral = true
+
+		case numberPrefixes[text] && numeral:
+
+			flush(i)
+
+			start = i
+
+		case numberPrefixes[text]:
+
+		case numberUnits[text] && numeral && (text != "之" || strings.HasSuffix(texts[i-1], "分")):
+
+		default:
+
+			flush(i)
+
+			start = i + 1
+
+		}
+
+	}
+
+	flush(len(tokens))
+
+	return expressions, covered
+
+}
+
+// Reports whether a token is numerals alone, without units or digit punctuation
+
+func isNumeralWord(text string) bool {
+
+	for _, r := range text {
+
+		if !strings.ContainsRune(numeralCharacters, r) {
+
+			return false
+
+		}
+
+	}
+
+	return true
+
+}
+
+// Values of the numeral digits; 〇 and 零 are zero
+
+var numeralDigits = map[rune]int{'〇': 0, '零': 0, '一': 1, '壹': 1, '二': 2, '两': 2, '贰': 2, '三': 3, '叁': 3, '四': 4, '肆': 4, '五': 5, '伍': 5, '六': 6, '陆': 6, '七': 7, '柒': 7, '八': 8, '捌': 8, '九': 9, '玖': 9}
+
+// Values of the numeral units
+
+var numeralUnits = map[rune]int{'十': 10, '拾': 10, '百': 100, '佰': 100, '千': 1000, '仟': 1000, '万': 10000, '亿': 100000000}
+
+// A number written in Chinese numerals, digits or both (2.5万)
+
+var numeralPattern = regexp.MustCompile(`[0-9.]*[〇零一二两三四五六七八九十百千万亿壹贰叁肆伍陆柒捌玖拾佰仟]+|[0-9]+(\.[0-9]+)?`)
+
+// Normalized dates and times, after the numerals were turned into digits
+
+var (
+	fullDatePattern = regexp.MustCompile(`^([0-9]{4})年([0-9]{1,2})月([0-9]{1,2})[日号]$`)
+
+	clockPattern = regexp.MustCompile(`(上午|早上|凌晨|中午|下午|傍晚|晚上|夜里)?([0-9]+)(?:点(半|钟|([0-9]+)(分?))?|[:：]([0-9]{2}))`)
+)
+
+// Times of day after which an hour such as 3点 is in the afternoon or evening
+
+var afternoonPeriods = map[string]bool{"下午": true, "傍晚": true, "晚上": true}
+
+// NormalizeNumber writes the numerals of a number, date or time expression as Arabic numbers for analytics:
+
+// 三百二十一 → 321, 第三 → 第3, 2.5万元 → 25000元, 百分之三十 → 30%, 二〇二四年三月五日 → 2024-03-05 and
+
+// 下午三点半 → 15:30; times are on the 24-hour clock, so 下午3:30 and 晚上八点 give 15:30 and 20:00. Weekdays
+
+// (星期三) are kept as written.
+
+func NormalizeNumber(expression string) string {
+
+	for _, weekday := range []string{"星期", "礼拜", "周"} {
+
+		if strings.HasPrefix(expression, weekday) {
+
+			return expression
+
+		}
+
+	}
+
+	normalized := expression
+
+	if strings.HasPrefix(normalized, "百分之") {
+
+		normalized = strings.TrimPrefix(normalized, "百分之") + "%"
+
+	}
+
+	normalized = numeralPattern.ReplaceAllStringFunc(normalized, func(number string) string {
+
+		// Numbers already in digits keep their leading zeros, as in 2024-05-01
+
+		if strings.Trim(number, "0123456789.") == "" {
+
+			return number
+
+		}
+
+		return strconv.FormatFloat(numeralValue(number), 'f', -1, 64)
+
+	})
+
+	if match := fullDatePattern.FindStringSubmatch(normalized); match != nil {
+
+		month, _ := strconv.Atoi(match[2])
+
+		day, _ := strconv.Atoi(match[3])
+
+		return fmt.Sprintf("%s-%02d-%02d", match[1], month, day)
+
+	}
+
+	return clockPattern.ReplaceAllStringFunc(normalized, func(clock string) string {
+
+		match := clockPattern.FindStringSubmatch(clock)
+
+		hour, _ := strconv.Atoi(match[2])
+
+		minutes, _ := strconv.Atoi(match[4] + match[6])
+
+		switch {
+
+		case match[3] == "半":
+
+			minutes = 30
+
+		case match[4] != "" && match[5] == "":
+
+			// 三点五 is a decimal rather than a time
+
+			return match[1] + match[2] + "." + match[4]
+
+		}
+
+		if afternoonPeriods[match[1]] && hour < 12 || match[1] == "中午" && hour < 6 {
+
+			hour += 12
+
+		}
+
+		return fmt.Sprintf("%d:%02d", hour, minutes)
+
+	})
+
+}
+
+// Value of a number such as 三百二十一, 二〇二四 (read digit by digit, as years are), 一万二千 or 2.5万
+
+func numeralValue(number string) float64 {
+
+	digits := strings.TrimRightFunc(number, func(r rune) bool { return r != '.' && (r < '0' || r > '9') })
+
+	if digits != "" {
+
+		value, _ := strconv.ParseFloat(digits, 64)
+
+		// Only 万 and 亿 may follow digits
+
+		for _, r := range number[len(digits):] {
+
+			value *= float64(numeralUnits[r])
+
+		}
+
+		return value
+
+	}
+
+	if !strings.ContainsAny(number, "十拾百佰千仟万亿") {
+
+		value := 0
+
+		for _, r := range number {
+
+			value = value*10 + numeralDigits[r]
+
+		}
+
+		return float64(value)
+
+	}
+
+	// 三亿五千万: 亿 closes the total, 万 the section below it
+
+	total, section, digit := 0, 0, 0
+
+	for _, r := range number {
+
+		unit, ok := numeralUnits[r]
+
+		switch {
+
+		case !ok:
+
+			digit = numeralDigits[r]
+
+		case unit == 100000000:
+
+			total = (total + section + digit) * unit
+
+			section, digit = 0, 0
+
+		case unit == 10000:
+
+			section = (section + digit) * unit
+
+			digit = 0
+
+		default:
+
+			// 十二 starts with an implied 一
+
+			if digit == 0 && unit == 10 {
+
+				digit = 1
+
+			}
+
+			section += digit * unit
+
+			digit = 0
+
+		}
+
+	}
+
+	return float64(total + section + digit)
+
+}
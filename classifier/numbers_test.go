@@ -0,0 +1,95 @@
+package classifier
+
+import "testing"
+
+func TestNormalizeNumber(t *testing.T) {
+
+	tests := []struct {
+		expression string
+
+		want string
+	}{
+		{"三百二十一", "321"},
+
+		{"第三", "第3"},
+
+		{"2.5万元", "25000元"},
+
+		{"百分之三十", "30%"},
+
+		{"二〇二四年三月五日", "2024-03-05"},
+
+		{"2024年3月5日", "2024-03-05"},
+
+		{"上午十点半", "10:30"},
+
+		{"下午三点半", "15:30"},
+
+		{"下午3:30", "15:30"},
+
+		{"下午3：30", "15:30"},
+
+		{"晚上八点", "20:00"},
+
+		{"中午十二点", "12:00"},
+
+		{"中午一点", "13:00"},
+
+		{"凌晨两点十五分", "2:15"},
+
+		{"三点五", "3.5"},
+
+		{"星期三", "星期三"},
+	}
+
+	for _, test := range tests {
+
+		if got := NormalizeNumber(test.expression); got != test.want {
+
+			t.Errorf("NormalizeNumber(%q) = %q, want %q", test.expression, got, test.want)
+
+		}
+
+	}
+
+}
+
+func TestISODate(t *testing.T) {
+
+	tests := []struct {
+		expression string
+
+		want string
+
+		ok bool
+	}{
+		{"二〇二四年三月五日", "2024-03-05", true},
+
+		{"1998年", "1998", true},
+
+		{"三月五日", "--03-05", true},
+
+		{"下午三点半", "15:30", true},
+
+		{"下午3:30", "15:30", true},
+
+		{"上午9:05", "09:05", true},
+
+		{"十三月", "--13", false},
+
+		{"三百二十一", "", false},
+	}
+
+	for _, test := range tests {
+
+		got, ok := ISODate(test.expression)
+
+		if got != test.want || ok != test.ok {
+
+			t.Errorf("ISODate(%q) = %q, %v, want %q, %v", test.expression, got, ok, test.want, test.ok)
+
+		}
+
+	}
+
+}
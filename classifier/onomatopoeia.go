@@ -0,0 +1,159 @@
+package classifier
+
+import (
+	_ "embed"
+
+	"strings"
+
+	"sync"
+
+	"github.com/jdkato/prose/v2"
+)
+
+//go:embed dict/onomatopoeia.txt
+
+var onomatopoeiaLexicon string
+
+// Words of the onomatopoeia lexicon, parsed once
+
+var (
+	onomatopoeiaOnce sync.Once
+
+	onomatopoeiaWords map[string]bool
+)
+
+// Characters written mostly for sounds. A reduplication made only of them (嗡嗡, 叽叽喳喳, 轰隆隆) reads as
+
+// onomatopoeia even when the lexicon lacks it.
+
+const soundCharacters = "哗啦咕噜嗡嘀嗒哒叮咚铛轰隆砰嘭乒乓咔嚓喀嘎吱喳叽哇呜呱哈嘿嘻呵咯嘟呼噗嗖唰咣哐啷啪嗵喵汪咩哞咝啾嘤唧喔噼窸窣沙淅沥"
+
+// Parses the embedded onomatopoeia lexicon on first use
+
+func onomatopoeiaEntries() map[string]bool {
+
+	onomatopoeiaOnce.Do(func() {
+
+		onomatopoeiaWords = make(map[string]bool)
+
+		for _, line := range strings.Split(ResourceText("onomatopoeia.txt"), "\n") {
+
+			if word := strings.TrimSpace(line); word != "" && !strings.HasPrefix(word, "#") {
+
+				onomatopoeiaWords[word] = true
+
+			}
+
+		}
+
+	})
+
+	return onomatopoeiaWords
+
+}
+
+// Reports whether a word is in the lexicon, or is a lexicon word said twice (砰砰, 哗啦哗啦)
+
+func inOnomatopoeiaLexicon(word string) bool {
+
+	lexicon := onomatopoeiaEntries()
+
+	half := len(word) / 2
+
+	return lexicon[word] || half > 0 && word[:half] == word[half:] && lexicon[word[:half]]
+
+}
+
+// Reports whether a word is onomatopoeia: from the lexicon, or a reduplication of sound characters
+
+func isOnomatopoeia(word string) bool {
+
+	if inOnomatopoeiaLexicon(word) {
+
+		return true
+
+	}
+
+	runes := []rune(word)
+
+	if len(runes) < 2 {
+
+		return false
+
+	}
+
+	for _, r := range runes {
+
+		if !strings.ContainsRune(soundCharacters, r) {
+
+			return false
+
+		}
+
+	}
+
+	// AA, AAB, ABB, AABB, or ABAB
+
+	for i := 1; i < len(runes); i++ {
+
+		if runes[i] == runes[i-1] {
+
+			return true
+
+		}
+
+	}
+
+	return len(runes) == 4 && runes[0] == runes[2] && runes[1] == runes[3]
+
+}
+
+// Finds onomatopoeia, which segmentation often splits into single characters (嗡 嗡), joining the longest
+
+// run of up to six tokens, and marks the tokens they cover
+
+func recognizeOnomatopoeia(tokens []prose.Token) ([]string, []bool) {
+
+	var words []string
+
+	covered := make([]bool, len(tokens))
+
+	for i := 0; i < len(tokens); i++ {
+
+		word, end := "", 0
+
+		joined := ""
+
+		for j := i; j < len(tokens) && j < i+6; j++ {
+
+			joined += tokens[j].Text
+
+			if isOnomatopoeia(joined) {
+
+				word, end = joined, j+1
+
+			}
+
+		}
+
+		if word == "" {
+
+			continue
+
+		}
+
+		words = append(words, word)
+
+		for k := i; k < end; k++ {
+
+			covered[k] = true
+
+		}
+
+		i = end - 1
+
+	}
+
+	return words, covered
+
+}
@@ -0,0 +1,229 @@
+package classifier
+
+import (
+	_ "embed"
+
+	"bufio"
+
+	"fmt"
+
+	"regexp"
+
+	"strings"
+
+	"github.com/jdkato/prose/v2"
+)
+
+//go:embed dict/phrase_grammar.txt
+
+var phraseGrammar string
+
+// A rule of the chunking grammar: the phrases of a category as a pattern over the tag string of a sentence
+
+type phraseRule struct {
+	Category string
+
+	Pattern *regexp.Regexp
+}
+
+// Tag patterns of a rule, <NN|JJ> or <N.*>
+
+var tagPattern = regexp.MustCompile(`<([^<>]*)>`)
+
+// What a pattern may contain besides tags: groups, alternatives and repetitions
+
+var grammarOperators = regexp.MustCompile(`[()|?*+{},0-9]`)
+
+// WithPhraseGrammar replaces the chunking grammar of the phrases stage, one "Category: pattern" rule per line
+// such as "ChineseNounPhrases: <JJ>*<NN>+"; # starts a comment. The default grammar is the phrase_grammar.txt
+// resource.
+
+func WithPhraseGrammar(grammar string) Option {
+
+	return func(c *config) error {
+
+		if _, err := parsePhraseGrammar(grammar); err != nil {
+
+			return fmt.Errorf("invalid phrase grammar: %v", err)
+
+		}
+
+		c.phraseGrammar = grammar
+
+		return nil
+
+	}
+
+}
+
+// Parses a chunking grammar, compiling each pattern into a regular expression over tag strings such as
+// "<NN><VB>"
+
+func parsePhraseGrammar(grammar string) ([]phraseRule, error) {
+
+	var rules []phraseRule
+
+	scanner := bufio.NewScanner(strings.NewReader(grammar))
+
+	lineNumber := 0
+
+	for scanner.Scan() {
+
+		lineNumber++
+
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+
+		if strings.TrimSpace(line) == "" {
+
+			continue
+
+		}
+
+		category, pattern, ok := strings.Cut(line, ":")
+
+		category, pattern = strings.TrimSpace(category), strings.TrimSpace(pattern)
+
+		if !ok || category == "" || pattern == "" {
+
+			return nil, fmt.Errorf("line %d: expected \"Category: pattern\", got %q", lineNumber, strings.TrimSpace(line))
+
+		}
+
+		if categoryStage(category) != "phrases" {
+
+			return nil, fmt.Errorf("line %d: %q is not a phrase category", lineNumber, category)
+
+		}
+
+		pattern = strings.Join(strings.Fields(pattern), "")
+
+		if rest := grammarOperators.ReplaceAllString(tagPattern.ReplaceAllString(pattern, ""), ""); rest != "" {
+
+			return nil, fmt.Errorf("line %d: %q outside a tag in %q", lineNumber, rest, pattern)
+
+		}
+
+		// Tags are matched whole, and . stays within a tag
+
+		expression := tagPattern.ReplaceAllStringFunc(pattern, func(tags string) string {
+
+			return "(?:<(?:" + strings.ReplaceAll(tags[1:len(tags)-1], ".", "[^<>]") + ")>)"
+
+		})
+
+		compiled, err := regexp.Compile("^(?:" + expression + ")")
+
+		if err != nil {
+
+			return nil, fmt.Errorf("line %d: %v", lineNumber, err)
+
+		}
+
+		compiled.Longest()
+
+		rules = append(rules, phraseRule{Category: category, Pattern: compiled})
+
+	}
+
+	return rules, scanner.Err()
+
+}
+
+// Extracts the phrases of one sentence by the chunking grammar. Non-Chinese tokens are skipped, and each
+// category's phrases do not overlap.
+
+func extractPhrases(tokens []prose.Token, rules []phraseRule) map[string][]string {
+
+	var chinese []prose.Token
+
+	var tags strings.Builder
+
+	var offsets []int // Offset of each token's tag in the tag string
+
+	for _, tok := range tokens {
+
+		if IsChineseText(tok.Text) {
+
+			chinese = append(chinese, tok)
+
+			offsets = append(offsets, tags.Len())
+
+			tags.WriteString("<" + tok.Tag + ">")
+
+		}
+
+	}
+
+	offsets = append(offsets, tags.Len())
+
+	// Token index of each tag offset
+
+	index := make(map[int]int, len(offsets))
+
+	for i, offset := range offsets {
+
+		index[offset] = i
+
+	}
+
+	var categories []string
+
+	byCategory := make(map[string][]phraseRule)
+
+	for _, rule := range rules {
+
+		if byCategory[rule.Category] == nil {
+
+			categories = append(categories, rule.Category)
+
+		}
+
+		byCategory[rule.Category] = append(byCategory[rule.Category], rule)
+
+	}
+
+	phrases := make(map[string][]string)
+
+	for _, category := range categories {
+
+		for start := 0; start < len(chinese); {
+
+			end := start
+
+			for _, rule := range byCategory[category] {
+
+				if match := rule.Pattern.FindStringIndex(tags.String()[offsets[start]:]); match != nil {
+
+					end = max(end, index[offsets[start]+match[1]])
+
+				}
+
+			}
+
+			if end == start {
+
+				start++
+
+				continue
+
+			}
+
+			var words []string
+
+			for _, tok := range chinese[start:end] {
+
+				words = append(words, tok.Text)
+
+			}
+
+			phrases[category] = append(phrases[category], strings.Join(words, " "))
+
+			start = end
+
+		}
+
+	}
+
+	return phrases
+
+}
@@ -0,0 +1,122 @@
+package classifier
+
+// PolitenessKinds are the kinds of politeness markers, in report order
+
+var PolitenessKinds = []string{"honorific", "humble", "request", "thanks", "apology", "greeting"}
+
+// Politeness markers and their kind: honorific forms of address and reference (您, 贵公司), humble forms for
+
+// oneself (敝公司), softened requests (请, 麻烦您), thanks, apologies and greetings. Words that only contain a
+
+// marker, such as 申请, map to "" so the longest match skips them.
+
+var politenessMarkers = map[string]string{
+
+	"您": "honorific", "您们": "honorific", "阁下": "honorific", "贵公司": "honorific", "贵司": "honorific", "贵方": "honorific",
+
+	"贵单位": "honorific", "贵校": "honorific", "贵院": "honorific", "贵店": "honorific", "贵行": "honorific", "贵国": "honorific",
+
+	"贵宾": "honorific", "贵姓": "honorific", "贵庚": "honorific", "尊姓大名": "honorific", "令尊": "honorific", "令堂": "honorific",
+
+	"令郎": "honorific", "令爱": "honorific", "高见": "honorific", "大作": "honorific", "惠顾": "honorific", "光临": "honorific",
+
+	"莅临": "honorific", "垂询": "honorific", "赐教": "honorific", "指教": "honorific", "先生": "honorific", "女士": "honorific",
+
+	"鄙人": "humble", "在下": "humble", "敝公司": "humble", "敝司": "humble", "敝姓": "humble", "寒舍": "humble", "拙作": "humble",
+
+	"拙见": "humble", "愚见": "humble", "拜读": "humble", "拜访": "humble", "拜托": "humble", "恭候": "humble", "敬上": "humble",
+
+	"请": "request", "请您": "request", "敬请": "request", "恳请": "request", "烦请": "request", "劳驾": "request", "麻烦": "request",
+
+	"麻烦您": "request", "能否": "request", "可否": "request", "是否可以": "request", "有劳": "request", "请问": "request", "稍等": "request",
+
+	"谢谢": "thanks", "感谢": "thanks", "多谢": "thanks", "谢谢您": "thanks", "非常感谢": "thanks", "感谢您": "thanks", "辛苦了": "thanks",
+
+	"承蒙": "thanks", "感激不尽": "thanks",
+
+	"抱歉": "apology", "对不起": "apology", "不好意思": "apology", "请原谅": "apology", "敬请谅解": "apology", "见谅": "apology",
+
+	"打扰了": "apology", "给您带来不便": "apology", "深表歉意": "apology", "失礼": "apology",
+
+	"您好": "greeting", "你好": "greeting", "早上好": "greeting", "晚上好": "greeting", "再见": "greeting", "欢迎": "greeting",
+
+	"欢迎光临": "greeting", "祝您": "greeting", "此致敬礼": "greeting",
+
+	"申请": "", "邀请": "", "请求": "", "请假": "", "宴请": "", "聘请": "", "提请": "", "报请": "", "请客": "",
+}
+
+// Length in runes of the longest politeness marker
+
+var politenessMaxLength = func() int {
+
+	longest := 0
+
+	for marker := range politenessMarkers {
+
+		longest = max(longest, len([]rune(marker)))
+
+	}
+
+	return longest
+
+}()
+
+// PolitenessKind returns the kind of a politeness marker, such as "request" for 请, or "" for other words
+
+func PolitenessKind(marker string) string {
+
+	return politenessMarkers[marker]
+
+}
+
+// Finds politeness markers in the raw text, the longest at each position, so 麻烦您 counts once as itself
+
+// rather than as 麻烦 and 您
+
+func findPolitenessMarkers(text string) []string {
+
+	var found []string
+
+	for _, run := range splitHanRuns(text) {
+
+		runes := []rune(run)
+
+		for i := 0; i < len(runes); {
+
+			matched := 0
+
+			for n := min(politenessMaxLength, len(runes)-i); n >= 1; n-- {
+
+				if _, ok := politenessMarkers[string(runes[i:i+n])]; ok {
+
+					matched = n
+
+					break
+
+				}
+
+			}
+
+			if matched == 0 {
+
+				i++
+
+				continue
+
+			}
+
+			if marker := string(runes[i : i+matched]); politenessMarkers[marker] != "" {
+
+				found = append(found, marker)
+
+			}
+
+			i += matched
+
+		}
+
+	}
+
+	return found
+
+}
@@ -0,0 +1,106 @@
+package classifier
+
+import (
+	_ "embed"
+
+	"sort"
+
+	"strconv"
+
+	"strings"
+
+	"sync"
+)
+
+//go:embed dict/radicals.txt
+
+var radicalTable string
+
+// Radical is one of the 214 Kangxi radicals
+
+type Radical struct {
+	Number int
+
+	Character string // Traditional form, e.g. 言
+
+	Simplified string // Simplified form where it differs, e.g. 讠
+
+	Strokes int
+
+	Name string // English name, e.g. "speech"
+
+}
+
+// Last code point of the CJK Unified Ideographs block that is in radical-stroke order; later additions are not
+
+const lastRadicalOrdered = 0x9FA5
+
+// Radicals with the first code point of their section of the CJK Unified Ideographs block, parsed once
+
+var (
+	radicalsOnce sync.Once
+
+	radicals []Radical
+
+	radicalStarts []rune
+)
+
+// Parses the embedded radical table on first use
+
+func radicalEntries() ([]Radical, []rune) {
+
+	radicalsOnce.Do(func() {
+
+		for _, line := range strings.Split(ResourceText("radicals.txt"), "\n") {
+
+			fields := strings.Split(strings.TrimSpace(line), "\t")
+
+			if len(fields) < 6 || strings.HasPrefix(fields[0], "#") {
+
+				continue
+
+			}
+
+			number, _ := strconv.Atoi(fields[0])
+
+			strokes, _ := strconv.Atoi(fields[3])
+
+			start, err := strconv.ParseInt(strings.TrimPrefix(fields[4], "U+"), 16, 32)
+
+			if err != nil {
+
+				continue
+
+			}
+
+			radicals = append(radicals, Radical{Number: number, Character: fields[1], Simplified: fields[2], Strokes: strokes, Name: fields[5]})
+
+			radicalStarts = append(radicalStarts, rune(start))
+
+		}
+
+	})
+
+	return radicals, radicalStarts
+
+}
+
+// LookupRadical returns the Kangxi radical a character is indexed under, such as 言 for 说 or 水 for 河.
+
+// Characters outside U+4E00-U+9FA5 have none.
+
+func LookupRadical(character rune) (Radical, bool) {
+
+	entries, starts := radicalEntries()
+
+	if len(starts) == 0 || character < starts[0] || character > lastRadicalOrdered {
+
+		return Radical{}, false
+
+	}
+
+	i := sort.Search(len(starts), func(i int) bool { return starts[i] > character }) - 1
+
+	return entries[i], true
+
+}
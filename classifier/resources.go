@@ -0,0 +1,392 @@
+package classifier
+
+import (
+	"crypto/sha256"
+
+	_ "embed"
+
+	"encoding/hex"
+
+	"fmt"
+
+	"io/fs"
+
+	"os"
+
+	"path/filepath"
+
+	"sort"
+
+	"strconv"
+
+	"strings"
+
+	"sync"
+)
+
+//go:embed dict/stopwords.txt
+
+var stopwordList string
+
+//go:embed dict/hsk.txt
+
+var hskList string
+
+// ResourceInfo describes a language resource: the version it declares, where it is read from and a digest of
+
+// its content, so a run can be traced to the exact data that produced it
+
+type ResourceInfo struct {
+	Name string // Path relative to the resource directory, e.g. "idioms.txt" or "domains/it.txt"
+
+	Version string // From the "# version:" line of the file, "unversioned" when it has none
+
+	Source string // "embedded", or the override file it is read from
+
+	SHA256 string
+}
+
+// Embedded resources by name and the override directory's replacements, read when the directory is set
+
+var (
+	resourcesMu sync.Mutex
+
+	embeddedResources = make(map[string]string)
+
+	overriddenResources = make(map[string]string)
+
+	overridePaths = make(map[string]string)
+)
+
+func init() {
+
+	for name, data := range map[string]string{
+
+		"core.txt": coreDictionary,
+
+		"entities.txt": entityDictionary,
+
+		"hmm_emit.txt": hmmEmissions,
+
+		"idioms.txt": idiomDictionary,
+
+		"proverbs.txt": proverbDictionary,
+
+		"xiehouyu.txt": xiehouyuDictionary,
+
+		"slang.txt": slangLexicon,
+
+		"stopwords.txt": stopwordList,
+
+		"hsk.txt": hskList,
+
+		"loanwords.txt": loanwordLexicon,
+
+		"onomatopoeia.txt": onomatopoeiaLexicon,
+
+		"radicals.txt": radicalTable,
+
+		"strokes.txt": strokeTable,
+
+		"components.txt": componentTable,
+
+		"phrase_grammar.txt": phraseGrammar,
+
+		"cantonese.txt": cantoneseLexicon,
+
+		"cantonese_slang.txt": cantoneseSlangLexicon,
+	} {
+
+		RegisterResource(name, data)
+
+	}
+
+	entries, _ := domainDictionaries.ReadDir("dict/domains")
+
+	for _, entry := range entries {
+
+		data, _ := domainDictionaries.ReadFile("dict/domains/" + entry.Name())
+
+		RegisterResource("domains/"+entry.Name(), string(data))
+
+	}
+
+}
+
+// RegisterResource adds an embedded resource of another package, such as the command's character table, so it
+
+// can be listed and overridden like the classifier's own
+
+func RegisterResource(name, embedded string) {
+
+	resourcesMu.Lock()
+
+	defer resourcesMu.Unlock()
+
+	embeddedResources[name] = embedded
+
+}
+
+// SetResourceDir makes the files of dir replace the embedded resources of the same name (for example
+
+// dir/idioms.txt or dir/domains/it.txt). Files are read at once, so later changes do not affect a run; call it
+
+// before creating a classifier, as resources are parsed on first use. Files that match no resource are an
+
+// error, so a misspelled override is not silently ignored.
+
+func SetResourceDir(dir string) error {
+
+	overrides := make(map[string]string)
+
+	paths := make(map[string]string)
+
+	err := filepath.WalkDir(dir, func(file string, entry fs.DirEntry, err error) error {
+
+		if err != nil {
+
+			return err
+
+		}
+
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".txt") {
+
+			return nil
+
+		}
+
+		relative, err := filepath.Rel(dir, file)
+
+		if err != nil {
+
+			return err
+
+		}
+
+		name := filepath.ToSlash(relative)
+
+		if _, ok := EmbeddedResource(name); !ok {
+
+			return fmt.Errorf("%s overrides no resource (available: %s)", file, strings.Join(resourceNames(), ", "))
+
+		}
+
+		data, err := os.ReadFile(file)
+
+		if err != nil {
+
+			return err
+
+		}
+
+		overrides[name] = string(data)
+
+		paths[name] = file
+
+		return nil
+
+	})
+
+	if err != nil {
+
+		return fmt.Errorf("failed to read resource directory: %v", err)
+
+	}
+
+	resourcesMu.Lock()
+
+	defer resourcesMu.Unlock()
+
+	overriddenResources = overrides
+
+	overridePaths = paths
+
+	return nil
+
+}
+
+// EmbeddedResource returns the copy of a resource built into the binary
+
+func EmbeddedResource(name string) (string, bool) {
+
+	resourcesMu.Lock()
+
+	defer resourcesMu.Unlock()
+
+	data, ok := embeddedResources[name]
+
+	return data, ok
+
+}
+
+// ResourceText returns a resource from the override directory if it has one, or else the embedded copy
+
+func ResourceText(name string) string {
+
+	resourcesMu.Lock()
+
+	defer resourcesMu.Unlock()
+
+	if data, ok := overriddenResources[name]; ok {
+
+		return data
+
+	}
+
+	return embeddedResources[name]
+
+}
+
+// Resources lists every registered resource in name order, as it would be read now
+
+func Resources() []ResourceInfo {
+
+	var resources []ResourceInfo
+
+	for _, name := range resourceNames() {
+
+		data := ResourceText(name)
+
+		digest := sha256.Sum256([]byte(data))
+
+		source := "embedded"
+
+		resourcesMu.Lock()
+
+		if file, ok := overridePaths[name]; ok {
+
+			source = file
+
+		}
+
+		resourcesMu.Unlock()
+
+		resources = append(resources, ResourceInfo{Name: name, Version: resourceVersion(data), Source: source, SHA256: hex.EncodeToString(digest[:])})
+
+	}
+
+	return resources
+
+}
+
+// Sorted names of the registered resources
+
+func resourceNames() []string {
+
+	resourcesMu.Lock()
+
+	defer resourcesMu.Unlock()
+
+	var names []string
+
+	for name := range embeddedResources {
+
+		names = append(names, name)
+
+	}
+
+	sort.Strings(names)
+
+	return names
+
+}
+
+// Version declared by a "# version: ..." line among the leading comments of a resource
+
+func resourceVersion(data string) string {
+
+	for _, line := range strings.Split(data, "\n") {
+
+		line = strings.TrimSpace(line)
+
+		if !strings.HasPrefix(line, "#") {
+
+			break
+
+		}
+
+		if version, ok := strings.CutPrefix(strings.TrimSpace(strings.TrimPrefix(line, "#")), "version:"); ok {
+
+			return strings.TrimSpace(version)
+
+		}
+
+	}
+
+	return "unversioned"
+
+}
+
+// Words of the stopword resource
+
+var (
+	stopwordsOnce sync.Once
+
+	stopwords map[string]bool
+)
+
+// Parses the stopword list on first use
+
+func stopwordSet() map[string]bool {
+
+	stopwordsOnce.Do(func() {
+
+		stopwords = make(map[string]bool)
+
+		for _, line := range strings.Split(ResourceText("stopwords.txt"), "\n") {
+
+			if fields := strings.Fields(line); len(fields) > 0 && !strings.HasPrefix(fields[0], "#") {
+
+				stopwords[fields[0]] = true
+
+			}
+
+		}
+
+	})
+
+	return stopwords
+
+}
+
+// HSK levels of the words of the HSK resource
+
+var (
+	hskOnce sync.Once
+
+	hskLevels map[string]int
+)
+
+// HSKLevel returns the HSK level (1 for the easiest) of a word of the HSK vocabulary lists
+
+func HSKLevel(word string) (int, bool) {
+
+	hskOnce.Do(func() {
+
+		hskLevels = make(map[string]int)
+
+		for _, line := range strings.Split(ResourceText("hsk.txt"), "\n") {
+
+			fields := strings.Fields(line)
+
+			if len(fields) != 2 || strings.HasPrefix(fields[0], "#") {
+
+				continue
+
+			}
+
+			if level, err := strconv.Atoi(fields[1]); err == nil && level > 0 {
+
+				hskLevels[fields[0]] = level
+
+			}
+
+		}
+
+	})
+
+	level, ok := hskLevels[word]
+
+	return level, ok
+
+}
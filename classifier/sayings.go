@@ -0,0 +1,153 @@
+package classifier
+
+import (
+	_ "embed"
+
+	"strings"
+
+	"unicode"
+)
+
+//go:embed dict/proverbs.txt
+
+var proverbDictionary string
+
+//go:embed dict/xiehouyu.txt
+
+var xiehouyuDictionary string
+
+// Set of sayings keyed by their Han characters alone, for matching in running text whatever punctuation
+
+// separates their parts
+
+type sayingSet struct {
+	sayings map[string]string // Han characters → the saying as listed
+
+	maxLen int
+}
+
+// Han characters of a saying, without punctuation or the homophones given in brackets (照旧（舅）)
+
+func sayingKey(saying string) string {
+
+	var key strings.Builder
+
+	depth := 0
+
+	for _, r := range saying {
+
+		switch {
+
+		case r == '（' || r == '(':
+
+			depth++
+
+		case r == '）' || r == ')':
+
+			depth--
+
+		case depth == 0 && unicode.Is(unicode.Han, r):
+
+			key.WriteRune(r)
+
+		}
+
+	}
+
+	return key.String()
+
+}
+
+// Builds the set from the lines of a saying dictionary. With riddles, the part before —— of a xiehouyu is
+
+// a key of its own, since the answer is often left unsaid.
+
+func newSayingSet(resource string, riddles bool) sayingSet {
+
+	set := sayingSet{sayings: make(map[string]string)}
+
+	add := func(key, saying string) {
+
+		if len([]rune(key)) < 2 {
+
+			return
+
+		}
+
+		set.sayings[key] = saying
+
+		set.maxLen = max(set.maxLen, len([]rune(key)))
+
+	}
+
+	for _, line := range strings.Split(ResourceText(resource), "\n") {
+
+		saying := strings.TrimSpace(line)
+
+		if saying == "" || strings.HasPrefix(saying, "#") {
+
+			continue
+
+		}
+
+		add(sayingKey(saying), saying)
+
+		if riddle, _, ok := strings.Cut(saying, "——"); riddles && ok {
+
+			add(sayingKey(riddle), saying)
+
+		}
+
+	}
+
+	return set
+
+}
+
+// Finds sayings in the text, written with or without punctuation between their parts, as they are listed;
+
+// matches stay within a sentence, the longest wins and they do not overlap
+
+func (s sayingSet) find(text string) []string {
+
+	var found []string
+
+	for _, sentence := range SplitSentences(text) {
+
+		runes := []rune(sayingKey(sentence))
+
+		for i := 0; i < len(runes); {
+
+			matched := 0
+
+			for n := min(s.maxLen, len(runes)-i); n >= 2; n-- {
+
+				if _, ok := s.sayings[string(runes[i:i+n])]; ok {
+
+					matched = n
+
+					break
+
+				}
+
+			}
+
+			if matched == 0 {
+
+				i++
+
+				continue
+
+			}
+
+			found = append(found, s.sayings[string(runes[i:i+matched])])
+
+			i += matched
+
+		}
+
+	}
+
+	return found
+
+}
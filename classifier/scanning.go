@@ -0,0 +1,137 @@
+package classifier
+
+import (
+	"fmt"
+
+	"slices"
+
+	"sort"
+
+	"strings"
+)
+
+// Modes of resolving overlapping dictionary matches when idioms and slang are scanned in running text:
+// "greedy" takes the longest entry at each position from left to right, "longest" takes the longest matches
+// of a run first and shorter ones only in the gaps they leave, and "all" keeps every match, overlapping and
+// nested ones included
+
+var scanModes = []string{"greedy", "longest", "all"}
+
+// A dictionary entry found in a run of characters, runes [Start, End)
+
+type scanMatch struct {
+	Start int
+
+	End int
+}
+
+// WithScanMode sets how overlapping idiom and slang matches are counted: "greedy" (the default) takes the
+// longest entry at each position from left to right, "longest" prefers the longer of two overlapping entries
+// wherever it starts, and "all" counts every entry found, so an idiom nested in another counts too.
+
+func WithScanMode(mode string) Option {
+
+	return func(c *config) error {
+
+		mode = strings.ToLower(strings.TrimSpace(mode))
+
+		if !slices.Contains(scanModes, mode) {
+
+			return fmt.Errorf("unknown scan mode %q (available: %s)", mode, strings.Join(scanModes, ", "))
+
+		}
+
+		c.scanMode = mode
+
+		return nil
+
+	}
+
+}
+
+// ScanModes lists the modes WithScanMode accepts
+
+func ScanModes() []string {
+
+	return append([]string{}, scanModes...)
+
+}
+
+// ScanMode returns the mode overlapping idiom and slang matches are resolved by
+
+func (c *Classifier) ScanMode() string {
+
+	return c.scanMode
+
+}
+
+// Resolves the matches of a run, sorted by start and then longest first, by the scanning mode; the result
+// is in text order
+
+func resolveMatches(matches []scanMatch, mode string) []scanMatch {
+
+	switch mode {
+
+	case "all":
+
+		return matches
+
+	case "longest":
+
+		byLength := append([]scanMatch{}, matches...)
+
+		sort.SliceStable(byLength, func(i, j int) bool {
+
+			return byLength[i].End-byLength[i].Start > byLength[j].End-byLength[j].Start
+
+		})
+
+		var kept []scanMatch
+
+		for _, match := range byLength {
+
+			overlaps := false
+
+			for _, other := range kept {
+
+				if match.Start < other.End && other.Start < match.End {
+
+					overlaps = true
+
+					break
+
+				}
+
+			}
+
+			if !overlaps {
+
+				kept = append(kept, match)
+
+			}
+
+		}
+
+		sort.Slice(kept, func(i, j int) bool { return kept[i].Start < kept[j].Start })
+
+		return kept
+
+	default:
+
+		var kept []scanMatch
+
+		for _, match := range matches {
+
+			if len(kept) == 0 || match.Start >= kept[len(kept)-1].End {
+
+				kept = append(kept, match)
+
+			}
+
+		}
+
+		return kept
+
+	}
+
+}
@@ -0,0 +1,589 @@
+package classifier
+
+import (
+	"bufio"
+
+	_ "embed"
+
+	"fmt"
+
+	"io"
+
+	"math"
+
+	"strconv"
+
+	"strings"
+
+	"unicode"
+
+	"github.com/jdkato/prose/v2"
+)
+
+//go:embed dict/core.txt
+
+var coreDictionary string
+
+// Maps jieba-style dictionary tags onto the Penn tags used by the category rules
+
+var jiebaToPennTags = map[string]string{
+
+	"n": "NN", "nr": "NN", "ns": "NN", "nt": "NN", "nz": "NN", "ng": "NN", "t": "NN", "s": "NN", "f": "NN",
+
+	"i": "NN", "l": "NN", "j": "NN",
+
+	"v": "VB", "vn": "VB", "vd": "VB",
+
+	"a": "JJ", "ad": "JJ", "an": "JJ",
+
+	"d": "RB",
+
+	"r": "PRP", "p": "IN", "c": "CC", "m": "CD", "q": "CD",
+
+	"u": "RP", "uj": "RP", "ul": "RP", "uz": "RP", "ug": "RP", "uv": "RP", "ud": "RP",
+
+	"y": "UH", "e": "UH", "o": "UH",
+}
+
+// Dictionary entry holding a word's corpus frequency and jieba-style POS tag
+
+type dictEntry struct {
+	Frequency int
+
+	Tag string
+
+	Source string // Dictionary that defined the entry, e.g. "core.txt", "domains/it.txt" or a user dictionary file
+
+	layer dictionaryLayer
+}
+
+// Precedence of the dictionaries a word can come from
+
+type dictionaryLayer int
+
+// User dictionaries override domain dictionaries, which override the built-in resources
+
+const (
+	builtinLayer dictionaryLayer = iota
+
+	domainLayer
+
+	userLayer
+)
+
+// Word dictionary used to build the segmentation lattice
+
+type dictionary struct {
+	entries map[string]dictEntry
+
+	total float64
+
+	maxWordLen int
+
+	conflicts []DictionaryConflict // Words tagged differently by different dictionaries
+
+}
+
+// DictionaryDefinition is a word's entry in one dictionary
+
+type DictionaryDefinition struct {
+	Source string
+
+	Frequency int
+
+	Tag string
+}
+
+// DictionaryConflict is a word that two dictionaries tag differently: the definition kept by precedence and
+
+// the one it overrode
+
+type DictionaryConflict struct {
+	Word string
+
+	Kept DictionaryDefinition
+
+	Overridden DictionaryDefinition
+}
+
+// Creates a dictionary preloaded with the embedded core lexicon
+
+func newDictionary() (*dictionary, error) {
+
+	dict := &dictionary{entries: make(map[string]dictEntry)}
+
+	if err := dict.load(strings.NewReader(ResourceText("core.txt")), "core.txt", builtinLayer); err != nil {
+
+		return nil, fmt.Errorf("failed to load core dictionary: %v", err)
+
+	}
+
+	return dict, nil
+
+}
+
+// Loads "word [frequency] [tag]" lines of a dictionary of the given layer
+
+func (d *dictionary) load(r io.Reader, source string, layer dictionaryLayer) error {
+
+	scanner := bufio.NewScanner(r)
+
+	lineNumber := 0
+
+	for scanner.Scan() {
+
+		lineNumber++
+
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+
+			continue
+
+		}
+
+		fields := strings.Fields(line)
+
+		frequency := 0
+
+		tag := ""
+
+		if len(fields) > 1 {
+
+			parsed, err := strconv.Atoi(fields[1])
+
+			switch {
+
+			case err == nil:
+
+				frequency = parsed
+
+			case len(fields) == 2:
+
+				// "word tag": the frequency may be left out
+
+				tag = fields[1]
+
+			default:
+
+				return fmt.Errorf("invalid frequency on line %d: %v", lineNumber, err)
+
+			}
+
+		}
+
+		if len(fields) > 2 {
+
+			tag = fields[2]
+
+		}
+
+		d.define(fields[0], frequency, tag, source, layer)
+
+	}
+
+	return scanner.Err()
+
+}
+
+// Defines a word from a dictionary of the given layer. A higher layer replaces the frequency and tag of a lower
+
+// one, and within a layer the later dictionary wins; a lower layer only fills in a missing tag. A frequency of
+
+// 0 keeps the known one, or suggests one just high enough to keep a new word whole, and an empty tag keeps
+
+// the known tag. Different tags from different dictionaries are recorded as a conflict.
+
+func (d *dictionary) define(word string, frequency int, tag, source string, layer dictionaryLayer) {
+
+	existing, ok := d.entries[word]
+
+	if !ok {
+
+		if frequency == 0 {
+
+			frequency = d.suggestFrequency(word)
+
+		}
+
+		d.addWord(word, dictEntry{Frequency: frequency, Tag: tag, Source: source, layer: layer})
+
+		return
+
+	}
+
+	incoming := DictionaryDefinition{Source: source, Frequency: frequency, Tag: tag}
+
+	known := DictionaryDefinition{Source: existing.Source, Frequency: existing.Frequency, Tag: existing.Tag}
+
+	conflict := source != existing.Source && tag != "" && existing.Tag != "" && tag != existing.Tag
+
+	if layer < existing.layer {
+
+		if conflict {
+
+			d.conflicts = append(d.conflicts, DictionaryConflict{Word: word, Kept: known, Overridden: incoming})
+
+		}
+
+		if existing.Tag == "" {
+
+			existing.Tag = tag
+
+			d.entries[word] = existing
+
+		}
+
+		return
+
+	}
+
+	if conflict {
+
+		d.conflicts = append(d.conflicts, DictionaryConflict{Word: word, Kept: incoming, Overridden: known})
+
+	}
+
+	if frequency == 0 {
+
+		frequency = existing.Frequency
+
+	}
+
+	if tag == "" {
+
+		tag = existing.Tag
+
+	}
+
+	d.addWord(word, dictEntry{Frequency: frequency, Tag: tag, Source: source, layer: layer})
+
+}
+
+// Adds or replaces a word, keeping the total frequency consistent
+
+func (d *dictionary) addWord(word string, entry dictEntry) {
+
+	if existing, ok := d.entries[word]; ok {
+
+		d.total -= float64(existing.Frequency)
+
+	}
+
+	d.entries[word] = entry
+
+	d.total += float64(entry.Frequency)
+
+	if length := len([]rune(word)); length > d.maxWordLen {
+
+		d.maxWordLen = length
+
+	}
+
+}
+
+// Frequency just high enough for a word to be kept whole instead of split the way it segments now,
+
+// as jieba suggests for dictionary entries without a frequency
+
+func (d *dictionary) suggestFrequency(word string) int {
+
+	p := 1.0
+
+	for _, part := range d.segment(word) {
+
+		frequency := d.entries[part].Frequency
+
+		if frequency < 1 {
+
+			frequency = 1
+
+		}
+
+		p *= float64(frequency) / d.total
+
+	}
+
+	return max(1, int(p*d.total)+1)
+
+}
+
+// Adds a word of a built-in resource without discarding what the dictionary already knows about it, keeping
+
+// the higher frequency and existing tag; words of domain and user dictionaries stay as defined there
+
+func (d *dictionary) mergeWord(word string, frequency int, tag, source string) {
+
+	existing, ok := d.entries[word]
+
+	if ok && existing.layer > builtinLayer {
+
+		d.define(word, frequency, tag, source, builtinLayer)
+
+		return
+
+	}
+
+	if ok {
+
+		frequency = max(frequency, existing.Frequency)
+
+		if existing.Tag != "" {
+
+			tag = existing.Tag
+
+			source = existing.Source
+
+		}
+
+	}
+
+	d.addWord(word, dictEntry{Frequency: frequency, Tag: tag, Source: source})
+
+}
+
+// Segments a run of Chinese characters by choosing the most probable path through the word lattice
+
+func (d *dictionary) segment(text string) []string {
+
+	return d.bestPath([]rune(text), false)
+
+}
+
+// Splits a word into its most probable sub-words, never using the whole word itself
+
+func (d *dictionary) decompose(word string) []string {
+
+	return d.bestPath([]rune(word), true)
+
+}
+
+// Most probable path through the word lattice of runes, optionally excluding the single word spanning all of them
+
+func (d *dictionary) bestPath(runes []rune, excludeWhole bool) []string {
+
+	n := len(runes)
+
+	if n == 0 {
+
+		return nil
+
+	}
+
+	logTotal := math.Log(d.total)
+
+	// best[i] is the highest log probability of segmenting runes[i:], next[i] the end of its first word
+
+	best := make([]float64, n+1)
+
+	next := make([]int, n+1)
+
+	for i := n - 1; i >= 0; i-- {
+
+		best[i] = math.Inf(-1)
+
+		for j := i + 1; j <= n && j-i <= d.maxWordLen; j++ {
+
+			if excludeWhole && i == 0 && j == n {
+
+				continue
+
+			}
+
+			entry, ok := d.entries[string(runes[i:j])]
+
+			if !ok && j != i+1 {
+
+				continue
+
+			}
+
+			frequency := entry.Frequency
+
+			if frequency < 1 {
+
+				frequency = 1
+
+			}
+
+			score := math.Log(float64(frequency)) - logTotal + best[j]
+
+			if score > best[i] {
+
+				best[i] = score
+
+				next[i] = j
+
+			}
+
+		}
+
+	}
+
+	var words []string
+
+	for i := 0; i < n; i = next[i] {
+
+		words = append(words, string(runes[i:next[i]]))
+
+	}
+
+	return words
+
+}
+
+// Returns the Penn-style tag for a word, falling back to the tag assigned by prose
+
+func (d *dictionary) pennTag(word, fallback string) string {
+
+	if entry, ok := d.entries[word]; ok {
+
+		if tag, ok := jiebaToPennTags[entry.Tag]; ok {
+
+			return tag
+
+		}
+
+	}
+
+	return fallback
+
+}
+
+// Re-segments prose tokens so runs of Chinese characters are split into dictionary words
+
+func segmentTokens(tokens []prose.Token, dict *dictionary, segmenter Segmenter) ([]prose.Token, error) {
+
+	// All Chinese runs go to the segmenter in one batch
+
+	var runs []string
+
+	for _, tok := range tokens {
+
+		for _, run := range splitHanRuns(tok.Text) {
+
+			if unicode.Is(unicode.Han, []rune(run)[0]) {
+
+				runs = append(runs, run)
+
+			}
+
+		}
+
+	}
+
+	words, err := segmentRuns(segmenter, runs)
+
+	if err != nil {
+
+		return nil, err
+
+	}
+
+	var segmented []prose.Token
+
+	for _, tok := range tokens {
+
+		for _, run := range splitHanRuns(tok.Text) {
+
+			if !unicode.Is(unicode.Han, []rune(run)[0]) {
+
+				tag := tok.Tag
+
+				if isPunctuation(run) {
+
+					tag = "."
+
+				}
+
+				segmented = append(segmented, prose.Token{Text: run, Tag: tag, Label: tok.Label})
+
+				continue
+
+			}
+
+			for _, word := range words[0] {
+
+				segmented = append(segmented, prose.Token{Text: word, Tag: dict.pennTag(word, tok.Tag), Label: tok.Label})
+
+			}
+
+			words = words[1:]
+
+		}
+
+	}
+
+	return segmented, nil
+
+}
+
+// Splits text into alternating runs of Chinese and non-Chinese characters, dropping whitespace
+
+func splitHanRuns(text string) []string {
+
+	var runs []string
+
+	var current []rune
+
+	currentIsHan := false
+
+	for _, r := range text {
+
+		if unicode.IsSpace(r) {
+
+			if len(current) > 0 {
+
+				runs = append(runs, string(current))
+
+				current = nil
+
+			}
+
+			continue
+
+		}
+
+		isHan := unicode.Is(unicode.Han, r)
+
+		if len(current) > 0 && isHan != currentIsHan {
+
+			runs = append(runs, string(current))
+
+			current = nil
+
+		}
+
+		current = append(current, r)
+
+		currentIsHan = isHan
+
+	}
+
+	if len(current) > 0 {
+
+		runs = append(runs, string(current))
+
+	}
+
+	return runs
+
+}
+
+// Checks if a string consists only of punctuation and symbols
+
+func isPunctuation(text string) bool {
+
+	for _, r := range text {
+
+		if !unicode.IsPunct(r) && !unicode.IsSymbol(r) {
+
+			return false
+
+		}
+
+	}
+
+	return true
+
+}
@@ -0,0 +1,338 @@
+package classifier
+
+import (
+	"bufio"
+
+	"bytes"
+
+	"fmt"
+
+	"os/exec"
+
+	"sort"
+
+	"strings"
+)
+
+// Segmenter splits runs of Chinese characters into words. Runs come in batches, one text at a time,
+
+// so backends that run an external process or model can segment a whole text in one call. The words
+
+// of each run must join back into the run.
+
+type Segmenter interface {
+	Segment(runs []string) ([][]string, error)
+}
+
+// Built-in segmenters over the loaded dictionaries, selected by name with WithSegmentation
+
+var builtinSegmenters = map[string]func(d *dictionary) Segmenter{
+
+	// Most probable path through the word lattice, as jieba does; the default
+
+	"jieba": func(d *dictionary) Segmenter { return dagSegmenter{d} },
+
+	// Bidirectional maximum matching, which favors long dictionary words such as names and terms
+
+	"maxmatch": func(d *dictionary) Segmenter { return maxMatchSegmenter{d} },
+}
+
+// Segmenters lists the names of the built-in segmenters
+
+func Segmenters() []string {
+
+	var names []string
+
+	for name := range builtinSegmenters {
+
+		names = append(names, name)
+
+	}
+
+	sort.Strings(names)
+
+	return names
+
+}
+
+// WithSegmentation selects a built-in segmenter by name (see Segmenters)
+
+func WithSegmentation(name string) Option {
+
+	return func(c *config) error {
+
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		if _, ok := builtinSegmenters[name]; !ok {
+
+			return fmt.Errorf("unknown segmenter %q (available: %s)", name, strings.Join(Segmenters(), ", "))
+
+		}
+
+		c.segmentation = name
+
+		return nil
+
+	}
+
+}
+
+// WithSegmenter replaces the built-in segmenters, e.g. with an adapter for gse or pkuseg.
+
+// POS tags still come from the dictionaries and the tagger.
+
+func WithSegmenter(segmenter Segmenter) Option {
+
+	return func(c *config) error {
+
+		if segmenter == nil {
+
+			return fmt.Errorf("segmenter must not be nil")
+
+		}
+
+		c.segmenter = segmenter
+
+		return nil
+
+	}
+
+}
+
+// Segments with the most probable dictionary path
+
+type dagSegmenter struct {
+	dict *dictionary
+}
+
+func (s dagSegmenter) Segment(runs []string) ([][]string, error) {
+
+	words := make([][]string, len(runs))
+
+	for i, run := range runs {
+
+		words[i] = s.dict.segment(run)
+
+	}
+
+	return words, nil
+
+}
+
+// Segments by forward and backward maximum matching, keeping the result with fewer words, then fewer
+
+// single characters; ties go to backward matching, which is right more often for Chinese
+
+type maxMatchSegmenter struct {
+	dict *dictionary
+}
+
+func (s maxMatchSegmenter) Segment(runs []string) ([][]string, error) {
+
+	words := make([][]string, len(runs))
+
+	for i, run := range runs {
+
+		runes := []rune(run)
+
+		forward, backward := s.forward(runes), s.backward(runes)
+
+		if len(forward) < len(backward) || len(forward) == len(backward) && singleCharacters(forward) < singleCharacters(backward) {
+
+			words[i] = forward
+
+		} else {
+
+			words[i] = backward
+
+		}
+
+	}
+
+	return words, nil
+
+}
+
+// Takes the longest dictionary word at each position, left to right
+
+func (s maxMatchSegmenter) forward(runes []rune) []string {
+
+	var words []string
+
+	for i := 0; i < len(runes); {
+
+		j := min(i+s.dict.maxWordLen, len(runes))
+
+		for ; j > i+1; j-- {
+
+			if _, ok := s.dict.entries[string(runes[i:j])]; ok {
+
+				break
+
+			}
+
+		}
+
+		words = append(words, string(runes[i:j]))
+
+		i = j
+
+	}
+
+	return words
+
+}
+
+// Takes the longest dictionary word ending at each position, right to left
+
+func (s maxMatchSegmenter) backward(runes []rune) []string {
+
+	var words []string
+
+	for j := len(runes); j > 0; {
+
+		i := max(j-s.dict.maxWordLen, 0)
+
+		for ; i < j-1; i++ {
+
+			if _, ok := s.dict.entries[string(runes[i:j])]; ok {
+
+				break
+
+			}
+
+		}
+
+		words = append([]string{string(runes[i:j])}, words...)
+
+		j = i
+
+	}
+
+	return words
+
+}
+
+// Counts the words of one character
+
+func singleCharacters(words []string) int {
+
+	count := 0
+
+	for _, word := range words {
+
+		if len([]rune(word)) == 1 {
+
+			count++
+
+		}
+
+	}
+
+	return count
+
+}
+
+// NewCommandSegmenter runs an external segmenter, such as a pkuseg or gse wrapper script, for each batch:
+
+// the command reads one run per line on stdin and writes its words separated by spaces, one line per run
+
+func NewCommandSegmenter(command string) (Segmenter, error) {
+
+	fields := strings.Fields(command)
+
+	if len(fields) == 0 {
+
+		return nil, fmt.Errorf("segmenter command must not be empty")
+
+	}
+
+	return commandSegmenter{command: fields}, nil
+
+}
+
+// External segmenter process
+
+type commandSegmenter struct {
+	command []string
+}
+
+func (s commandSegmenter) Segment(runs []string) ([][]string, error) {
+
+	if len(runs) == 0 {
+
+		return nil, nil
+
+	}
+
+	cmd := exec.Command(s.command[0], s.command[1:]...)
+
+	cmd.Stdin = strings.NewReader(strings.Join(runs, "\n") + "\n")
+
+	output, err := cmd.Output()
+
+	if err != nil {
+
+		return nil, fmt.Errorf("segmenter command failed: %v", err)
+
+	}
+
+	var words [][]string
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+
+		words = append(words, strings.Fields(scanner.Text()))
+
+	}
+
+	if err := scanner.Err(); err != nil {
+
+		return nil, fmt.Errorf("failed to read segmenter output: %v", err)
+
+	}
+
+	if len(words) != len(runs) {
+
+		return nil, fmt.Errorf("segmenter command returned %d lines for %d runs", len(words), len(runs))
+
+	}
+
+	return words, nil
+
+}
+
+// Segments runs with a segmenter and checks that its words reproduce each run
+
+func segmentRuns(segmenter Segmenter, runs []string) ([][]string, error) {
+
+	words, err := segmenter.Segment(runs)
+
+	if err != nil {
+
+		return nil, err
+
+	}
+
+	if len(words) != len(runs) {
+
+		return nil, fmt.Errorf("segmenter returned %d results for %d runs", len(words), len(runs))
+
+	}
+
+	for i, run := range runs {
+
+		if strings.Join(words[i], "") != run {
+
+			return nil, fmt.Errorf("segmenter changed the text %q into %q", run, strings.Join(words[i], " "))
+
+		}
+
+	}
+
+	return words, nil
+
+}
@@ -0,0 +1,149 @@
+package classifier
+
+import (
+	"strings"
+
+	"unicode/utf8"
+
+	"github.com/jdkato/prose/v2"
+)
+
+// SentenceTerminators are the characters that end a Chinese sentence
+
+const SentenceTerminators = "。！？；…!?;"
+
+// Sentence is one sentence of a classified text, numbered from 1; its tokens are Result.Tokens[Start:End]
+
+type Sentence struct {
+	ID int
+
+	Text string
+
+	Start int
+
+	End int
+}
+
+// SplitSentences splits text into sentences on Chinese sentence-final punctuation, keeping the punctuation.
+
+// A line break within a sentence becomes a space.
+
+func SplitSentences(text string) []string {
+
+	var sentences []string
+
+	var current strings.Builder
+
+	flush := func() {
+
+		sentence := strings.TrimSpace(current.String())
+
+		if sentence != "" {
+
+			sentences = append(sentences, sentence)
+
+		}
+
+		current.Reset()
+
+	}
+
+	runes := []rune(text)
+
+	for i, r := range runes {
+
+		if r == '\n' {
+
+			r = ' '
+
+		}
+
+		current.WriteRune(r)
+
+		if strings.ContainsRune(SentenceTerminators, r) {
+
+			// Keep runs such as ！？ or …… attached to the sentence they end
+
+			if i+1 < len(runes) && strings.ContainsRune(SentenceTerminators, runes[i+1]) {
+
+				continue
+
+			}
+
+			flush()
+
+		}
+
+	}
+
+	flush()
+
+	return sentences
+
+}
+
+// Tokenizes each sentence on its own, so no token spans a sentence boundary. Returns the tokens and the
+
+// number of characters they cover per sentence.
+
+func (c *Classifier) tokenizeSentences(sentences []string) ([]prose.Token, []int, error) {
+
+	var tokens []prose.Token
+
+	lengths := make([]int, len(sentences))
+
+	for i, sentence := range sentences {
+
+		sentenceTokens, err := c.tokenizer(sentence)
+
+		if err != nil {
+
+			return nil, nil, err
+
+		}
+
+		for _, tok := range sentenceTokens {
+
+			lengths[i] += utf8.RuneCountInString(tok.Text)
+
+		}
+
+		tokens = append(tokens, sentenceTokens...)
+
+	}
+
+	return tokens, lengths, nil
+
+}
+
+// Finds the token range of every sentence. Segmentation only splits tokens into words that rejoin to them,
+
+// so the sentences' character counts from before segmentation still delimit them.
+
+func sentenceSpans(sentences []string, lengths []int, tokens []prose.Token) []Sentence {
+
+	spans := make([]Sentence, len(sentences))
+
+	start := 0
+
+	for i, sentence := range sentences {
+
+		end, covered := start, 0
+
+		for end < len(tokens) && covered < lengths[i] {
+
+			covered += utf8.RuneCountInString(tokens[end].Text)
+
+			end++
+
+		}
+
+		spans[i] = Sentence{ID: i + 1, Text: sentence, Start: start, End: end}
+
+		start = end
+
+	}
+
+	return spans
+
+}
@@ -0,0 +1,121 @@
+package classifier
+
+import (
+	"bufio"
+
+	_ "embed"
+
+	"fmt"
+
+	"io"
+
+	"strings"
+)
+
+//go:embed dict/slang.txt
+
+var slangLexicon string
+
+// SlangTerm is an entry of a slang lexicon: the term and its optional metadata, such as "meaning" and "since"
+
+type SlangTerm struct {
+	Term string
+
+	Meta map[string]string
+}
+
+// ParseSlang reads a slang lexicon: one term per line, optionally followed by tab-separated key=value
+
+// metadata; empty lines and # comments are skipped
+
+func ParseSlang(r io.Reader) ([]SlangTerm, error) {
+
+	var terms []SlangTerm
+
+	scanner := bufio.NewScanner(r)
+
+	line := 0
+
+	for scanner.Scan() {
+
+		line++
+
+		fields := strings.Split(strings.TrimSpace(scanner.Text()), "\t")
+
+		term := strings.TrimSpace(fields[0])
+
+		if term == "" || strings.HasPrefix(term, "#") {
+
+			continue
+
+		}
+
+		entry := SlangTerm{Term: term, Meta: make(map[string]string)}
+
+		for _, field := range fields[1:] {
+
+			key, value, ok := strings.Cut(strings.TrimSpace(field), "=")
+
+			if !ok || key == "" {
+
+				return nil, fmt.Errorf("line %d: metadata %q is not key=value", line, field)
+
+			}
+
+			entry.Meta[key] = value
+
+		}
+
+		terms = append(terms, entry)
+
+	}
+
+	if err := scanner.Err(); err != nil {
+
+		return nil, fmt.Errorf("failed to read slang lexicon: %v", err)
+
+	}
+
+	return terms, nil
+
+}
+
+// DefaultSlang returns the terms of the embedded slang lexicon (dict/slang.txt)
+
+func DefaultSlang() []SlangTerm {
+
+	terms, err := ParseSlang(strings.NewReader(ResourceText("slang.txt")))
+
+	if err != nil {
+
+		panic(fmt.Sprintf("invalid embedded slang lexicon: %v", err))
+
+	}
+
+	return terms
+
+}
+
+// WithSlangLexicon replaces the embedded slang lexicon, keeping the terms' metadata
+
+func WithSlangLexicon(terms ...SlangTerm) Option {
+
+	return func(c *config) error {
+
+		c.slang = append([]SlangTerm{}, terms...)
+
+		return nil
+
+	}
+
+}
+
+// Slang looks up a slang term, case-insensitively, with its metadata
+
+func (c *Classifier) Slang(term string) (SlangTerm, bool) {
+
+	entry, ok := c.slang[strings.ToLower(term)]
+
+	return entry, ok
+
+}
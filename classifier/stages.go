@@ -0,0 +1,220 @@
+package classifier
+
+import (
+	"fmt"
+
+	"sort"
+
+	"strings"
+
+	"time"
+)
+
+// Stage is one analyzer of the pipeline, which can be enabled or disabled on its own
+
+type Stage struct {
+	Name string
+
+	Description string
+
+	Categories []string // Categories the stage fills; empty for stages that only improve other stages
+
+	Cost time.Duration // Rough processing time per 10,000 characters
+
+}
+
+// Analyzers in pipeline order. Costs were measured on a laptop and are meant for comparing stages.
+
+var stages = []Stage{
+
+	{Name: "tagger", Description: "statistical POS tagger for words without a dictionary tag (loads a model on first use)", Cost: 400 * time.Millisecond},
+
+	{Name: "characters", Description: "individual characters", Categories: []string{"ChineseCharacters"}, Cost: 5 * time.Millisecond},
+
+	{Name: "pos", Description: "nouns, verbs, adjectives and adverbs", Categories: []string{"ChineseNouns", "ChineseVerbs", "ChineseAdjectives", "ChineseAdverbs", "ChineseOtherExpressions"}, Cost: 5 * time.Millisecond},
+
+	{Name: "function-words", Description: "prepositions, conjunctions and particles", Categories: []string{"ChineseFunctionWords"}, Cost: 5 * time.Millisecond},
+
+	{Name: "idioms", Description: "idiom lexicon lookup", Categories: []string{"ChineseIdioms"}, Cost: 10 * time.Millisecond},
+
+	{Name: "sayings", Description: "proverbs and two-part allegorical sayings (xiehouyu) lexicon lookup", Categories: []string{"ChineseProverbs", "ChineseXiehouyu"}, Cost: 10 * time.Millisecond},
+
+	{Name: "slang", Description: "slang lexicon lookup", Categories: []string{"ChineseSlang"}, Cost: 10 * time.Millisecond},
+
+	{Name: "entities", Description: "person, place and organization names (dictionary, title and suffix rules)", Categories: []string{"ChinesePersons", "ChinesePlaces", "ChineseOrganizations"}, Cost: 15 * time.Millisecond},
+
+	{Name: "loanwords", Description: "phonetic loanwords (lexicon and transliteration characters)", Categories: []string{"ChineseLoanwords"}, Cost: 5 * time.Millisecond},
+
+	{Name: "measure-words", Description: "measure words after numerals and demonstratives", Categories: []string{"ChineseMeasureWords"}, Cost: 5 * time.Millisecond},
+
+	{Name: "numbers", Description: "numbers, dates and times joined across tokens", Categories: []string{"ChineseNumbersDates"}, Cost: 5 * time.Millisecond},
+
+	{Name: "onomatopoeia", Description: "onomatopoeia (lexicon and reduplicated sound characters)", Categories: []string{"ChineseOnomatopoeia"}, Cost: 5 * time.Millisecond},
+
+	{Name: "abbreviations", Description: "abbreviations and Latin acronyms", Categories: []string{"ChineseAbbreviations"}, Cost: 20 * time.Millisecond},
+
+	{Name: "phrases", Description: "noun and verb phrase chunks", Categories: []string{"ChineseCommonPhrases", "ChineseNounPhrases", "ChineseVerbPhrases"}, Cost: 30 * time.Millisecond},
+
+	{Name: "dialogue", Description: "quoted speech with speaker attribution", Categories: []string{"ChineseDialogue"}, Cost: 5 * time.Millisecond},
+
+	{Name: "politeness", Description: "honorifics, humble forms and polite requests, thanks, apologies and greetings", Categories: []string{"ChinesePoliteness"}, Cost: 5 * time.Millisecond},
+
+	{Name: "domains", Description: "domain-term categories of the enabled domain dictionaries", Cost: 5 * time.Millisecond},
+}
+
+// Stage presets: fast skips the tagger model and phrase chunking, full runs everything
+
+var profiles = map[string][]string{
+
+	"fast": {"characters", "pos", "function-words", "idioms", "sayings", "slang", "entities", "loanwords", "measure-words", "numbers", "onomatopoeia", "abbreviations", "dialogue", "politeness", "domains"},
+
+	"full": stageNames(),
+}
+
+// Stages lists the pipeline stages in the order they run
+
+func Stages() []Stage {
+
+	return append([]Stage{}, stages...)
+
+}
+
+// Names of all stages
+
+func stageNames() []string {
+
+	var names []string
+
+	for _, stage := range stages {
+
+		names = append(names, stage.Name)
+
+	}
+
+	return names
+
+}
+
+// Profiles lists the names of the stage presets
+
+func Profiles() []string {
+
+	var names []string
+
+	for name := range profiles {
+
+		names = append(names, name)
+
+	}
+
+	sort.Strings(names)
+
+	return names
+
+}
+
+// ProfileStages returns the stages a preset such as "fast" or "full" enables
+
+func ProfileStages(profile string) ([]string, error) {
+
+	names, ok := profiles[strings.ToLower(strings.TrimSpace(profile))]
+
+	if !ok {
+
+		return nil, fmt.Errorf("unknown profile %q (available: %s)", profile, strings.Join(Profiles(), ", "))
+
+	}
+
+	return append([]string{}, names...), nil
+
+}
+
+// ParseStages parses a comma-separated list such as "pos,idioms" into validated stage names
+
+func ParseStages(value string) ([]string, error) {
+
+	var names []string
+
+	for _, name := range strings.Split(value, ",") {
+
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		if name == "" {
+
+			continue
+
+		}
+
+		if findStage(name) == nil {
+
+			return nil, fmt.Errorf("unknown stage %q (available: %s)", name, strings.Join(stageNames(), ", "))
+
+		}
+
+		names = append(names, name)
+
+	}
+
+	return names, nil
+
+}
+
+// Looks up a stage by name
+
+func findStage(name string) *Stage {
+
+	for i := range stages {
+
+		if stages[i].Name == name {
+
+			return &stages[i]
+
+		}
+
+	}
+
+	return nil
+
+}
+
+// EstimateDuration estimates how long the given stages take on a text of this many characters
+
+func EstimateDuration(names []string, characters int) time.Duration {
+
+	var perUnit time.Duration
+
+	for _, name := range names {
+
+		if stage := findStage(name); stage != nil {
+
+			perUnit += stage.Cost
+
+		}
+
+	}
+
+	return perUnit * time.Duration(characters) / 10000
+
+}
+
+// Stage that fills a category; domain categories belong to the domains stage
+
+func categoryStage(category string) string {
+
+	for _, stage := range stages {
+
+		for _, c := range stage.Categories {
+
+			if c == category {
+
+				return stage.Name
+
+			}
+
+		}
+
+	}
+
+	return "domains"
+
+}
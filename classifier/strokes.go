@@ -0,0 +1,67 @@
+package classifier
+
+import (
+	_ "embed"
+
+	"strconv"
+
+	"strings"
+
+	"sync"
+)
+
+//go:embed dict/strokes.txt
+
+var strokeTable string
+
+// Stroke count of each character of the stroke table, parsed once
+
+var (
+	strokesOnce sync.Once
+
+	strokeCounts map[rune]int
+)
+
+// Parses the embedded stroke table on first use
+
+func strokeEntries() map[rune]int {
+
+	strokesOnce.Do(func() {
+
+		strokeCounts = make(map[rune]int)
+
+		for _, line := range strings.Split(ResourceText("strokes.txt"), "\n") {
+
+			count, characters, ok := strings.Cut(strings.TrimSpace(line), "\t")
+
+			strokes, err := strconv.Atoi(count)
+
+			if !ok || err != nil {
+
+				continue
+
+			}
+
+			for _, r := range characters {
+
+				strokeCounts[r] = strokes
+
+			}
+
+		}
+
+	})
+
+	return strokeCounts
+
+}
+
+// StrokeCount returns the number of strokes of a character as written in mainland China, such as 9 for 说
+
+func StrokeCount(character rune) (int, bool) {
+
+	strokes, ok := strokeEntries()[character]
+
+	return strokes, ok
+
+}
@@ -0,0 +1,55 @@
+package classifier
+
+import "testing"
+
+// Mainland counts, including characters whose Taiwan count differs
+
+func TestStrokeCount(t *testing.T) {
+
+	tests := []struct {
+		character rune
+
+		want int
+	}{
+		{'一', 1},
+
+		{'人', 2},
+
+		{'长', 4},
+
+		{'瓦', 4},
+
+		{'瓶', 10},
+
+		{'巨', 4},
+
+		{'距', 11},
+
+		{'建', 8},
+
+		{'延', 6},
+
+		{'以', 4},
+
+		{'鼎', 12},
+
+		{'骨', 9},
+
+		{'黄', 11},
+
+		{'及', 3},
+
+		{'边', 5},
+	}
+
+	for _, test := range tests {
+
+		if got, ok := StrokeCount(test.character); !ok || got != test.want {
+
+			t.Errorf("StrokeCount(%q) = %d, %v, want %d", test.character, got, ok, test.want)
+
+		}
+
+	}
+
+}
@@ -0,0 +1,144 @@
+package classifier
+
+import (
+	"bufio"
+
+	"fmt"
+
+	"io"
+
+	"strings"
+)
+
+// Categories of POS tags from the jieba (lowercase), Chinese Treebank and Penn tagsets. The tagsets do not
+
+// clash: CTB and Penn share tags such as NN and JJ with the same meaning. Unmapped tags go to
+
+// ChineseOtherExpressions.
+
+var defaultTagCategories = map[string]string{
+
+	// jieba / ICTCLAS
+
+	"n": "ChineseNouns", "nr": "ChineseNouns", "ns": "ChineseNouns", "nt": "ChineseNouns", "nz": "ChineseNouns",
+
+	"ng": "ChineseNouns", "t": "ChineseNouns", "s": "ChineseNouns", "f": "ChineseNouns",
+
+	"i": "ChineseNouns", "l": "ChineseNouns", "j": "ChineseNouns",
+
+	"v": "ChineseVerbs", "vn": "ChineseVerbs", "vd": "ChineseVerbs",
+
+	"a": "ChineseAdjectives", "ad": "ChineseAdjectives", "an": "ChineseAdjectives",
+
+	"d": "ChineseAdverbs",
+
+	// Chinese Treebank
+
+	"NR": "ChineseNouns", "NT": "ChineseNouns",
+
+	"VV": "ChineseVerbs", "VC": "ChineseVerbs", "VE": "ChineseVerbs",
+
+	"VA": "ChineseAdjectives",
+
+	"AD": "ChineseAdverbs",
+
+	// Penn, as produced by the prose tagger; NN and JJ are shared with CTB
+
+	"NN": "ChineseNouns", "VB": "ChineseVerbs", "JJ": "ChineseAdjectives", "RB": "ChineseAdverbs",
+}
+
+// WithTagCategories maps POS tags to categories, overriding the defaults for the tags given. Tags are
+
+// jieba tags (n, v, a, d, m, q, r, p, c, u, ...), CTB tags (NN, VV, AD, ...) or Penn tags; a word's
+
+// dictionary tag is used when it has one, otherwise the tagger's.
+
+func WithTagCategories(mapping map[string]string) Option {
+
+	return func(c *config) error {
+
+		for tag, category := range mapping {
+
+			if c.tagCategories == nil {
+
+				c.tagCategories = make(map[string]string)
+
+			}
+
+			c.tagCategories[tag] = category
+
+		}
+
+		return nil
+
+	}
+
+}
+
+// ParseTagCategories reads a tag mapping, one "tag category" pair per line; # starts a comment
+
+func ParseTagCategories(r io.Reader) (map[string]string, error) {
+
+	mapping := make(map[string]string)
+
+	scanner := bufio.NewScanner(r)
+
+	lineNumber := 0
+
+	for scanner.Scan() {
+
+		lineNumber++
+
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+
+		fields := strings.Fields(line)
+
+		if len(fields) == 0 {
+
+			continue
+
+		}
+
+		if len(fields) != 2 {
+
+			return nil, fmt.Errorf("line %d: expected \"tag category\", got %q", lineNumber, strings.TrimSpace(line))
+
+		}
+
+		mapping[fields[0]] = fields[1]
+
+	}
+
+	if err := scanner.Err(); err != nil {
+
+		return nil, err
+
+	}
+
+	return mapping, nil
+
+}
+
+// Category of a token from its POS tag, preferring the native tag of its dictionary entry
+
+func (c *Classifier) tagCategory(text, tag string) string {
+
+	if entry, ok := c.dict.entries[text]; ok && entry.Tag != "" {
+
+		if category, ok := c.tagCategories[entry.Tag]; ok {
+
+			return category
+
+		}
+
+	}
+
+	if category, ok := c.tagCategories[tag]; ok {
+
+		return category
+
+	}
+
+	return "ChineseOtherExpressions"
+
+}
@@ -0,0 +1,79 @@
+package classifier
+
+import (
+	"strings"
+
+	"unicode"
+)
+
+// IsChineseText checks if a given string contains only Chinese characters
+
+func IsChineseText(text string) bool {
+
+	for _, r := range text {
+
+		if !unicode.Is(unicode.Han, r) && r != ' ' && r != '-' { // Allow spaces and hyphens
+
+			return false
+
+		}
+
+	}
+
+	return true
+
+}
+
+// Extracts and returns individual Chinese characters from a string
+
+func extractChineseCharacters(text string) []string {
+
+	var characters []string
+
+	for _, r := range text {
+
+		if unicode.Is(unicode.Han, r) {
+
+			characters = append(characters, string(r))
+
+		}
+
+	}
+
+	return characters
+
+}
+
+// CapitalizePhrase capitalizes the first character of each word or phrase, as items are counted
+
+func CapitalizePhrase(phrase string) string {
+
+	runes := []rune(phrase)
+
+	if len(runes) > 0 {
+
+		runes[0] = unicode.ToUpper(runes[0])
+
+	}
+
+	return string(runes)
+
+}
+
+// Checks whether the phrase equals any list entry, ignoring case
+
+func matchesPhraseList(phrase string, list []string) bool {
+
+	for _, item := range list {
+
+		if strings.EqualFold(item, phrase) {
+
+			return true
+
+		}
+
+	}
+
+	return false
+
+}
@@ -0,0 +1,142 @@
+package classifier
+
+import (
+	"fmt"
+
+	"regexp"
+
+	"strconv"
+
+	"strings"
+)
+
+// TimelineEntry is a date or time of the text with the sentence it was found in
+
+type TimelineEntry struct {
+	Sentence Sentence
+
+	Expression string // As written, e.g. 二〇二四年三月五日上午十点半
+
+	ISO string // ISO 8601 form, e.g. 2024-03-05T10:30; partial dates such as --03-05 keep only what was written
+
+}
+
+// Dates and times as NormalizeNumber writes them
+
+var (
+	isoDatePattern = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}$`)
+
+	yearMonthPattern = regexp.MustCompile(`^(公元)?([0-9]{4})年(([0-9]{1,2})月)?$`)
+
+	monthDayPattern = regexp.MustCompile(`^([0-9]{1,2})月(([0-9]{1,2})[日号])?$`)
+
+	timePattern = regexp.MustCompile(`^([0-9]{1,2}):([0-9]{2})$`)
+)
+
+// ISODate writes a date or time expression of ChineseNumbersDates in ISO 8601 form: 二〇二四年三月五日 →
+
+// 2024-03-05, 1998年 → 1998, 三月五日 → --03-05 and 下午三点半 → 15:30. Other numbers are not dates.
+
+func ISODate(expression string) (string, bool) {
+
+	normalized := NormalizeNumber(expression)
+
+	if isoDatePattern.MatchString(normalized) {
+
+		return normalized, true
+
+	}
+
+	if match := yearMonthPattern.FindStringSubmatch(normalized); match != nil {
+
+		if match[4] == "" {
+
+			return match[2], true
+
+		}
+
+		month, _ := strconv.Atoi(match[4])
+
+		return fmt.Sprintf("%s-%02d", match[2], month), month >= 1 && month <= 12
+
+	}
+
+	if match := monthDayPattern.FindStringSubmatch(normalized); match != nil {
+
+		month, _ := strconv.Atoi(match[1])
+
+		if match[3] == "" {
+
+			return fmt.Sprintf("--%02d", month), month >= 1 && month <= 12
+
+		}
+
+		day, _ := strconv.Atoi(match[3])
+
+		return fmt.Sprintf("--%02d-%02d", month, day), month >= 1 && month <= 12 && day >= 1 && day <= 31
+
+	}
+
+	if match := timePattern.FindStringSubmatch(normalized); match != nil {
+
+		hour, _ := strconv.Atoi(match[1])
+
+		minute, _ := strconv.Atoi(match[2])
+
+		return fmt.Sprintf("%02d:%02d", hour, minute), hour <= 24 && minute < 60
+
+	}
+
+	return "", false
+
+}
+
+// Timeline lists the dates and times of a classified text in text order. A time following a full date in
+
+// the same sentence is joined to it (2024-03-05T10:30).
+
+func Timeline(result *Result) []TimelineEntry {
+
+	var entries []TimelineEntry
+
+	for _, sentence := range result.Sentences {
+
+		expressions, _ := recognizeNumbers(result.Tokens[sentence.Start:sentence.End])
+
+		joinable := false
+
+		for _, expression := range expressions {
+
+			iso, ok := ISODate(expression)
+
+			if !ok {
+
+				joinable = false
+
+				continue
+
+			}
+
+			if last := len(entries) - 1; joinable && strings.Contains(iso, ":") {
+
+				entries[last].Expression += expression
+
+				entries[last].ISO += "T" + iso
+
+				joinable = false
+
+				continue
+
+			}
+
+			entries = append(entries, TimelineEntry{Sentence: sentence, Expression: expression, ISO: iso})
+
+			joinable = isoDatePattern.MatchString(iso)
+
+		}
+
+	}
+
+	return entries
+
+}
@@ -0,0 +1,99 @@
+package classifier
+
+import (
+	"strings"
+
+	"unicode"
+
+	"golang.org/x/text/width"
+)
+
+// DisplayWidth returns the number of terminal columns a string takes up: wide and full-width characters
+
+// such as Han, kana and full-width punctuation take two, combining marks none, and everything else one.
+
+// Ambiguous-width characters (…, →) count as one, as in most non-CJK terminal settings.
+
+func DisplayWidth(s string) int {
+
+	columns := 0
+
+	for _, r := range s {
+
+		columns += runeWidth(r)
+
+	}
+
+	return columns
+
+}
+
+// Columns one character takes up
+
+func runeWidth(r rune) int {
+
+	if unicode.Is(unicode.Mn, r) || r == '\u200b' {
+
+		return 0
+
+	}
+
+	switch width.LookupRune(r).Kind() {
+
+	case width.EastAsianWide, width.EastAsianFullwidth:
+
+		return 2
+
+	}
+
+	return 1
+
+}
+
+// PadRight pads a string with spaces to the given display width, for left-aligned columns
+
+func PadRight(s string, columns int) string {
+
+	return s + strings.Repeat(" ", max(columns-DisplayWidth(s), 0))
+
+}
+
+// PadLeft pads a string with spaces to the given display width, for right-aligned columns
+
+func PadLeft(s string, columns int) string {
+
+	return strings.Repeat(" ", max(columns-DisplayWidth(s), 0)) + s
+
+}
+
+// TruncateWidth shortens a string to at most the given display width, ending it with … when cut
+
+func TruncateWidth(s string, columns int) string {
+
+	if DisplayWidth(s) <= columns {
+
+		return s
+
+	}
+
+	var truncated strings.Builder
+
+	used := 0
+
+	for _, r := range s {
+
+		if used+runeWidth(r) > columns-1 {
+
+			break
+
+		}
+
+		truncated.WriteRune(r)
+
+		used += runeWidth(r)
+
+	}
+
+	return truncated.String() + "…"
+
+}
@@ -0,0 +1,327 @@
+// Package client talks to the job API of "cwClassifier serve" as described by its /openapi.json
+
+package client
+
+import (
+	"context"
+
+	"encoding/json"
+
+	"fmt"
+
+	"io"
+
+	"net/http"
+
+	"net/url"
+
+	"strings"
+
+	"time"
+)
+
+// Job is the state of a submitted document (schema Job)
+
+type Job struct {
+	ID string `json:"id"`
+
+	Status string `json:"status"` // queued, running, done or failed
+
+	LinesDone int `json:"lines_done"`
+
+	LinesTotal int `json:"lines_total"`
+
+	Error string `json:"error,omitempty"`
+
+	Submitted time.Time `json:"submitted"`
+
+	Finished time.Time `json:"finished,omitzero"`
+}
+
+// Done reports whether the job is done or failed
+
+func (j *Job) Done() bool {
+
+	return j.Status == "done" || j.Status == "failed"
+
+}
+
+// Results of a job, as in results.json (schema/results-v1.schema.json)
+
+type Results struct {
+	SchemaVersion string `json:"schemaVersion"`
+
+	Source string `json:"source"`
+
+	Seed int64 `json:"seed"`
+
+	Categories map[string][]Item `json:"categories"` // Category → items, most frequent first
+
+	Suppressed *Suppressed `json:"suppressed,omitempty"` // Occurrences a blocklist dropped
+
+	ScanMode string `json:"scanMode,omitempty"` // How overlapping idiom and slang matches were counted: greedy, longest or all
+
+}
+
+// Suppressed audits the occurrences a blocklist dropped from all categories
+
+type Suppressed struct {
+	Occurrences int `json:"occurrences"`
+
+	Items map[string]int `json:"items"` // Blocked item → occurrences dropped
+}
+
+// Item is a ranked item of a category
+
+type Item struct {
+	Item string `json:"item"`
+
+	Frequency int `json:"frequency"`
+
+	Confidence float64 `json:"confidence,omitempty"`
+
+	Gloss string `json:"gloss,omitempty"`
+
+	Morphemes []Morpheme `json:"morphemes,omitempty"`
+
+	Examples []string `json:"examples,omitempty"`
+
+	Pinyin string `json:"pinyin,omitempty"`
+
+	Converted string `json:"converted,omitempty"`
+
+	Forms []Form `json:"forms,omitempty"`
+
+	Normalized string `json:"normalized,omitempty"`
+
+	Strokes int `json:"strokes,omitempty"`
+
+	Decomposition string `json:"decomposition,omitempty"`
+
+	Band string `json:"band,omitempty"`
+}
+
+// Form is a surface form counted under an item
+
+type Form struct {
+	Form string `json:"form"`
+
+	Frequency int `json:"frequency"`
+}
+
+// Morpheme is a word-building part of a multi-character item
+
+type Morpheme struct {
+	Text string `json:"text"`
+
+	Pinyin string `json:"pinyin,omitempty"`
+
+	Gloss string `json:"gloss,omitempty"`
+}
+
+// SubmitOptions describe an uploaded document; empty fields take the server defaults
+
+type SubmitOptions struct {
+	Filename string // Its extension selects the reader when InputFormat is auto
+
+	InputFormat string // auto, text, html, srt, csv, jsonl, pdf or epub
+
+}
+
+// Error is a response of the API other than success, such as 404 for an unknown job or 409 for results
+
+// that are not ready
+
+type Error struct {
+	StatusCode int
+
+	Message string
+}
+
+func (e *Error) Error() string {
+
+	return fmt.Sprintf("cwClassifier API: %d %s", e.StatusCode, e.Message)
+
+}
+
+// Client of one server, such as http://localhost:8090
+
+type Client struct {
+	BaseURL string
+
+	HTTPClient *http.Client
+}
+
+// New returns a client for the server at baseURL
+
+func New(baseURL string) *Client {
+
+	return &Client{BaseURL: strings.TrimSuffix(baseURL, "/"), HTTPClient: &http.Client{Timeout: 5 * time.Minute}}
+
+}
+
+// Submit uploads a document and returns its queued job
+
+func (c *Client) Submit(ctx context.Context, document io.Reader, options SubmitOptions) (*Job, error) {
+
+	query := url.Values{}
+
+	if options.Filename != "" {
+
+		query.Set("filename", options.Filename)
+
+	}
+
+	if options.InputFormat != "" {
+
+		query.Set("input-format", options.InputFormat)
+
+	}
+
+	target := c.BaseURL + "/jobs"
+
+	if len(query) > 0 {
+
+		target += "?" + query.Encode()
+
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, target, document)
+
+	if err != nil {
+
+		return nil, err
+
+	}
+
+	request.Header.Set("Content-Type", "application/octet-stream")
+
+	var job Job
+
+	if err := c.do(request, &job); err != nil {
+
+		return nil, err
+
+	}
+
+	return &job, nil
+
+}
+
+// Job returns the current state of a job
+
+func (c *Client) Job(ctx context.Context, id string) (*Job, error) {
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+"/jobs/"+url.PathEscape(id), nil)
+
+	if err != nil {
+
+		return nil, err
+
+	}
+
+	var job Job
+
+	if err := c.do(request, &job); err != nil {
+
+		return nil, err
+
+	}
+
+	return &job, nil
+
+}
+
+// Results returns the results of a done job
+
+func (c *Client) Results(ctx context.Context, id string) (*Results, error) {
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+"/jobs/"+url.PathEscape(id)+"/results", nil)
+
+	if err != nil {
+
+		return nil, err
+
+	}
+
+	var results Results
+
+	if err := c.do(request, &results); err != nil {
+
+		return nil, err
+
+	}
+
+	return &results, nil
+
+}
+
+// Wait polls a job every interval until it is done or failed, or ctx ends
+
+func (c *Client) Wait(ctx context.Context, id string, interval time.Duration) (*Job, error) {
+
+	ticker := time.NewTicker(interval)
+
+	defer ticker.Stop()
+
+	for {
+
+		job, err := c.Job(ctx, id)
+
+		if err != nil {
+
+			return nil, err
+
+		}
+
+		if job.Done() {
+
+			return job, nil
+
+		}
+
+		select {
+
+		case <-ctx.Done():
+
+			return job, ctx.Err()
+
+		case <-ticker.C:
+
+		}
+
+	}
+
+}
+
+// Sends a request and decodes a JSON response into out; other responses become an *Error
+
+func (c *Client) do(request *http.Request, out interface{}) error {
+
+	response, err := c.HTTPClient.Do(request)
+
+	if err != nil {
+
+		return err
+
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode/100 != 2 {
+
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+
+		return &Error{StatusCode: response.StatusCode, Message: strings.TrimSpace(string(message))}
+
+	}
+
+	if err := json.NewDecoder(response.Body).Decode(out); err != nil {
+
+		return fmt.Errorf("failed to decode response: %v", err)
+
+	}
+
+	return nil
+
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"bufio"
+
+	"encoding/json"
+
+	"flag"
+
+	"fmt"
+
+	"os"
+
+	"path/filepath"
+
+	"sort"
+
+	"strings"
+)
+
+// Vocabulary differences of one category between two runs
+
+type categoryComparison struct {
+	Added []jsonItem `json:"added"`
+
+	Removed []jsonItem `json:"removed"`
+
+	Changed []comparisonRow `json:"changed"`
+}
+
+// Differences between two stored runs, written as comparison.json
+
+type runComparison struct {
+	A string `json:"a"`
+
+	B string `json:"b"`
+
+	Categories map[string]categoryComparison `json:"categories"`
+}
+
+// Handles "cwClassifier compare [--output dir] run1/ run2/"
+
+func runCompareCommand(args []string) error {
+
+	flags := flag.NewFlagSet("compare", flag.ContinueOnError)
+
+	output := flags.String("output", ".", "Directory for ChineseComparison.txt and comparison.json")
+
+	if err := flags.Parse(args); err != nil {
+
+		return err
+
+	}
+
+	if flags.NArg() != 2 {
+
+		return fmt.Errorf("usage: cwClassifier compare [--output dir] run1/ run2/ (directories with results.json from --format json)")
+
+	}
+
+	a, err := loadRunResults(flags.Arg(0))
+
+	if err != nil {
+
+		return err
+
+	}
+
+	b, err := loadRunResults(flags.Arg(1))
+
+	if err != nil {
+
+		return err
+
+	}
+
+	if schemaMajor(a.SchemaVersion) != schemaMajor(b.SchemaVersion) {
+
+		return fmt.Errorf("runs use incompatible schema versions %s and %s", a.SchemaVersion, b.SchemaVersion)
+
+	}
+
+	comparison := compareResults(flags.Arg(0), flags.Arg(1), a, b)
+
+	if err := os.MkdirAll(*output, 0755); err != nil {
+
+		return fmt.Errorf("failed to create output directory: %v", err)
+
+	}
+
+	if err := writeComparisonText(filepath.Join(*output, "ChineseComparison.txt"), comparison); err != nil {
+
+		return err
+
+	}
+
+	data, err := json.MarshalIndent(comparison, "", "  ")
+
+	if err != nil {
+
+		return fmt.Errorf("failed to encode comparison: %v", err)
+
+	}
+
+	if err := os.WriteFile(filepath.Join(*output, "comparison.json"), data, 0644); err != nil {
+
+		return fmt.Errorf("failed to write comparison: %v", err)
+
+	}
+
+	fmt.Printf("Compared %s and %s; wrote ChineseComparison.txt and comparison.json to %s\n", flags.Arg(0), flags.Arg(1), *output)
+
+	return nil
+
+}
+
+// Reads the results.json of a run, given its directory or the file itself
+
+func loadRunResults(path string) (jsonResults, error) {
+
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+
+		path = filepath.Join(path, "results.json")
+
+	}
+
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+
+		return jsonResults{}, fmt.Errorf("failed to read run: %v", err)
+
+	}
+
+	var results jsonResults
+
+	if err := json.Unmarshal(data, &results); err != nil {
+
+		return jsonResults{}, fmt.Errorf("failed to read run %s: %v", path, err)
+
+	}
+
+	return results, nil
+
+}
+
+// Major part of a schema version; runs are comparable when it matches
+
+func schemaMajor(version string) string {
+
+	return strings.SplitN(version, ".", 2)[0]
+
+}
+
+// Splits the items of every category into added, removed and changed ones, from run a to run b
+
+func compareResults(nameA, nameB string, a, b jsonResults) runComparison {
+
+	comparison := runComparison{A: nameA, B: nameB, Categories: make(map[string]categoryComparison)}
+
+	names := make(map[string]bool)
+
+	for name := range a.Categories {
+
+		names[name] = true
+
+	}
+
+	for name := range b.Categories {
+
+		names[name] = true
+
+	}
+
+	for name := range names {
+
+		category := categoryComparison{Added: []jsonItem{}, Removed: []jsonItem{}, Changed: []comparisonRow{}}
+
+		for _, row := range compareRuns(a.Categories[name], b.Categories[name]) {
+
+			switch {
+
+			case row.A == 0:
+
+				category.Added = append(category.Added, jsonItem{Item: row.Item, Frequency: row.B})
+
+			case row.B == 0:
+
+				category.Removed = append(category.Removed, jsonItem{Item: row.Item, Frequency: row.A})
+
+			case row.Delta != 0:
+
+				category.Changed = append(category.Changed, row)
+
+			}
+
+		}
+
+		comparison.Categories[name] = category
+
+	}
+
+	return comparison
+
+}
+
+// Writes ChineseComparison.txt: per category, added (+), removed (-) and changed (~) items with their
+
+// frequencies in both runs, in aligned columns
+
+func writeComparisonText(path string, comparison runComparison) error {
+
+	file, err := os.Create(path)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to create comparison: %v", err)
+
+	}
+
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+
+	fmt.Fprintf(writer, "# %s → %s\n", comparison.A, comparison.B)
+
+	var names []string
+
+	for name := range comparison.Categories {
+
+		names = append(names, name)
+
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+
+		category := comparison.Categories[name]
+
+		fmt.Fprintf(writer, "\n%s: %d added, %d removed, %d changed\n", name, len(category.Added), len(category.Removed), len(category.Changed))
+
+		var rows [][]string
+
+		for _, entry := range category.Added {
+
+			rows = append(rows, []string{"+", entry.Item, fmt.Sprintf("0 → %d", entry.Frequency), fmt.Sprintf("%+d", entry.Frequency)})
+
+		}
+
+		for _, entry := range category.Removed {
+
+			rows = append(rows, []string{"-", entry.Item, fmt.Sprintf("%d → 0", entry.Frequency), fmt.Sprintf("%+d", -entry.Frequency)})
+
+		}
+
+		for _, row := range category.Changed {
+
+			rows = append(rows, []string{"~", row.Item, fmt.Sprintf("%d → %d", row.A, row.B), fmt.Sprintf("%+d", row.Delta)})
+
+		}
+
+		writeTable(writer, nil, rows, []bool{false, false, true, true})
+
+	}
+
+	return writer.Flush()
+
+}
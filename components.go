@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bufio"
+
+	"fmt"
+
+	"sort"
+
+	"strconv"
+
+	"strings"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+
+	"golang.org/x/text/encoding"
+)
+
+// Characters of the text a component must be shared by to be listed in ChineseComponents.txt
+
+const minSharedComponent = 2
+
+// Ideographic description sequence of a single character, empty when it is not composed of others
+
+func characterDecomposition(character string) string {
+
+	runes := []rune(character)
+
+	if len(runes) != 1 {
+
+		return ""
+
+	}
+
+	sequence, _ := classifier.Decompose(runes[0])
+
+	return sequence
+
+}
+
+// Characters of the text that contain one component, at any level
+
+type componentGroup struct {
+	component string
+
+	frequency int
+
+	characters []classifier.ItemFrequency
+}
+
+// Writes ChineseComponents.txt: every component shared by several characters of ChineseCharacters (such as
+
+// 心 in 想, 意 and 感), with those characters by frequency, components in the most characters first
+
+func writeComponentReport(path string, characters []classifier.ItemFrequency, enc encoding.Encoding) error {
+
+	groups := make(map[string]*componentGroup)
+
+	for _, entry := range characters {
+
+		for _, component := range classifier.AllComponents([]rune(entry.Item)[0]) {
+
+			group := groups[component]
+
+			if group == nil {
+
+				group = &componentGroup{component: component}
+
+				groups[component] = group
+
+			}
+
+			group.frequency += entry.Frequency
+
+			group.characters = append(group.characters, entry)
+
+		}
+
+	}
+
+	var ordered []*componentGroup
+
+	for _, group := range groups {
+
+		if len(group.characters) >= minSharedComponent {
+
+			ordered = append(ordered, group)
+
+		}
+
+	}
+
+	sort.Slice(ordered, func(i, j int) bool {
+
+		if len(ordered[i].characters) != len(ordered[j].characters) {
+
+			return len(ordered[i].characters) > len(ordered[j].characters)
+
+		}
+
+		if ordered[i].frequency != ordered[j].frequency {
+
+			return ordered[i].frequency > ordered[j].frequency
+
+		}
+
+		return ordered[i].component < ordered[j].component
+
+	})
+
+	file, err := createOutputFile(path, enc)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to create component report: %v", err)
+
+	}
+
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+
+	var rows [][]string
+
+	for _, group := range ordered {
+
+		var listed []string
+
+		for _, entry := range group.characters {
+
+			listed = append(listed, fmt.Sprintf("%s(%d)", entry.Item, entry.Frequency))
+
+		}
+
+		rows = append(rows, []string{group.component, strconv.Itoa(len(group.characters)), strconv.Itoa(group.frequency), strings.Join(listed, " ")})
+
+	}
+
+	writeTable(writer, []string{"component", "characters", "frequency", "characters by frequency"}, rows, []bool{false, true, true, false})
+
+	return writer.Flush()
+
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"sort"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+)
+
+// Morpheme of a compound word as written to the JSON output
+
+type jsonMorpheme struct {
+	Text string `json:"text"`
+
+	Pinyin string `json:"pinyin,omitempty"`
+
+	Gloss string `json:"gloss,omitempty"`
+}
+
+// Splits every multi-character word of the word categories into its morphemes
+
+func decomposeWords(ranked map[string][]classifier.ItemFrequency, c *classifier.Classifier) map[string][]string {
+
+	decompositions := make(map[string][]string)
+
+	for _, category := range vocabularyCategories {
+
+		for _, entry := range ranked[category] {
+
+			if _, done := decompositions[entry.Item]; done {
+
+				continue
+
+			}
+
+			if morphemes := c.Decompose(entry.Item); len(morphemes) > 1 {
+
+				decompositions[entry.Item] = morphemes
+
+			}
+
+		}
+
+	}
+
+	return decompositions
+
+}
+
+// Distinct morphemes of all decompositions, sorted, for glossing
+
+func morphemeTexts(decompositions map[string][]string) []string {
+
+	seen := make(map[string]bool)
+
+	var texts []string
+
+	for _, morphemes := range decompositions {
+
+		for _, morpheme := range morphemes {
+
+			if !seen[morpheme] {
+
+				seen[morpheme] = true
+
+				texts = append(texts, morpheme)
+
+			}
+
+		}
+
+	}
+
+	sort.Strings(texts)
+
+	return texts
+
+}
+
+// Attaches pinyin and glosses to the morphemes of each decomposed word
+
+func morphemeDetails(decompositions map[string][]string, characters map[rune]characterInfo, glosses map[string]string) map[string][]jsonMorpheme {
+
+	details := make(map[string][]jsonMorpheme)
+
+	for word, morphemes := range decompositions {
+
+		for _, morpheme := range morphemes {
+
+			details[word] = append(details[word], jsonMorpheme{
+
+				Text: morpheme,
+
+				Pinyin: wordPinyin(morpheme, characters),
+
+				Gloss: glosses[morpheme],
+			})
+
+		}
+
+	}
+
+	return details
+
+}
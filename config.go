@@ -0,0 +1,287 @@
+package main
+
+import (
+	"bufio"
+
+	"flag"
+
+	"fmt"
+
+	"os"
+
+	"slices"
+
+	"strconv"
+
+	"strings"
+)
+
+// Options whose values are category=value pairs, which config files may also write as a YAML mapping or a
+
+// TOML table of that name
+
+var configMappings = []string{"category-files", "min-length"}
+
+// Option set in a config file, by flag name
+
+type configSetting struct {
+	Name string
+
+	Value string
+
+	Line int
+}
+
+// Reads a config file of option settings in the flat subset shared by YAML and TOML: "name: value" or
+
+// "name = value" per line with # comments, where names are flag names (format, min-length, dict) and lists,
+
+// [a, b] or YAML "- item" lines, become comma-separated values; TOML [sections] and YAML --- are skipped, but
+
+// for the options of configMappings a nested YAML mapping or a TOML table of the option's name gives its pairs
+
+func readConfigFile(path string) ([]configSetting, error) {
+
+	file, err := os.Open(path)
+
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to open config file: %v", err)
+
+	}
+
+	defer file.Close()
+
+	var settings []configSetting
+
+	// Setting whose pairs the following lines give, -1 outside a mapping; a TOML table lasts until the next one,
+
+	// a YAML mapping while its lines are indented
+
+	mapping, table := -1, false
+
+	scanner := bufio.NewScanner(file)
+
+	for number := 1; scanner.Scan(); number++ {
+
+		raw := stripConfigComment(scanner.Text())
+
+		line := strings.TrimSpace(raw)
+
+		if line == "" || line == "---" {
+
+			continue
+
+		}
+
+		if section, ok := strings.CutPrefix(line, "["); ok && strings.HasSuffix(line, "]") && !strings.ContainsAny(line, ":=") {
+
+			mapping, table = -1, false
+
+			if section = strings.ReplaceAll(strings.TrimSpace(strings.TrimSuffix(section, "]")), "_", "-"); slices.Contains(configMappings, section) {
+
+				settings = append(settings, configSetting{Name: section, Line: number})
+
+				mapping, table = len(settings)-1, true
+
+			}
+
+			continue
+
+		}
+
+		// A YAML list item extends the setting above it
+
+		if item, ok := strings.CutPrefix(line, "- "); ok {
+
+			if len(settings) == 0 {
+
+				return nil, fmt.Errorf("%s:%d: list item without an option", path, number)
+
+			}
+
+			last := &settings[len(settings)-1]
+
+			if last.Value != "" {
+
+				last.Value += ","
+
+			}
+
+			last.Value += unquoteConfigValue(strings.TrimSpace(item))
+
+			continue
+
+		}
+
+		separator := strings.IndexAny(line, ":=")
+
+		if separator < 0 {
+
+			return nil, fmt.Errorf("%s:%d: expected \"option: value\" or \"option = value\"", path, number)
+
+		}
+
+		name := strings.ReplaceAll(strings.TrimSpace(line[:separator]), "_", "-")
+
+		value := strings.TrimSpace(line[separator+1:])
+
+		if mapping >= 0 && (table || raw[0] == ' ' || raw[0] == '\t') {
+
+			pair := unquoteConfigValue(strings.TrimSpace(line[:separator])) + "=" + unquoteConfigValue(value)
+
+			if settings[mapping].Value != "" {
+
+				pair = "," + pair
+
+			}
+
+			settings[mapping].Value += pair
+
+			continue
+
+		}
+
+		mapping = -1
+
+		if list, ok := strings.CutPrefix(value, "["); ok && strings.HasSuffix(list, "]") {
+
+			var items []string
+
+			for _, item := range strings.Split(strings.TrimSuffix(list, "]"), ",") {
+
+				if item = strings.TrimSpace(item); item != "" {
+
+					items = append(items, unquoteConfigValue(item))
+
+				}
+
+			}
+
+			value = strings.Join(items, ",")
+
+		} else {
+
+			value = unquoteConfigValue(value)
+
+		}
+
+		settings = append(settings, configSetting{Name: name, Value: value, Line: number})
+
+		if value == "" && slices.Contains(configMappings, name) {
+
+			mapping = len(settings) - 1
+
+		}
+
+	}
+
+	if err := scanner.Err(); err != nil {
+
+		return nil, fmt.Errorf("failed to read config file: %v", err)
+
+	}
+
+	return settings, nil
+
+}
+
+// Drops a # comment from a config line, keeping # inside quoted values
+
+func stripConfigComment(line string) string {
+
+	var quote rune
+
+	for i, r := range line {
+
+		switch {
+
+		case quote != 0:
+
+			if r == quote {
+
+				quote = 0
+
+			}
+
+		case r == '"' || r == '\'':
+
+			quote = r
+
+		case r == '#':
+
+			return line[:i]
+
+		}
+
+	}
+
+	return line
+
+}
+
+// Removes the quotes of a quoted config value
+
+func unquoteConfigValue(value string) string {
+
+	if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+
+		if unquoted, err := strconv.Unquote(value); err == nil {
+
+			return unquoted
+
+		}
+
+	}
+
+	if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+
+		return value[1 : len(value)-1]
+
+	}
+
+	return value
+
+}
+
+// Sets the flags a config file names, except those given on the command line, which override it
+
+func applyConfig(flags *flag.FlagSet, path string) error {
+
+	settings, err := readConfigFile(path)
+
+	if err != nil {
+
+		return err
+
+	}
+
+	given := make(map[string]bool)
+
+	flags.Visit(func(f *flag.Flag) { given[f.Name] = true })
+
+	for _, setting := range settings {
+
+		if setting.Name == "config" || flags.Lookup(setting.Name) == nil {
+
+			return fmt.Errorf("%s:%d: unknown option %q", path, setting.Line, setting.Name)
+
+		}
+
+		if given[setting.Name] {
+
+			continue
+
+		}
+
+		if err := flags.Set(setting.Name, setting.Value); err != nil {
+
+			return fmt.Errorf("%s:%d: invalid value for %s: %v", path, setting.Line, setting.Name, err)
+
+		}
+
+	}
+
+	return nil
+
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+
+	"path/filepath"
+
+	"slices"
+
+	"testing"
+)
+
+func TestReadConfigFile(t *testing.T) {
+
+	tests := []struct {
+		name string
+
+		config string
+
+		want []configSetting
+
+		failed bool
+	}{
+		{"yaml", "format: txt\nmin-confidence: 0.5 # lower keeps more\n", []configSetting{{"format", "txt", 1}, {"min-confidence", "0.5", 2}}, false},
+
+		{"toml", "format = [\"txt\", \"json\"]\n[output]\ndict = 'a.dict'\n", []configSetting{{"format", "txt,json", 1}, {"dict", "a.dict", 3}}, false},
+
+		{"yaml list", "---\ndict:\n  - a.dict\n  - \"b.dict\"\n", []configSetting{{"dict", "a.dict,b.dict", 2}}, false},
+
+		{"yaml mapping", "min_length:\n  nouns: 2\n  verbs: 3\nformat: json\n", []configSetting{{"min-length", "nouns=2,verbs=3", 1}, {"format", "json", 4}}, false},
+
+		{"toml table", "[min-length]\nnouns = 2\nverbs = 3\n[other]\nseed = 7\n", []configSetting{{"min-length", "nouns=2,verbs=3", 1}, {"seed", "7", 5}}, false},
+
+		{"quoted hash", "output-dir: \"runs#1\" # kept apart\n", []configSetting{{"output-dir", "runs#1", 1}}, false},
+
+		{"list item without option", "- a.dict\n", nil, true},
+
+		{"no separator", "format txt\n", nil, true},
+	}
+
+	for _, test := range tests {
+
+		path := filepath.Join(t.TempDir(), "config.yaml")
+
+		if err := os.WriteFile(path, []byte(test.config), 0644); err != nil {
+
+			t.Fatal(err)
+
+		}
+
+		got, err := readConfigFile(path)
+
+		if (err != nil) != test.failed || !slices.Equal(got, test.want) {
+
+			t.Errorf("%s: readConfigFile = %v, %v; want %v, failed %v", test.name, got, err, test.want, test.failed)
+
+		}
+
+	}
+
+}
@@ -0,0 +1,481 @@
+package main
+
+import (
+	"crypto/sha256"
+
+	"encoding/hex"
+
+	"encoding/json"
+
+	"flag"
+
+	"fmt"
+
+	"os"
+
+	"path/filepath"
+
+	"regexp"
+
+	"slices"
+
+	"strconv"
+
+	"strings"
+
+	"time"
+)
+
+// Environment variable naming the corpus store directory
+
+const corpusStoreVariable = "CWCLASSIFIER_CORPORA"
+
+// Prefix of managed corpus names where a directory is expected, as in --batch corpus:news
+
+const corpusPrefix = "corpus:"
+
+// Corpus names double as directory names, so they cannot start with a dot
+
+var corpusNamePattern = regexp.MustCompile(`^[\p{L}\p{N}_][\p{L}\p{N}_.-]*$`)
+
+// Document copied into a managed corpus
+
+type corpusDocument struct {
+	Name string `json:"name"` // File name in the corpus's documents directory
+
+	Source string `json:"source"` // Path the document was added from
+
+	SHA256 string `json:"sha256"`
+
+	Bytes int `json:"bytes"`
+
+	Added time.Time `json:"added"`
+}
+
+// Manifest of a managed corpus, kept as corpus.json next to its documents directory
+
+type corpusManifest struct {
+	Name string `json:"name"`
+
+	Created time.Time `json:"created"`
+
+	Updated time.Time `json:"updated"`
+
+	Documents []corpusDocument `json:"documents"`
+}
+
+// Handles "cwClassifier corpus add|list|remove|stats [--store dir] name ...": named corpora of documents kept
+
+// in a local store, which --batch corpus:name analyzes
+
+func runCorpusCommand(args []string) error {
+
+	usage := fmt.Errorf("usage: cwClassifier corpus add name file...; corpus list [name]; corpus remove name [document...]; corpus stats name [stats flags]")
+
+	if len(args) == 0 {
+
+		return usage
+
+	}
+
+	flags := flag.NewFlagSet("corpus "+args[0], flag.ContinueOnError)
+
+	storeValue := flags.String("store", os.Getenv(corpusStoreVariable), "Directory of the corpus store (default $"+corpusStoreVariable+", or cwClassifier/corpora in the user config directory)")
+
+	if err := flags.Parse(args[1:]); err != nil {
+
+		return err
+
+	}
+
+	store, err := corpusStore(*storeValue)
+
+	if err != nil {
+
+		return err
+
+	}
+
+	switch {
+
+	case args[0] == "add" && flags.NArg() >= 2:
+
+		return addToCorpus(store, flags.Arg(0), flags.Args()[1:])
+
+	case args[0] == "list" && flags.NArg() <= 1:
+
+		return listCorpora(store, flags.Arg(0))
+
+	case args[0] == "remove" && flags.NArg() >= 1:
+
+		return removeFromCorpus(store, flags.Arg(0), flags.Args()[1:])
+
+	case args[0] == "stats" && flags.NArg() >= 1:
+
+		manifest, err := loadCorpus(store, flags.Arg(0))
+
+		if err != nil {
+
+			return err
+
+		}
+
+		if len(manifest.Documents) == 0 {
+
+			return fmt.Errorf("corpus %q has no documents", manifest.Name)
+
+		}
+
+		statsArgs := flags.Args()[1:]
+
+		for _, document := range manifest.Documents {
+
+			statsArgs = append(statsArgs, filepath.Join(store, manifest.Name, "documents", document.Name))
+
+		}
+
+		fmt.Printf("Corpus %s: %d documents\n\n", manifest.Name, len(manifest.Documents))
+
+		return runStatsCommand(statsArgs)
+
+	}
+
+	return usage
+
+}
+
+// Directory of the corpus store: the given one, or cwClassifier/corpora in the user config directory
+
+func corpusStore(dir string) (string, error) {
+
+	if dir != "" {
+
+		return dir, nil
+
+	}
+
+	configDir, err := os.UserConfigDir()
+
+	if err != nil {
+
+		return "", fmt.Errorf("failed to locate config directory: %v", err)
+
+	}
+
+	return filepath.Join(configDir, "cwClassifier", "corpora"), nil
+
+}
+
+// Reads the manifest of a corpus of the store
+
+func loadCorpus(store, name string) (corpusManifest, error) {
+
+	var manifest corpusManifest
+
+	if !corpusNamePattern.MatchString(name) {
+
+		return manifest, fmt.Errorf("invalid corpus name %q: use letters, digits, '.', '_' and '-', not starting with '.' or '-'", name)
+
+	}
+
+	data, err := os.ReadFile(filepath.Join(store, name, "corpus.json"))
+
+	if os.IsNotExist(err) {
+
+		return manifest, fmt.Errorf("no corpus %q in %s", name, store)
+
+	}
+
+	if err != nil {
+
+		return manifest, fmt.Errorf("failed to read corpus %s: %v", name, err)
+
+	}
+
+	if err := json.Unmarshal(data, &manifest); err != nil {
+
+		return manifest, fmt.Errorf("invalid manifest of corpus %s: %v", name, err)
+
+	}
+
+	return manifest, nil
+
+}
+
+// Writes the manifest of a corpus
+
+func saveCorpus(store string, manifest corpusManifest) error {
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+
+	if err != nil {
+
+		return fmt.Errorf("failed to encode corpus manifest: %v", err)
+
+	}
+
+	if err := os.WriteFile(filepath.Join(store, manifest.Name, "corpus.json"), data, 0644); err != nil {
+
+		return fmt.Errorf("failed to write corpus manifest: %v", err)
+
+	}
+
+	return nil
+
+}
+
+// Copies files into a corpus, creating it if needed; files whose content is already in it are skipped
+
+func addToCorpus(store, name string, files []string) error {
+
+	if !corpusNamePattern.MatchString(name) {
+
+		return fmt.Errorf("invalid corpus name %q: use letters, digits, '.', '_' and '-', not starting with '.' or '-'", name)
+
+	}
+
+	manifest := corpusManifest{Name: name, Created: time.Now().UTC()}
+
+	if _, err := os.Stat(filepath.Join(store, name, "corpus.json")); err == nil {
+
+		if manifest, err = loadCorpus(store, name); err != nil {
+
+			return err
+
+		}
+
+	}
+
+	documents := filepath.Join(store, name, "documents")
+
+	if err := os.MkdirAll(documents, os.ModePerm); err != nil {
+
+		return fmt.Errorf("failed to create corpus directory: %v", err)
+
+	}
+
+	added := 0
+
+	for _, file := range files {
+
+		data, err := os.ReadFile(file)
+
+		if err != nil {
+
+			return fmt.Errorf("failed to read %s: %v", file, err)
+
+		}
+
+		sum := sha256.Sum256(data)
+
+		hash := hex.EncodeToString(sum[:])
+
+		if slices.ContainsFunc(manifest.Documents, func(d corpusDocument) bool { return d.SHA256 == hash }) {
+
+			fmt.Printf("%s is already in corpus %s\n", file, name)
+
+			continue
+
+		}
+
+		// Documents of the same file name from different places are told apart by their hash
+
+		documentName := filepath.Base(file)
+
+		if slices.ContainsFunc(manifest.Documents, func(d corpusDocument) bool { return d.Name == documentName }) {
+
+			documentName = strings.TrimSuffix(documentName, filepath.Ext(documentName)) + "-" + hash[:8] + filepath.Ext(documentName)
+
+		}
+
+		if err := os.WriteFile(filepath.Join(documents, documentName), data, 0644); err != nil {
+
+			return fmt.Errorf("failed to copy %s: %v", file, err)
+
+		}
+
+		source, _ := filepath.Abs(file)
+
+		manifest.Documents = append(manifest.Documents, corpusDocument{Name: documentName, Source: source, SHA256: hash, Bytes: len(data), Added: time.Now().UTC()})
+
+		added++
+
+	}
+
+	manifest.Updated = time.Now().UTC()
+
+	if err := saveCorpus(store, manifest); err != nil {
+
+		return err
+
+	}
+
+	fmt.Printf("Added %d documents to corpus %s (%d in all); analyze it with --batch %s%s\n", added, name, len(manifest.Documents), corpusPrefix, name)
+
+	return nil
+
+}
+
+// Lists the corpora of the store, or the documents of one corpus
+
+func listCorpora(store, name string) error {
+
+	if name != "" {
+
+		manifest, err := loadCorpus(store, name)
+
+		if err != nil {
+
+			return err
+
+		}
+
+		var rows [][]string
+
+		for _, document := range manifest.Documents {
+
+			rows = append(rows, []string{document.Name, strconv.Itoa(document.Bytes), document.Added.Format(time.DateOnly), document.Source})
+
+		}
+
+		writeTable(os.Stdout, []string{"document", "bytes", "added", "source"}, rows, []bool{false, true, false, false})
+
+		return nil
+
+	}
+
+	entries, err := os.ReadDir(store)
+
+	if err != nil && !os.IsNotExist(err) {
+
+		return fmt.Errorf("failed to read corpus store: %v", err)
+
+	}
+
+	var rows [][]string
+
+	for _, entry := range entries {
+
+		manifest, err := loadCorpus(store, entry.Name())
+
+		if !entry.IsDir() || err != nil {
+
+			continue
+
+		}
+
+		bytes := 0
+
+		for _, document := range manifest.Documents {
+
+			bytes += document.Bytes
+
+		}
+
+		rows = append(rows, []string{manifest.Name, strconv.Itoa(len(manifest.Documents)), strconv.Itoa(bytes), manifest.Updated.Format(time.DateOnly)})
+
+	}
+
+	if len(rows) == 0 {
+
+		fmt.Printf("No corpora in %s; create one with cwClassifier corpus add name file...\n", store)
+
+		return nil
+
+	}
+
+	writeTable(os.Stdout, []string{"corpus", "documents", "bytes", "updated"}, rows, []bool{false, true, true, false})
+
+	return nil
+
+}
+
+// Removes documents from a corpus, or the whole corpus when none are named
+
+func removeFromCorpus(store, name string, documents []string) error {
+
+	manifest, err := loadCorpus(store, name)
+
+	if err != nil {
+
+		return err
+
+	}
+
+	if len(documents) == 0 {
+
+		if err := os.RemoveAll(filepath.Join(store, name)); err != nil {
+
+			return fmt.Errorf("failed to remove corpus %s: %v", name, err)
+
+		}
+
+		fmt.Printf("Removed corpus %s with its %d documents\n", name, len(manifest.Documents))
+
+		return nil
+
+	}
+
+	for _, document := range documents {
+
+		i := slices.IndexFunc(manifest.Documents, func(d corpusDocument) bool { return d.Name == document })
+
+		if i < 0 {
+
+			return fmt.Errorf("corpus %s has no document %q (see cwClassifier corpus list %s)", name, document, name)
+
+		}
+
+		if err := os.Remove(filepath.Join(store, name, "documents", document)); err != nil && !os.IsNotExist(err) {
+
+			return fmt.Errorf("failed to remove %s: %v", document, err)
+
+		}
+
+		manifest.Documents = slices.Delete(manifest.Documents, i, i+1)
+
+	}
+
+	manifest.Updated = time.Now().UTC()
+
+	if err := saveCorpus(store, manifest); err != nil {
+
+		return err
+
+	}
+
+	fmt.Printf("Removed %d documents from corpus %s (%d left)\n", len(documents), name, len(manifest.Documents))
+
+	return nil
+
+}
+
+// Directory a --batch value refers to: the documents of a managed corpus for corpus:name, the value otherwise
+
+func resolveCorpusPath(value string) (string, error) {
+
+	name, ok := strings.CutPrefix(value, corpusPrefix)
+
+	if !ok {
+
+		return value, nil
+
+	}
+
+	store, err := corpusStore(os.Getenv(corpusStoreVariable))
+
+	if err != nil {
+
+		return "", err
+
+	}
+
+	if _, err := loadCorpus(store, name); err != nil {
+
+		return "", err
+
+	}
+
+	return filepath.Join(store, name, "documents"), nil
+
+}
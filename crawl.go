@@ -0,0 +1,905 @@
+package main
+
+import (
+	"bufio"
+
+	"bytes"
+
+	"compress/gzip"
+
+	"encoding/xml"
+
+	"errors"
+
+	"fmt"
+
+	"io"
+
+	"mime"
+
+	"net/http"
+
+	"net/url"
+
+	"os"
+
+	"regexp"
+
+	"strconv"
+
+	"strings"
+
+	"sync"
+
+	"time"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// Identifies the crawler to the sites it visits
+
+const crawlUserAgent = "cwClassifier/1.0 (+https://github.com/ljg-cqu/txt-cwClassifier)"
+
+// Name matched against the User-agent lines of robots.txt
+
+const crawlRobotsName = "cwclassifier"
+
+// Largest page or sitemap the crawler reads
+
+const maxCrawlPageSize = 20 << 20
+
+// Longest wait a Retry-After header can ask for
+
+const maxCrawlRetryAfter = 5 * time.Minute
+
+// Settings of --crawl
+
+type crawlOptions struct {
+	Concurrency int // Pages fetched at once across all hosts
+
+	Delay time.Duration // Least time between two requests to one host; a longer robots.txt Crawl-delay wins
+
+	Retries int // Retries of a request after network errors, 429 and 5xx responses, with exponential backoff
+
+	Messages io.Writer // Warnings about unreachable robots.txt files; nil writes them to standard output
+
+}
+
+// Fetches pages politely: requests to a host go one at a time, spaced by the delay, and respect its robots.txt
+
+type crawler struct {
+	options crawlOptions
+
+	client *http.Client
+
+	mu sync.Mutex
+
+	hosts map[string]*crawlHost
+}
+
+// Politeness state of one host (scheme and authority)
+
+type crawlHost struct {
+	turn sync.Mutex // Held for each request to the host, from waiting for its slot until the response is read
+
+	next time.Time
+
+	robotsOnce sync.Once
+
+	robots *robotsRules
+}
+
+// A fetched page, or why it could not be fetched
+
+type crawledPage struct {
+	URL string
+
+	Data []byte
+
+	ContentType string
+
+	Err error
+}
+
+func newCrawler(options crawlOptions) *crawler {
+
+	if options.Messages == nil {
+
+		options.Messages = os.Stdout
+
+	}
+
+	return &crawler{options: options, client: &http.Client{Timeout: 60 * time.Second}, hosts: make(map[string]*crawlHost)}
+
+}
+
+// Politeness state of the host of a URL
+
+func (c *crawler) host(target *url.URL) *crawlHost {
+
+	c.mu.Lock()
+
+	defer c.mu.Unlock()
+
+	key := target.Scheme + "://" + target.Host
+
+	if c.hosts[key] == nil {
+
+		c.hosts[key] = &crawlHost{}
+
+	}
+
+	return c.hosts[key]
+
+}
+
+// Waits for the host's next request slot; the caller holds the host's turn
+
+func (c *crawler) wait(host *crawlHost) {
+
+	delay := c.options.Delay
+
+	if host.robots != nil && host.robots.delay > delay {
+
+		delay = host.robots.delay
+
+	}
+
+	time.Sleep(time.Until(host.next))
+
+	host.next = time.Now().Add(delay)
+
+}
+
+// Fetches a URL, retrying network errors, 429 and 5xx responses with exponential backoff or as Retry-After asks
+
+func (c *crawler) get(target *url.URL) ([]byte, string, error) {
+
+	host := c.host(target)
+
+	backoff := time.Second
+
+	for attempt := 0; ; attempt++ {
+
+		// Backoff waits below happen outside the turn, so other pages of the host may go first
+
+		host.turn.Lock()
+
+		c.wait(host)
+
+		data, contentType, retryAfter, err := c.fetch(target)
+
+		host.turn.Unlock()
+
+		if err == nil || retryAfter < 0 || attempt >= c.options.Retries {
+
+			return data, contentType, err
+
+		}
+
+		if retryAfter == 0 {
+
+			retryAfter = backoff
+
+			backoff *= 2
+
+		}
+
+		time.Sleep(retryAfter)
+
+	}
+
+}
+
+// Sends one request. A retryable failure returns a non-negative wait (0 for the default backoff); others return -1.
+
+func (c *crawler) fetch(target *url.URL) ([]byte, string, time.Duration, error) {
+
+	request, err := http.NewRequest(http.MethodGet, target.String(), nil)
+
+	if err != nil {
+
+		return nil, "", -1, err
+
+	}
+
+	request.Header.Set("User-Agent", crawlUserAgent)
+
+	response, err := c.client.Do(request)
+
+	if err != nil {
+
+		return nil, "", 0, err
+
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusTooManyRequests || response.StatusCode/100 == 5 {
+
+		retryAfter := time.Duration(0)
+
+		if seconds, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil && seconds > 0 {
+
+			retryAfter = min(time.Duration(seconds)*time.Second, maxCrawlRetryAfter)
+
+		}
+
+		return nil, "", retryAfter, &crawlStatusError{StatusCode: response.StatusCode, Status: response.Status}
+
+	}
+
+	if response.StatusCode != http.StatusOK {
+
+		return nil, "", -1, &crawlStatusError{StatusCode: response.StatusCode, Status: response.Status}
+
+	}
+
+	data, err := io.ReadAll(io.LimitReader(response.Body, maxCrawlPageSize+1))
+
+	if err != nil {
+
+		return nil, "", 0, err
+
+	}
+
+	if len(data) > maxCrawlPageSize {
+
+		return nil, "", -1, fmt.Errorf("larger than %d MB", maxCrawlPageSize>>20)
+
+	}
+
+	return data, response.Header.Get("Content-Type"), -1, nil
+
+}
+
+// Response other than 200 OK, kept apart from network errors so that callers can branch on the status code
+
+type crawlStatusError struct {
+	StatusCode int
+
+	Status string
+}
+
+func (e *crawlStatusError) Error() string {
+
+	return e.Status
+
+}
+
+// Reports whether robots.txt of the URL's host lets the crawler fetch it, fetching robots.txt on first use
+
+func (c *crawler) allowed(target *url.URL) bool {
+
+	host := c.host(target)
+
+	host.robotsOnce.Do(func() {
+
+		robotsURL := &url.URL{Scheme: target.Scheme, Host: target.Host, Path: "/robots.txt"}
+
+		data, _, err := c.get(robotsURL)
+
+		var statusErr *crawlStatusError
+
+		switch {
+
+		case err == nil:
+
+			host.robots = parseRobots(data, crawlRobotsName)
+
+		case errors.As(err, &statusErr) && statusErr.StatusCode/100 == 4 && statusErr.StatusCode != http.StatusTooManyRequests:
+
+			// No robots.txt (or one we may not read) means no restrictions
+
+			host.robots = &robotsRules{}
+
+		default:
+
+			// An unreachable robots.txt (network errors, 429 and 5xx after retries) means the site does not want
+
+			// crawling right now, as RFC 9309 asks
+
+			fmt.Fprintf(c.options.Messages, "Warning: robots.txt of %s is unreachable (%v); skipping its pages\n", target.Host, err)
+
+			host.robots = &robotsRules{rules: []robotsRule{{pattern: regexp.MustCompile("^/"), length: 1}}}
+
+		}
+
+	})
+
+	path := target.EscapedPath()
+
+	if path == "" {
+
+		path = "/"
+
+	}
+
+	if target.RawQuery != "" {
+
+		path += "?" + target.RawQuery
+
+	}
+
+	return host.robots.allows(path)
+
+}
+
+// Fetches every URL with at most Concurrency requests at once; pages come back in the order of the URLs
+
+func (c *crawler) crawl(urls []*url.URL) []crawledPage {
+
+	pages := make([]crawledPage, len(urls))
+
+	slots := make(chan struct{}, c.options.Concurrency)
+
+	var wg sync.WaitGroup
+
+	for i, target := range urls {
+
+		wg.Add(1)
+
+		slots <- struct{}{}
+
+		go func() {
+
+			defer wg.Done()
+
+			defer func() { <-slots }()
+
+			pages[i].URL = target.String()
+
+			if !c.allowed(target) {
+
+				pages[i].Err = fmt.Errorf("disallowed by robots.txt")
+
+				return
+
+			}
+
+			pages[i].Data, pages[i].ContentType, pages[i].Err = c.get(target)
+
+		}()
+
+	}
+
+	wg.Wait()
+
+	return pages
+
+}
+
+// Reads the URLs to crawl from a sitemap (a URL or file, possibly gzipped, or a sitemap index) or from a file
+
+// with one URL per line; # comments and blank lines are skipped, and repeated URLs are crawled once
+
+func (c *crawler) loadURLList(spec string) ([]*url.URL, error) {
+
+	var data []byte
+
+	var err error
+
+	if strings.HasPrefix(spec, "http://") || strings.HasPrefix(spec, "https://") {
+
+		var location *url.URL
+
+		location, err = url.Parse(spec)
+
+		if err == nil {
+
+			data, _, err = c.get(location)
+
+		}
+
+	} else {
+
+		data, err = os.ReadFile(spec)
+
+	}
+
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to read URL list %s: %v", spec, err)
+
+	}
+
+	var list []string
+
+	if isSitemap(data) {
+
+		list, err = c.sitemapURLs(data, make(map[string]bool))
+
+		if err != nil {
+
+			return nil, err
+
+		}
+
+	} else {
+
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+
+		for scanner.Scan() {
+
+			if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+
+				list = append(list, line)
+
+			}
+
+		}
+
+		if err := scanner.Err(); err != nil {
+
+			return nil, fmt.Errorf("failed to read URL list %s: %v", spec, err)
+
+		}
+
+	}
+
+	var urls []*url.URL
+
+	seen := make(map[string]bool)
+
+	for _, text := range list {
+
+		target, err := url.Parse(text)
+
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+
+			return nil, fmt.Errorf("invalid URL %q in %s", text, spec)
+
+		}
+
+		target.Fragment = ""
+
+		if !seen[target.String()] {
+
+			seen[target.String()] = true
+
+			urls = append(urls, target)
+
+		}
+
+	}
+
+	return urls, nil
+
+}
+
+// Sitemap or sitemap index document (sitemaps.org protocol)
+
+type sitemapDocument struct {
+	URLs []struct {
+		Loc string `xml:"loc"`
+	} `xml:"url"`
+
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+// Reports whether data is a sitemap, gzipped or not, rather than a plain URL list
+
+func isSitemap(data []byte) bool {
+
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+
+		return true
+
+	}
+
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+
+	return bytes.HasPrefix(trimmed, []byte("<?xml")) || bytes.HasPrefix(trimmed, []byte("<urlset")) || bytes.HasPrefix(trimmed, []byte("<sitemapindex"))
+
+}
+
+// Page URLs of a sitemap, following the sitemaps of an index; seen guards against index loops
+
+func (c *crawler) sitemapURLs(data []byte, seen map[string]bool) ([]string, error) {
+
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+
+		if err != nil {
+
+			return nil, fmt.Errorf("failed to read sitemap: %v", err)
+
+		}
+
+		data, err = io.ReadAll(io.LimitReader(reader, maxCrawlPageSize))
+
+		if err != nil {
+
+			return nil, fmt.Errorf("failed to read sitemap: %v", err)
+
+		}
+
+	}
+
+	var document sitemapDocument
+
+	if err := xml.Unmarshal(data, &document); err != nil {
+
+		return nil, fmt.Errorf("failed to parse sitemap: %v", err)
+
+	}
+
+	var urls []string
+
+	for _, entry := range document.URLs {
+
+		urls = append(urls, strings.TrimSpace(entry.Loc))
+
+	}
+
+	for _, entry := range document.Sitemaps {
+
+		loc := strings.TrimSpace(entry.Loc)
+
+		if seen[loc] {
+
+			continue
+
+		}
+
+		seen[loc] = true
+
+		location, err := url.Parse(loc)
+
+		if err != nil {
+
+			return nil, fmt.Errorf("invalid sitemap URL %q: %v", loc, err)
+
+		}
+
+		child, _, err := c.get(location)
+
+		if err != nil {
+
+			return nil, fmt.Errorf("failed to fetch sitemap %s: %v", loc, err)
+
+		}
+
+		childURLs, err := c.sitemapURLs(child, seen)
+
+		if err != nil {
+
+			return nil, err
+
+		}
+
+		urls = append(urls, childURLs...)
+
+	}
+
+	return urls, nil
+
+}
+
+// Rules of the robots.txt group that applies to the crawler
+
+type robotsRules struct {
+	rules []robotsRule
+
+	delay time.Duration // Crawl-delay, which not every site sets
+
+}
+
+// Allow or Disallow line, with * and $ as in RFC 9309
+
+type robotsRule struct {
+	pattern *regexp.Regexp
+
+	length int // Length of the path pattern; the longest matching rule decides
+
+	allow bool
+}
+
+// Parses robots.txt, keeping the group of the named crawler, or the * group when no group names it
+
+func parseRobots(data []byte, name string) *robotsRules {
+
+	groups := make(map[string]*robotsRules)
+
+	var agents []string
+
+	inRules := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	for scanner.Scan() {
+
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+
+		field, value, ok := strings.Cut(line, ":")
+
+		if !ok {
+
+			continue
+
+		}
+
+		field = strings.ToLower(strings.TrimSpace(field))
+
+		value = strings.TrimSpace(value)
+
+		if field == "user-agent" {
+
+			// Consecutive User-agent lines share the rules that follow them
+
+			if inRules {
+
+				agents = nil
+
+				inRules = false
+
+			}
+
+			agent := strings.ToLower(value)
+
+			agents = append(agents, agent)
+
+			if groups[agent] == nil {
+
+				groups[agent] = &robotsRules{}
+
+			}
+
+			continue
+
+		}
+
+		if len(agents) == 0 {
+
+			continue
+
+		}
+
+		inRules = true
+
+		for _, agent := range agents {
+
+			group := groups[agent]
+
+			switch field {
+
+			case "allow", "disallow":
+
+				if value == "" {
+
+					continue
+
+				}
+
+				expression := "^" + strings.ReplaceAll(regexp.QuoteMeta(value), `\*`, ".*")
+
+				if strings.HasSuffix(expression, `\$`) {
+
+					expression = strings.TrimSuffix(expression, `\$`) + "$"
+
+				}
+
+				if pattern, err := regexp.Compile(expression); err == nil {
+
+					group.rules = append(group.rules, robotsRule{pattern: pattern, length: len(value), allow: field == "allow"})
+
+				}
+
+			case "crawl-delay":
+
+				if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+
+					group.delay = time.Duration(seconds * float64(time.Second))
+
+				}
+
+			}
+
+		}
+
+	}
+
+	// The longest agent naming the crawler wins, so the choice does not depend on map order
+
+	best := ""
+
+	for agent := range groups {
+
+		if agent != "*" && strings.Contains(name, agent) && (len(agent) > len(best) || len(agent) == len(best) && agent < best) {
+
+			best = agent
+
+		}
+
+	}
+
+	if best != "" {
+
+		return groups[best]
+
+	}
+
+	if group, ok := groups["*"]; ok {
+
+		return group
+
+	}
+
+	return &robotsRules{}
+
+}
+
+// Reports whether a path (with its query) may be fetched: the longest matching rule decides, Allow winning ties
+
+func (r *robotsRules) allows(path string) bool {
+
+	best := -1
+
+	allowed := true
+
+	for _, rule := range r.rules {
+
+		if !rule.pattern.MatchString(path) || rule.length < best || (rule.length == best && !rule.allow) {
+
+			continue
+
+		}
+
+		best = rule.length
+
+		allowed = rule.allow
+
+	}
+
+	return allowed
+
+}
+
+// Input format of a page from its Content-Type, "auto" when the type says nothing useful
+
+func crawlFormat(contentType, inputFormat string) string {
+
+	if inputFormat != "auto" {
+
+		return inputFormat
+
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	switch mediaType {
+
+	case "text/html", "application/xhtml+xml":
+
+		return "html"
+
+	case "application/pdf":
+
+		return "pdf"
+
+	case "application/epub+zip":
+
+		return "epub"
+
+	case "text/csv":
+
+		return "csv"
+
+	case "text/plain":
+
+		return "text"
+
+	}
+
+	return "auto"
+
+}
+
+// Converts a text page declared in another charset, such as GBK or Big5, to UTF-8
+
+func decodePage(data []byte, contentType string) ([]byte, error) {
+
+	_, params, _ := mime.ParseMediaType(contentType)
+
+	charset := strings.ToLower(params["charset"])
+
+	if charset == "" || charset == "utf-8" || charset == "utf8" {
+
+		return data, nil
+
+	}
+
+	enc, err := htmlindex.Get(charset)
+
+	if err != nil {
+
+		return nil, fmt.Errorf("unsupported charset %q", charset)
+
+	}
+
+	return enc.NewDecoder().Bytes(data)
+
+}
+
+// Crawls the URLs of a list or sitemap and analyzes the pages as one corpus, each page a section
+
+func categorizeCrawl(spec string, settings crawlOptions, options analysisOptions) error {
+
+	settings.Messages = options.messages()
+
+	c := newCrawler(settings)
+
+	urls, err := c.loadURLList(spec)
+
+	if err != nil {
+
+		return err
+
+	}
+
+	if len(urls) == 0 {
+
+		return fmt.Errorf("no URLs in %s", spec)
+
+	}
+
+	fmt.Fprintf(options.messages(), "Crawling %d pages, %d at a time\n", len(urls), settings.Concurrency)
+
+	var documents []batchDocument
+
+	for _, page := range c.crawl(urls) {
+
+		if page.Err != nil {
+
+			fmt.Fprintf(options.messages(), "Skipping %s: %v\n", page.URL, page.Err)
+
+			continue
+
+		}
+
+		data, err := decodePage(page.Data, page.ContentType)
+
+		if err != nil {
+
+			fmt.Fprintf(options.messages(), "Skipping %s: %v\n", page.URL, err)
+
+			continue
+
+		}
+
+		location, _ := url.Parse(page.URL)
+
+		lines, _, _, err := documentLines(location.Path, data, crawlFormat(page.ContentType, options.InputFormat))
+
+		if err != nil {
+
+			fmt.Fprintf(options.messages(), "Skipping %s: %v\n", page.URL, err)
+
+			continue
+
+		}
+
+		if skip, description := mostlyNonChinese(lines, options.MinHanRatio); skip {
+
+			fmt.Fprintf(options.messages(), "Skipping %s: %s\n", page.URL, description)
+
+			continue
+
+		}
+
+		documents = append(documents, batchDocument{Path: page.URL, Lines: lines})
+
+	}
+
+	if len(documents) == 0 {
+
+		return fmt.Errorf("no page of %s could be analyzed", spec)
+
+	}
+
+	return categorizeDocuments(spec, documents, options)
+
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"io"
+
+	"net/http"
+
+	"net/http/httptest"
+
+	"net/url"
+
+	"sync"
+
+	"testing"
+
+	"time"
+)
+
+func TestParseRobots(t *testing.T) {
+
+	robots := `User-agent: *
+Disallow: /private/
+Crawl-delay: 2
+
+User-agent: cw
+Disallow: /
+
+User-agent: cwclassifier
+User-agent: other
+Disallow: /drafts/
+Allow: /drafts/public$
+Disallow: /*.pdf
+`
+
+	tests := []struct {
+		name string
+
+		path string
+
+		want bool
+	}{
+		{"cwclassifier", "/drafts/notes", false},
+
+		{"cwclassifier", "/drafts/public", true},
+
+		{"cwclassifier", "/drafts/public/more", false},
+
+		{"cwclassifier", "/docs/a.pdf", false},
+
+		{"cwclassifier", "/private/", true},
+
+		{"cwbot", "/anything", false},
+
+		{"somebot", "/private/x", false},
+
+		{"somebot", "/drafts/notes", true},
+	}
+
+	for _, test := range tests {
+
+		if got := parseRobots([]byte(robots), test.name).allows(test.path); got != test.want {
+
+			t.Errorf("parseRobots(%q).allows(%q) = %v, want %v", test.name, test.path, got, test.want)
+
+		}
+
+	}
+
+	if delay := parseRobots([]byte(robots), "somebot").delay; delay != 2*time.Second {
+
+		t.Errorf("Crawl-delay of the * group = %v, want 2s", delay)
+
+	}
+
+	if rules := parseRobots([]byte("User-agent: googlebot\nDisallow: /\n"), "cwclassifier"); !rules.allows("/") {
+
+		t.Errorf("a robots.txt without a matching or * group should allow everything")
+
+	}
+
+}
+
+// robots.txt answered with an error status: missing or forbidden ones allow everything, while 429 and 5xx,
+
+// still failing after retries, keep the crawler away
+
+func TestCrawlerAllowedStatus(t *testing.T) {
+
+	tests := []struct {
+		status int
+
+		want bool
+	}{
+		{http.StatusNotFound, true},
+
+		{http.StatusForbidden, true},
+
+		{http.StatusTooManyRequests, false},
+
+		{http.StatusServiceUnavailable, false},
+	}
+
+	for _, test := range tests {
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			w.WriteHeader(test.status)
+
+		}))
+
+		c := newCrawler(crawlOptions{Messages: io.Discard})
+
+		target, _ := url.Parse(server.URL + "/page.html")
+
+		if got := c.allowed(target); got != test.want {
+
+			t.Errorf("allowed with robots.txt status %d = %v, want %v", test.status, got, test.want)
+
+		}
+
+		server.Close()
+
+	}
+
+}
+
+// Pages of one host are fetched one at a time even with no delay and several pages in flight
+
+func TestCrawlerOneRequestPerHost(t *testing.T) {
+
+	var mu sync.Mutex
+
+	inFlight, most := 0, 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		mu.Lock()
+
+		inFlight++
+
+		most = max(most, inFlight)
+
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+
+		inFlight--
+
+		mu.Unlock()
+
+		if r.URL.Path == "/robots.txt" {
+
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+
+		}
+
+		w.Write([]byte("<p>你好</p>"))
+
+	}))
+
+	defer server.Close()
+
+	var urls []*url.URL
+
+	for _, path := range []string{"/a", "/b", "/c", "/d", "/e", "/f"} {
+
+		target, _ := url.Parse(server.URL + path)
+
+		urls = append(urls, target)
+
+	}
+
+	c := newCrawler(crawlOptions{Concurrency: 4, Messages: io.Discard})
+
+	for _, page := range c.crawl(urls) {
+
+		if page.Err != nil {
+
+			t.Errorf("%s: %v", page.URL, page.Err)
+
+		}
+
+	}
+
+	if most != 1 {
+
+		t.Errorf("%d requests to the host overlapped, want 1 at a time", most)
+
+	}
+
+}
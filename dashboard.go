@@ -0,0 +1,666 @@
+package main
+
+import (
+	"bytes"
+
+	"encoding/json"
+
+	"flag"
+
+	"fmt"
+
+	"html/template"
+
+	"net/http"
+
+	"os"
+
+	"path/filepath"
+
+	"sort"
+
+	"strings"
+
+	"time"
+)
+
+// How often the progress stream checks for a new snapshot
+
+const progressPollInterval = time.Second
+
+// Characters of context shown on each side of a KWIC match
+
+const kwicWidth = 20
+
+// Stored run: the results.json of one analysis and the directory it lives in
+
+type dashboardRun struct {
+	ID string
+
+	Results jsonResults
+}
+
+// Keyword-in-context line
+
+type kwicLine struct {
+	Line int
+
+	Left string
+
+	Match string
+
+	Right string
+}
+
+// Row of a side-by-side comparison of two runs
+
+type comparisonRow struct {
+	Item string `json:"item"`
+
+	A int `json:"a"`
+
+	B int `json:"b"`
+
+	Delta int `json:"delta"`
+}
+
+// Handles "cwClassifier dashboard [--addr :8080] [--runs dir]"
+
+func runDashboardCommand(args []string) error {
+
+	flags := flag.NewFlagSet("dashboard", flag.ContinueOnError)
+
+	addr := flags.String("addr", "localhost:8080", "Address to serve the dashboard on")
+
+	runsDir := flags.String("runs", ".", "Directory searched for runs: it and its subdirectories containing results.json (--format json)")
+
+	if err := flags.Parse(args); err != nil {
+
+		return err
+
+	}
+
+	fmt.Printf("Serving dashboard for runs under %s at http://%s/\n", *runsDir, *addr)
+
+	return http.ListenAndServe(*addr, newDashboardHandler(*runsDir))
+
+}
+
+// Routes of the dashboard; runs are reloaded on every request, so new runs show up without a restart
+
+func newDashboardHandler(runsDir string) http.Handler {
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+
+		if r.URL.Path != "/" {
+
+			http.NotFound(w, r)
+
+			return
+
+		}
+
+		runs, err := loadDashboardRuns(runsDir)
+
+		if err != nil {
+
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+
+			return
+
+		}
+
+		renderDashboard(w, "index", map[string]interface{}{"Runs": runs})
+
+	})
+
+	mux.HandleFunc("/run", func(w http.ResponseWriter, r *http.Request) {
+
+		run, ok := findDashboardRun(w, runsDir, r.URL.Query().Get("id"))
+
+		if !ok {
+
+			return
+
+		}
+
+		query := strings.TrimSpace(r.URL.Query().Get("q"))
+
+		category := r.URL.Query().Get("category")
+
+		if category == "" && query == "" {
+
+			category = firstCategory(run.Results)
+
+		}
+
+		items := make(map[string][]jsonItem)
+
+		for name, entries := range run.Results.Categories {
+
+			if query == "" && name != category {
+
+				continue
+
+			}
+
+			for _, entry := range entries {
+
+				if query == "" || strings.Contains(strings.ToLower(entry.Item), strings.ToLower(query)) {
+
+					items[name] = append(items[name], entry)
+
+				}
+
+			}
+
+		}
+
+		renderDashboard(w, "run", map[string]interface{}{
+
+			"Run": run,
+
+			"Categories": sortedCategories(run.Results),
+
+			"Category": category,
+
+			"Query": query,
+
+			"Items": items,
+		})
+
+	})
+
+	mux.HandleFunc("/kwic", func(w http.ResponseWriter, r *http.Request) {
+
+		run, ok := findDashboardRun(w, runsDir, r.URL.Query().Get("id"))
+
+		if !ok {
+
+			return
+
+		}
+
+		// Phrase items join their words with spaces that are not in the source text
+
+		item := r.URL.Query().Get("item")
+
+		data, err := os.ReadFile(run.Results.Source)
+
+		if err != nil {
+
+			http.Error(w, fmt.Sprintf("source text of this run is unavailable: %v", err), http.StatusNotFound)
+
+			return
+
+		}
+
+		renderDashboard(w, "kwic", map[string]interface{}{
+
+			"Run": run,
+
+			"Item": item,
+
+			"Lines": findKWIC(string(data), strings.ReplaceAll(item, " ", ""), kwicWidth),
+		})
+
+	})
+
+	mux.HandleFunc("/compare", func(w http.ResponseWriter, r *http.Request) {
+
+		a, ok := findDashboardRun(w, runsDir, r.URL.Query().Get("a"))
+
+		if !ok {
+
+			return
+
+		}
+
+		b, ok := findDashboardRun(w, runsDir, r.URL.Query().Get("b"))
+
+		if !ok {
+
+			return
+
+		}
+
+		category := r.URL.Query().Get("category")
+
+		if category == "" {
+
+			category = firstCategory(a.Results)
+
+		}
+
+		categories := sortedCategories(a.Results)
+
+		for _, name := range sortedCategories(b.Results) {
+
+			if _, ok := a.Results.Categories[name]; !ok {
+
+				categories = append(categories, name)
+
+			}
+
+		}
+
+		renderDashboard(w, "compare", map[string]interface{}{
+
+			"A": a,
+
+			"B": b,
+
+			"Categories": categories,
+
+			"Category": category,
+
+			"Rows": compareRuns(a.Results.Categories[category], b.Results.Categories[category]),
+		})
+
+	})
+
+	mux.HandleFunc("/progress", func(w http.ResponseWriter, r *http.Request) {
+
+		streamProgress(w, r, runsDir)
+
+	})
+
+	return mux
+
+}
+
+// Streams the progress.json of a running job (?id=dir under the runs directory) as server-sent events,
+
+// one event per new snapshot, until the job is done or the client goes away
+
+func streamProgress(w http.ResponseWriter, r *http.Request, runsDir string) {
+
+	id := filepath.Clean(filepath.FromSlash(r.URL.Query().Get("id")))
+
+	if filepath.IsAbs(id) || id == ".." || strings.HasPrefix(id, ".."+string(filepath.Separator)) {
+
+		http.Error(w, fmt.Sprintf("invalid run %q", id), http.StatusBadRequest)
+
+		return
+
+	}
+
+	flusher, ok := w.(http.Flusher)
+
+	if !ok {
+
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+
+		return
+
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+
+	w.Header().Set("Cache-Control", "no-cache")
+
+	path := filepath.Join(runsDir, id, "progress.json")
+
+	ticker := time.NewTicker(progressPollInterval)
+
+	defer ticker.Stop()
+
+	var last []byte
+
+	for {
+
+		if data, err := os.ReadFile(path); err == nil && !bytes.Equal(data, last) {
+
+			last = data
+
+			fmt.Fprintf(w, "data: %s\n\n", data)
+
+			flusher.Flush()
+
+			var snapshot progressSnapshot
+
+			if json.Unmarshal(data, &snapshot) == nil && snapshot.Done {
+
+				return
+
+			}
+
+		}
+
+		select {
+
+		case <-r.Context().Done():
+
+			return
+
+		case <-ticker.C:
+
+		}
+
+	}
+
+}
+
+// Finds every directory under runsDir (one level deep) holding a results.json
+
+func loadDashboardRuns(runsDir string) ([]dashboardRun, error) {
+
+	candidates := []string{runsDir}
+
+	entries, err := os.ReadDir(runsDir)
+
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to list runs: %v", err)
+
+	}
+
+	for _, entry := range entries {
+
+		if entry.IsDir() {
+
+			candidates = append(candidates, filepath.Join(runsDir, entry.Name()))
+
+		}
+
+	}
+
+	var runs []dashboardRun
+
+	for _, dir := range candidates {
+
+		data, err := os.ReadFile(filepath.Join(dir, "results.json"))
+
+		if err != nil {
+
+			continue
+
+		}
+
+		var results jsonResults
+
+		if err := json.Unmarshal(data, &results); err != nil {
+
+			return nil, fmt.Errorf("failed to read run %s: %v", dir, err)
+
+		}
+
+		id, _ := filepath.Rel(runsDir, dir)
+
+		runs = append(runs, dashboardRun{ID: filepath.ToSlash(id), Results: results})
+
+	}
+
+	return runs, nil
+
+}
+
+// Looks up a run by ID, answering 404 when it does not exist
+
+func findDashboardRun(w http.ResponseWriter, runsDir, id string) (dashboardRun, bool) {
+
+	runs, err := loadDashboardRuns(runsDir)
+
+	if err != nil {
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return dashboardRun{}, false
+
+	}
+
+	for _, run := range runs {
+
+		if run.ID == id {
+
+			return run, true
+
+		}
+
+	}
+
+	http.Error(w, fmt.Sprintf("unknown run %q", id), http.StatusNotFound)
+
+	return dashboardRun{}, false
+
+}
+
+// Category names of a run in alphabetical order
+
+func sortedCategories(results jsonResults) []string {
+
+	var names []string
+
+	for name := range results.Categories {
+
+		names = append(names, name)
+
+	}
+
+	sort.Strings(names)
+
+	return names
+
+}
+
+// Category shown when none is selected
+
+func firstCategory(results jsonResults) string {
+
+	if _, ok := results.Categories["ChineseNouns"]; ok {
+
+		return "ChineseNouns"
+
+	}
+
+	if names := sortedCategories(results); len(names) > 0 {
+
+		return names[0]
+
+	}
+
+	return ""
+
+}
+
+// Finds every occurrence of item in text with up to width characters of context on each side
+
+func findKWIC(text, item string, width int) []kwicLine {
+
+	var matches []kwicLine
+
+	if item == "" {
+
+		return matches
+
+	}
+
+	for lineIndex, line := range strings.Split(text, "\n") {
+
+		offset := 0
+
+		for {
+
+			index := strings.Index(line[offset:], item)
+
+			if index < 0 {
+
+				break
+
+			}
+
+			start := offset + index
+
+			end := start + len(item)
+
+			left := []rune(line[:start])
+
+			right := []rune(line[end:])
+
+			if len(left) > width {
+
+				left = left[len(left)-width:]
+
+			}
+
+			if len(right) > width {
+
+				right = right[:width]
+
+			}
+
+			matches = append(matches, kwicLine{Line: lineIndex + 1, Left: string(left), Match: item, Right: string(right)})
+
+			offset = end
+
+		}
+
+	}
+
+	return matches
+
+}
+
+// Lines up the items of one category in two runs, largest combined frequency first
+
+func compareRuns(a, b []jsonItem) []comparisonRow {
+
+	rows := make(map[string]*comparisonRow)
+
+	for _, entry := range a {
+
+		rows[entry.Item] = &comparisonRow{Item: entry.Item, A: entry.Frequency}
+
+	}
+
+	for _, entry := range b {
+
+		if row, ok := rows[entry.Item]; ok {
+
+			row.B = entry.Frequency
+
+		} else {
+
+			rows[entry.Item] = &comparisonRow{Item: entry.Item, B: entry.Frequency}
+
+		}
+
+	}
+
+	var comparison []comparisonRow
+
+	for _, row := range rows {
+
+		row.Delta = row.B - row.A
+
+		comparison = append(comparison, *row)
+
+	}
+
+	sort.Slice(comparison, func(i, j int) bool {
+
+		if comparison[i].A+comparison[i].B != comparison[j].A+comparison[j].B {
+
+			return comparison[i].A+comparison[i].B > comparison[j].A+comparison[j].B
+
+		}
+
+		return comparison[i].Item < comparison[j].Item
+
+	})
+
+	return comparison
+
+}
+
+// Renders one dashboard page
+
+func renderDashboard(w http.ResponseWriter, page string, data map[string]interface{}) {
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if err := dashboardTemplates.ExecuteTemplate(w, page, data); err != nil {
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+	}
+
+}
+
+var dashboardTemplates = template.Must(template.New("dashboard").Parse(`
+{{define "header"}}<!DOCTYPE html>
+<html lang="zh">
+<head>
+<meta charset="utf-8">
+<title>cwClassifier dashboard</title>
+<style>
+body { font-family: "Noto Sans CJK SC", "PingFang SC", "Microsoft YaHei", sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+td, th { padding: .2em .8em; border-bottom: 1px solid #ddd; text-align: left; }
+td.num { text-align: right; }
+nav a { margin-right: .8em; }
+.kwic td.left { text-align: right; color: #555; }
+.kwic td.match { font-weight: bold; background: #fff9c4; }
+.kwic td.right { color: #555; }
+.up { color: #2e7d32; } .down { color: #c62828; }
+</style>
+</head>
+<body>
+<p><a href="/">All runs</a></p>
+{{end}}
+
+{{define "footer"}}</body>
+</html>
+{{end}}
+
+{{define "index"}}{{template "header"}}
+<h1>Runs</h1>
+{{if .Runs}}
+<table>
+<tr><th>Run</th><th>Source</th><th>Categories</th></tr>
+{{range .Runs}}<tr><td><a href="/run?id={{.ID}}">{{.ID}}</a></td><td>{{.Results.Source}}</td><td class="num">{{len .Results.Categories}}</td></tr>
+{{end}}</table>
+<h2>Compare two runs</h2>
+<form action="/compare">
+<select name="a">{{range .Runs}}<option>{{.ID}}</option>{{end}}</select>
+<select name="b">{{range .Runs}}<option>{{.ID}}</option>{{end}}</select>
+<button>Compare</button>
+</form>
+{{else}}<p>No runs found. Analyze a text with --format json to create one.</p>{{end}}
+{{template "footer"}}{{end}}
+
+{{define "run"}}{{template "header"}}
+<h1>{{.Run.ID}}</h1>
+<p>{{.Run.Results.Source}}</p>
+<form action="/run"><input type="hidden" name="id" value="{{.Run.ID}}"><input name="q" value="{{.Query}}" placeholder="Search items"><button>Search</button></form>
+<nav>{{$run := .Run}}{{range .Categories}}<a href="/run?id={{$run.ID}}&category={{.}}">{{.}}</a>{{end}}</nav>
+{{range $category, $items := .Items}}
+<h2>{{$category}}</h2>
+<table>
+<tr><th>Item</th><th>Frequency</th><th>Gloss</th></tr>
+{{range $items}}<tr><td><a href="/kwic?id={{$run.ID}}&item={{.Item}}">{{.Item}}</a></td><td class="num">{{.Frequency}}</td><td>{{.Gloss}}</td></tr>
+{{end}}</table>
+{{else}}<p>No matching items.</p>
+{{end}}
+{{template "footer"}}{{end}}
+
+{{define "kwic"}}{{template "header"}}
+<h1>{{.Item}}</h1>
+<p><a href="/run?id={{.Run.ID}}">{{.Run.ID}}</a> · {{len .Lines}} occurrences</p>
+<table class="kwic">
+{{range .Lines}}<tr><td class="num">{{.Line}}</td><td class="left">{{.Left}}</td><td class="match">{{.Match}}</td><td class="right">{{.Right}}</td></tr>
+{{end}}</table>
+{{template "footer"}}{{end}}
+
+{{define "compare"}}{{template "header"}}
+<h1>{{.A.ID}} ↔ {{.B.ID}}</h1>
+<nav>{{$a := .A}}{{$b := .B}}{{range .Categories}}<a href="/compare?a={{$a.ID}}&b={{$b.ID}}&category={{.}}">{{.}}</a>{{end}}</nav>
+<h2>{{.Category}}</h2>
+<table>
+<tr><th>Item</th><th>{{.A.ID}}</th><th>{{.B.ID}}</th><th>Change</th></tr>
+{{range .Rows}}<tr><td>{{.Item}}</td><td class="num">{{.A}}</td><td class="num">{{.B}}</td><td class="num {{if gt .Delta 0}}up{{else if lt .Delta 0}}down{{end}}">{{.Delta}}</td></tr>
+{{end}}</table>
+{{template "footer"}}{{end}}
+`))
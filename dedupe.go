@@ -0,0 +1,253 @@
+package main
+
+import (
+	"fmt"
+
+	"hash/fnv"
+
+	"math/bits"
+
+	"os"
+
+	"path/filepath"
+
+	"sort"
+
+	"strings"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+)
+
+// Document of a batch with the lines read from it
+
+type batchDocument struct {
+	Path string
+
+	Lines []string
+}
+
+// 64-bit simhash over word bigrams of the segmented text; similar documents get hashes a few bits apart
+
+func simhash(words []string) uint64 {
+
+	var weights [64]int
+
+	add := func(feature string) {
+
+		hash := fnv.New64a()
+
+		hash.Write([]byte(feature))
+
+		sum := hash.Sum64()
+
+		for bit := 0; bit < 64; bit++ {
+
+			if sum&(1<<uint(bit)) != 0 {
+
+				weights[bit]++
+
+			} else {
+
+				weights[bit]--
+
+			}
+
+		}
+
+	}
+
+	if len(words) == 1 {
+
+		add(words[0])
+
+	}
+
+	for i := 0; i+1 < len(words); i++ {
+
+		add(words[i] + "\x00" + words[i+1])
+
+	}
+
+	var fingerprint uint64
+
+	for bit, weight := range weights {
+
+		if weight > 0 {
+
+			fingerprint |= 1 << uint(bit)
+
+		}
+
+	}
+
+	return fingerprint
+
+}
+
+// Drops documents whose simhash is within maxDistance bits of an earlier kept document.
+
+// Returns the kept documents and, for each dropped one, the document it duplicates.
+
+func dropNearDuplicates(documents []batchDocument, c *classifier.Classifier, maxDistance int) ([]batchDocument, map[string]string) {
+
+	var kept []batchDocument
+
+	var fingerprints []uint64
+
+	dropped := make(map[string]string)
+
+	for _, document := range documents {
+
+		fingerprint := simhash(c.Segment(strings.Join(document.Lines, "\n")))
+
+		duplicate := -1
+
+		for i, other := range fingerprints {
+
+			if bits.OnesCount64(fingerprint^other) <= maxDistance {
+
+				duplicate = i
+
+				break
+
+			}
+
+		}
+
+		if duplicate >= 0 {
+
+			dropped[document.Path] = kept[duplicate].Path
+
+			continue
+
+		}
+
+		kept = append(kept, document)
+
+		fingerprints = append(fingerprints, fingerprint)
+
+	}
+
+	return kept, dropped
+
+}
+
+// Analyzes every supported document in a directory as one corpus, optionally dropping near-duplicates first
+
+func categorizeBatch(dir string, options analysisOptions) error {
+
+	entries, err := os.ReadDir(dir)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to read batch directory: %v", err)
+
+	}
+
+	var documents []batchDocument
+
+	for _, entry := range entries {
+
+		if entry.IsDir() {
+
+			continue
+
+		}
+
+		if _, ok := inputExtensions[strings.ToLower(filepath.Ext(entry.Name()))]; !ok && options.InputFormat == "auto" {
+
+			continue
+
+		}
+
+		path := filepath.Join(dir, entry.Name())
+
+		// Each document is decoded from its own encoding, so a corpus may mix UTF-8, GBK and Big5 files
+
+		lines, _, inputEncoding, err := readInputLines(path, options.InputFormat)
+
+		if err != nil {
+
+			return fmt.Errorf("%s: %v", entry.Name(), err)
+
+		}
+
+		if inputEncoding != "utf-8" {
+
+			fmt.Fprintf(options.messages(), "%s is encoded in %s; it was decoded before analysis\n", entry.Name(), inputEncodingNames[inputEncoding])
+
+		}
+
+		if skip, description := mostlyNonChinese(lines, options.MinHanRatio); skip {
+
+			fmt.Fprintf(options.messages(), "Skipping %s: %s\n", entry.Name(), description)
+
+			continue
+
+		}
+
+		documents = append(documents, batchDocument{Path: path, Lines: lines})
+
+	}
+
+	if len(documents) == 0 {
+
+		return fmt.Errorf("no supported documents in %s", dir)
+
+	}
+
+	return categorizeDocuments(dir, documents, options)
+
+}
+
+// Analyzes documents as one corpus, each a section, after dropping near-duplicates if enabled
+
+func categorizeDocuments(source string, documents []batchDocument, options analysisOptions) error {
+
+	if options.Dedupe {
+
+		c, err := classifier.New()
+
+		if err != nil {
+
+			return err
+
+		}
+
+		var dropped map[string]string
+
+		documents, dropped = dropNearDuplicates(documents, c, options.DedupeDistance)
+
+		var paths []string
+
+		for path := range dropped {
+
+			paths = append(paths, path)
+
+		}
+
+		sort.Strings(paths)
+
+		for _, path := range paths {
+
+			fmt.Fprintf(options.messages(), "Skipping %s: near-duplicate of %s\n", filepath.Base(path), filepath.Base(dropped[path]))
+
+		}
+
+	}
+
+	var lines []string
+
+	for _, document := range documents {
+
+		lines = append(lines, document.Lines...)
+
+		options.Sections = append(options.Sections, len(document.Lines))
+
+	}
+
+	fmt.Fprintf(options.messages(), "Analyzing %d documents from %s\n", len(documents), source)
+
+	return categorizeLines(source, lines, options)
+
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bufio"
+
+	"fmt"
+
+	"sort"
+
+	"strconv"
+
+	"strings"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+
+	"golang.org/x/text/encoding"
+)
+
+// Words listed per speaker in the dialogue report
+
+const dialogueTopWords = 10
+
+// Categories whose words make up a speaker's vocabulary
+
+var dialogueWordCategories = []string{"ChineseNouns", "ChineseVerbs", "ChineseAdjectives", "ChineseAdverbs", "ChineseIdioms", "ChineseSlang"}
+
+// Writes ChineseDialogueSpeakers.txt: each speaker with their number of quotes and the words they use most,
+
+// so dialogue vocabulary can be studied apart from narration. Quotes without a speaker are grouped as "?".
+
+func writeDialogueReport(path string, c *classifier.Classifier, result *classifier.Result, enc encoding.Encoding) error {
+
+	speech := make(map[string][]string)
+
+	for _, quote := range result.Quotes {
+
+		speaker := quote.Speaker
+
+		if speaker == "" {
+
+			speaker = "?"
+
+		}
+
+		speech[speaker] = append(speech[speaker], quote.Text)
+
+	}
+
+	var speakers []string
+
+	for speaker := range speech {
+
+		speakers = append(speakers, speaker)
+
+	}
+
+	sort.Slice(speakers, func(i, j int) bool {
+
+		if len(speech[speakers[i]]) != len(speech[speakers[j]]) {
+
+			return len(speech[speakers[i]]) > len(speech[speakers[j]])
+
+		}
+
+		return speakers[i] < speakers[j]
+
+	})
+
+	file, err := createOutputFile(path, enc)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to create dialogue report: %v", err)
+
+	}
+
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+
+	var rows [][]string
+
+	for _, speaker := range speakers {
+
+		// Each quote is its own sentence, so no phrase runs from one into the next
+
+		spoken, err := c.Classify(strings.Join(speech[speaker], "。\n"))
+
+		if err != nil {
+
+			return fmt.Errorf("failed to classify dialogue of %s: %v", speaker, err)
+
+		}
+
+		counts := make(map[string]int)
+
+		for _, category := range dialogueWordCategories {
+
+			for _, entry := range spoken.Ranked[category] {
+
+				counts[entry.Item] += entry.Frequency
+
+			}
+
+		}
+
+		var words []string
+
+		for i, entry := range classifier.RankByFrequency(counts) {
+
+			if i == dialogueTopWords {
+
+				break
+
+			}
+
+			words = append(words, fmt.Sprintf("%s(%d)", entry.Item, entry.Frequency))
+
+		}
+
+		rows = append(rows, []string{speaker, strconv.Itoa(len(speech[speaker])), strings.Join(words, " ")})
+
+	}
+
+	writeTable(writer, []string{"speaker", "quotes", "words"}, rows, []bool{false, true, false})
+
+	return writer.Flush()
+
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bufio"
+
+	"fmt"
+
+	"strconv"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+
+	"golang.org/x/text/encoding"
+)
+
+// Writes ChineseDictionaryConflicts.txt: each word that dictionaries tag differently, with the definition kept
+
+// by precedence (user > domain > built-in) and the one it overrode
+
+func writeDictionaryConflicts(path string, conflicts []classifier.DictionaryConflict, enc encoding.Encoding) error {
+
+	file, err := createOutputFile(path, enc)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to create dictionary conflict report: %v", err)
+
+	}
+
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+
+	var rows [][]string
+
+	for _, conflict := range conflicts {
+
+		rows = append(rows, []string{conflict.Word, conflict.Kept.Tag, strconv.Itoa(conflict.Kept.Frequency), conflict.Kept.Source, conflict.Overridden.Tag, strconv.Itoa(conflict.Overridden.Frequency), conflict.Overridden.Source})
+
+	}
+
+	writeTable(writer, []string{"word", "kept tag", "frequency", "source", "overridden tag", "frequency", "source"}, rows, []bool{false, false, true, false, false, true, false})
+
+	return writer.Flush()
+
+}
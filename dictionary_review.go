@@ -0,0 +1,257 @@
+package main
+
+import (
+	"bufio"
+
+	"flag"
+
+	"fmt"
+
+	"os"
+
+	"strings"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+
+	"golang.org/x/text/encoding"
+)
+
+// POS tag proposed for discovered words; reviewers can correct it before applying
+
+const reviewDefaultTag = "n"
+
+// Reviewed candidate: whether it was accepted, and the dictionary line it becomes
+
+type reviewEntry struct {
+	Accepted bool
+
+	Word string
+
+	Tag string
+}
+
+// Writes ChineseDictionaryReview.txt: one "[ ]<TAB>word<TAB>tag" line per candidate for reviewers to mark
+
+// with [x], followed by its statistics as a comment
+
+func writeDictionaryReview(path string, candidates []classifier.WordCandidate, enc encoding.Encoding) error {
+
+	file, err := createOutputFile(path, enc)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to create dictionary review: %v", err)
+
+	}
+
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+
+	writer.WriteString("# Mark words to add with [x] and correct their POS tag if needed, then run:\n")
+
+	writer.WriteString("#   cwClassifier dict apply --review ChineseDictionaryReview.txt --dict userdict.txt\n")
+
+	for _, candidate := range candidates {
+
+		fmt.Fprintf(writer, "[ ]\t%s\t%s\t# frequency %d, score %.3f\n", candidate.Word, reviewDefaultTag, candidate.Frequency, candidate.Score)
+
+	}
+
+	return writer.Flush()
+
+}
+
+// Reads a review file; lines marked [x] or [X] are accepted
+
+func readDictionaryReview(path string) ([]reviewEntry, error) {
+
+	file, err := os.Open(path)
+
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to open review file: %v", err)
+
+	}
+
+	defer file.Close()
+
+	var entries []reviewEntry
+
+	scanner := bufio.NewScanner(file)
+
+	lineNumber := 0
+
+	for scanner.Scan() {
+
+		lineNumber++
+
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+
+		line = strings.TrimSpace(line)
+
+		if line == "" {
+
+			continue
+
+		}
+
+		mark, rest, ok := strings.Cut(line, "]")
+
+		if !ok || !strings.HasPrefix(mark, "[") {
+
+			return nil, fmt.Errorf("line %d of %s does not start with [ ] or [x]", lineNumber, path)
+
+		}
+
+		fields := strings.Fields(rest)
+
+		if len(fields) == 0 {
+
+			return nil, fmt.Errorf("line %d of %s has no word", lineNumber, path)
+
+		}
+
+		entry := reviewEntry{Accepted: strings.EqualFold(strings.TrimSpace(mark[1:]), "x"), Word: fields[0], Tag: reviewDefaultTag}
+
+		if len(fields) > 1 {
+
+			entry.Tag = fields[1]
+
+		}
+
+		entries = append(entries, entry)
+
+	}
+
+	if err := scanner.Err(); err != nil {
+
+		return nil, fmt.Errorf("error reading review file: %v", err)
+
+	}
+
+	return entries, nil
+
+}
+
+// Handles "cwClassifier dict apply --review file --dict userdict.txt"
+
+func runDictCommand(args []string) error {
+
+	if len(args) == 0 || args[0] != "apply" {
+
+		return fmt.Errorf("usage: cwClassifier dict apply --review ChineseDictionaryReview.txt --dict userdict.txt")
+
+	}
+
+	flags := flag.NewFlagSet("dict apply", flag.ContinueOnError)
+
+	review := flags.String("review", "", "Reviewed ChineseDictionaryReview.txt with accepted words marked [x]")
+
+	dict := flags.String("dict", "", "User dictionary to extend (created if missing); pass it to --dict on later runs")
+
+	if err := flags.Parse(args[1:]); err != nil {
+
+		return err
+
+	}
+
+	if *review == "" || *dict == "" {
+
+		return fmt.Errorf("both --review and --dict are required")
+
+	}
+
+	entries, err := readDictionaryReview(*review)
+
+	if err != nil {
+
+		return err
+
+	}
+
+	added, present, err := applyDictionaryReview(*dict, entries)
+
+	if err != nil {
+
+		return err
+
+	}
+
+	fmt.Printf("Added %d words to %s (%d already present)\n", added, *dict, present)
+
+	return nil
+
+}
+
+// Appends the accepted words missing from the user dictionary, without frequencies so each gets one
+
+// just high enough to be kept whole
+
+func applyDictionaryReview(path string, entries []reviewEntry) (added, present int, err error) {
+
+	known := make(map[string]bool)
+
+	if data, err := os.ReadFile(path); err == nil {
+
+		for _, line := range strings.Split(string(data), "\n") {
+
+			if fields := strings.Fields(line); len(fields) > 0 && !strings.HasPrefix(fields[0], "#") {
+
+				known[fields[0]] = true
+
+			}
+
+		}
+
+	} else if !os.IsNotExist(err) {
+
+		return 0, 0, fmt.Errorf("failed to read user dictionary: %v", err)
+
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+
+	if err != nil {
+
+		return 0, 0, fmt.Errorf("failed to open user dictionary: %v", err)
+
+	}
+
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+
+	for _, entry := range entries {
+
+		if !entry.Accepted {
+
+			continue
+
+		}
+
+		if known[entry.Word] {
+
+			present++
+
+			continue
+
+		}
+
+		known[entry.Word] = true
+
+		fmt.Fprintf(writer, "%s %s\n", entry.Word, entry.Tag)
+
+		added++
+
+	}
+
+	if err := writer.Flush(); err != nil {
+
+		return 0, 0, fmt.Errorf("failed to write user dictionary: %v", err)
+
+	}
+
+	return added, present, nil
+
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+
+	"fmt"
+
+	"io"
+
+	"io/fs"
+
+	"os"
+
+	"path/filepath"
+
+	"slices"
+
+	"strconv"
+
+	"sync"
+)
+
+// Analyzes lines as categorizeLines does but into a temporary directory, then lists the files the run would
+
+// have written to its output directory and removes them
+
+func dryRun(inputFile string, lines []string, options analysisOptions) error {
+
+	target := outputDirectory(options)
+
+	dir, err := os.MkdirTemp("", "cwClassifier-dry-run-")
+
+	if err != nil {
+
+		return fmt.Errorf("failed to create temporary directory: %v", err)
+
+	}
+
+	defer os.RemoveAll(dir)
+
+	if options.DryRunWrites == nil {
+
+		options.DryRunWrites = &dryRunWrites{}
+
+	}
+
+	// The MT cache keeps the translations fetched for this run in memory only
+
+	if t, ok := options.Translator.(*cachingTranslator); ok {
+
+		options.Translator = t.dryRunCopy(options.DryRunWrites)
+
+	}
+
+	options.DryRun, options.OutputDir = false, dir
+
+	if err := categorizeLines(inputFile, lines, options); err != nil {
+
+		return err
+
+	}
+
+	return reportDryRun(options.messages(), dir, target, options.DryRunWrites.list())
+
+}
+
+// Files outside the output directory, such as the --discover-into dictionary, the MT and slang caches and the
+
+// GUI settings, that a dry run skips writing and lists with its output
+
+type dryRunWrites struct {
+	mu sync.Mutex // The workers of a --queue share one list
+
+	paths []string
+}
+
+// Records a file the run would have written, once
+
+func (w *dryRunWrites) add(path string) {
+
+	w.mu.Lock()
+
+	defer w.mu.Unlock()
+
+	if slices.Contains(w.paths, path) {
+
+		return
+
+	}
+
+	w.paths = append(w.paths, path)
+
+}
+
+// Files recorded so far
+
+func (w *dryRunWrites) list() []string {
+
+	w.mu.Lock()
+
+	defer w.mu.Unlock()
+
+	return slices.Clone(w.paths)
+
+}
+
+// Prints each file of a dry run's directory with its lines (the items of a category file), size and whether
+
+// it would be created or overwrite a file of the output directory, followed by the other files the run would update
+
+func reportDryRun(w io.Writer, dir, target string, others []string) error {
+
+	var rows [][]string
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+
+		if err != nil || entry.IsDir() {
+
+			return err
+
+		}
+
+		data, err := os.ReadFile(path)
+
+		if err != nil {
+
+			return err
+
+		}
+
+		name, _ := filepath.Rel(dir, path)
+
+		action := "create"
+
+		if _, err := os.Stat(filepath.Join(target, name)); err == nil {
+
+			action = "overwrite"
+
+		}
+
+		// Binary files such as Parquet tables have no lines to count
+
+		lines := "-"
+
+		if bytes.IndexByte(data, 0) < 0 {
+
+			count := bytes.Count(data, []byte("\n"))
+
+			if len(data) > 0 && data[len(data)-1] != '\n' {
+
+				count++
+
+			}
+
+			lines = strconv.Itoa(count)
+
+		}
+
+		rows = append(rows, []string{filepath.Join(target, name), lines, strconv.Itoa(len(data)), action})
+
+		return nil
+
+	})
+
+	if err != nil {
+
+		return fmt.Errorf("failed to list dry run output: %v", err)
+
+	}
+
+	written := len(rows)
+
+	for _, path := range others {
+
+		action := "create"
+
+		if _, err := os.Stat(path); err == nil {
+
+			action = "update"
+
+		}
+
+		rows = append(rows, []string{path, "-", "-", action})
+
+	}
+
+	fmt.Fprintf(w, "Dry run: %d files would be written to %s", written, target)
+
+	if len(others) > 0 {
+
+		fmt.Fprintf(w, " and %d files outside it", len(others))
+
+	}
+
+	fmt.Fprintln(w)
+
+	writeTable(w, []string{"file", "lines", "bytes", "action"}, rows, []bool{false, true, true, false})
+
+	return nil
+
+}
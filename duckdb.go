@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+
+	"io"
+
+	"os"
+
+	"os/exec"
+
+	"path/filepath"
+
+	"strings"
+
+	"text/template"
+)
+
+// Builds ChineseResults.duckdb from the Parquet tables; the duckdb CLI runs it, so no cgo driver is needed
+
+var duckdbTemplate = template.Must(template.New("duckdb").Parse(`-- Generated by cwClassifier; run with: duckdb ChineseResults.duckdb < ChineseResults.sql
+CREATE OR REPLACE TABLE tokens AS SELECT * FROM read_parquet('ChineseTokens.parquet');
+CREATE OR REPLACE TABLE categories AS SELECT * FROM read_parquet('ChineseCategories.parquet');
+CREATE OR REPLACE TABLE files AS SELECT * FROM (VALUES ({{.Source}}, {{.Lines}})) AS f(source, lines);
+
+-- Twenty most frequent items of every category
+CREATE OR REPLACE VIEW top_words AS
+SELECT category, rank, item, frequency FROM categories WHERE rank <= 20 ORDER BY category, rank;
+
+-- Size of every category
+CREATE OR REPLACE VIEW category_stats AS
+SELECT category, count(*) AS distinct_items, sum(frequency) AS occurrences FROM categories GROUP BY category ORDER BY category;
+
+-- Overall numbers for the analyzed file
+CREATE OR REPLACE VIEW file_stats AS
+SELECT source, lines,
+	(SELECT count(*) FROM tokens) AS tokens,
+	(SELECT count(*) FROM tokens WHERE len(categories) > 0) AS chinese_tokens,
+	(SELECT count(*) FROM categories WHERE category = 'ChineseCharacters') AS distinct_characters,
+	(SELECT sum(frequency) FROM categories WHERE category = 'ChineseCharacters') AS characters
+FROM files;
+`))
+
+// Writes ChineseResults.sql and, when the duckdb CLI is installed, loads it into ChineseResults.duckdb.
+
+// The Parquet tables must already be in outputDir. A missing CLI is reported to messages.
+
+func writeDuckDB(outputDir, source string, lines int, messages io.Writer) error {
+
+	var script strings.Builder
+
+	err := duckdbTemplate.Execute(&script, map[string]interface{}{
+
+		"Source": sqlString(filepath.Base(source)),
+
+		"Lines": lines,
+	})
+
+	if err != nil {
+
+		return fmt.Errorf("failed to render DuckDB script: %v", err)
+
+	}
+
+	if err := os.WriteFile(filepath.Join(outputDir, "ChineseResults.sql"), []byte(script.String()), 0644); err != nil {
+
+		return fmt.Errorf("failed to write DuckDB script: %v", err)
+
+	}
+
+	cli, err := exec.LookPath("duckdb")
+
+	if err != nil {
+
+		fmt.Fprintln(messages, "duckdb CLI not found; ChineseResults.sql was written and can be loaded with: duckdb ChineseResults.duckdb < ChineseResults.sql")
+
+		return nil
+
+	}
+
+	// Rebuild from scratch so reruns never mix old and new results
+
+	os.Remove(filepath.Join(outputDir, "ChineseResults.duckdb"))
+
+	cmd := exec.Command(cli, "ChineseResults.duckdb")
+
+	cmd.Dir = outputDir
+
+	cmd.Stdin = strings.NewReader(script.String())
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+
+		return fmt.Errorf("failed to build DuckDB database: %v: %s", err, strings.TrimSpace(string(output)))
+
+	}
+
+	return nil
+
+}
+
+// Quotes a value as an SQL string literal
+
+func sqlString(value string) string {
+
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bytes"
+
+	"fmt"
+
+	"io"
+
+	"os"
+
+	"strings"
+
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+
+	"golang.org/x/text/encoding/traditionalchinese"
+
+	"golang.org/x/text/encoding/unicode"
+
+	"golang.org/x/text/transform"
+)
+
+// Encodings selectable with --encoding for plain-text outputs; UTF-8 is written as is
+
+var outputEncodings = map[string]encoding.Encoding{
+
+	"utf-8": nil,
+
+	"gb18030": simplifiedchinese.GB18030,
+
+	"big5": traditionalchinese.Big5,
+}
+
+// Parses the --encoding value; an empty value means UTF-8
+
+func parseEncoding(name string) (encoding.Encoding, error) {
+
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	if name == "" || name == "utf8" {
+
+		name = "utf-8"
+
+	}
+
+	enc, ok := outputEncodings[name]
+
+	if !ok {
+
+		return nil, fmt.Errorf("unknown output encoding %q (available: utf-8, gb18030, big5)", name)
+
+	}
+
+	return enc, nil
+
+}
+
+// Output file that transcodes on write and flushes the encoder before closing the file
+
+type encodedFile struct {
+	*transform.Writer
+
+	file *os.File
+}
+
+func (f *encodedFile) Close() error {
+
+	if err := f.Writer.Close(); err != nil {
+
+		f.file.Close()
+
+		return err
+
+	}
+
+	return f.file.Close()
+
+}
+
+// Creates a plain-text output file in the given encoding (nil for UTF-8).
+
+// Characters the encoding cannot represent, such as simplified characters in Big5, become its substitute byte.
+
+func createOutputFile(path string, enc encoding.Encoding) (io.WriteCloser, error) {
+
+	file, err := os.Create(path)
+
+	if err != nil {
+
+		return nil, err
+
+	}
+
+	if enc == nil {
+
+		return file, nil
+
+	}
+
+	return &encodedFile{Writer: transform.NewWriter(file, encoding.ReplaceUnsupported(enc.NewEncoder())), file: file}, nil
+
+}
+
+// Legacy encodings tried on input that is not UTF-8; GB18030 also reads GBK and GB2312
+
+var legacyInputEncodings = []struct {
+	Name string
+
+	Encoding encoding.Encoding
+}{
+
+	{"gb18030", simplifiedchinese.GB18030},
+
+	{"big5", traditionalchinese.Big5},
+}
+
+// Display names of the detected input encodings
+
+var inputEncodingNames = map[string]string{
+
+	"utf-8": "UTF-8",
+
+	"utf-16le": "UTF-16LE",
+
+	"utf-16be": "UTF-16BE",
+
+	"gb18030": "GB18030 (GBK)",
+
+	"big5": "Big5",
+}
+
+// Detects the encoding of an input document and returns it decoded to UTF-8 with the encoding's name. A byte
+
+// order mark decides; otherwise valid UTF-8 is taken as is, and other input is decoded with the legacy encoding
+
+// that yields the most common characters. Undecodable input is left for the UTF-8 reader.
+
+func decodeInput(data []byte) ([]byte, string) {
+
+	for _, bom := range []struct {
+		Mark []byte
+
+		Name string
+
+		Endianness unicode.Endianness
+	}{
+
+		{[]byte{0xff, 0xfe}, "utf-16le", unicode.LittleEndian},
+
+		{[]byte{0xfe, 0xff}, "utf-16be", unicode.BigEndian},
+	} {
+
+		if bytes.HasPrefix(data, bom.Mark) {
+
+			if decoded, err := unicode.UTF16(bom.Endianness, unicode.ExpectBOM).NewDecoder().Bytes(data); err == nil {
+
+				return decoded, bom.Name
+
+			}
+
+		}
+
+	}
+
+	if utf8.Valid(data) {
+
+		return bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), "utf-8"
+
+	}
+
+	characters, err := loadCharacterTable()
+
+	if err != nil {
+
+		return data, "utf-8"
+
+	}
+
+	best, bestName, bestScore := data, "utf-8", 0
+
+	for _, candidate := range legacyInputEncodings {
+
+		decoded, err := candidate.Encoding.NewDecoder().Bytes(data)
+
+		if err != nil {
+
+			continue
+
+		}
+
+		// Text misread in the other encoding turns into rare characters and invalid sequences
+
+		score := 0
+
+		for _, r := range string(decoded) {
+
+			if _, ok := characters[r]; ok {
+
+				score++
+
+			} else if r == utf8.RuneError {
+
+				score--
+
+			}
+
+		}
+
+		if score > bestScore {
+
+			best, bestName, bestScore = decoded, candidate.Name, score
+
+		}
+
+	}
+
+	return best, bestName
+
+}
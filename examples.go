@@ -0,0 +1,225 @@
+package main
+
+import (
+	"math/rand"
+
+	"sort"
+
+	"strings"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+)
+
+// Example sentences sampled per word, and how many of each category's most frequent words get them
+
+const (
+	examplesPerWord = 3
+
+	exampleWordsPerCategory = 50
+)
+
+// Distinct sentence with the number of times it occurs in the text
+
+type exampleCandidate struct {
+	Sentence string
+
+	Count int
+
+	Characters map[rune]bool
+}
+
+// Samples example sentences for the most frequent words of each word category.
+
+// The same seed always gives the same sample.
+
+func sampleExamples(ranked map[string][]classifier.ItemFrequency, sentences []string, seed int64) map[string][]string {
+
+	counts := make(map[string]int)
+
+	var distinct []string
+
+	for _, sentence := range sentences {
+
+		if counts[sentence] == 0 {
+
+			distinct = append(distinct, sentence)
+
+		}
+
+		counts[sentence]++
+
+	}
+
+	var words []string
+
+	seen := make(map[string]bool)
+
+	for _, category := range vocabularyCategories {
+
+		for i, entry := range ranked[category] {
+
+			if i >= exampleWordsPerCategory {
+
+				break
+
+			}
+
+			if !seen[entry.Item] {
+
+				seen[entry.Item] = true
+
+				words = append(words, entry.Item)
+
+			}
+
+		}
+
+	}
+
+	// Words are visited in a fixed order so the random stream, and therefore the sample, is reproducible
+
+	sort.Strings(words)
+
+	random := rand.New(rand.NewSource(seed))
+
+	examples := make(map[string][]string)
+
+	for _, word := range words {
+
+		var candidates []exampleCandidate
+
+		for _, sentence := range distinct {
+
+			if strings.Contains(sentence, word) {
+
+				candidates = append(candidates, exampleCandidate{Sentence: sentence, Count: counts[sentence], Characters: characterSet(sentence)})
+
+			}
+
+		}
+
+		if len(candidates) > 0 {
+
+			examples[word] = pickDiverseSentences(candidates, examplesPerWord, random)
+
+		}
+
+	}
+
+	return examples
+
+}
+
+// Draws up to n sentences at random, weighted by how often each occurs and by how little it overlaps
+
+// with the sentences already drawn, so the sample covers different contexts
+
+func pickDiverseSentences(candidates []exampleCandidate, n int, random *rand.Rand) []string {
+
+	var chosen []exampleCandidate
+
+	for len(chosen) < n && len(candidates) > 0 {
+
+		weights := make([]float64, len(candidates))
+
+		total := 0.0
+
+		for i, candidate := range candidates {
+
+			similarity := 0.0
+
+			for _, other := range chosen {
+
+				if s := jaccard(candidate.Characters, other.Characters); s > similarity {
+
+					similarity = s
+
+				}
+
+			}
+
+			weights[i] = float64(candidate.Count) * (1 - similarity + 0.01)
+
+			total += weights[i]
+
+		}
+
+		target := random.Float64() * total
+
+		pick := len(candidates) - 1
+
+		for i, weight := range weights {
+
+			if target < weight {
+
+				pick = i
+
+				break
+
+			}
+
+			target -= weight
+
+		}
+
+		chosen = append(chosen, candidates[pick])
+
+		candidates = append(candidates[:pick], candidates[pick+1:]...)
+
+	}
+
+	var sentences []string
+
+	for _, candidate := range chosen {
+
+		sentences = append(sentences, candidate.Sentence)
+
+	}
+
+	return sentences
+
+}
+
+// Set of the characters of a sentence
+
+func characterSet(sentence string) map[rune]bool {
+
+	set := make(map[rune]bool)
+
+	for _, r := range sentence {
+
+		set[r] = true
+
+	}
+
+	return set
+
+}
+
+// Jaccard similarity of two character sets
+
+func jaccard(a, b map[rune]bool) float64 {
+
+	shared := 0
+
+	for r := range a {
+
+		if b[r] {
+
+			shared++
+
+		}
+
+	}
+
+	union := len(a) + len(b) - shared
+
+	if union == 0 {
+
+		return 0
+
+	}
+
+	return float64(shared) / float64(union)
+
+}
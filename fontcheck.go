@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bufio"
+
+	"fmt"
+
+	"os"
+
+	"sort"
+
+	"unicode"
+
+	"golang.org/x/image/font/sfnt"
+
+	"golang.org/x/text/encoding"
+)
+
+// Character the font cannot render, with how often it occurs in the text
+
+type missingGlyph struct {
+	Char rune
+
+	Frequency int
+}
+
+// Loads a TrueType/OpenType font or collection; collections are checked against every face they contain
+
+func loadFontFaces(path string) ([]*sfnt.Font, error) {
+
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to read font: %v", err)
+
+	}
+
+	collection, err := sfnt.ParseCollection(data)
+
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to parse font %s: %v", path, err)
+
+	}
+
+	var faces []*sfnt.Font
+
+	for i := 0; i < collection.NumFonts(); i++ {
+
+		face, err := collection.Font(i)
+
+		if err != nil {
+
+			return nil, fmt.Errorf("failed to parse font %s: %v", path, err)
+
+		}
+
+		faces = append(faces, face)
+
+	}
+
+	return faces, nil
+
+}
+
+// Finds the characters of the text that none of the faces has a glyph for, most frequent first
+
+func findMissingGlyphs(lines []string, faces []*sfnt.Font) []missingGlyph {
+
+	counts := make(map[rune]int)
+
+	for _, line := range lines {
+
+		for _, r := range line {
+
+			if !unicode.IsSpace(r) && !unicode.IsControl(r) {
+
+				counts[r]++
+
+			}
+
+		}
+
+	}
+
+	var buffer sfnt.Buffer
+
+	var missing []missingGlyph
+
+	for r, count := range counts {
+
+		if !hasGlyph(faces, &buffer, r) {
+
+			missing = append(missing, missingGlyph{Char: r, Frequency: count})
+
+		}
+
+	}
+
+	sort.Slice(missing, func(i, j int) bool {
+
+		if missing[i].Frequency != missing[j].Frequency {
+
+			return missing[i].Frequency > missing[j].Frequency
+
+		}
+
+		return missing[i].Char < missing[j].Char
+
+	})
+
+	return missing
+
+}
+
+// Reports whether any face maps the character to a real glyph (index 0 is .notdef, the tofu box)
+
+func hasGlyph(faces []*sfnt.Font, buffer *sfnt.Buffer, r rune) bool {
+
+	for _, face := range faces {
+
+		index, err := face.GlyphIndex(buffer, r)
+
+		if err == nil && index != 0 {
+
+			return true
+
+		}
+
+	}
+
+	return false
+
+}
+
+// Writes the missing characters as "char<TAB>code point<TAB>frequency" lines
+
+func writeMissingGlyphs(path string, missing []missingGlyph, enc encoding.Encoding) error {
+
+	file, err := createOutputFile(path, enc)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to create font coverage report: %v", err)
+
+	}
+
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+
+	for _, glyph := range missing {
+
+		fmt.Fprintf(writer, "%c\tU+%04X\t%d\n", glyph.Char, glyph.Char, glyph.Frequency)
+
+	}
+
+	if err := writer.Flush(); err != nil {
+
+		return fmt.Errorf("failed to write font coverage report: %v", err)
+
+	}
+
+	return nil
+
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+
+	"strings"
+)
+
+// Output formats that can be requested with --format
+
+var outputFormats = []string{"txt", "json", "html", "annotated", "ruby", "epub", "pdf", "parquet", "duckdb", "index", "table", "wordlist"}
+
+// Parses a comma-separated --format value into the set of enabled output formats
+
+func parseFormats(value string) (map[string]bool, error) {
+
+	formats := make(map[string]bool)
+
+	for _, name := range strings.Split(value, ",") {
+
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		if name == "" {
+
+			continue
+
+		}
+
+		if !matchesPhraseList(name, outputFormats) {
+
+			return nil, fmt.Errorf("unknown output format %q (available: %s)", name, strings.Join(outputFormats, ", "))
+
+		}
+
+		formats[name] = true
+
+	}
+
+	if len(formats) == 0 {
+
+		formats["txt"] = true
+
+	}
+
+	return formats, nil
+
+}
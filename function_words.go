@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bufio"
+
+	"fmt"
+
+	"strconv"
+
+	"github.com/jdkato/prose/v2"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+
+	"golang.org/x/text/encoding"
+)
+
+// Writes ChineseFunctionWordReport.txt: each function word with its type, count and rate per 1,000 Chinese tokens
+
+func writeFunctionWordReport(path string, tokens []prose.Token, enc encoding.Encoding) error {
+
+	counts := make(map[string]int)
+
+	total := 0
+
+	for _, tok := range tokens {
+
+		if !classifier.IsChineseText(tok.Text) {
+
+			continue
+
+		}
+
+		total++
+
+		if _, ok := classifier.FunctionWordType(tok.Text); ok {
+
+			counts[tok.Text]++
+
+		}
+
+	}
+
+	file, err := createOutputFile(path, enc)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to create function word report: %v", err)
+
+	}
+
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+
+	fmt.Fprintf(writer, "# %d Chinese tokens\n", total)
+
+	var rows [][]string
+
+	for _, entry := range classifier.RankByFrequency(counts) {
+
+		kind, _ := classifier.FunctionWordType(entry.Item)
+
+		rows = append(rows, []string{entry.Item, kind, strconv.Itoa(entry.Frequency), fmt.Sprintf("%.2f", float64(entry.Frequency)*1000/float64(total))})
+
+	}
+
+	writeTable(writer, nil, rows, []bool{false, false, true, true})
+
+	return writer.Flush()
+
+}
@@ -0,0 +1,436 @@
+package main
+
+import (
+	"encoding/json"
+
+	"fmt"
+
+	"html/template"
+
+	"net"
+
+	"net/http"
+
+	"os"
+
+	"os/exec"
+
+	"path/filepath"
+
+	"runtime"
+
+	"strconv"
+
+	"sync"
+
+	"time"
+
+	"github.com/sqweek/dialog"
+)
+
+// Address of the queue page; port 0 picks a free one
+
+const queueAddr = "localhost:0"
+
+// File analyzed by the queue, with its state for the queue page
+
+type queueJob struct {
+	ID int
+
+	File string
+
+	OutputDir string // Folder next to the file the results are written to
+
+	Status string // "queued", "running", "done" or "failed"
+
+	Error string
+
+	Started time.Time
+
+	Finished time.Time
+
+	Progress string // Lines classified so far while running, e.g. "400/1200 lines"
+}
+
+// Time the job has run, or ran, for
+
+func (j queueJob) Elapsed() string {
+
+	switch {
+
+	case j.Started.IsZero():
+
+		return ""
+
+	case j.Finished.IsZero():
+
+		return time.Since(j.Started).Round(time.Second).String()
+
+	default:
+
+		return j.Finished.Sub(j.Started).Round(100 * time.Millisecond).String()
+
+	}
+
+}
+
+// Files waiting for, and being analyzed by, a fixed number of workers in the order they were added
+
+type fileQueue struct {
+	mu sync.Mutex
+
+	ready *sync.Cond // Signaled when a file is added
+
+	jobs []*queueJob
+
+	options analysisOptions
+}
+
+// Starts a queue analyzing up to workers files at a time with the same options
+
+func newFileQueue(options analysisOptions, workers int) *fileQueue {
+
+	// Chunked runs write the progress.json snapshots the page reports progress from
+
+	if options.FlushEvery == 0 {
+
+		options.FlushEvery = jobChunkLines
+
+	}
+
+	q := &fileQueue{options: options}
+
+	q.ready = sync.NewCond(&q.mu)
+
+	for i := 0; i < workers; i++ {
+
+		go q.work()
+
+	}
+
+	return q
+
+}
+
+// Adds a file to the end of the queue
+
+func (q *fileQueue) add(file string) {
+
+	if absolute, err := filepath.Abs(file); err == nil {
+
+		file = absolute
+
+	}
+
+	q.mu.Lock()
+
+	q.jobs = append(q.jobs, &queueJob{ID: len(q.jobs) + 1, File: file, OutputDir: openWithOutputDir(file), Status: "queued"})
+
+	q.mu.Unlock()
+
+	q.ready.Signal()
+
+}
+
+// Analyzes queued files one after another
+
+func (q *fileQueue) work() {
+
+	for {
+
+		q.mu.Lock()
+
+		job := q.next()
+
+		for job == nil {
+
+			q.ready.Wait()
+
+			job = q.next()
+
+		}
+
+		job.Status, job.Started = "running", time.Now()
+
+		q.mu.Unlock()
+
+		options := q.options
+
+		options.OutputDir = job.OutputDir
+
+		err := categorizeChineseText(job.File, options)
+
+		q.mu.Lock()
+
+		job.Status, job.Finished = "done", time.Now()
+
+		if err != nil {
+
+			job.Status, job.Error = "failed", err.Error()
+
+		}
+
+		q.mu.Unlock()
+
+	}
+
+}
+
+// First queued job, nil when there is none; the queue must be locked
+
+func (q *fileQueue) next() *queueJob {
+
+	for _, job := range q.jobs {
+
+		if job.Status == "queued" {
+
+			return job
+
+		}
+
+	}
+
+	return nil
+
+}
+
+// Copies of the jobs, running ones with their progress
+
+func (q *fileQueue) snapshot() []queueJob {
+
+	q.mu.Lock()
+
+	jobs := make([]queueJob, len(q.jobs))
+
+	for i, job := range q.jobs {
+
+		jobs[i] = *job
+
+	}
+
+	q.mu.Unlock()
+
+	for i, job := range jobs {
+
+		if job.Status != "running" {
+
+			continue
+
+		}
+
+		var progress progressSnapshot
+
+		if data, err := os.ReadFile(filepath.Join(job.OutputDir, "progress.json")); err == nil && json.Unmarshal(data, &progress) == nil {
+
+			jobs[i].Progress = fmt.Sprintf("%d/%d lines", progress.LinesDone, progress.LinesTotal)
+
+		}
+
+	}
+
+	return jobs
+
+}
+
+// Output folder of a finished job
+
+func (q *fileQueue) outputDir(id int) (string, bool) {
+
+	q.mu.Lock()
+
+	defer q.mu.Unlock()
+
+	if id < 1 || id > len(q.jobs) || q.jobs[id-1].Status != "done" {
+
+		return "", false
+
+	}
+
+	return q.jobs[id-1].OutputDir, true
+
+}
+
+// Serves the queue page on a local port, opening it in the browser, with the files given already queued
+
+func runFileQueue(files []string, options analysisOptions, workers int) error {
+
+	q := newFileQueue(options, workers)
+
+	for _, file := range files {
+
+		q.add(file)
+
+	}
+
+	listener, err := net.Listen("tcp", queueAddr)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to start queue page: %v", err)
+
+	}
+
+	url := "http://" + listener.Addr().String() + "/"
+
+	fmt.Printf("Queue running at %s (%d files at a time); press Ctrl+C to quit\n", url, workers)
+
+	if err := openWithSystem(url); err != nil {
+
+		fmt.Println("Open the address in a browser:", err)
+
+	}
+
+	return http.Serve(listener, newQueueHandler(q))
+
+}
+
+// Routes of the queue page
+
+func newQueueHandler(q *fileQueue) http.Handler {
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+
+		if r.URL.Path != "/" {
+
+			http.NotFound(w, r)
+
+			return
+
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+		if err := queueTemplate.Execute(w, q.snapshot()); err != nil {
+
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		}
+
+	})
+
+	mux.HandleFunc("/add", func(w http.ResponseWriter, r *http.Request) {
+
+		if r.Method != http.MethodPost {
+
+			http.Error(w, "POST required", http.StatusMethodNotAllowed)
+
+			return
+
+		}
+
+		// Without a path the native file dialog picks the file, as in a run without arguments
+
+		file := r.FormValue("path")
+
+		if file == "" {
+
+			file, _ = dialog.File().Title("Add File to Queue").Filter("Text Files (*.txt)", "txt").Filter("Documents (*.html, *.srt, *.csv, *.jsonl, *.pdf, *.epub)", "html", "htm", "srt", "csv", "jsonl", "pdf", "epub").Filter("All Files", "*").Load()
+
+		}
+
+		if file != "" {
+
+			q.add(file)
+
+		}
+
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+
+	})
+
+	mux.HandleFunc("/open", func(w http.ResponseWriter, r *http.Request) {
+
+		if r.Method != http.MethodPost {
+
+			http.Error(w, "POST required", http.StatusMethodNotAllowed)
+
+			return
+
+		}
+
+		id, _ := strconv.Atoi(r.FormValue("id"))
+
+		dir, ok := q.outputDir(id)
+
+		if !ok {
+
+			http.NotFound(w, r)
+
+			return
+
+		}
+
+		if err := openWithSystem(dir); err != nil {
+
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+
+			return
+
+		}
+
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+
+	})
+
+	return mux
+
+}
+
+// Opens a folder in the file manager, or an address in the browser
+
+func openWithSystem(target string) error {
+
+	var cmd *exec.Cmd
+
+	switch runtime.GOOS {
+
+	case "windows":
+
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", target)
+
+	case "darwin":
+
+		cmd = exec.Command("open", target)
+
+	default:
+
+		cmd = exec.Command("xdg-open", target)
+
+	}
+
+	return cmd.Start()
+
+}
+
+// The page refreshes itself while files are queued or running
+
+var queueTemplate = template.Must(template.New("queue").Parse(`<!DOCTYPE html>
+<html lang="zh">
+<head>
+<meta charset="utf-8">
+{{range .}}{{if or (eq .Status "queued") (eq .Status "running")}}<meta http-equiv="refresh" content="1">{{break}}{{end}}{{end}}
+<title>cwClassifier queue</title>
+<style>
+body { font-family: "Noto Sans CJK SC", "PingFang SC", "Microsoft YaHei", sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+td, th { padding: .2em .8em; border-bottom: 1px solid #ddd; text-align: left; }
+td.num { text-align: right; }
+.done { color: #2e7d32; } .failed { color: #c62828; } .running { color: #1565c0; }
+form { display: inline; }
+</style>
+</head>
+<body>
+<h1>Queue</h1>
+<form method="post" action="/add"><button>Add file…</button></form>
+<form method="post" action="/add"><input name="path" size="60" placeholder="or a file path"><button>Add</button></form>
+{{if .}}
+<table>
+<tr><th>#</th><th>File</th><th>Status</th><th>Progress</th><th>Elapsed</th><th>Results</th></tr>
+{{range .}}<tr><td class="num">{{.ID}}</td><td>{{.File}}</td><td class="{{.Status}}">{{.Status}}{{if .Error}}: {{.Error}}{{end}}</td><td>{{.Progress}}</td><td class="num">{{.Elapsed}}</td><td>{{if eq .Status "done"}}<form method="post" action="/open"><input type="hidden" name="id" value="{{.ID}}"><button>Open folder</button></form>{{end}}</td></tr>
+{{end}}</table>
+{{else}}<p>No files queued yet.</p>{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"io"
+
+	"os"
+
+	"path/filepath"
+
+	"strings"
+
+	"testing"
+
+	"time"
+)
+
+// Translation backend answering from the text alone, slowly enough that the workers of a queue overlap
+
+type echoTranslator struct{}
+
+func (echoTranslator) Name() string {
+
+	return "echo"
+
+}
+
+func (echoTranslator) Translate(texts []string, targetLanguage string) ([]string, error) {
+
+	time.Sleep(10 * time.Millisecond)
+
+	translations := make([]string, len(texts))
+
+	for i, text := range texts {
+
+		translations[i] = targetLanguage + ":" + text
+
+	}
+
+	return translations, nil
+
+}
+
+// Workers of a queue share the options, the MT cache and a dry run's list of files among them; run with -race
+
+func TestFileQueueWorkers(t *testing.T) {
+
+	dir := t.TempDir()
+
+	var files []string
+
+	for i, text := range []string{"老王坐高铁去北京。", "小李在车站等他。", "他们一起吃了晚饭。", "第二天早上老王回家了。", "小李说：“再见。”"} {
+
+		file := filepath.Join(dir, string(rune('a'+i))+".txt")
+
+		if err := os.WriteFile(file, []byte(text+"\n"+text+"\n"), 0644); err != nil {
+
+			t.Fatal(err)
+
+		}
+
+		files = append(files, file)
+
+	}
+
+	mt := &cachingTranslator{backend: echoTranslator{}, cachePath: filepath.Join(dir, "mt-cache.json"), cache: make(map[string]string)}
+
+	for _, dryRun := range []bool{false, true} {
+
+		options := analysisOptions{Formats: map[string]bool{"txt": true, "json": true}, Translator: mt, TargetLanguage: "en", Messages: io.Discard, DryRun: dryRun}
+
+		if dryRun {
+
+			options.DryRunWrites = &dryRunWrites{}
+
+		}
+
+		queue := newFileQueue(options, 3)
+
+		for _, file := range files {
+
+			queue.add(file)
+
+		}
+
+		deadline := time.Now().Add(30 * time.Second)
+
+		for finished := 0; finished < len(files); {
+
+			if time.Now().After(deadline) {
+
+				t.Fatalf("dry run %v: only %d of %d files finished", dryRun, finished, len(files))
+
+			}
+
+			time.Sleep(20 * time.Millisecond)
+
+			finished = 0
+
+			for _, job := range queue.snapshot() {
+
+				if job.Status == "failed" {
+
+					t.Fatalf("dry run %v: %s failed: %s", dryRun, job.File, job.Error)
+
+				}
+
+				if job.Status == "done" {
+
+					finished++
+
+				}
+
+			}
+
+		}
+
+	}
+
+	if len(mt.cache) == 0 {
+
+		t.Error("no translations were cached")
+
+	}
+
+	for key, translation := range mt.cache {
+
+		if _, text, _ := strings.Cut(key, "\t"); translation != "en:"+text {
+
+			t.Errorf("cache holds %q for %q", translation, key)
+
+		}
+
+	}
+
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+
+	"fmt"
+
+	"os"
+
+	"path/filepath"
+
+	"slices"
+)
+
+// Choices of the last run started from the file dialog, restored on the next launch
+
+type guiSettings struct {
+	InputDir string `json:"inputDir,omitempty"` // Directory the input file was picked from
+
+	OutputDir string `json:"outputDir,omitempty"` // Folder the cwClassifier_output directory was written to
+
+	Stages []string `json:"stages,omitempty"` // Pipeline stages, which decide the categories
+
+	Formats []string `json:"formats,omitempty"` // Output formats
+}
+
+// Path of the per-user settings file, e.g. ~/.config/cwClassifier/gui.json
+
+func guiSettingsPath() (string, error) {
+
+	configDir, err := os.UserConfigDir()
+
+	if err != nil {
+
+		return "", fmt.Errorf("failed to locate config directory: %v", err)
+
+	}
+
+	return filepath.Join(configDir, "cwClassifier", "gui.json"), nil
+
+}
+
+// Reads the settings of the last run; a missing or unreadable file gives empty settings so the dialog starts afresh
+
+func loadGUISettings() guiSettings {
+
+	var settings guiSettings
+
+	path, err := guiSettingsPath()
+
+	if err != nil {
+
+		return settings
+
+	}
+
+	if data, err := os.ReadFile(path); err == nil {
+
+		if err := json.Unmarshal(data, &settings); err != nil {
+
+			fmt.Printf("Warning: ignoring settings in %s: %v\n", path, err)
+
+			return guiSettings{}
+
+		}
+
+	}
+
+	return settings
+
+}
+
+// Writes the settings of a finished run for the next launch
+
+func saveGUISettings(settings guiSettings) error {
+
+	path, err := guiSettingsPath()
+
+	if err != nil {
+
+		return err
+
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+
+		return fmt.Errorf("failed to create config directory: %v", err)
+
+	}
+
+	data, err := json.MarshalIndent(settings, "", "  ")
+
+	if err != nil {
+
+		return fmt.Errorf("failed to encode settings: %v", err)
+
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+
+		return fmt.Errorf("failed to write settings: %v", err)
+
+	}
+
+	return nil
+
+}
+
+// Names of the enabled output formats in a stable order
+
+func enabledFormats(formats map[string]bool) []string {
+
+	var names []string
+
+	for name, enabled := range formats {
+
+		if enabled {
+
+			names = append(names, name)
+
+		}
+
+	}
+
+	slices.Sort(names)
+
+	return names
+
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"fmt"
+
+	"hash/fnv"
+
+	"html/template"
+
+	"os"
+
+	"sort"
+
+	"strings"
+
+	"github.com/jdkato/prose/v2"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+)
+
+// Highlight colors for the built-in categories; other categories get a color derived from their name
+
+var categoryColors = map[string]string{
+
+	"ChineseAbbreviations": "#ffe0b2",
+
+	"ChineseAdjectives": "#c8e6c9",
+
+	"ChineseAdverbs": "#dcedc8",
+
+	"ChineseIdioms": "#f8bbd0",
+
+	"ChineseNouns": "#bbdefb",
+
+	"ChineseOtherExpressions": "#eeeeee",
+
+	"ChineseSlang": "#e1bee7",
+
+	"ChineseVerbs": "#fff9c4",
+}
+
+// Span of highlighted output: either plain text or a categorized token
+
+type htmlSpan struct {
+	Text string
+
+	Class string
+
+	Tooltip string
+
+	Category bool
+}
+
+// Legend entry for one category present in the document
+
+type htmlLegendEntry struct {
+	Category string
+
+	Class string
+
+	Color string
+}
+
+var highlightTemplate = template.Must(template.New("highlight").Parse(`<!DOCTYPE html>
+<html lang="zh">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: "Noto Sans CJK SC", "PingFang SC", "Microsoft YaHei", sans-serif; line-height: 2; margin: 2em; }
+.legend span { display: inline-block; padding: 0 .5em; margin: 0 .5em .5em 0; border-radius: 3px; }
+.text p { margin: 0; min-height: 1em; }
+.tok { border-radius: 3px; cursor: help; }
+{{.Styles}}</style>
+</head>
+<body>
+<div class="legend">{{range .Legend}}<span class="{{.Class}}">{{.Category}}</span>{{end}}</div>
+<div class="text">
+{{range .Lines}}<p>{{range .}}{{if .Category}}<span class="tok {{.Class}}" title="{{.Tooltip}}">{{.Text}}</span>{{else}}{{.Text}}{{end}}{{end}}</p>
+{{end}}</div>
+</body>
+</html>
+`))
+
+// Writes an HTML copy of the input where each Chinese token is colored by category, with POS and frequency tooltips
+
+func writeHighlightedHTML(path, title string, lines []alignedLine, c *classifier.Classifier, ranked map[string][]classifier.ItemFrequency) error {
+
+	frequencies := make(map[string]map[string]int)
+
+	for category, entries := range ranked {
+
+		frequencies[category] = make(map[string]int)
+
+		for _, entry := range entries {
+
+			frequencies[category][entry.Item] = entry.Frequency
+
+		}
+
+	}
+
+	used := make(map[string]bool)
+
+	var htmlLines [][]htmlSpan
+
+	for _, line := range lines {
+
+		var spans []htmlSpan
+
+		for _, aligned := range line.Tokens {
+
+			if aligned.Gap != "" {
+
+				spans = append(spans, htmlSpan{Text: aligned.Gap})
+
+			}
+
+			tok := aligned.Token
+
+			if !classifier.IsChineseText(tok.Text) {
+
+				spans = append(spans, htmlSpan{Text: tok.Text})
+
+				continue
+
+			}
+
+			category := highlightCategory(tok, c)
+
+			used[category] = true
+
+			spans = append(spans, htmlSpan{
+
+				Text: tok.Text,
+
+				Class: categoryClass(category),
+
+				Tooltip: fmt.Sprintf("%s · POS %s · frequency %d", category, tok.Tag, frequencies[category][classifier.CapitalizePhrase(tok.Text)]),
+
+				Category: true,
+			})
+
+		}
+
+		if line.Trailing != "" {
+
+			spans = append(spans, htmlSpan{Text: line.Trailing})
+
+		}
+
+		htmlLines = append(htmlLines, spans)
+
+	}
+
+	var legend []htmlLegendEntry
+
+	for category := range used {
+
+		legend = append(legend, htmlLegendEntry{Category: category, Class: categoryClass(category), Color: categoryColor(category)})
+
+	}
+
+	sort.Slice(legend, func(i, j int) bool {
+
+		return legend[i].Category < legend[j].Category
+
+	})
+
+	// Class names and colors are generated here, so they are trusted as CSS
+
+	var styles strings.Builder
+
+	for _, entry := range legend {
+
+		fmt.Fprintf(&styles, ".%s { background: %s; }\n", entry.Class, entry.Color)
+
+	}
+
+	file, err := os.Create(path)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to create highlighted HTML: %v", err)
+
+	}
+
+	defer file.Close()
+
+	err = highlightTemplate.Execute(file, map[string]interface{}{
+
+		"Title": title,
+
+		"Legend": legend,
+
+		"Styles": template.CSS(styles.String()),
+
+		"Lines": htmlLines,
+	})
+
+	if err != nil {
+
+		return fmt.Errorf("failed to render highlighted HTML: %v", err)
+
+	}
+
+	return nil
+
+}
+
+// Picks the single category used to color a token, preferring dictionary-backed categories over POS
+
+func highlightCategory(tok prose.Token, c *classifier.Classifier) string {
+
+	categories := c.TokenCategories(tok)
+
+	if len(categories) == 1 && classifier.IsAbbreviation(tok.Text) {
+
+		return "ChineseAbbreviations"
+
+	}
+
+	return categories[0]
+
+}
+
+// CSS class name for a category
+
+func categoryClass(category string) string {
+
+	return "c-" + strings.ToLower(strings.TrimPrefix(category, "Chinese"))
+
+}
+
+// Highlight color for a category, stable across runs
+
+func categoryColor(category string) string {
+
+	if color, ok := categoryColors[category]; ok {
+
+		return color
+
+	}
+
+	hash := fnv.New32a()
+
+	hash.Write([]byte(category))
+
+	return fmt.Sprintf("hsl(%d, 70%%, 85%%)", hash.Sum32()%360)
+
+}
@@ -0,0 +1,672 @@
+package main
+
+import (
+	"archive/zip"
+
+	"bufio"
+
+	"bytes"
+
+	"encoding/csv"
+
+	"encoding/json"
+
+	"encoding/xml"
+
+	"fmt"
+
+	"html"
+
+	"io"
+
+	"os"
+
+	"path"
+
+	"path/filepath"
+
+	"regexp"
+
+	"sort"
+
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// Input formats that can be forced with --input-format; "auto" detects them
+
+var inputFormats = []string{"auto", "text", "html", "srt", "csv", "jsonl", "pdf", "epub"}
+
+// File extensions of the supported input formats
+
+var inputExtensions = map[string]string{
+
+	".txt": "text",
+
+	".html": "html",
+
+	".htm": "html",
+
+	".xhtml": "html",
+
+	".srt": "srt",
+
+	".csv": "csv",
+
+	".jsonl": "jsonl",
+
+	".ndjson": "jsonl",
+
+	".pdf": "pdf",
+
+	".epub": "epub",
+}
+
+// SRT cue timing line, e.g. "00:00:01,000 --> 00:00:04,000"
+
+var srtTimingPattern = regexp.MustCompile(`^\d{2}:\d{2}:\d{2}[,.]\d{3}\s+-->\s+\d{2}:\d{2}:\d{2}[,.]\d{3}`)
+
+// Leading SRT cue number followed by a timing line
+
+var srtContentPattern = regexp.MustCompile(`^\s*\d+\r?\n\d{2}:\d{2}:\d{2}[,.]\d{3}\s+-->`)
+
+// Elements whose content is not part of the text (including ruby annotations), and elements that end a line
+
+var (
+	htmlSkippedPattern = regexp.MustCompile(`(?is)<(script|style|head|template|rt|rp)\b.*?</(script|style|head|template|rt|rp)>|<!--.*?-->`)
+
+	htmlBreakPattern = regexp.MustCompile(`(?i)<(br|/p|/div|/h[1-6]|/li|/tr|/title|/blockquote|/section|/article)\b[^>]*>`)
+
+	htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+)
+
+// Parses the --input-format value
+
+func parseInputFormat(value string) (string, error) {
+
+	value = strings.ToLower(strings.TrimSpace(value))
+
+	if value == "" {
+
+		return "auto", nil
+
+	}
+
+	if !matchesPhraseList(value, inputFormats) {
+
+		return "", fmt.Errorf("unknown input format %q (available: %s)", value, strings.Join(inputFormats, ", "))
+
+	}
+
+	return value, nil
+
+}
+
+// Guesses the input format from the extension, falling back to the first bytes of the content
+
+func detectInputFormat(inputFile string, head []byte) string {
+
+	if format, ok := inputExtensions[strings.ToLower(filepath.Ext(inputFile))]; ok {
+
+		return format
+
+	}
+
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(head, []byte("\xef\xbb\xbf")))
+
+	lower := bytes.ToLower(trimmed)
+
+	switch {
+
+	case bytes.HasPrefix(head, []byte("%PDF-")):
+
+		return "pdf"
+
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")) && bytes.Contains(head, []byte("application/epub+zip")):
+
+		return "epub"
+
+	case bytes.HasPrefix(lower, []byte("<!doctype html")) || bytes.HasPrefix(lower, []byte("<html")):
+
+		return "html"
+
+	case srtContentPattern.Match(trimmed):
+
+		return "srt"
+
+	case bytes.HasPrefix(trimmed, []byte("{")) && json.Valid(firstLine(trimmed)):
+
+		return "jsonl"
+
+	}
+
+	return "text"
+
+}
+
+// First line of a byte slice, without the line break
+
+func firstLine(data []byte) []byte {
+
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+
+		return bytes.TrimRight(data[:i], "\r")
+
+	}
+
+	return data
+
+}
+
+// Reads the input file as lines of text, converting it from the given or detected format and from the
+
+// encoding detected for it; returns the format and the encoding
+
+func readInputLines(inputFile, format string) ([]string, string, string, error) {
+
+	data, err := os.ReadFile(inputFile)
+
+	if err != nil {
+
+		return nil, "", "", fmt.Errorf("failed to open input file: %v", err)
+
+	}
+
+	return documentLines(inputFile, data, format)
+
+}
+
+// Converts a document to lines of text from the given format, or the one detected from its name and content.
+
+// Text formats are decoded from the encoding detected for the document, so one batch may mix UTF-8, GBK and Big5.
+
+func documentLines(name string, data []byte, format string) ([]string, string, string, error) {
+
+	var err error
+
+	if format == "" || format == "auto" {
+
+		head := data
+
+		if len(head) > 4096 {
+
+			head = head[:4096]
+
+		}
+
+		format = detectInputFormat(name, head)
+
+	}
+
+	// PDF and EPUB are binary containers that declare their own encodings
+
+	inputEncoding := "utf-8"
+
+	if format != "pdf" && format != "epub" {
+
+		data, inputEncoding = decodeInput(data)
+
+	}
+
+	var lines []string
+
+	switch format {
+
+	case "html":
+
+		lines = htmlLines(string(data))
+
+	case "srt":
+
+		lines = srtLines(string(data))
+
+	case "csv":
+
+		lines, err = csvLines(data)
+
+	case "jsonl":
+
+		lines, err = jsonlLines(data)
+
+	case "pdf":
+
+		lines, err = pdfLines(data)
+
+	case "epub":
+
+		lines, err = epubLines(data)
+
+	default:
+
+		lines, err = textLines(data)
+
+	}
+
+	if err != nil {
+
+		return nil, "", "", fmt.Errorf("error reading input file as %s: %v", format, err)
+
+	}
+
+	return lines, format, inputEncoding, nil
+
+}
+
+// Plain text, line by line
+
+func textLines(data []byte) ([]string, error) {
+
+	var lines []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	for scanner.Scan() {
+
+		lines = append(lines, scanner.Text())
+
+	}
+
+	return lines, scanner.Err()
+
+}
+
+// Visible text of an HTML document, one line per block element
+
+func htmlLines(document string) []string {
+
+	document = htmlSkippedPattern.ReplaceAllString(document, "")
+
+	document = htmlBreakPattern.ReplaceAllString(document, "\n")
+
+	document = html.UnescapeString(htmlTagPattern.ReplaceAllString(document, ""))
+
+	var lines []string
+
+	for _, line := range strings.Split(document, "\n") {
+
+		if line = strings.TrimSpace(line); line != "" {
+
+			lines = append(lines, line)
+
+		}
+
+	}
+
+	return lines
+
+}
+
+// Subtitle text of an SRT file, without cue numbers, timings or formatting tags
+
+func srtLines(document string) []string {
+
+	var lines []string
+
+	blockStart := true
+
+	for _, line := range strings.Split(strings.ReplaceAll(document, "\r\n", "\n"), "\n") {
+
+		line = strings.TrimSpace(strings.TrimPrefix(line, "\ufeff"))
+
+		switch {
+
+		case line == "":
+
+			blockStart = true
+
+		case blockStart && isDigits(line):
+
+			blockStart = false
+
+		case srtTimingPattern.MatchString(line):
+
+			blockStart = false
+
+		default:
+
+			blockStart = false
+
+			lines = append(lines, strings.TrimSpace(htmlTagPattern.ReplaceAllString(line, "")))
+
+		}
+
+	}
+
+	return lines
+
+}
+
+// Reports whether the string is a non-empty run of ASCII digits
+
+func isDigits(text string) bool {
+
+	for _, r := range text {
+
+		if r < '0' || r > '9' {
+
+			return false
+
+		}
+
+	}
+
+	return text != ""
+
+}
+
+// Every CSV cell containing Chinese, one line per cell
+
+func csvLines(data []byte) ([]string, error) {
+
+	reader := csv.NewReader(bytes.NewReader(data))
+
+	reader.FieldsPerRecord = -1
+
+	reader.LazyQuotes = true
+
+	var lines []string
+
+	for {
+
+		record, err := reader.Read()
+
+		if err == io.EOF {
+
+			break
+
+		}
+
+		if err != nil {
+
+			return nil, err
+
+		}
+
+		for _, field := range record {
+
+			if containsChinese(field) {
+
+				lines = append(lines, field)
+
+			}
+
+		}
+
+	}
+
+	return lines, nil
+
+}
+
+// Text of each JSON Lines record: its "text" field when present, otherwise every string value
+
+func jsonlLines(data []byte) ([]string, error) {
+
+	var lines []string
+
+	for number, line := range strings.Split(string(data), "\n") {
+
+		if strings.TrimSpace(line) == "" {
+
+			continue
+
+		}
+
+		var record interface{}
+
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+
+			return nil, fmt.Errorf("line %d: %v", number+1, err)
+
+		}
+
+		if object, ok := record.(map[string]interface{}); ok {
+
+			if text, ok := object["text"].(string); ok {
+
+				lines = append(lines, text)
+
+				continue
+
+			}
+
+		}
+
+		lines = append(lines, jsonStrings(record)...)
+
+	}
+
+	return lines, nil
+
+}
+
+// Collects the string values of a decoded JSON value, visiting object keys in sorted order
+
+func jsonStrings(value interface{}) []string {
+
+	switch v := value.(type) {
+
+	case string:
+
+		return []string{v}
+
+	case []interface{}:
+
+		var texts []string
+
+		for _, item := range v {
+
+			texts = append(texts, jsonStrings(item)...)
+
+		}
+
+		return texts
+
+	case map[string]interface{}:
+
+		var keys []string
+
+		for key := range v {
+
+			keys = append(keys, key)
+
+		}
+
+		sort.Strings(keys)
+
+		var texts []string
+
+		for _, key := range keys {
+
+			texts = append(texts, jsonStrings(v[key])...)
+
+		}
+
+		return texts
+
+	}
+
+	return nil
+
+}
+
+// Plain text of every PDF page
+
+func pdfLines(data []byte) ([]string, error) {
+
+	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+
+	if err != nil {
+
+		return nil, err
+
+	}
+
+	var lines []string
+
+	for i := 1; i <= reader.NumPage(); i++ {
+
+		page := reader.Page(i)
+
+		if page.V.IsNull() {
+
+			continue
+
+		}
+
+		text, err := page.GetPlainText(nil)
+
+		if err != nil {
+
+			return nil, fmt.Errorf("page %d: %v", i, err)
+
+		}
+
+		lines = append(lines, strings.Split(text, "\n")...)
+
+	}
+
+	return lines, nil
+
+}
+
+// EPUB container and package documents, reduced to what is needed to find the chapters in reading order
+
+type epubContainer struct {
+	Rootfiles []struct {
+		Path string `xml:"full-path,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+type epubPackage struct {
+	Manifest []struct {
+		ID string `xml:"id,attr"`
+
+		Href string `xml:"href,attr"`
+	} `xml:"manifest>item"`
+
+	Spine []struct {
+		IDRef string `xml:"idref,attr"`
+	} `xml:"spine>itemref"`
+}
+
+// Text of every chapter of an EPUB, in spine order
+
+func epubLines(data []byte) ([]string, error) {
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+
+	if err != nil {
+
+		return nil, err
+
+	}
+
+	files := make(map[string]*zip.File)
+
+	for _, file := range archive.File {
+
+		files[file.Name] = file
+
+	}
+
+	var container epubContainer
+
+	if err := readZipXML(files, "META-INF/container.xml", &container); err != nil {
+
+		return nil, err
+
+	}
+
+	if len(container.Rootfiles) == 0 {
+
+		return nil, fmt.Errorf("container.xml names no package document")
+
+	}
+
+	packagePath := container.Rootfiles[0].Path
+
+	var pkg epubPackage
+
+	if err := readZipXML(files, packagePath, &pkg); err != nil {
+
+		return nil, err
+
+	}
+
+	hrefs := make(map[string]string)
+
+	for _, item := range pkg.Manifest {
+
+		hrefs[item.ID] = item.Href
+
+	}
+
+	var lines []string
+
+	for _, ref := range pkg.Spine {
+
+		chapter, err := readZipFile(files, path.Join(path.Dir(packagePath), hrefs[ref.IDRef]))
+
+		if err != nil {
+
+			return nil, err
+
+		}
+
+		lines = append(lines, htmlLines(string(chapter))...)
+
+	}
+
+	return lines, nil
+
+}
+
+// Reads one file of a zip archive
+
+func readZipFile(files map[string]*zip.File, name string) ([]byte, error) {
+
+	file, ok := files[name]
+
+	if !ok {
+
+		return nil, fmt.Errorf("missing %s", name)
+
+	}
+
+	reader, err := file.Open()
+
+	if err != nil {
+
+		return nil, err
+
+	}
+
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+
+}
+
+// Reads and decodes one XML file of a zip archive
+
+func readZipXML(files map[string]*zip.File, name string, v interface{}) error {
+
+	data, err := readZipFile(files, name)
+
+	if err != nil {
+
+		return err
+
+	}
+
+	if err := xml.Unmarshal(data, v); err != nil {
+
+		return fmt.Errorf("invalid %s: %v", name, err)
+
+	}
+
+	return nil
+
+}
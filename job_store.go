@@ -0,0 +1,227 @@
+package main
+
+import (
+	"fmt"
+
+	"os"
+
+	"path/filepath"
+
+	"strings"
+
+	"sync"
+
+	"time"
+)
+
+// Storage of job states and results, shared by all replicas of the server so any of them can answer for
+
+// any job. Objects are JSON documents under keys such as "<id>.json"; Expire removes the ones not written
+
+// since cutoff.
+
+type jobStore interface {
+	Put(key string, data []byte) error
+
+	Get(key string) ([]byte, bool, error)
+
+	Expire(cutoff time.Time) (int, error)
+}
+
+// Opens the store named by --store: "memory", a directory or s3://bucket/prefix
+
+func openJobStore(spec string) (jobStore, error) {
+
+	switch {
+
+	case spec == "" || spec == "memory":
+
+		return &memoryStore{objects: make(map[string]storedObject)}, nil
+
+	case strings.HasPrefix(spec, "s3://"):
+
+		return newS3Store(spec)
+
+	default:
+
+		if err := os.MkdirAll(spec, 0755); err != nil {
+
+			return nil, fmt.Errorf("failed to create job store directory: %v", err)
+
+		}
+
+		return diskStore(spec), nil
+
+	}
+
+}
+
+// Reports whether a store key belongs to a job, so expiry leaves other files of a shared directory or bucket alone
+
+func isJobKey(key string) bool {
+
+	id, _, _ := strings.Cut(key, ".")
+
+	return isJobID(id) && strings.HasSuffix(key, ".json")
+
+}
+
+// Object of the memory store with its last write
+
+type storedObject struct {
+	data []byte
+
+	modified time.Time
+}
+
+// Store of a single server, lost on restart
+
+type memoryStore struct {
+	mu sync.Mutex
+
+	objects map[string]storedObject
+}
+
+func (s *memoryStore) Put(key string, data []byte) error {
+
+	s.mu.Lock()
+
+	defer s.mu.Unlock()
+
+	s.objects[key] = storedObject{data: data, modified: time.Now()}
+
+	return nil
+
+}
+
+func (s *memoryStore) Get(key string) ([]byte, bool, error) {
+
+	s.mu.Lock()
+
+	defer s.mu.Unlock()
+
+	object, ok := s.objects[key]
+
+	return object.data, ok, nil
+
+}
+
+func (s *memoryStore) Expire(cutoff time.Time) (int, error) {
+
+	s.mu.Lock()
+
+	defer s.mu.Unlock()
+
+	expired := 0
+
+	for key, object := range s.objects {
+
+		if object.modified.Before(cutoff) {
+
+			delete(s.objects, key)
+
+			expired++
+
+		}
+
+	}
+
+	return expired, nil
+
+}
+
+// Store of one file per object in a directory, such as a volume shared by the replicas
+
+type diskStore string
+
+func (s diskStore) Put(key string, data []byte) error {
+
+	// Write to a temporary file first so readers never see half an object
+
+	temp, err := os.CreateTemp(string(s), ".tmp-*")
+
+	if err != nil {
+
+		return fmt.Errorf("failed to store %s: %v", key, err)
+
+	}
+
+	_, err = temp.Write(data)
+
+	if closeErr := temp.Close(); err == nil {
+
+		err = closeErr
+
+	}
+
+	if err == nil {
+
+		err = os.Rename(temp.Name(), filepath.Join(string(s), key))
+
+	}
+
+	if err != nil {
+
+		os.Remove(temp.Name())
+
+		return fmt.Errorf("failed to store %s: %v", key, err)
+
+	}
+
+	return nil
+
+}
+
+func (s diskStore) Get(key string) ([]byte, bool, error) {
+
+	data, err := os.ReadFile(filepath.Join(string(s), key))
+
+	if os.IsNotExist(err) {
+
+		return nil, false, nil
+
+	}
+
+	if err != nil {
+
+		return nil, false, fmt.Errorf("failed to read %s: %v", key, err)
+
+	}
+
+	return data, true, nil
+
+}
+
+func (s diskStore) Expire(cutoff time.Time) (int, error) {
+
+	entries, err := os.ReadDir(string(s))
+
+	if err != nil {
+
+		return 0, fmt.Errorf("failed to list job store: %v", err)
+
+	}
+
+	expired := 0
+
+	for _, entry := range entries {
+
+		info, err := entry.Info()
+
+		if err != nil || info.IsDir() || !isJobKey(entry.Name()) || !info.ModTime().Before(cutoff) {
+
+			continue
+
+		}
+
+		if err := os.Remove(filepath.Join(string(s), entry.Name())); err == nil {
+
+			expired++
+
+		}
+
+	}
+
+	return expired, nil
+
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"encoding/json"
+
+	"fmt"
+
+	"os"
+
+	"sort"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+)
+
+// Ranked item as written to the JSON output
+
+type jsonItem struct {
+	Item string `json:"item"`
+
+	Frequency int `json:"frequency"`
+
+	Confidence float64 `json:"confidence,omitempty"` // How reliably the item belongs to its category, 0-1
+
+	Gloss string `json:"gloss,omitempty"`
+
+	Morphemes []jsonMorpheme `json:"morphemes,omitempty"` // Word-building parts of multi-character words
+
+	Examples []string `json:"examples,omitempty"` // Sampled example sentences of frequent words
+
+	Pinyin string `json:"pinyin,omitempty"` // Pinyin of the item with --pinyin
+
+	Converted string `json:"converted,omitempty"` // The item in the script of --dual-script
+
+	Forms []jsonForm `json:"forms,omitempty"` // Surface forms counted under the item with --lemmas
+
+	Normalized string `json:"normalized,omitempty"` // Value of a ChineseNumbersDates item in Arabic numerals
+
+	Strokes int `json:"strokes,omitempty"` // Stroke count of a ChineseCharacters item with --strokes
+
+	Decomposition string `json:"decomposition,omitempty"` // Components of a ChineseCharacters item with --components, such as ⿱相心
+
+	Band string `json:"band,omitempty"` // Frequency band of a ChineseCharacters item with --bands, such as top-500
+
+}
+
+// Top-level JSON output document, described by schema/results-v1.schema.json
+
+type jsonResults struct {
+	SchemaVersion string `json:"schemaVersion"`
+
+	Source string `json:"source"`
+
+	Seed int64 `json:"seed"`
+
+	Categories map[string][]jsonItem `json:"categories"`
+
+	Suppressed *jsonSuppressed `json:"suppressed,omitempty"` // Occurrences the --blocklist dropped
+
+	ScanMode string `json:"scanMode,omitempty"` // How overlapping idiom and slang matches were counted
+}
+
+// Audit of the occurrences the blocklist dropped from all categories
+
+type jsonSuppressed struct {
+	Occurrences int `json:"occurrences"`
+
+	Items map[string]int `json:"items"` // Blocked item → occurrences dropped
+}
+
+// Translates every distinct ranked item and extra text (such as morphemes) once, returning text → gloss
+
+func glossItems(t translator, ranked map[string][]classifier.ItemFrequency, extra []string, targetLanguage string) (map[string]string, error) {
+
+	glosses := make(map[string]string)
+
+	if t == nil {
+
+		return glosses, nil
+
+	}
+
+	var items []string
+
+	seen := make(map[string]bool)
+
+	for _, entries := range ranked {
+
+		for _, entry := range entries {
+
+			if !seen[entry.Item] {
+
+				seen[entry.Item] = true
+
+				items = append(items, entry.Item)
+
+			}
+
+		}
+
+	}
+
+	for _, text := range extra {
+
+		if !seen[text] {
+
+			seen[text] = true
+
+			items = append(items, text)
+
+		}
+
+	}
+
+	sort.Strings(items)
+
+	translations, err := t.Translate(items, targetLanguage)
+
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to gloss items: %v", err)
+
+	}
+
+	for i, item := range items {
+
+		glosses[item] = translations[i]
+
+	}
+
+	return glosses, nil
+
+}
+
+// Writes all categories with frequencies (and glosses, morphemes, examples, pinyin, converted, surface forms and normalized numbers when available) as one JSON document
+
+func writeJSONResults(path, source string, seed int64, ranked map[string][]classifier.ItemFrequency, c *classifier.Classifier, glosses map[string]string, morphemes map[string][]jsonMorpheme, examples map[string][]string, pinyin *pinyinAnnotator, dualScript *scriptConverter, forms map[string]map[string]map[string]int, suppressed map[string]int, bands map[rune]characterInfo, strokes, components bool) error {
+
+	data, err := json.MarshalIndent(buildJSONResults(source, seed, ranked, c, glosses, morphemes, examples, pinyin, dualScript, forms, suppressed, bands, strokes, components), "", "  ")
+
+	if err != nil {
+
+		return fmt.Errorf("failed to encode JSON results: %v", err)
+
+	}
+
+	if err := validateResults(data); err != nil {
+
+		return err
+
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+
+		return fmt.Errorf("failed to write JSON results: %v", err)
+
+	}
+
+	return nil
+
+}
+
+// Builds the JSON results document; glosses, morphemes, examples, pinyin, dualScript, forms and suppressed may be nil,
+
+// bands (the character table) adds the frequency bands of characters when given, and strokes and components add
+
+// their stroke counts and decompositions
+
+func buildJSONResults(source string, seed int64, ranked map[string][]classifier.ItemFrequency, c *classifier.Classifier, glosses map[string]string, morphemes map[string][]jsonMorpheme, examples map[string][]string, pinyin *pinyinAnnotator, dualScript *scriptConverter, forms map[string]map[string]map[string]int, suppressed map[string]int, bands map[rune]characterInfo, strokes, components bool) jsonResults {
+
+	results := jsonResults{SchemaVersion: resultsSchemaVersion, Source: source, Seed: seed, Categories: make(map[string][]jsonItem), ScanMode: c.ScanMode()}
+
+	if suppressed != nil {
+
+		results.Suppressed = &jsonSuppressed{Items: suppressed}
+
+		for _, count := range suppressed {
+
+			results.Suppressed.Occurrences += count
+
+		}
+
+	}
+
+	for category, entries := range ranked {
+
+		items := []jsonItem{}
+
+		for _, entry := range entries {
+
+			item := jsonItem{Item: entry.Item, Frequency: entry.Frequency, Confidence: c.Confidence(category, entry.Item), Gloss: glosses[entry.Item], Morphemes: morphemes[entry.Item], Examples: examples[entry.Item]}
+
+			if pinyin != nil {
+
+				item.Pinyin = pinyin.annotate(category, entry.Item)
+
+			}
+
+			if dualScript != nil {
+
+				item.Converted = dualScript.convert(entry.Item)
+
+			}
+
+			if surfaces, ok := forms[category][entry.Item]; ok {
+
+				item.Forms = itemForms(surfaces)
+
+			}
+
+			if strokes && category == "ChineseCharacters" {
+
+				item.Strokes = characterStrokes(entry.Item)
+
+			}
+
+			if components && category == "ChineseCharacters" {
+
+				item.Decomposition = characterDecomposition(entry.Item)
+
+			}
+
+			if bands != nil && category == "ChineseCharacters" {
+
+				item.Band = characterBand(bands, entry.Item)
+
+			}
+
+			if category == "ChineseNumbersDates" {
+
+				item.Normalized = classifier.NormalizeNumber(entry.Item)
+
+			}
+
+			items = append(items, item)
+
+		}
+
+		results.Categories[category] = items
+
+	}
+
+	return results
+
+}
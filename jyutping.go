@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bufio"
+
+	_ "embed"
+
+	"fmt"
+
+	"strings"
+
+	"unicode/utf8"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+)
+
+//go:embed dict/jyutping.txt
+
+var jyutpingData string
+
+func init() {
+
+	classifier.RegisterResource("jyutping.txt", jyutpingData)
+
+}
+
+// Loads the Cantonese readings of characters, keyed by character. Traditional characters without a reading of
+// their own take that of their simplified form, so 學 reads hok6 like 学.
+
+func loadJyutpingTable() (map[rune]characterInfo, error) {
+
+	table := make(map[rune]characterInfo)
+
+	scanner := bufio.NewScanner(strings.NewReader(classifier.ResourceText("jyutping.txt")))
+
+	for scanner.Scan() {
+
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+
+			continue
+
+		}
+
+		fields := strings.Fields(line)
+
+		if len(fields) != 2 || utf8.RuneCountInString(fields[0]) != 1 {
+
+			return nil, fmt.Errorf("invalid Jyutping table line %q", line)
+
+		}
+
+		r, _ := utf8.DecodeRuneInString(fields[0])
+
+		table[r] = characterInfo{Rank: len(table) + 1, Readings: strings.Split(fields[1], "/")}
+
+	}
+
+	if err := scanner.Err(); err != nil {
+
+		return nil, err
+
+	}
+
+	simplified, err := loadConversionTable("TSCharacters.txt")
+
+	if err != nil {
+
+		return nil, err
+
+	}
+
+	for traditional, simple := range simplified {
+
+		t, _ := utf8.DecodeRuneInString(traditional)
+
+		s, _ := utf8.DecodeRuneInString(simple)
+
+		if _, ok := table[t]; ok {
+
+			continue
+
+		}
+
+		if info, ok := table[s]; ok {
+
+			table[t] = info
+
+		}
+
+	}
+
+	return table, nil
+
+}
+
+// Readings of the words of the Cantonese lexicon, which settle characters read differently in them (屋企 uk1
+// kei2, where 企 alone is kei5)
+
+func loadJyutpingWords() (map[string][]string, int) {
+
+	words := make(map[string][]string)
+
+	maxLength := 0
+
+	for _, word := range classifier.CantoneseWords() {
+
+		syllables := strings.Fields(word.Jyutping)
+
+		if length := utf8.RuneCountInString(word.Word); length > 1 && length == len(syllables) {
+
+			words[word.Word] = syllables
+
+			maxLength = max(maxLength, length)
+
+		}
+
+	}
+
+	return words, maxLength
+
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+
+	"unicode"
+)
+
+// Dominant language of a text, guessed from its scripts, and the share of Han characters among its letters
+
+type languageProfile struct {
+	Language string
+
+	HanRatio float64
+}
+
+// Scripts counted for language detection, with the language each one indicates
+
+var languageScripts = []struct {
+	Language string
+
+	Table *unicode.RangeTable
+}{
+
+	{"Chinese", unicode.Han},
+
+	{"Japanese", unicode.Hiragana},
+
+	{"Japanese", unicode.Katakana},
+
+	{"Korean", unicode.Hangul},
+
+	{"Latin-script", unicode.Latin},
+
+	{"Cyrillic-script", unicode.Cyrillic},
+
+	{"Arabic", unicode.Arabic},
+}
+
+// Guesses the dominant language of the lines from the scripts of their letters. Kana make a text Japanese
+
+// even when Han characters (kanji) outnumber them.
+
+func detectLanguage(lines []string) languageProfile {
+
+	counts := make(map[string]int)
+
+	letters := 0
+
+	for _, line := range lines {
+
+		for _, r := range line {
+
+			if !unicode.IsLetter(r) {
+
+				continue
+
+			}
+
+			letters++
+
+			for _, script := range languageScripts {
+
+				if unicode.Is(script.Table, r) {
+
+					counts[script.Language]++
+
+					break
+
+				}
+
+			}
+
+		}
+
+	}
+
+	if letters == 0 {
+
+		return languageProfile{Language: "unknown"}
+
+	}
+
+	profile := languageProfile{Language: "other", HanRatio: float64(counts["Chinese"]) / float64(letters)}
+
+	best := 0
+
+	for _, script := range languageScripts {
+
+		if counts[script.Language] > best {
+
+			profile.Language, best = script.Language, counts[script.Language]
+
+		}
+
+	}
+
+	if profile.Language == "Chinese" && counts["Japanese"]*10 >= letters {
+
+		profile.Language = "Japanese"
+
+	}
+
+	return profile
+
+}
+
+// Reports whether a document is not Chinese (too few Han characters, or Japanese), describing it for the log.
+
+// A minimum ratio of 0 disables the check.
+
+func mostlyNonChinese(lines []string, minHanRatio float64) (bool, string) {
+
+	profile := detectLanguage(lines)
+
+	description := fmt.Sprintf("mostly %s, %.0f%% Han characters", profile.Language, profile.HanRatio*100)
+
+	if minHanRatio == 0 {
+
+		return false, description
+
+	}
+
+	return profile.HanRatio < minHanRatio || profile.Language == "Japanese", description
+
+}
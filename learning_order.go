@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bufio"
+
+	"fmt"
+
+	"strconv"
+
+	"strings"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+
+	"golang.org/x/text/encoding"
+)
+
+// A character of the text in the learning order, with the characters of the text it is built from and builds
+
+type learningStep struct {
+	entry classifier.ItemFrequency
+
+	weight int // Frequency of the character plus that of the characters of the text containing it
+
+	components []string // Characters of the text among its components, at any level
+
+	usedIn int // Characters of the text containing it
+}
+
+// Orders the characters of the text for study: a character comes after every character of the text it
+
+// contains (相 and 心 before 想), and among the characters ready to learn the one weighing most comes first,
+
+// weighing its frequency together with that of the characters it unlocks
+
+func learningOrder(characters []classifier.ItemFrequency) []learningStep {
+
+	steps := make(map[string]*learningStep)
+
+	for _, entry := range characters {
+
+		steps[entry.Item] = &learningStep{entry: entry, weight: entry.Frequency}
+
+	}
+
+	for _, entry := range characters {
+
+		for _, component := range classifier.AllComponents([]rune(entry.Item)[0]) {
+
+			if step, ok := steps[component]; ok {
+
+				step.weight += entry.Frequency
+
+				step.usedIn++
+
+				steps[entry.Item].components = append(steps[entry.Item].components, component)
+
+			}
+
+		}
+
+	}
+
+	learned := make(map[string]bool)
+
+	var order []learningStep
+
+	for len(order) < len(characters) {
+
+		var next *learningStep
+
+		for _, entry := range characters {
+
+			step := steps[entry.Item]
+
+			if learned[entry.Item] || !componentsLearned(step, learned) {
+
+				continue
+
+			}
+
+			if next == nil || step.weight > next.weight || step.weight == next.weight && simplerCharacter(step.entry.Item, next.entry.Item) {
+
+				next = step
+
+			}
+
+		}
+
+		// Decompositions form no cycles, but a character whose variant components refer back to it is still placed
+
+		if next == nil {
+
+			for _, entry := range characters {
+
+				if !learned[entry.Item] {
+
+					next = steps[entry.Item]
+
+					break
+
+				}
+
+			}
+
+		}
+
+		learned[next.entry.Item] = true
+
+		order = append(order, *next)
+
+	}
+
+	return order
+
+}
+
+// Whether every character of the text a character contains has been learned
+
+func componentsLearned(step *learningStep, learned map[string]bool) bool {
+
+	for _, component := range step.components {
+
+		if !learned[component] && component != step.entry.Item {
+
+			return false
+
+		}
+
+	}
+
+	return true
+
+}
+
+// Whether a character has fewer strokes than another, characters of unknown stroke count last
+
+func simplerCharacter(a, b string) bool {
+
+	strokesA, strokesB := characterStrokes(a), characterStrokes(b)
+
+	if strokesA == 0 || strokesB == 0 {
+
+		return strokesA != 0 && strokesB == 0
+
+	}
+
+	return strokesA < strokesB
+
+}
+
+// Writes ChineseLearningOrder.txt: the characters of ChineseCharacters in the order to study them, with the
+
+// characters of the text each builds on and how many it is used in
+
+func writeLearningOrderReport(path string, characters []classifier.ItemFrequency, enc encoding.Encoding) error {
+
+	file, err := createOutputFile(path, enc)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to create learning order report: %v", err)
+
+	}
+
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+
+	var rows [][]string
+
+	for i, step := range learningOrder(characters) {
+
+		rows = append(rows, []string{strconv.Itoa(i + 1), step.entry.Item, strconv.Itoa(step.entry.Frequency), strconv.Itoa(step.usedIn), strings.Join(step.components, " ")})
+
+	}
+
+	writeTable(writer, []string{"order", "character", "frequency", "used in", "builds on"}, rows, []bool{true, false, true, true, false})
+
+	return writer.Flush()
+
+}
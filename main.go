@@ -8,10 +8,126 @@ Automatically outputs results to "cwClassifier_output" directory
 
 Extracts Chinese characters, nouns, verbs, adjectives, adverbs
 
+Extracts Chinese abbreviations and Latin acronyms embedded in Chinese text
+
 Categorizes text into noun phrases, verb phrases, idioms, and slang
 
+Collects function words (prepositions, conjunctions, particles) with their rate per 1,000 tokens in ChineseFunctionWordReport.txt; --stop-function-words keeps them out of the other categories
+Counts measure words (个, 只, 条, 张, ...) after numerals and demonstratives, with the nouns each pairs with in ChineseMeasureWordPairs.txt
+
+Optional term extraction (--terms) exports C-value ranked candidate terms as CSV and TBX
+
+Optional chat mode (--chat) reads "speaker: message" transcripts and reports per-speaker vocabulary, slang and message statistics
+
+Optional novel mode (--novel) detects chapter headings (第X章) and reports per-chapter vocabulary introduction and character-name frequencies
+
+Optional character network (--network sentence|paragraph) links the person names recognized by the entities stage that co-occur, exported as GraphML and Gephi CSV
+
+Optional new-word discovery (--discover) proposes out-of-dictionary words for review; "cwClassifier dict apply" merges accepted ones into a user dictionary, or --discover-into adds high-scoring ones directly
+
+Optional morphology report (--morphology) lists productive affixes with their stems, counts and productivity
+
+Optional parallel corpus (--parallel) exports translated example sentences as ChineseSentences.tmx
+
+Optional highlighted HTML copy of the input (--format html) colors each token by category
+
+Optional annotated copy of the input (--format annotated) marks items inline, e.g. 【成语:画蛇添足】
+
+Optional pinyin (--pinyin marks|numbers|zhuyin|jyutping) annotates every item in category files and JSON output, reading polyphones from a word list and from their words in the text; zhuyin writes bopomofo (ㄓㄨㄥ ㄨㄣˊ) for readers in Taiwan, jyutping Cantonese readings (zung1 man4)
+
+Optional Cantonese mode (--cantonese) for Hong Kong forum text adds a Cantonese lexicon (嘅, 唔, 咁, 靓) and slang (食花生, 巴打) to segmentation, annotates items with Jyutping and lists the Cantonese words found in ChineseCantonese.txt
+
+Optional polyphone report (--polyphones) lists each polyphonic character (行, 长, 得) with the readings it takes in the words of the text (银行 háng, 行人 xíng) in ChinesePolyphones.txt, for pronunciation audits
+
+Optional tone patterns (--tones) count the tones of words (3-3, 2-4-1) and of adjacent syllables in each sentence, with the tone sandhi contexts (3-3, 不 and 一 before other tones) in ChineseTonePatterns.txt
+
+Optional script conversion (--script simplified|traditional|taiwan|hongkong) converts the input before analysis with OpenCC-style tables, reading Taiwan and Hong Kong forms and vocabulary; --dual-script adds each item in another script to category files and JSON output
+Optional graded-reading output (--format ruby,epub) puts pinyin over characters above a difficulty threshold (--ruby-threshold)
+
+Optional printable vocabulary list (--format pdf) with word, pinyin, gloss and frequency, in columns or flashcards
+
+Optional font coverage check (--font-check) lists characters the given font cannot render
+
+Optional sentence index (--format index) maps each sentence ID to its items by category, and each category to its sentences
+Optional console tables (--format table) print the top items of each category, aligned for full-width characters
+
+Optional Parquet tables (--format parquet) of tokens and ranked category items for pandas, Polars or Spark
+
+Optional DuckDB database (--format duckdb) with top_words, category_stats and file_stats views, built with the duckdb CLI
+
+Optional word lists (--format wordlist) for corpus tools: ChineseWordList.txt in AntConc's word list layout and ChineseWordList.tsv with type, lemma, POS, frequency, range and DP columns for #LancsBox or spreadsheets
+
+Optional machine translation (--mt deepl|google|local) attaches cached glosses to JSON output (--format json)
+
+JSON output decomposes multi-character words into morphemes with pinyin (and glosses with --mt)
+
+JSON output gives frequent words a few example sentences, sampled by frequency and diversity (reproducible with --seed)
+
+JSON output scores each item's confidence from how it was matched; --min-confidence drops weak matches from all outputs
+
+JSON output follows the versioned schema in schema/results-v1.schema.json and is validated against it before writing
+
+Dashboard mode (cwClassifier dashboard --runs dir) serves a web UI to browse runs, search items, view KWIC and compare two runs
+
+Server mode (cwClassifier serve --addr host:port) runs analyses as jobs: POST /jobs returns a job ID, GET /jobs/{id} reports progress, GET /jobs/{id}/events streams it as server-sent events and GET /jobs/{id}/results returns the JSON results; --store keeps them on disk or in S3 so replicas can share them, removed after --ttl; GET /openapi.json describes the API, and the client package wraps it for Go programs
+Compare mode (cwClassifier compare run1/ run2/) diffs two stored JSON runs into added, removed and changed items per category, as text and JSON
+Optional stage selection (--profile fast|full, --stages pos,idioms,...) trades categories for speed, with a cost estimate per run
+
+Optional segmenter choice (--segmenter jieba|maxmatch|command) swaps word segmentation at runtime; command runs an external one such as pkuseg or gse
+
+Recognizes person, place and organization names (ChinesePersons, ChinesePlaces, ChineseOrganizations) from an embedded name dictionary and title and suffix rules
+Collects phonetic loanwords (咖啡, 沙发, 奥特曼) in ChineseLoanwords from an embedded lexicon and the characters transliterations are written with
+Gathers numbers, dates and times (三百二十一, 二〇二四年三月五日, 下午3:30) that segmentation splits into ChineseNumbersDates instead of the word categories, each with its value in Arabic numerals (321, 2024-03-05, 15:30)
+Lists the dates and times in ISO 8601 form (2024-03-05T10:30) with their sentences in text order in ChineseTimeline.txt
+Collects onomatopoeia (哗啦, 咕噜, 嗡嗡) in ChineseOnomatopoeia from an embedded lexicon and reduplicated sound characters
+Idioms come from an embedded dictionary of about 22,000 chengyu, matched across word boundaries; --idioms file adds more
+Overlapping idiom and slang matches are counted by --scan greedy (longest at each position, left to right), longest (longest anywhere first) or all (every match, nested ones included), and results.json records the mode
+Optional variant normalization (--variants) counts variant characters (峯 → 峰, 羣 → 群) and legacy code points as their standard forms, listing the mappings found in ChineseVariants.txt
+Optional per-project blocklist (--blocklist file) drops junk tokens from all outputs and records how many occurrences it suppressed in ChineseSuppressed.txt and results.json
+Proverbs (ChineseProverbs) and xiehouyu (ChineseXiehouyu) come from embedded dictionaries, matched whether or not punctuation separates their parts; a xiehouyu riddle said without its answer counts as the whole saying
+Extracts quoted speech (“…”, 「…」) into ChineseDialogue and attributes it to speakers named in the narration (老王说：), with each speaker's most used words in ChineseDialogueSpeakers.txt
+Optional speaker comparison (--speakers) reads speaker tags leading subtitle and script lines (（老王）, [老王], 老王：), analyzes the lines without them and compares each character's vocabulary and characteristic words in ChineseSpeakers.txt
+Finds politeness markers (您, 请, 贵公司, 抱歉) in ChinesePoliteness and profiles the document's register by kind in ChinesePolitenessProfile.txt, e.g. for customer-service chat QA
+Optional per-category minimum lengths (--min-length ChineseNouns=2) drop the single-character items segmentation leaves in word categories
+Groups ChineseCharacters by Kangxi radical (言 讠, 水, 心, ...) in ChineseRadicals.txt, from an embedded table derived from the radical-stroke order of Unicode
+Optional stroke counts (--strokes) annotate ChineseCharacters and chart their distribution in ChineseStrokeCounts.txt; --sort-characters strokes lists characters by stroke count
+Optional frequency bands (--bands) tag each character as top-500, top-1500, top-3000 or rare by its rank in the embedded character table, with the band distribution of the text in ChineseFrequencyBands.txt
+Optional rank splitting (--split-ranks sections|files) divides every category's txt list into its top 1,000 items, ranks 1,001-5,000 and the tail, as sections of one file or as a file per band, to focus study or review by band
+Optional component decomposition (--components) breaks each character into its parts (想 → 相 + 心) and lists the characters of the text sharing each component in ChineseComponents.txt, for learners
+
+Optional learning order (--learning-order) sequences the characters of the text for study in ChineseLearningOrder.txt, components (口, 木) before the characters built from them, weighted by frequency
+Language resources (core lexicon, idioms, stopwords, HSK levels, pinyin tables, ...) are embedded and versioned so the tool works offline; --resources dir replaces any of them by file name, and "cwClassifier resources" lists or exports them
+Detects the dominant language of each input; files with fewer Han characters than --min-han-ratio are skipped in batch mode and warned about otherwise
+Optional mixed-language mode (--mixed) analyzes only the Chinese runs of bilingual documents and reports their layout
+Optional lemmatization (--lemmas) counts reduplicated (看看, 干干净净), erhua (花儿) and aspect-marked (看了) forms under their dictionary form, listing the forms in ChineseWordForms.txt and JSON output; --erhua merge|keep|both sets whether 花儿 counts as 花, as itself or as both
+Optional unknown-word recognition (--hmm) regroups characters the dictionaries leave single into words; --strict-dict segments with the dictionaries alone
+Optional slang lexicon (--slang file or URL) refreshes internet slang without a new build; URLs are fetched each run and cached for offline use
+Optional POS tag mapping (--tag-map file) assigns jieba, Chinese Treebank or Penn tags to categories
+
+Noun and verb phrases are chunked by a grammar of patterns over POS tags (ChineseNounPhrases: <DT|NN|JJ>+); --phrase-grammar file replaces it without a rebuild
+
+Optional user dictionaries (--dict words.txt,...) merge custom words such as product names into segmentation; user entries override domain entries, which override built-in ones, and differing tags are listed in ChineseDictionaryConflicts.txt
+
+Optional domain dictionaries (--domains medical,legal,finance,it) improve segmentation and add domain-term categories
+
+Categories of your own (--category-files MedicalTerms=medical.txt, or a category-files mapping in the config file) report the words of a word list in their own files
+
+Optional output encoding (--encoding gb18030|big5) transcodes text and CSV outputs for legacy tools
+
 Counts frequency of occurrence for each linguistic element
 
+Optional sampling (--sample 5% or --sample 200) runs the full pipeline on part of a large corpus for quick parameter tuning
+
+Optional progress snapshots (--flush-every N) write partial results to progress.json during long runs; the dashboard streams them at /progress as server-sent events
+
+Optional frequency statistics (--stats per10k,range,dispersion,dp) add normalized rates and section coverage to category files; Parquet tables always carry them
+
+Optional dispersion-aware ranking (--rank dispersion) orders corpus vocabulary by frequency and Gries' DP, so words used everywhere outrank one-document spikes
+
+Optional jieba backend: building with -tags gojieba (after go get github.com/yanyiwu/gojieba) segments and tags with gojieba instead of prose
+
+Output is reproducible: equal frequencies are ordered by item, and stochastic steps draw from --seed
+
 Filters content to focus exclusively on Chinese characters
 
 Outputs results to separate category files sorted by frequency
@@ -20,12 +136,37 @@ Workflow:
 
 User selects input text file via GUI dialog
 
+Input format (plain text, HTML, SRT, CSV, JSONL, PDF, EPUB) is detected from the extension and content; --input-format overrides it
+
+A file given as argument is analyzed without the file dialog; --print nouns writes just that category to standard output, e.g. cwClassifier --print nouns book.txt | head
+Batch mode (--batch dir) analyzes every document of a directory as one corpus, optionally dropping near-duplicates (--dedupe)
+Input encodings are detected per file (UTF-8, UTF-16, GB18030/GBK, Big5), so one batch may mix them; documents not in UTF-8 are named when read
+Crawl mode (--crawl urls.txt or sitemap) fetches a list of pages politely (robots.txt, per-host delay, retries with backoff) and analyzes them as one corpus
+
 Program processes text using the prose NLP library
 
+Segmentation and categorization live in the classifier package (classifier.New with functional options) for embedding in other programs
+
+Chinese runs are segmented into words by a dictionary lattice, choosing the most probable path
+
 Chinese text is categorized into various linguistic categories
 
 Results are automatically written to the "cwClassifier_output" directory
 
+Optional file queue (--queue 2) analyzes files in parallel from a local page listing each file's progress, state and elapsed time, with a button opening its result folder
+
+Optional dry run (--dry-run) performs the full analysis but writes nothing, listing the files that would be created or overwritten with their lines and sizes, to validate settings on a big corpus first
+
+Options can be kept in a YAML or TOML config file (--config run.yaml) of settings by flag name, with flags on the command line overriding it, so batch jobs are reproducible
+
+Subcommands (cwClassifier help lists them) each take their own flags: classify, the default, analyzes a text; stats prints its size and category sizes without writing files; compare, serve, dashboard, resources, corpus, register and dict
+
+Named corpora kept by "cwClassifier corpus add|list|remove|stats" in a local store ($CWCLASSIFIER_CORPORA) are analyzed with --batch corpus:name, so repeated runs, comparisons and time series use the same documents
+
+Runs started from the file dialog remember the input folder, output folder, stages and formats of the last one in a per-user gui.json
+
+A file opened with the classifier ("cwClassifier register" adds it to the "Open with" menu of .txt files, or a single input file argument, with or without flags) is analyzed into a folder next to it, e.g. novel_cwClassifier_output
+
 Each category is saved to a separate text file sorted by frequency
 
 */
@@ -35,438 +176,2295 @@ package main
 import (
 	"bufio"
 
+	"flag"
+
 	"fmt"
 
+	"io"
+
 	"os"
 
 	"path/filepath"
 
-	"sort"
+	"slices"
+
+	"strconv"
 
 	"strings"
 
-	"unicode"
+	"time"
+
+	"unicode/utf8"
 
-	"github.com/jdkato/prose/v2"
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
 
 	"github.com/sqweek/dialog"
+
+	"golang.org/x/text/encoding"
 )
 
-// Checks if a given string contains only Chinese characters
+// Options controlling a single categorization run
 
-func isChineseText(text string) bool {
+type analysisOptions struct {
+	Domains []string // Optional domain dictionaries to enable, e.g. "medical", "it"
 
-	for _, r := range text {
+	Dictionaries []string // User dictionary files ("word [frequency] [tag]" per line) merged into segmentation
 
-		if !unicode.Is(unicode.Han, r) && r != ' ' && r != '-' { // Allow spaces and hyphens
+	CategoryFiles map[string]string // Categories of their own for the words of word list files, e.g. "MedicalTerms" → "medical.txt"
 
-			return false
+	HMM bool // Regroup unknown single characters into words with the HMM
 
-		}
+	StrictDictionary bool // Segment with the dictionaries alone, for reproducible experiments
 
-	}
+	Lemmas bool // Count words under their dictionary forms, reporting the forms they were written in
 
-	return true
+	Erhua string // Erhua words (花儿) count as the word without 儿 ("merge"), as themselves ("keep") or as both; empty leaves them as segmented, or merges them with Lemmas
 
-}
+	StopFunctionWords bool // Drop function words and particles from all categories but ChineseFunctionWords and ChineseCharacters
 
-// Extracts and returns individual Chinese characters from a string
+	Slang []classifier.SlangTerm // Slang lexicon replacing the embedded one
 
-func extractChineseCharacters(text string) []string {
+	Idioms []string // Extra idioms added to the embedded idiom dictionary
 
-	var characters []string
+	Variants bool // Count variant and legacy character forms (峯, 羣, compatibility ideographs) as their standard forms, reported in ChineseVariants.txt
 
-	for _, r := range text {
+	Blocklist []string // Junk tokens dropped from every category, with the occurrences dropped recorded
 
-		if unicode.Is(unicode.Han, r) {
+	TagCategories map[string]string // POS tag (jieba, CTB or Penn) → category, overriding the default mapping
 
-			characters = append(characters, string(r))
+	PhraseGrammar string // Chunking grammar of noun and verb phrases; empty keeps the embedded grammar
 
-		}
+	ScanMode string // How overlapping idiom and slang matches are counted, "greedy", "longest" or "all"; empty is greedy
 
-	}
+	Stages []string // Pipeline stages to run, e.g. "pos", "idioms"
 
-	return characters
+	MinConfidence float64 // Items categorized with lower confidence are dropped
 
-}
+	MinLengths map[string]int // Category → fewest characters an item needs, e.g. 2 for ChineseNouns
 
-// Capitalizes the first character of each word or phrase
+	Segmentation string // Built-in segmenter, e.g. "jieba" or "maxmatch"
 
-func capitalizePhrase(phrase string) string {
+	Segmenter classifier.Segmenter // External segmenter replacing the built-in ones; nil uses Segmentation
 
-	runes := []rune(phrase)
+	Chat bool // Treat the input as a "speaker: message" chat transcript and report per speaker
 
-	if len(runes) > 0 {
+	Speakers bool // Strip the speaker tags of subtitle lines and compare the vocabulary of each speaker
 
-		runes[0] = unicode.ToUpper(runes[0])
+	Novel bool // Split long fiction into chapters and report vocabulary growth and character names per chapter
 
-	}
+	Network string // Unit for the character co-occurrence network, "sentence" or "paragraph"; empty skips it
 
-	return string(runes)
+	Discover bool // Propose out-of-dictionary words in ChineseNewWords.txt
 
-}
+	DiscoverMinFrequency int // Occurrences a candidate needs
+
+	DiscoverInto string // User dictionary that confident candidates are added to without review
+
+	DiscoverMinScore float64 // Score a candidate needs to be added to DiscoverInto
+
+	Morphology bool // Report affix productivity (子, 儿, 化, 性, 者, 老, 小) in ChineseMorphology.txt
+
+	ExtractTerms bool // Score noun phrases as candidate terms and export them as CSV and TBX
+
+	ParallelCorpus string // Tab-separated "source<TAB>translation" file used for TMX export
+
+	TargetLanguage string // Language code of the translations, e.g. "en"
+
+	Formats map[string]bool // Enabled output formats, e.g. "txt", "json"
+
+	Translator translator // Optional MT backend used for glosses and missing TMX translations
+
+	Pinyin string // Pinyin style of category items, "marks", "numbers", "zhuyin" or "jyutping"; empty adds no pinyin
+
+	Cantonese bool // Analyze written Cantonese with its lexicon and slang, writing the Cantonese words found to ChineseCantonese.txt
+
+	Polyphones bool // Write the readings of polyphonic characters used in the text to ChinesePolyphones.txt
+
+	Tones bool // Write the tone distribution, word tone patterns and tone sandhi contexts to ChineseTonePatterns.txt
+
+	Strokes bool // Add stroke counts to ChineseCharacters and write the stroke count distribution to ChineseStrokeCounts.txt
+
+	Bands bool // Add frequency bands to ChineseCharacters and write their distribution to ChineseFrequencyBands.txt
+
+	Components bool // Add component decompositions to ChineseCharacters and write the characters sharing each component to ChineseComponents.txt
+
+	LearningOrder bool // Write the characters of the text in a suggested study order to ChineseLearningOrder.txt
+
+	SortCharacters string // Order of ChineseCharacters, "frequency" or "strokes"; empty orders by frequency
+
+	SplitRanks string // Split category txt output by rank into top-1k, 1k-5k and tail, "sections" of one file or "files"; empty writes one list
+
+	DualScript string // Script in which category items are also given, e.g. "traditional"; empty adds none
+
+	RubyThreshold int // Characters outside this many most frequent ones get pinyin in ruby/EPUB output
+
+	PDFFont string // CJK TrueType font embedded in the vocabulary PDF
+
+	PDFLayout string // Vocabulary PDF layout: "columns" or "cards"
+
+	FontCheck string // Font file to check for characters it cannot render
+
+	Encoding encoding.Encoding // Encoding of plain-text outputs; nil writes UTF-8
+
+	Dedupe bool // In batch and crawl mode, drop near-duplicate documents before aggregation
+
+	DedupeDistance int // Largest simhash Hamming distance at which documents count as near-duplicates
+
+	InputFormat string // Input format, or "auto" to detect it from the extension and content
+
+	Script string // Script the input is converted to before analysis, e.g. "simplified" for Taiwan texts; empty keeps it
+
+	OutputDir string // Directory results are written to; empty writes them to cwClassifier_output
 
-// Counts appearances of items and stores them in a frequency map
+	DryRun bool // Analyze fully but only list the files that would be written, with their lines and sizes
 
-func countFrequencies(content []string) map[string]int {
+	DryRunWrites *dryRunWrites // Set during a dry run: collects the files outside the output directory (dictionaries, caches) that are not written
 
-	counts := make(map[string]int)
+	FlushEvery int // Classify this many lines at a time, writing a progress.json snapshot after each chunk; 0 classifies in one pass
 
-	for _, item := range content {
+	Statistics map[string]bool // Normalized statistics added to category text files: "per10k", "range", "dispersion"
 
-		capitalizedItem := capitalizePhrase(item)
+	RankBy string // Item order: "frequency", or "dispersion" for frequency discounted by Gries' DP
 
-		counts[capitalizedItem]++
+	Sections []int // Line count of each batch document or crawled page, the sections for range and dispersion; nil uses paragraphs
+
+	Mixed bool // Split interleaved languages into runs and analyze only the Chinese ones
+
+	MinHanRatio float64 // Share of Han characters among letters below which a document counts as non-Chinese
+
+	Sample *sampleSpec // Optional sample of sentences to analyze instead of the whole input
+
+	Seed int64 // Seed for every stochastic step (sampling, clustering); same input, options and seed give identical output
+
+	Print string // Category whose items are written to PrintOutput, e.g. "nouns"; empty prints none
+
+	PrintOutput io.Writer // Standard output, kept for --print while messages go to standard error
+
+	Messages io.Writer // Progress messages and warnings; nil writes them to standard output
+
+}
+
+// Where progress messages and warnings go, so that --print keeps standard output to the printed items
+
+func (options analysisOptions) messages() io.Writer {
+
+	if options.Messages != nil {
+
+		return options.Messages
 
 	}
 
-	return counts
+	return os.Stdout
 
 }
 
-// Converts frequency map to sorted slice (only items, sorted by frequency)
+// Categorizes text into linguistic categories, focusing exclusively on Chinese content
+
+func categorizeChineseText(inputFile string, options analysisOptions) error {
+
+	// Convert HTML, subtitles, CSV, JSON Lines, PDF or EPUB input to lines of text
 
-func sortByFrequency(counts map[string]int) []string {
+	lines, _, inputEncoding, err := readInputLines(inputFile, options.InputFormat)
 
-	type itemFrequency struct {
-		Item string
+	if err != nil {
+
+		return err
 
-		Frequency int
 	}
 
-	var items []itemFrequency
+	if inputEncoding != "utf-8" {
+
+		fmt.Fprintf(options.messages(), "%s is encoded in %s; it was decoded before analysis\n", filepath.Base(inputFile), inputEncodingNames[inputEncoding])
+
+	}
 
-	for item, freq := range counts {
+	if skip, description := mostlyNonChinese(lines, options.MinHanRatio); skip {
 
-		items = append(items, itemFrequency{Item: item, Frequency: freq})
+		fmt.Fprintf(options.messages(), "Warning: %s is %s; only its Chinese text is analyzed\n", filepath.Base(inputFile), description)
 
 	}
 
-	sort.Slice(items, func(i, j int) bool {
+	return categorizeLines(inputFile, lines, options)
 
-		return items[i].Frequency > items[j].Frequency
+}
 
-	})
+// Fixed output directory, unless the file was opened with the classifier
 
-	var sortedItems []string
+func outputDirectory(options analysisOptions) string {
 
-	for _, entry := range items {
+	if options.OutputDir != "" {
 
-		sortedItems = append(sortedItems, entry.Item)
+		return options.OutputDir
 
 	}
 
-	return sortedItems
+	return "cwClassifier_output"
 
 }
 
-// Extracts noun phrases using Chinese POS rules
+// Categorizes lines already read from the input, which is a file or a batch directory
+
+func categorizeLines(inputFile string, lines []string, options analysisOptions) error {
+
+	if options.DryRun {
 
-func extractNounPhrases(tokens []prose.Token) []string {
+		return dryRun(inputFile, lines, options)
 
-	var nounPhrases []string
+	}
 
-	var currentPhrase []string
+	outputDir := outputDirectory(options)
 
-	for _, tok := range tokens {
+	// Create the output directory if it doesn't exist; a new one left empty, as with --print alone, is removed again
 
-		if isChineseText(tok.Text) {
+	if _, err := os.Stat(outputDir); os.IsNotExist(err) {
 
-			switch tok.Tag {
+		defer os.Remove(outputDir) // Removes only an empty directory
 
-			case "DT", "NN", "JJ": // Determiners, Nouns, Adjectives
+	}
 
-				currentPhrase = append(currentPhrase, tok.Text)
+	err := os.MkdirAll(outputDir, os.ModePerm)
 
-			default:
+	if err != nil {
 
-				if len(currentPhrase) > 0 {
+		return fmt.Errorf("failed to create output directory: %v", err)
 
-					nounPhrases = append(nounPhrases, strings.Join(currentPhrase, " "))
+	}
 
-					currentPhrase = nil
+	// Input is converted to the script asked for, such as Taiwan texts to the simplified script of the dictionaries
 
-				}
+	if options.Script != "" {
 
-			}
+		converter, err := newScriptConverter(options.Script)
+
+		if err != nil {
+
+			return fmt.Errorf("failed to load script conversion tables: %v", err)
+
+		}
+
+		for i, line := range lines {
+
+			lines[i] = converter.convert(line)
 
 		}
 
 	}
 
-	if len(currentPhrase) > 0 {
+	// Variant forms such as 峯 and 羣 are counted as the standard 峰 and 群
+
+	var variants map[variantMapping]int
 
-		nounPhrases = append(nounPhrases, strings.Join(currentPhrase, " "))
+	if options.Variants {
+
+		table, err := loadVariantTable()
+
+		if err != nil {
+
+			return fmt.Errorf("failed to load variant table: %v", err)
+
+		}
+
+		lines, variants = normalizeVariants(lines, table)
 
 	}
 
-	return nounPhrases
+	// Chat transcripts are analyzed without the speaker names
 
-}
+	var messages []chatMessage
 
-// Extracts verb phrases using Chinese POS rules
+	if options.Chat {
 
-func extractVerbPhrases(tokens []prose.Token) []string {
+		messages = parseChatLog(lines)
 
-	var verbPhrases []string
+		lines = chatMessageLines(messages)
 
-	var currentPhrase []string
+	}
 
-	for _, tok := range tokens {
+	// Subtitle lines are analyzed without their speaker tags
 
-		if isChineseText(tok.Text) {
+	var speakers []speakerLines
 
-			switch tok.Tag {
+	if options.Speakers {
 
-			case "VB", "RB", "MD": // Verbs, Adverbs, Modals
+		lines, speakers = splitSpeakers(lines)
 
-				currentPhrase = append(currentPhrase, tok.Text)
+	}
 
-			default:
+	// Mixed-language documents are analyzed without their non-Chinese runs
 
-				if len(currentPhrase) > 0 {
+	if options.Mixed {
 
-					verbPhrases = append(verbPhrases, strings.Join(currentPhrase, " "))
+		var runs []languageRun
 
-					currentPhrase = nil
+		runs, lines = splitLanguageRuns(lines)
 
-				}
+		if err := writeLanguageLayout(filepath.Join(outputDir, "ChineseLanguageLayout.txt"), runs, options.Encoding); err != nil {
 
-			}
+			return err
 
 		}
 
 	}
 
-	if len(currentPhrase) > 0 {
+	// Preview runs analyze a sample of the sentences only
 
-		verbPhrases = append(verbPhrases, strings.Join(currentPhrase, " "))
+	if options.Sample != nil {
+
+		total := len(lines)
+
+		lines = sampleLines(lines, options.Sample, options.Seed)
+
+		fmt.Fprintf(options.messages(), "Analyzing a sample of %d sentences from %d lines\n", len(lines), total)
 
 	}
 
-	return verbPhrases
+	// Lines are kept apart, so quotes are attributed within the narration of their own line
 
-}
+	var content string
 
-// Categorizes text into linguistic categories, focusing exclusively on Chinese content
+	for _, line := range lines {
 
-func categorizeChineseText(inputFile string) error {
+		content += line + "\n"
 
-	// Define fixed output directory
+	}
 
-	outputDir := "cwClassifier_output"
+	// Segment and categorize the text
 
-	// Create the output directory if it doesn't exist
+	classifierOptions := []classifier.Option{
 
-	err := os.MkdirAll(outputDir, os.ModePerm)
+		classifier.WithDomains(options.Domains...),
 
-	if err != nil {
+		classifier.WithDictionaryFiles(options.Dictionaries...),
 
-		return fmt.Errorf("failed to create output directory: %v", err)
+		classifier.WithCategoryFiles(options.CategoryFiles),
+
+		classifier.WithTagCategories(options.TagCategories),
+
+		classifier.WithStages(options.Stages...),
 
+		classifier.WithMinConfidence(options.MinConfidence),
+
+		classifier.WithMinLengths(options.MinLengths),
+
+		classifier.WithHMM(options.HMM),
 	}
 
-	file, err := os.Open(inputFile)
+	if options.StrictDictionary {
 
-	if err != nil {
+		classifierOptions = append(classifierOptions, classifier.WithStrictDictionary())
+
+	}
+
+	if options.Lemmas {
 
-		return fmt.Errorf("failed to open input file: %v", err)
+		classifierOptions = append(classifierOptions, classifier.WithLemmas())
 
 	}
 
-	defer file.Close()
+	if options.Erhua != "" {
 
-	scanner := bufio.NewScanner(file)
+		classifierOptions = append(classifierOptions, classifier.WithErhua(options.Erhua))
 
-	var content string
+	}
 
-	for scanner.Scan() {
+	if options.StopFunctionWords {
 
-		content += scanner.Text() + " "
+		classifierOptions = append(classifierOptions, classifier.WithFunctionWordStopwords())
 
 	}
 
-	if err := scanner.Err(); err != nil {
+	if options.Cantonese {
 
-		return fmt.Errorf("error reading input file: %v", err)
+		classifierOptions = append(classifierOptions, classifier.WithCantonese())
 
 	}
 
-	doc, err := prose.NewDocument(content)
+	if options.ScanMode != "" {
 
-	if err != nil {
+		classifierOptions = append(classifierOptions, classifier.WithScanMode(options.ScanMode))
+
+	}
 
-		return fmt.Errorf("error creating Prose document: %v", err)
+	if options.PhraseGrammar != "" {
+
+		classifierOptions = append(classifierOptions, classifier.WithPhraseGrammar(options.PhraseGrammar))
 
 	}
 
-	categoryFiles := map[string]string{
+	if options.Slang != nil {
+
+		classifierOptions = append(classifierOptions, classifier.WithSlangLexicon(options.Slang...))
+
+	}
 
-		"ChineseCharacters": "ChineseCharacters.txt",
+	if len(options.Blocklist) > 0 {
 
-		"ChineseAdjectives": "ChineseAdjectives.txt",
+		classifierOptions = append(classifierOptions, classifier.WithBlocklist(options.Blocklist...))
 
-		"ChineseAdverbs": "ChineseAdverbs.txt",
+	}
 
-		"ChineseCommonPhrases": "ChineseCommonPhrases.txt",
+	if len(options.Idioms) > 0 {
 
-		"ChineseIdioms": "ChineseIdioms.txt",
+		classifierOptions = append(classifierOptions, classifier.WithIdioms(append(classifier.DefaultIdioms(), options.Idioms...)...))
 
-		"ChineseNouns": "ChineseNouns.txt",
+	}
 
-		"ChineseNounPhrases": "ChineseNounPhrases.txt",
+	if options.Segmenter != nil {
 
-		"ChineseSlang": "ChineseSlang.txt",
+		classifierOptions = append(classifierOptions, classifier.WithSegmenter(options.Segmenter))
 
-		"ChineseVerbPhrases": "ChineseVerbPhrases.txt",
+	} else if options.Segmentation != "" {
 
-		"ChineseVerbs": "ChineseVerbs.txt",
+		classifierOptions = append(classifierOptions, classifier.WithSegmentation(options.Segmentation))
 
-		"ChineseOtherExpressions": "ChineseOtherExpressions.txt",
 	}
 
-	idioms := []string{"井底之蛙", "守株待兔", "画蛇添足", "纸上谈兵"}
+	c, err := classifier.New(classifierOptions...)
 
-	slang := []string{"吃土", "学霸", "宅男", "高富帅"}
+	if err != nil {
 
-	results := make(map[string][]string)
+		return err
 
-	// Extracting and categorizing tokens
+	}
 
-	for _, tok := range doc.Tokens() {
+	if conflicts := c.DictionaryConflicts(); len(conflicts) > 0 {
 
-		text := tok.Text
+		fmt.Fprintf(options.messages(), "%d words are tagged differently by different dictionaries; the user > domain > built-in definition is used (see ChineseDictionaryConflicts.txt)\n", len(conflicts))
 
-		if isChineseText(text) {
+		if err := writeDictionaryConflicts(filepath.Join(outputDir, "ChineseDictionaryConflicts.txt"), conflicts, options.Encoding); err != nil {
 
-			// Extract individual characters
+			return err
 
-			results["ChineseCharacters"] = append(results["ChineseCharacters"], extractChineseCharacters(text)...)
+		}
 
-			switch tok.Tag {
+	}
 
-			case "NN":
+	characters := utf8.RuneCountInString(content)
 
-				results["ChineseNouns"] = append(results["ChineseNouns"], text)
+	fmt.Fprintf(options.messages(), "Running %s (estimated %v for %d characters)\n", strings.Join(c.Stages(), ", "), classifier.EstimateDuration(c.Stages(), characters).Round(time.Millisecond), characters)
 
-			case "VB":
+	var result *classifier.Result
 
-				results["ChineseVerbs"] = append(results["ChineseVerbs"], text)
+	if options.FlushEvery > 0 {
 
-			case "JJ":
+		result, err = classifyIncrementally(c, lines, options.FlushEvery, outputDir, options.messages())
 
-				results["ChineseAdjectives"] = append(results["ChineseAdjectives"], text)
+	} else {
 
-			case "RB":
+		result, err = c.Classify(content)
 
-				results["ChineseAdverbs"] = append(results["ChineseAdverbs"], text)
+	}
 
-			default:
+	if err != nil {
 
-				results["ChineseOtherExpressions"] = append(results["ChineseOtherExpressions"], text)
+		return err
 
-			}
+	}
 
-			if matchesPhraseList(text, idioms) {
+	tokens := result.Tokens
 
-				results["ChineseIdioms"] = append(results["ChineseIdioms"], text)
+	results := result.Items
 
-			}
+	if len(result.Suppressed) > 0 {
 
-			if matchesPhraseList(text, slang) {
+		total := 0
 
-				results["ChineseSlang"] = append(results["ChineseSlang"], text)
+		for _, count := range result.Suppressed {
 
-			}
+			total += count
 
 		}
 
+		fmt.Fprintf(options.messages(), "The blocklist suppressed %d occurrences of %d items\n", total, len(result.Suppressed))
+
 	}
 
-	// Extract phrases
+	ranked := result.Ranked
 
-	results["ChineseNounPhrases"] = extractNounPhrases(doc.Tokens())
+	// The character table ranks characters into frequency bands
 
-	results["ChineseVerbPhrases"] = extractVerbPhrases(doc.Tokens())
+	var bands map[rune]characterInfo
 
-	// Output results
+	if options.Bands {
 
-	for category, filename := range categoryFiles {
+		bands, err = loadCharacterTable()
 
-		filePath := filepath.Join(outputDir, filename)
+		if err != nil {
 
-		file, err := os.Create(filePath)
+			return fmt.Errorf("failed to load character table: %v", err)
 
-		if err != nil {
+		}
+
+	}
+
+	// Characters can be listed by stroke count for learners, most frequent first within a count
+
+	if options.SortCharacters == "strokes" {
+
+		ranked["ChineseCharacters"] = sortByStrokes(ranked["ChineseCharacters"])
+
+	}
+
+	// Forms counted under their dictionary forms
+
+	if (options.Lemmas || options.Erhua != "") && options.Formats["txt"] {
+
+		if err := writeWordForms(filepath.Join(outputDir, "ChineseWordForms.txt"), c.Categories(), ranked, result.Forms, options.Encoding); err != nil {
+
+			return err
+
+		}
+
+	}
 
-			return fmt.Errorf("failed to create output file for %s: %v", category, err)
+	// Export candidate terms for termbase building
+
+	if options.ExtractTerms {
+
+		if err := writeTerms(outputDir, extractTerms(results["ChineseNounPhrases"]), options.Encoding); err != nil {
+
+			return err
 
 		}
 
-		defer file.Close()
+	}
+
+	// Propose words the dictionaries lack, for dictionary maintenance
+
+	if options.Discover {
 
-		writer := bufio.NewWriter(file)
+		candidates := c.DiscoverWords(content, options.DiscoverMinFrequency)
 
-		countedContent := countFrequencies(results[category])
+		if err := writeNewWords(filepath.Join(outputDir, "ChineseNewWords.txt"), candidates, options.Encoding); err != nil {
 
-		sortedContent := sortByFrequency(countedContent)
+			return err
+
+		}
 
-		for _, item := range sortedContent {
+		if err := writeDictionaryReview(filepath.Join(outputDir, "ChineseDictionaryReview.txt"), candidates, options.Encoding); err != nil {
 
-			writer.WriteString(item + "\n")
+			return err
 
 		}
 
-		writer.Flush()
+		if options.DiscoverInto != "" && options.DryRunWrites != nil {
+
+			options.DryRunWrites.add(options.DiscoverInto)
+
+		} else if options.DiscoverInto != "" {
+
+			if err := addDiscoveredWords(options.DiscoverInto, candidates, options.DiscoverMinScore, options.messages()); err != nil {
+
+				return err
+
+			}
+
+		}
 
 	}
 
-	return nil
+	// Report which stems the productive affixes attach to
 
-}
+	if options.Morphology {
 
-func matchesPhraseList(phrase string, list []string) bool {
+		if err := writeMorphologyReport(filepath.Join(outputDir, "ChineseMorphology.txt"), analyzeAffixes(tokens), options.Encoding); err != nil {
 
-	for _, item := range list {
+			return err
 
-		if strings.EqualFold(item, phrase) {
+		}
 
-			return true
+	}
+
+	// Compare the vocabulary of the characters of a subtitle or script
+
+	if options.Speakers {
+
+		if err := writeSpeakerReport(filepath.Join(outputDir, "ChineseSpeakers.txt"), c, speakers, options.Encoding); err != nil {
+
+			return err
 
 		}
 
 	}
 
-	return false
+	// Report vocabulary, slang and message statistics per chat speaker
 
-}
+	if options.Chat {
 
-func main() {
+		if err := writeChatReport(filepath.Join(outputDir, "ChineseChatReport.txt"), messages, c, options.Encoding); err != nil {
+
+			return err
 
-	fmt.Println("Select the input text file:")
+		}
+
+	}
 
-	inputFile, err := dialog.File().Title("Select Input File").Filter("Text Files (*.txt)", "txt").Load()
+	// Track vocabulary introduction and character names across the chapters of long fiction
 
-	if err != nil || inputFile == "" {
+	if options.Novel {
 
-		fmt.Println("No file selected or error occurred:", err)
+		if err := writeChapterReports(outputDir, splitChapters(lines), c, ranked["ChinesePersons"], options.Encoding); err != nil {
 
-		return
+			return err
+
+		}
 
 	}
 
-	// Perform categorization with fixed output directory
+	// Link character names that appear in the same sentence or paragraph
 
-	err = categorizeChineseText(inputFile)
+	if options.Network != "" {
 
-	if err != nil {
+		nodes, edges := buildCharacterNetwork(lines, options.Network, ranked["ChinesePersons"])
 
-		fmt.Println("Error during categorization:", err)
+		if err := writeCharacterNetwork(outputDir, nodes, edges, options.Encoding); err != nil {
 
-		return
+			return err
+
+		}
 
 	}
 
-	fmt.Println("Chinese content has been categorized and written to output files.")
+	// Export translated example sentences as a translation memory
+
+	if options.ParallelCorpus != "" || options.Translator != nil {
+
+		translations := make(map[string]string)
+
+		if options.ParallelCorpus != "" {
+
+			translations, err = loadParallelCorpus(options.ParallelCorpus)
+
+			if err != nil {
+
+				return err
+
+			}
+
+		}
+
+		sentences := classifier.SplitSentences(content)
+
+		if options.Translator != nil {
+
+			if err := translateMissingSentences(options.Translator, sentences, translations, options.TargetLanguage); err != nil {
+
+				return err
+
+			}
+
+		}
+
+		pairs := pairSentences(sentences, translations)
+
+		if err := writeTMX(filepath.Join(outputDir, "ChineseSentences.tmx"), pairs, options.TargetLanguage); err != nil {
+
+			return err
+
+		}
+
+	}
+
+	// Report characters the typesetting font lacks, before anything is printed with it
+
+	if options.FontCheck != "" {
+
+		faces, err := loadFontFaces(options.FontCheck)
+
+		if err != nil {
+
+			return err
+
+		}
+
+		missing := findMissingGlyphs(lines, faces)
+
+		if err := writeMissingGlyphs(filepath.Join(outputDir, "ChineseMissingGlyphs.txt"), missing, options.Encoding); err != nil {
+
+			return err
+
+		}
+
+		fmt.Fprintf(options.messages(), "%s cannot render %d distinct characters of the text (see ChineseMissingGlyphs.txt)\n", filepath.Base(options.FontCheck), len(missing))
+
+	}
+
+	alignedLines := alignTokens(lines, tokens)
+
+	// Normalized frequencies for the tabular outputs
+
+	var stats map[string]map[string]itemStats
+
+	if len(options.Statistics) > 0 || options.Formats["parquet"] || options.Formats["duckdb"] || options.Formats["wordlist"] || options.RankBy == "dispersion" {
+
+		stats = computeItemStats(alignedLines, options.Sections, c, ranked)
+
+	}
+
+	if options.RankBy == "dispersion" {
+
+		rankByDispersion(ranked, stats)
+
+	}
+
+	// Pinyin readings are learned from the words of the whole text
+
+	var pinyin *pinyinAnnotator
+
+	if options.Pinyin != "" {
+
+		pinyin, err = newPinyinAnnotator(options.Pinyin, tokens)
+
+		if err != nil {
+
+			return err
+
+		}
+
+	}
+
+	var dualScript *scriptConverter
+
+	if options.DualScript != "" {
+
+		dualScript, err = newScriptConverter(options.DualScript)
+
+		if err != nil {
+
+			return fmt.Errorf("failed to load script conversion tables: %v", err)
+
+		}
+
+	}
+
+	// Output results
+
+	if options.Formats["txt"] {
+
+		for _, category := range c.Categories() {
+
+			filePath := filepath.Join(outputDir, category+".txt")
+
+			// Split into files, the top items go to the first band's file and later bands open files of their own
+
+			if options.SplitRanks == "files" {
+
+				filePath = filepath.Join(outputDir, category+"."+rankBands[0].Name+".txt")
+
+			}
+
+			file, err := createOutputFile(filePath, options.Encoding)
+
+			if err != nil {
+
+				return fmt.Errorf("failed to create output file for %s: %v", category, err)
+
+			}
+
+			defer file.Close()
+
+			writer := bufio.NewWriter(file)
+
+			band := ""
+
+			for i, entry := range ranked[category] {
+
+				if options.SplitRanks != "" && rankBand(i) != band {
+
+					band = rankBand(i)
+
+					if options.SplitRanks == "sections" {
+
+						writer.WriteString(rankBandHeading(band) + "\n")
+
+					} else if i > 0 {
+
+						writer.Flush()
+
+						if file, err = createOutputFile(filepath.Join(outputDir, category+"."+band+".txt"), options.Encoding); err != nil {
+
+							return fmt.Errorf("failed to create output file for %s: %v", category, err)
+
+						}
+
+						defer file.Close()
+
+						writer = bufio.NewWriter(file)
+
+					}
+
+				}
+
+				line := entry.Item
+
+				if pinyin != nil {
+
+					line += "\t" + pinyin.annotate(category, entry.Item)
+
+				}
+
+				if dualScript != nil {
+
+					line += "\t" + dualScript.convert(entry.Item)
+
+				}
+
+				if options.Strokes && category == "ChineseCharacters" {
+
+					line += "\t" + strconv.Itoa(characterStrokes(entry.Item))
+
+				}
+
+				if options.Components && category == "ChineseCharacters" {
+
+					line += "\t" + characterDecomposition(entry.Item)
+
+				}
+
+				if bands != nil && category == "ChineseCharacters" {
+
+					line += "\t" + characterBand(bands, entry.Item)
+
+				}
+
+				// Numbers and dates are followed by their value in Arabic numerals
+
+				if category == "ChineseNumbersDates" {
+
+					line += "\t" + classifier.NormalizeNumber(entry.Item)
+
+				}
+
+				if len(options.Statistics) > 0 {
+
+					fmt.Fprintf(writer, "%s\t%d%s\n", line, entry.Frequency, statColumns(stats[category][entry.Item], options.Statistics))
+
+				} else {
+
+					writer.WriteString(line + "\n")
+
+				}
+
+			}
+
+			writer.Flush()
+
+		}
+
+		if options.Variants {
+
+			if err := writeVariantReport(filepath.Join(outputDir, "ChineseVariants.txt"), variants, options.Encoding); err != nil {
+
+				return err
+
+			}
+
+		}
+
+		if options.Blocklist != nil {
+
+			if err := writeSuppressedReport(filepath.Join(outputDir, "ChineseSuppressed.txt"), result.Suppressed, options.Encoding); err != nil {
+
+				return err
+
+			}
+
+		}
+
+		if err := writeFunctionWordReport(filepath.Join(outputDir, "ChineseFunctionWordReport.txt"), tokens, options.Encoding); err != nil {
+
+			return err
+
+		}
+
+		if slices.Contains(c.Stages(), "measure-words") {
+
+			if err := writeMeasureWordReport(filepath.Join(outputDir, "ChineseMeasureWordPairs.txt"), c, result, options.Encoding); err != nil {
+
+				return err
+
+			}
+
+		}
+
+		if options.Strokes && slices.Contains(c.Stages(), "characters") {
+
+			if err := writeStrokeReport(filepath.Join(outputDir, "ChineseStrokeCounts.txt"), ranked["ChineseCharacters"], options.Encoding); err != nil {
+
+				return err
+
+			}
+
+		}
+
+		// The pronunciation reports read the text with an annotator of their own when --pinyin is off
+
+		annotator := pinyin
+
+		if (annotator == nil || annotator.jyutping) && (options.Polyphones || options.Tones) {
+
+			if annotator, err = newPinyinAnnotator("", tokens); err != nil {
+
+				return err
+
+			}
+
+		}
+
+		if options.Cantonese {
+
+			if err := writeCantoneseReport(filepath.Join(outputDir, "ChineseCantonese.txt"), tokens, options.Encoding); err != nil {
+
+				return err
+
+			}
+
+		}
+
+		if options.Polyphones {
+
+			if err := writePolyphoneReport(filepath.Join(outputDir, "ChinesePolyphones.txt"), annotator.polyphoneReadings(tokens, c), options.Encoding); err != nil {
+
+				return err
+
+			}
+
+		}
+
+		if options.Tones {
+
+			if err := writeTonePatternReport(filepath.Join(outputDir, "ChineseTonePatterns.txt"), annotator.toneStatistics(tokens), options.Encoding); err != nil {
+
+				return err
+
+			}
+
+		}
+
+		if bands != nil && slices.Contains(c.Stages(), "characters") {
+
+			if err := writeBandReport(filepath.Join(outputDir, "ChineseFrequencyBands.txt"), ranked["ChineseCharacters"], bands, options.Encoding); err != nil {
+
+				return err
+
+			}
+
+		}
+
+		if options.Components && slices.Contains(c.Stages(), "characters") {
+
+			if err := writeComponentReport(filepath.Join(outputDir, "ChineseComponents.txt"), ranked["ChineseCharacters"], options.Encoding); err != nil {
+
+				return err
+
+			}
+
+		}
+
+		if options.LearningOrder && slices.Contains(c.Stages(), "characters") {
+
+			if err := writeLearningOrderReport(filepath.Join(outputDir, "ChineseLearningOrder.txt"), ranked["ChineseCharacters"], options.Encoding); err != nil {
+
+				return err
+
+			}
+
+		}
+
+		if slices.Contains(c.Stages(), "characters") {
+
+			if err := writeRadicalReport(filepath.Join(outputDir, "ChineseRadicals.txt"), ranked["ChineseCharacters"], options.Encoding); err != nil {
+
+				return err
+
+			}
+
+		}
+
+		if slices.Contains(c.Stages(), "numbers") {
+
+			if err := writeTimeline(filepath.Join(outputDir, "ChineseTimeline.txt"), result, options.Encoding); err != nil {
+
+				return err
+
+			}
+
+		}
+
+		if slices.Contains(c.Stages(), "politeness") {
+
+			if err := writePolitenessProfile(filepath.Join(outputDir, "ChinesePolitenessProfile.txt"), result, options.Encoding); err != nil {
+
+				return err
+
+			}
+
+		}
+
+		if slices.Contains(c.Stages(), "dialogue") {
+
+			if err := writeDialogueReport(filepath.Join(outputDir, "ChineseDialogueSpeakers.txt"), c, result, options.Encoding); err != nil {
+
+				return err
+
+			}
+
+		}
+
+	}
+
+	// One category can be piped into grep, head or sort
+
+	if options.Print != "" {
+
+		category, err := resolveCategory(options.Print, c.Categories())
+
+		if err != nil {
+
+			return err
+
+		}
+
+		printCategory(options.PrintOutput, ranked[category])
+
+	}
+
+	// Quick interactive runs read the top items off the console
+
+	if options.Formats["table"] {
+
+		printCategoryTables(options.messages(), c.Categories(), ranked)
+
+	}
+
+	if options.Formats["html"] {
+
+		highlightPath := filepath.Join(outputDir, "ChineseHighlighted.html")
+
+		if err := writeHighlightedHTML(highlightPath, filepath.Base(inputFile), alignedLines, c, ranked); err != nil {
+
+			return err
+
+		}
+
+	}
+
+	if options.Formats["annotated"] {
+
+		if err := writeAnnotatedCopy(filepath.Join(outputDir, "ChineseAnnotated.txt"), alignedLines, c, options.Encoding); err != nil {
+
+			return err
+
+		}
+
+	}
+
+	if options.Formats["ruby"] || options.Formats["epub"] {
+
+		characters, err := loadCharacterTable()
+
+		if err != nil {
+
+			return fmt.Errorf("failed to load character table: %v", err)
+
+		}
+
+		title := filepath.Base(inputFile)
+
+		paragraphs := rubyParagraphs(alignedLines, characters, options.RubyThreshold)
+
+		if options.Formats["ruby"] {
+
+			if err := writeRubyHTML(filepath.Join(outputDir, "ChineseRuby.html"), title, paragraphs); err != nil {
+
+				return err
+
+			}
+
+		}
+
+		if options.Formats["epub"] {
+
+			if err := writeRubyEPUB(filepath.Join(outputDir, "ChineseRuby.epub"), title, paragraphs); err != nil {
+
+				return err
+
+			}
+
+		}
+
+	}
+
+	if options.Formats["parquet"] || options.Formats["duckdb"] {
+
+		if err := writeParquetTables(outputDir, alignedLines, c, ranked, stats); err != nil {
+
+			return err
+
+		}
+
+	}
+
+	if options.Formats["wordlist"] {
+
+		if err := writeWordLists(outputDir, tokens, c.Categories(), ranked, result.Forms, stats, options.Encoding); err != nil {
+
+			return err
+
+		}
+
+	}
+
+	if options.Formats["duckdb"] {
+
+		if err := writeDuckDB(outputDir, inputFile, len(lines), options.messages()); err != nil {
+
+			return err
+
+		}
+
+	}
+
+	// Multi-character words are decomposed into morphemes for the JSON output
+
+	var decompositions map[string][]string
+
+	if options.Formats["json"] {
+
+		decompositions = decomposeWords(ranked, c)
+
+	}
+
+	var glosses map[string]string
+
+	if options.Formats["json"] || options.Formats["pdf"] {
+
+		glosses, err = glossItems(options.Translator, ranked, morphemeTexts(decompositions), options.TargetLanguage)
+
+		if err != nil {
+
+			return err
+
+		}
+
+	}
+
+	if options.Formats["json"] {
+
+		characters, err := loadCharacterTable()
+
+		if err != nil {
+
+			return fmt.Errorf("failed to load character table: %v", err)
+
+		}
+
+		morphemes := morphemeDetails(decompositions, characters, glosses)
+
+		examples := sampleExamples(ranked, classifier.SplitSentences(content), options.Seed)
+
+		if err := writeJSONResults(filepath.Join(outputDir, "results.json"), inputFile, options.Seed, ranked, c, glosses, morphemes, examples, pinyin, dualScript, result.Forms, result.Suppressed, bands, options.Strokes, options.Components); err != nil {
+
+			return err
+
+		}
+
+	}
+
+	// Map sentence IDs to the items found in them
+
+	if options.Formats["index"] {
+
+		index, err := buildSentenceIndex(c, classifier.SplitSentences(content))
+
+		if err != nil {
+
+			return err
+
+		}
+
+		if err := writeSentenceIndex(filepath.Join(outputDir, "ChineseSentenceIndex.json"), index); err != nil {
+
+			return err
+
+		}
+
+	}
+
+	if options.Formats["pdf"] {
+
+		characters, err := loadCharacterTable()
+
+		if err != nil {
+
+			return fmt.Errorf("failed to load character table: %v", err)
+
+		}
+
+		vocabulary := buildVocabulary(ranked, characters, glosses)
+
+		if err := writeVocabularyPDF(filepath.Join(outputDir, "ChineseVocabulary.pdf"), filepath.Base(inputFile), options.PDFFont, options.PDFLayout, vocabulary); err != nil {
+
+			return err
+
+		}
+
+	}
+
+	return nil
+
+}
+
+func matchesPhraseList(phrase string, list []string) bool {
+
+	for _, item := range list {
+
+		if strings.EqualFold(item, phrase) {
+
+			return true
+
+		}
+
+	}
+
+	return false
+
+}
+
+// Resolves --segmenter to a built-in segmenter name or, for "command", an external segmenter
+
+func parseSegmenter(name, command string) (string, classifier.Segmenter, error) {
+
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	if name != "command" {
+
+		if !matchesPhraseList(name, classifier.Segmenters()) {
+
+			return "", nil, fmt.Errorf("unknown segmenter %q (available: %s, command)", name, strings.Join(classifier.Segmenters(), ", "))
+
+		}
+
+		return name, nil, nil
+
+	}
+
+	if strings.TrimSpace(command) == "" {
+
+		return "", nil, fmt.Errorf("--segmenter-command is required for the command segmenter")
+
+	}
+
+	segmenter, err := classifier.NewCommandSegmenter(command)
+
+	return "", segmenter, err
+
+}
+
+// Reads a word list with one word per line (the first field); empty lines and # comments are skipped
+
+func loadWordList(path string) ([]string, error) {
+
+	if path == "" {
+
+		return nil, nil
+
+	}
+
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to read word list: %v", err)
+
+	}
+
+	var words []string
+
+	for _, line := range strings.Split(string(data), "\n") {
+
+		if fields := strings.Fields(line); len(fields) > 0 && !strings.HasPrefix(fields[0], "#") {
+
+			words = append(words, fields[0])
+
+		}
+
+	}
+
+	return words, nil
+
+}
+
+// Reads the --tag-map file; no file keeps the default mapping
+
+func loadTagMap(path string) (map[string]string, error) {
+
+	if path == "" {
+
+		return nil, nil
+
+	}
+
+	file, err := os.Open(path)
+
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to open tag map: %v", err)
+
+	}
+
+	defer file.Close()
+
+	mapping, err := classifier.ParseTagCategories(file)
+
+	if err != nil {
+
+		return nil, fmt.Errorf("invalid tag map %s: %v", path, err)
+
+	}
+
+	return mapping, nil
+
+}
+
+// Reads the --phrase-grammar file; no file keeps the embedded grammar
+
+func loadPhraseGrammar(path string) (string, error) {
+
+	if path == "" {
+
+		return "", nil
+
+	}
+
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+
+		return "", fmt.Errorf("failed to read phrase grammar: %v", err)
+
+	}
+
+	return string(data), nil
+
+}
+
+// Splits a comma-separated flag value, dropping empty entries
+
+func splitList(value string) []string {
+
+	var items []string
+
+	for _, item := range strings.Split(value, ",") {
+
+		if item = strings.TrimSpace(item); item != "" {
+
+			items = append(items, item)
+
+		}
+
+	}
+
+	return items
+
+}
+
+// Lists the stages with their estimated cost for the --stages help text
+
+func stageCosts() string {
+
+	var costs []string
+
+	for _, stage := range classifier.Stages() {
+
+		costs = append(costs, fmt.Sprintf("%s (%v)", stage.Name, stage.Cost))
+
+	}
+
+	return strings.Join(costs, ", ")
+
+}
+
+// Subcommand with its own flags, and the prefix of the errors it returns
+
+type subcommand struct {
+	Name string
+
+	Summary string
+
+	Run func(args []string) error
+
+	Failure string // Label of the error a failed run prints; classify errors carry their own
+}
+
+// Subcommands of the tool; classify, the analysis of one text, also runs when the first argument is none of them
+
+var subcommands = []subcommand{
+
+	{"classify", "Analyze a text into category files and reports (the default)", runClassifyCommand, ""},
+
+	{"stats", "Print the size, vocabulary and category sizes of a text without writing files", runStatsCommand, "Stats error"},
+
+	{"compare", "Compare the results of two runs", runCompareCommand, "Compare error"},
+
+	{"serve", "Serve the job API", runServeCommand, "Server error"},
+
+	{"dashboard", "Browse stored runs in the browser", runDashboardCommand, "Dashboard error"},
+
+	{"resources", "List or export the embedded language resources", runResourcesCommand, "Resources error"},
+
+	{"corpus", "Add, list, remove and measure named corpora for --batch corpus:name", runCorpusCommand, "Corpus error"},
+
+	{"register", "Add the tool to the \"Open with\" menu of .txt files", runRegisterCommand, "Register error"},
+
+	{"dict", "Add the words accepted in ChineseDictionaryReview.txt to a user dictionary", runDictCommand, "Dictionary error"},
+}
+
+func main() {
+
+	// Subcommands take over before the analysis flags are parsed
+
+	if len(os.Args) > 1 && (os.Args[1] == "help" || os.Args[1] == "commands") {
+
+		var rows [][]string
+
+		for _, command := range subcommands {
+
+			rows = append(rows, []string{command.Name, command.Summary})
+
+		}
+
+		fmt.Println("Usage: cwClassifier [command] [flags] [file]; cwClassifier command --help lists the flags of a command")
+
+		writeTable(os.Stdout, []string{"command", "description"}, rows, []bool{false, false})
+
+		return
+
+	}
+
+	if len(os.Args) > 1 {
+
+		for _, command := range subcommands {
+
+			if os.Args[1] == command.Name {
+
+				exitOnError(command.Failure, command.Run(os.Args[2:]))
+
+				return
+
+			}
+
+		}
+
+	}
+
+	exitOnError("", runClassifyCommand(os.Args[1:]))
+
+}
+
+// Prints a failed command's error to standard error, after the failure label when there is one, and exits with
+
+// status 1 so that scripts can tell
+
+func exitOnError(failure string, err error) {
+
+	if err == nil {
+
+		return
+
+	}
+
+	if failure != "" {
+
+		fmt.Fprintln(os.Stderr, failure+":", err)
+
+	} else {
+
+		fmt.Fprintln(os.Stderr, err)
+
+	}
+
+	os.Exit(1)
+
+}
+
+// Where a classify run takes its input: "queue", "batch", "crawl" or "files" given on the command line, in that
+
+// order of precedence, or "dialog" when none is, the only mode that opens the file dialog and its saved settings
+
+func classifyInputMode(queue int, batch, crawl string, files int) string {
+
+	switch {
+
+	case queue != 0:
+
+		return "queue"
+
+	case batch != "":
+
+		return "batch"
+
+	case crawl != "":
+
+		return "crawl"
+
+	case files > 0:
+
+		return "files"
+
+	default:
+
+		return "dialog"
+
+	}
+
+}
+
+// Handles "cwClassifier [classify] [flags] [file]", the analysis of one text, a batch directory, a crawl or a
+
+// file queue; without an input the file dialog picks one. Invalid options and failures are returned.
+
+func runClassifyCommand(args []string) error {
+
+	flags := flag.NewFlagSet("classify", flag.ExitOnError)
+
+	dryRunFlag := flags.Bool("dry-run", false, "Run the full analysis but write nothing, listing the files that would be created or overwritten with their lines (items of category files) and sizes")
+
+	configFlag := flags.String("config", "", "YAML or TOML file of option settings by flag name (format: txt,json or format = \"txt\"); flags given on the command line override it")
+
+	resourcesFlag := flags.String("resources", os.Getenv(resourceDirVariable), "Directory whose files replace the embedded language resources of the same name, e.g. idioms.txt or stopwords.txt (list them with cwClassifier resources; default $"+resourceDirVariable+")")
+
+	domainsFlag := flags.String("domains", "", "Comma-separated domain dictionaries to enable ("+strings.Join(classifier.AvailableDomains(), ", ")+")")
+
+	dictFlag := flags.String("dict", "", "Comma-separated user dictionary files, one \"word [frequency] [POS tag]\" per line, e.g. product names")
+
+	categoryFilesFlag := flags.String("category-files", "", "Comma-separated categories of your own with their word lists in the --dict format, e.g. MedicalTerms=medical.txt; each is written to its own file like the domain categories")
+
+	mixedFlag := flags.Bool("mixed", false, "Split documents that interleave Chinese and other languages into language runs, analyze only the Chinese runs and report the layout in ChineseLanguageLayout.txt")
+
+	minHanRatioFlag := flags.Float64("min-han-ratio", 0.2, "Share of Han characters among letters (0-1) below which a file counts as non-Chinese, as does Japanese text: skipped in batch mode, warned about otherwise (0 disables)")
+
+	hmmFlag := flags.Bool("hmm", false, "Recognize unknown words (such as names) the dictionaries lack with jieba's HMM model")
+
+	lemmasFlag := flags.Bool("lemmas", false, "Count reduplicated, erhua and aspect-marked forms (看看, 花儿, 看了) under their dictionary form, listing the forms in ChineseWordForms.txt")
+
+	strokesFlag := flags.Bool("strokes", false, "Add the stroke count of every character to ChineseCharacters and write their distribution to ChineseStrokeCounts.txt")
+
+	bandsFlag := flags.Bool("bands", false, "Tag every character of ChineseCharacters with its frequency band (top-500, top-1500, top-3000, rare) and write the band distribution to ChineseFrequencyBands.txt")
+
+	componentsFlag := flags.Bool("components", false, "Add the components of every character (想 ⿱相心) to ChineseCharacters and list the characters sharing each component in ChineseComponents.txt")
+
+	learningOrderFlag := flags.Bool("learning-order", false, "Write the characters of the text in a suggested study order to ChineseLearningOrder.txt: components before the characters built from them, the most frequent and most reused first")
+
+	sortCharactersFlag := flags.String("sort-characters", "frequency", "Order of ChineseCharacters ("+strings.Join(characterOrders, ", ")+"); strokes lists the simplest characters first")
+
+	splitRanksFlag := flags.String("split-ranks", "", "Split every category's txt output by rank into top-1k, 1k-5k and tail bands ("+strings.Join(rankBandSplits, ", ")+"); files writes Category.top-1k.txt, Category.1k-5k.txt and Category.tail.txt")
+
+	erhuaFlag := flags.String("erhua", "", "Count erhua words such as 花儿 as the word without 儿, as themselves or as both ("+strings.Join(classifier.ErhuaModes(), ", ")+"); empty keeps the segmentation, or merges with --lemmas")
+
+	stopFunctionWordsFlag := flags.Bool("stop-function-words", false, "Treat the stopword list (function words and particles such as 的, 了, 吗, 把, or an overriding stopwords.txt) as stopwords: report them in ChineseFunctionWords only")
+
+	strictDictFlag := flags.Bool("strict-dict", false, "Segment with the dictionaries alone (no HMM, external segmenter or tagger segmentation) for reproducible experiments")
+
+	slangFlag := flags.String("slang", "", "Slang lexicon file or http(s) URL replacing the embedded one, one term per line with optional tab-separated key=value metadata")
+
+	variantsFlag := flags.Bool("variants", false, "Count variant characters (异体字 such as 峯, 羣, 啓) and legacy code points (compatibility ideographs, Kangxi radicals) as their standard forms, reporting the mappings in ChineseVariants.txt")
+
+	blocklistFlag := flags.String("blocklist", "", "File of junk tokens (OCR debris, boilerplate) to drop from all outputs, one per line; the occurrences dropped are recorded in ChineseSuppressed.txt and results.json")
+
+	idiomsFlag := flags.String("idioms", "", "File of extra idioms, one per line, added to the embedded dictionary of about 22,000 chengyu")
+
+	scanFlag := flags.String("scan", "", "How overlapping idiom and slang matches are counted: "+strings.Join(classifier.ScanModes(), ", ")+" (greedy takes the longest entry at each position left to right, longest prefers longer entries anywhere, all counts every match); empty is greedy")
+
+	phraseGrammarFlag := flags.String("phrase-grammar", "", "File of chunking rules for noun and verb phrases, one \"Category: pattern\" per line with patterns over POS tags such as <JJ>*<NN>+")
+
+	tagMapFlag := flags.String("tag-map", "", "File mapping POS tags (jieba n/v/a/d..., CTB NN/VV/AD..., Penn) to categories, one \"tag category\" per line")
+
+	profileFlag := flags.String("profile", "full", "Stage preset ("+strings.Join(classifier.Profiles(), ", ")+"); fast skips the tagger model and phrase chunking")
+
+	stagesFlag := flags.String("stages", "", "Comma-separated pipeline stages to run instead of the profile; estimated cost per 10,000 characters: "+stageCosts())
+
+	speakersFlag := flags.Bool("speakers", false, "Read speaker tags leading subtitle or script lines ((老王), [老王], 【老王】, 老王：), analyze the lines without them and compare each speaker's vocabulary in ChineseSpeakers.txt")
+
+	chatFlag := flags.Bool("chat", false, "Treat the input as a chat transcript (speaker: message per line) and report per speaker in ChineseChatReport.txt")
+
+	novelFlag := flags.Bool("novel", false, "Detect chapter headings (第X章) and report per-chapter vocabulary growth and character names")
+
+	networkFlag := flags.String("network", "", "Export a character co-occurrence network (GraphML and Gephi CSV), linking person names (entities stage) in the same "+strings.Join(networkUnits, " or "))
+
+	segmenterFlag := flags.String("segmenter", "jieba", "Word segmenter ("+strings.Join(append(classifier.Segmenters(), "command"), ", ")+"); command runs --segmenter-command")
+
+	segmenterCommandFlag := flags.String("segmenter-command", "", "External segmenter (e.g. a pkuseg or gse script) reading one text per line on stdin and writing space-separated words per line")
+
+	minLengthFlag := flags.String("min-length", "", "Per-category minimum item length in characters, e.g. ChineseNouns=2,ChineseVerbs=2; other categories keep all items")
+
+	minConfidenceFlag := flags.Float64("min-confidence", 0, "Drop items categorized with lower confidence (0-1): lexicon matches score 1, dictionary tags 0.9, tagger guesses 0.6, fallbacks 0.3")
+
+	discoverMinFrequencyFlag := flags.Int("discover-min-frequency", newWordMinFrequency, "Occurrences a new-word candidate needs")
+
+	discoverIntoFlag := flags.String("discover-into", "", "User dictionary to add new words scoring at least --discover-min-score to, without review (implies --discover)")
+
+	discoverMinScoreFlag := flags.Float64("discover-min-score", newWordMinScore, "Score (cohesion × smaller boundary entropy) a new word needs to be added by --discover-into")
+
+	discoverFlag := flags.Bool("discover", false, "Propose out-of-dictionary words (mutual information and boundary entropy) in ChineseNewWords.txt")
+
+	termsFlag := flags.Bool("terms", false, "Extract candidate terms (C-value) and export them as ChineseTerms.csv and ChineseTerms.tbx")
+
+	morphologyFlag := flags.Bool("morphology", false, "Report productive affixes (子, 儿, 化, 性, 者, 老, 小) and their stems in ChineseMorphology.txt")
+
+	parallelFlag := flags.String("parallel", "", "Tab-separated parallel corpus (source<TAB>translation) for exporting ChineseSentences.tmx")
+
+	targetLangFlag := flags.String("target-lang", "en", "Language code for translations in TMX output and glosses")
+
+	printFlag := flags.String("print", "", "Write one category's items and frequencies (e.g. nouns, idioms, ChineseVerbs) to standard output, most frequent first, for shell pipelines; other messages go to standard error and no output files are written unless --format is given")
+
+	formatFlag := flags.String("format", "txt", "Comma-separated output formats ("+strings.Join(outputFormats, ", ")+")")
+
+	mtFlag := flags.String("mt", "", "Machine-translation backend for glosses and TMX (deepl, google, local)")
+
+	mtCommandFlag := flags.String("mt-command", "", "Command for the local MT backend; reads one text per line on stdin")
+
+	scriptFlag := flags.String("script", "", "Convert the input to this script before analysis ("+strings.Join(scriptTargets, ", ")+"); simplified suits Taiwan and Hong Kong texts")
+
+	dualScriptFlag := flags.String("dual-script", "", "Add every item of category files and JSON output in this script ("+strings.Join(scriptTargets, ", ")+"); empty adds none")
+
+	polyphonesFlag := flags.Bool("polyphones", false, "Write the polyphonic characters (多音字) of the text with each reading used in its words to ChinesePolyphones.txt")
+
+	cantoneseFlag := flags.Bool("cantonese", false, "Analyze written Cantonese (Hong Kong forums): adds a Cantonese lexicon (嘅, 唔, 咁, 靓) and slang, annotates items with Jyutping unless --pinyin is set, and lists the Cantonese words found in ChineseCantonese.txt")
+
+	tonesFlag := flags.Bool("tones", false, "Write tone statistics to ChineseTonePatterns.txt: tone distribution, word tone patterns (3-3, 2-4), adjacent tone pairs and tone sandhi contexts per sentence")
+
+	pinyinFlag := flags.String("pinyin", "", "Add pinyin to every item of category files and JSON output ("+strings.Join(pinyinStyles, ", ")+"; zhuyin writes bopomofo, jyutping Cantonese readings); empty adds none")
+
+	rubyThresholdFlag := flags.Int("ruby-threshold", 1000, "In ruby/EPUB output, add pinyin to characters outside this many most frequent ones (0 annotates all)")
+
+	pdfFontFlag := flags.String("pdf-font", "", "CJK TrueType font to embed in the vocabulary PDF (--format pdf)")
+
+	pdfLayoutFlag := flags.String("pdf-layout", "columns", "Vocabulary PDF layout ("+strings.Join(pdfLayouts, ", ")+")")
+
+	fontCheckFlag := flags.String("font-check", "", "Font file (TTF, OTF or TTC) to check; characters it cannot render go to ChineseMissingGlyphs.txt")
+
+	encodingFlag := flags.String("encoding", "utf-8", "Encoding of text and CSV outputs (utf-8, gb18030, big5)")
+
+	inputFormatFlag := flags.String("input-format", "auto", "Input format ("+strings.Join(inputFormats, ", ")+"); auto detects it from the extension and content")
+
+	batchFlag := flags.String("batch", "", "Analyze every supported document in this directory, or in a managed corpus:name, as one corpus instead of selecting a file")
+
+	queueFlag := flags.Int("queue", 0, "Analyze files from a queue page in the browser, this many at a time, each into a folder next to it; files given are queued and more are added there (0 disables)")
+
+	crawlFlag := flags.String("crawl", "", "Fetch the pages of a URL list file (one per line) or a sitemap (file or URL) and analyze them as one corpus")
+
+	crawlConcurrencyFlag := flags.Int("crawl-concurrency", 4, "Pages fetched at once when crawling; requests to one host still go one at a time")
+
+	crawlDelayFlag := flags.Duration("crawl-delay", time.Second, "Least time between two requests to the same host when crawling; a longer robots.txt Crawl-delay wins")
+
+	crawlRetriesFlag := flags.Int("crawl-retries", 3, "Retries of a page after network errors, 429 or 5xx responses, with exponential backoff")
+
+	dedupeFlag := flags.Bool("dedupe", false, "In batch mode, drop near-duplicate documents (simhash) before aggregation")
+
+	dedupeDistanceFlag := flags.Int("dedupe-distance", 3, "Largest simhash distance in bits (of 64) at which documents count as near-duplicates")
+
+	statsFlag := flags.String("stats", "", "Comma-separated statistics added to category text files after the count ("+strings.Join(frequencyStatistics, ", ")+"); sections are batch documents or paragraphs")
+
+	rankFlag := flags.String("rank", "frequency", "Order of category items ("+strings.Join(rankOrders, ", ")+"); dispersion ranks by frequency × (1 − Gries' DP) across batch documents")
+
+	flushEveryFlag := flags.Int("flush-every", 0, "Classify this many lines at a time and write partial results to progress.json after each chunk (0 disables)")
+
+	sampleFlag := flags.String("sample", "", "Analyze only a sample of the input for a quick preview: a percentage of sentences (5%) or the first N sentences (200)")
+
+	seedFlag := flags.Int64("seed", 1, "Seed for stochastic steps such as sampling; reuse it to reproduce a run exactly")
+
+	flags.Parse(args)
+
+	if *configFlag != "" {
+
+		if err := applyConfig(flags, *configFlag); err != nil {
+
+			return fmt.Errorf("invalid options: %v", err)
+
+		}
+
+	}
+
+	if err := useResourceDir(*resourcesFlag); err != nil {
+
+		return fmt.Errorf("invalid options: %v", err)
+
+	}
+
+	// Runs started from the file dialog restore the stages and formats of the last one unless given as flags
+
+	mode := classifyInputMode(*queueFlag, *batchFlag, *crawlFlag, flags.NArg())
+
+	gui := mode == "dialog"
+
+	var settings guiSettings
+
+	if gui {
+
+		settings = loadGUISettings()
+
+		set := make(map[string]bool)
+
+		flags.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+		// Saved values this version no longer accepts are left out
+
+		if _, err := classifier.ParseStages(strings.Join(settings.Stages, ",")); err == nil && len(settings.Stages) > 0 && !set["stages"] && !set["profile"] {
+
+			*stagesFlag = strings.Join(settings.Stages, ",")
+
+		}
+
+		if _, err := parseFormats(strings.Join(settings.Formats, ",")); err == nil && len(settings.Formats) > 0 && !set["format"] {
+
+			*formatFlag = strings.Join(settings.Formats, ",")
+
+		}
+
+	}
+
+	domains, err := classifier.ParseDomains(*domainsFlag)
+
+	if err != nil {
+
+		return fmt.Errorf("invalid options: %v", err)
+
+	}
+
+	stages, err := classifier.ProfileStages(*profileFlag)
+
+	if *stagesFlag != "" && err == nil {
+
+		stages, err = classifier.ParseStages(*stagesFlag)
+
+	}
+
+	if err != nil {
+
+		return fmt.Errorf("invalid options: %v", err)
+
+	}
+
+	if *networkFlag != "" && !matchesPhraseList(*networkFlag, networkUnits) {
+
+		return fmt.Errorf("invalid options: unknown network unit %q (available: %s)", *networkFlag, strings.Join(networkUnits, ", "))
+
+	}
+
+	// The network's nodes are the persons of the entities stage
+
+	if *networkFlag != "" && !slices.Contains(stages, "entities") {
+
+		stages = append(stages, "entities")
+
+	}
+
+	if *batchFlag != "" && *crawlFlag != "" {
+
+		return fmt.Errorf("invalid options: --batch and --crawl cannot be combined")
+
+	}
+
+	if *queueFlag < 0 || *queueFlag > 0 && (*batchFlag != "" || *crawlFlag != "") {
+
+		return fmt.Errorf("invalid options: --queue takes a positive number of files at a time and cannot be combined with --batch or --crawl")
+
+	}
+
+	if *crawlConcurrencyFlag < 1 || *crawlRetriesFlag < 0 || *crawlDelayFlag < 0 {
+
+		return fmt.Errorf("invalid options: crawl concurrency must be at least 1, and retries and delay not negative")
+
+	}
+
+	if *minConfidenceFlag < 0 || *minConfidenceFlag > 1 {
+
+		return fmt.Errorf("invalid options: minimum confidence must be between 0 and 1, got %g", *minConfidenceFlag)
+
+	}
+
+	categoryFiles, err := classifier.ParseCategoryFiles(*categoryFilesFlag)
+
+	if err != nil {
+
+		return fmt.Errorf("invalid options: %v", err)
+
+	}
+
+	minLengths, err := classifier.ParseMinLengths(*minLengthFlag)
+
+	if err != nil {
+
+		return fmt.Errorf("invalid options: %v", err)
+
+	}
+
+	if *minHanRatioFlag < 0 || *minHanRatioFlag > 1 {
+
+		return fmt.Errorf("invalid options: minimum Han ratio must be between 0 and 1, got %g", *minHanRatioFlag)
+
+	}
+
+	// Standard output carries only the printed category with --print; messages and warnings go to standard error
+
+	messages := io.Writer(os.Stdout)
+
+	if *printFlag != "" {
+
+		messages = os.Stderr
+
+	}
+
+	var pendingWrites *dryRunWrites
+
+	if *dryRunFlag {
+
+		pendingWrites = &dryRunWrites{}
+
+	}
+
+	slang, err := loadSlangLexicon(*slangFlag, pendingWrites, messages)
+
+	if err != nil {
+
+		return fmt.Errorf("invalid options: %v", err)
+
+	}
+
+	idioms, err := loadWordList(*idiomsFlag)
+
+	if err != nil {
+
+		return fmt.Errorf("invalid options: %v", err)
+
+	}
+
+	blocklist, err := loadWordList(*blocklistFlag)
+
+	if err != nil {
+
+		return fmt.Errorf("invalid options: %v", err)
+
+	}
+
+	tagCategories, err := loadTagMap(*tagMapFlag)
+
+	if err != nil {
+
+		return fmt.Errorf("invalid options: %v", err)
+
+	}
+
+	scanMode := strings.ToLower(strings.TrimSpace(*scanFlag))
+
+	if scanMode != "" && !slices.Contains(classifier.ScanModes(), scanMode) {
+
+		return fmt.Errorf("invalid options: unknown scan mode %q (available: %s)", scanMode, strings.Join(classifier.ScanModes(), ", "))
+
+	}
+
+	phraseGrammar, err := loadPhraseGrammar(*phraseGrammarFlag)
+
+	if err != nil {
+
+		return fmt.Errorf("invalid options: %v", err)
+
+	}
+
+	segmentation, segmenter, err := parseSegmenter(*segmenterFlag, *segmenterCommandFlag)
+
+	if err != nil {
+
+		return fmt.Errorf("invalid options: %v", err)
+
+	}
+
+	statistics, err := parseStatistics(*statsFlag)
+
+	if err != nil {
+
+		return fmt.Errorf("invalid options: %v", err)
+
+	}
+
+	if !matchesPhraseList(*rankFlag, rankOrders) {
+
+		return fmt.Errorf("invalid options: unknown rank order %q (available: %s)", *rankFlag, strings.Join(rankOrders, ", "))
+
+	}
+
+	sample, err := parseSample(*sampleFlag)
+
+	if err != nil {
+
+		return fmt.Errorf("invalid options: %v", err)
+
+	}
+
+	pinyinStyle, err := parsePinyinStyle(*pinyinFlag)
+
+	if err != nil {
+
+		return fmt.Errorf("invalid options: %v", err)
+
+	}
+
+	// Cantonese text is read in Jyutping unless another romanization is asked for
+
+	if *cantoneseFlag && pinyinStyle == "" {
+
+		pinyinStyle = "jyutping"
+
+	}
+
+	sortCharacters := strings.ToLower(strings.TrimSpace(*sortCharactersFlag))
+
+	if sortCharacters != "" && !slices.Contains(characterOrders, sortCharacters) {
+
+		return fmt.Errorf("invalid options: unknown character order %q (available: %s)", sortCharacters, strings.Join(characterOrders, ", "))
+
+	}
+
+	splitRanks := strings.ToLower(strings.TrimSpace(*splitRanksFlag))
+
+	if splitRanks != "" && !slices.Contains(rankBandSplits, splitRanks) {
+
+		return fmt.Errorf("invalid options: unknown rank split %q (available: %s)", splitRanks, strings.Join(rankBandSplits, ", "))
+
+	}
+
+	erhua := strings.ToLower(strings.TrimSpace(*erhuaFlag))
+
+	if erhua != "" && !slices.Contains(classifier.ErhuaModes(), erhua) {
+
+		return fmt.Errorf("invalid options: unknown erhua mode %q (available: %s)", erhua, strings.Join(classifier.ErhuaModes(), ", "))
+
+	}
+
+	script, err := parseScript(*scriptFlag)
+
+	if err != nil {
+
+		return fmt.Errorf("invalid options: %v", err)
+
+	}
+
+	dualScript, err := parseScript(*dualScriptFlag)
+
+	if err != nil {
+
+		return fmt.Errorf("invalid options: %v", err)
+
+	}
+
+	formats, err := parseFormats(*formatFlag)
+
+	if err != nil {
+
+		return fmt.Errorf("invalid options: %v", err)
+
+	}
+
+	// Files are written with --print only when asked for
+
+	if *printFlag != "" {
+
+		formatSet := false
+
+		flags.Visit(func(f *flag.Flag) { formatSet = formatSet || f.Name == "format" })
+
+		if !formatSet {
+
+			formats = map[string]bool{}
+
+		}
+
+	}
+
+	outputEncoding, err := parseEncoding(*encodingFlag)
+
+	if err != nil {
+
+		return fmt.Errorf("invalid options: %v", err)
+
+	}
+
+	inputFormat, err := parseInputFormat(*inputFormatFlag)
+
+	if err != nil {
+
+		return fmt.Errorf("invalid options: %v", err)
+
+	}
+
+	mt, err := newTranslator(*mtFlag, *mtCommandFlag)
+
+	if err != nil {
+
+		return fmt.Errorf("invalid options: %v", err)
+
+	}
+
+	options := analysisOptions{
+
+		Domains: domains,
+
+		Dictionaries: splitList(*dictFlag),
+
+		CategoryFiles: categoryFiles,
+
+		Slang: slang,
+
+		HMM: *hmmFlag,
+
+		StrictDictionary: *strictDictFlag,
+
+		Lemmas: *lemmasFlag,
+
+		Erhua: erhua,
+
+		Strokes: *strokesFlag,
+
+		Components: *componentsFlag,
+
+		LearningOrder: *learningOrderFlag,
+
+		Bands: *bandsFlag,
+
+		SortCharacters: sortCharacters,
+
+		SplitRanks: splitRanks,
+
+		StopFunctionWords: *stopFunctionWordsFlag,
+
+		Mixed: *mixedFlag,
+
+		MinHanRatio: *minHanRatioFlag,
+
+		Idioms: idioms,
+
+		Blocklist: blocklist,
+
+		Variants: *variantsFlag,
+
+		TagCategories: tagCategories,
+
+		PhraseGrammar: phraseGrammar,
+
+		ScanMode: scanMode,
+
+		Stages: stages,
+
+		MinConfidence: *minConfidenceFlag,
+
+		MinLengths: minLengths,
+
+		Segmentation: segmentation,
+
+		Segmenter: segmenter,
+
+		ExtractTerms: *termsFlag,
+
+		Chat: *chatFlag,
+
+		Speakers: *speakersFlag,
+
+		Novel: *novelFlag,
+
+		Network: *networkFlag,
+
+		Discover: *discoverFlag || *discoverIntoFlag != "",
+
+		DiscoverMinFrequency: *discoverMinFrequencyFlag,
+
+		DiscoverInto: *discoverIntoFlag,
+
+		DiscoverMinScore: *discoverMinScoreFlag,
+
+		Morphology: *morphologyFlag,
+
+		ParallelCorpus: *parallelFlag,
+
+		TargetLanguage: *targetLangFlag,
+
+		Formats: formats,
+
+		Translator: mt,
+
+		Pinyin: pinyinStyle,
+
+		Polyphones: *polyphonesFlag,
+
+		Cantonese: *cantoneseFlag,
+
+		Tones: *tonesFlag,
+
+		DualScript: dualScript,
+
+		RubyThreshold: *rubyThresholdFlag,
+
+		PDFFont: *pdfFontFlag,
+
+		PDFLayout: *pdfLayoutFlag,
+
+		FontCheck: *fontCheckFlag,
+
+		Encoding: outputEncoding,
+
+		Statistics: statistics,
+
+		RankBy: *rankFlag,
+
+		FlushEvery: *flushEveryFlag,
+
+		Sample: sample,
+
+		Seed: *seedFlag,
+
+		Print: *printFlag,
+
+		PrintOutput: os.Stdout,
+
+		Messages: messages,
+
+		Dedupe: *dedupeFlag,
+
+		DedupeDistance: *dedupeDistanceFlag,
+
+		InputFormat: inputFormat,
+
+		Script: script,
+
+		DryRun: *dryRunFlag,
+
+		DryRunWrites: pendingWrites,
+	}
+
+	switch mode {
+
+	case "queue":
+
+		err = runFileQueue(flags.Args(), options, *queueFlag)
+
+	case "batch":
+
+		var dir string
+
+		if dir, err = resolveCorpusPath(*batchFlag); err == nil {
+
+			err = categorizeBatch(dir, options)
+
+		}
+
+	case "crawl":
+
+		err = categorizeCrawl(*crawlFlag, crawlOptions{Concurrency: *crawlConcurrencyFlag, Delay: *crawlDelayFlag, Retries: *crawlRetriesFlag}, options)
+
+	case "files":
+
+		// A lone file path is how "Open with" menus start the classifier, so results go next to the file, also
+
+		// when flags come with it
+
+		if flags.NArg() == 1 {
+
+			options.OutputDir = openWithOutputDir(flags.Arg(0))
+
+		}
+
+		err = categorizeChineseText(flags.Arg(0), options)
+
+	default:
+
+		fmt.Fprintln(messages, "Select the input text file:")
+
+		var inputFile string
+
+		inputFile, err = dialog.File().Title("Select Input File").SetStartDir(settings.InputDir).Filter("Text Files (*.txt)", "txt").Filter("Documents (*.html, *.srt, *.csv, *.jsonl, *.pdf, *.epub)", "html", "htm", "srt", "csv", "jsonl", "pdf", "epub").Filter("All Files", "*").Load()
+
+		if err != nil || inputFile == "" {
+
+			return fmt.Errorf("no file selected: %v", err)
+
+		}
+
+		// Results go to cwClassifier_output in the folder picked, or in the last one when the dialog is cancelled
+
+		fmt.Fprintln(messages, "Select the output folder:")
+
+		outputParent, browseErr := dialog.Directory().Title("Select Output Folder").SetStartDir(settings.OutputDir).Browse()
+
+		if browseErr != nil || outputParent == "" {
+
+			outputParent = settings.OutputDir
+
+		}
+
+		if outputParent != "" {
+
+			options.OutputDir = filepath.Join(outputParent, "cwClassifier_output")
+
+		}
+
+		// A dry run leaves the remembered folders, stages and formats as they were
+
+		if options.DryRunWrites != nil {
+
+			if path, err := guiSettingsPath(); err == nil {
+
+				options.DryRunWrites.add(path)
+
+			}
+
+		}
+
+		err = categorizeChineseText(inputFile, options)
+
+		if err == nil && !options.DryRun {
+
+			settings = guiSettings{InputDir: filepath.Dir(inputFile), OutputDir: outputParent, Stages: stages, Formats: enabledFormats(formats)}
+
+			if err := saveGUISettings(settings); err != nil {
+
+				fmt.Fprintln(messages, "Warning: settings were not saved:", err)
+
+			}
+
+		}
+
+	}
+
+	if err != nil {
+
+		return fmt.Errorf("error during categorization: %v", err)
+
+	}
+
+	if options.DryRun {
+
+		fmt.Fprintln(messages, "Dry run finished; no files were written.")
+
+		return nil
+
+	}
+
+	// The output directory is gone when nothing was written to it
+
+	if _, err := os.Stat(outputDirectory(options)); os.IsNotExist(err) {
+
+		return nil
+
+	}
+
+	fmt.Fprintln(messages, "Chinese content has been categorized and written to output files.")
+
+	if options.OutputDir != "" {
+
+		fmt.Fprintln(messages, "Results are in", options.OutputDir)
+
+	}
+
+	return nil
 
 }
@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+// Only runs without any command-line input open the file dialog and restore its saved stages and formats
+
+func TestClassifyInputMode(t *testing.T) {
+
+	tests := []struct {
+		queue int
+
+		batch string
+
+		crawl string
+
+		files int
+
+		want string
+	}{
+		{0, "", "", 0, "dialog"},
+
+		{0, "", "", 1, "files"},
+
+		{0, "", "", 3, "files"},
+
+		{2, "", "", 0, "queue"},
+
+		{2, "", "", 4, "queue"},
+
+		{0, "corpus:news", "", 0, "batch"},
+
+		{0, "", "urls.txt", 0, "crawl"},
+
+		{0, "docs", "", 2, "batch"},
+	}
+
+	for _, test := range tests {
+
+		if got := classifyInputMode(test.queue, test.batch, test.crawl, test.files); got != test.want {
+
+			t.Errorf("classifyInputMode(%d, %q, %q, %d) = %q, want %q", test.queue, test.batch, test.crawl, test.files, got, test.want)
+
+		}
+
+	}
+
+}
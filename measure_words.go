@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bufio"
+
+	"fmt"
+
+	"strconv"
+
+	"strings"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+
+	"golang.org/x/text/encoding"
+)
+
+// Nouns listed per measure word in the pairing report
+
+const measureWordTopNouns = 5
+
+// Writes ChineseMeasureWordPairs.txt: each measure word with its count and the nouns it most often measures
+
+func writeMeasureWordReport(path string, c *classifier.Classifier, result *classifier.Result, enc encoding.Encoding) error {
+
+	pairs := c.MeasureWordPairs(result)
+
+	file, err := createOutputFile(path, enc)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to create measure word report: %v", err)
+
+	}
+
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+
+	var rows [][]string
+
+	for _, entry := range result.Ranked["ChineseMeasureWords"] {
+
+		var nouns []string
+
+		for i, noun := range pairs[entry.Item] {
+
+			if i == measureWordTopNouns {
+
+				break
+
+			}
+
+			nouns = append(nouns, fmt.Sprintf("%s(%d)", noun.Item, noun.Frequency))
+
+		}
+
+		rows = append(rows, []string{entry.Item, strconv.Itoa(entry.Frequency), strings.Join(nouns, " ")})
+
+	}
+
+	writeTable(writer, []string{"measure word", "count", "nouns"}, rows, []bool{false, true, false})
+
+	return writer.Flush()
+
+}
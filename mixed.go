@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bufio"
+
+	"fmt"
+
+	"strings"
+
+	"unicode"
+
+	"unicode/utf8"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+
+	"golang.org/x/text/encoding"
+)
+
+// Display width of the excerpt of a language run quoted in the layout report
+
+const layoutExcerptWidth = 40
+
+// Consecutive sentences of one language in a mixed document, with the lines they span (1-based)
+
+type languageRun struct {
+	Language string
+
+	FirstLine int
+
+	LastLine int
+
+	Text string
+}
+
+// Splits mixed-language text into sentences on Chinese and Western sentence-final punctuation;
+
+// a Western full stop ends a sentence only before a space, so numbers and file names stay whole
+
+func splitMixedSentences(line string) []string {
+
+	var sentences []string
+
+	var current strings.Builder
+
+	runes := []rune(line)
+
+	for i, r := range runes {
+
+		current.WriteRune(r)
+
+		end := strings.ContainsRune(classifier.SentenceTerminators, r) || r == '.' && (i+1 == len(runes) || unicode.IsSpace(runes[i+1]))
+
+		if end && (i+1 == len(runes) || !strings.ContainsRune(classifier.SentenceTerminators+".", runes[i+1])) {
+
+			if sentence := strings.TrimSpace(current.String()); sentence != "" {
+
+				sentences = append(sentences, sentence)
+
+			}
+
+			current.Reset()
+
+		}
+
+	}
+
+	if sentence := strings.TrimSpace(current.String()); sentence != "" {
+
+		sentences = append(sentences, sentence)
+
+	}
+
+	return sentences
+
+}
+
+// Segments lines into runs of consecutive sentences in the same language. Returns the runs and the
+
+// lines with only their Chinese sentences kept, one per input line so line-based sections stay aligned.
+
+func splitLanguageRuns(lines []string) ([]languageRun, []string) {
+
+	var runs []languageRun
+
+	chinese := make([]string, len(lines))
+
+	for i, line := range lines {
+
+		var kept []string
+
+		for _, sentence := range splitMixedSentences(line) {
+
+			language := detectLanguage([]string{sentence}).Language
+
+			if language == "unknown" {
+
+				// Numbers and punctuation belong to the run around them
+
+				if len(runs) == 0 {
+
+					continue
+
+				}
+
+				language = runs[len(runs)-1].Language
+
+			}
+
+			if language == "Chinese" {
+
+				kept = append(kept, sentence)
+
+			}
+
+			if len(runs) > 0 && runs[len(runs)-1].Language == language {
+
+				run := &runs[len(runs)-1]
+
+				run.LastLine = i + 1
+
+				run.Text += " " + sentence
+
+				continue
+
+			}
+
+			runs = append(runs, languageRun{Language: language, FirstLine: i + 1, LastLine: i + 1, Text: sentence})
+
+		}
+
+		chinese[i] = strings.Join(kept, " ")
+
+	}
+
+	return runs, chinese
+
+}
+
+// Writes ChineseLanguageLayout.txt: the language runs of a mixed document in order, with their line
+
+// ranges and sizes; only the Chinese runs are analyzed
+
+func writeLanguageLayout(path string, runs []languageRun, enc encoding.Encoding) error {
+
+	file, err := createOutputFile(path, enc)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to create language layout: %v", err)
+
+	}
+
+	defer file.Close()
+
+	chinese, total := 0, 0
+
+	for _, run := range runs {
+
+		total += utf8.RuneCountInString(run.Text)
+
+		if run.Language == "Chinese" {
+
+			chinese += utf8.RuneCountInString(run.Text)
+
+		}
+
+	}
+
+	writer := bufio.NewWriter(file)
+
+	fmt.Fprintf(writer, "# %d language runs; analyzed %d characters of Chinese, skipped %d of other languages\n",
+
+		len(runs), chinese, total-chinese)
+
+	var rows [][]string
+
+	for _, run := range runs {
+
+		span := fmt.Sprintf("lines %d-%d", run.FirstLine, run.LastLine)
+
+		if run.FirstLine == run.LastLine {
+
+			span = fmt.Sprintf("line %d", run.FirstLine)
+
+		}
+
+		rows = append(rows, []string{span, run.Language, fmt.Sprintf("%d characters", utf8.RuneCountInString(run.Text)), classifier.TruncateWidth(run.Text, layoutExcerptWidth)})
+
+	}
+
+	writeTable(writer, nil, rows, []bool{false, false, true, false})
+
+	return writer.Flush()
+
+}
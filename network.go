@@ -0,0 +1,295 @@
+package main
+
+import (
+	"encoding/csv"
+
+	"encoding/xml"
+
+	"fmt"
+
+	"os"
+
+	"path/filepath"
+
+	"sort"
+
+	"strconv"
+
+	"golang.org/x/text/encoding"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+)
+
+// Text units within which two character names count as co-occurring
+
+var networkUnits = []string{"sentence", "paragraph"}
+
+// Character in the co-occurrence network, weighted by its number of mentions
+
+type networkNode struct {
+	Name string
+
+	Mentions int
+}
+
+// Undirected edge between two characters, weighted by the number of units they share
+
+type networkEdge struct {
+	Source string
+
+	Target string
+
+	Weight int
+}
+
+// Builds the character co-occurrence network from the person names the entities stage recognized. Paragraphs
+
+// are input lines; sentences are split at sentence-final punctuation within them. A name within a longer
+
+// one (张三 in 张三丰) is counted as the longer name only.
+
+func buildCharacterNetwork(lines []string, unit string, persons []classifier.ItemFrequency) ([]networkNode, []networkEdge) {
+
+	var units []string
+
+	for _, line := range lines {
+
+		if unit == "sentence" {
+
+			units = append(units, classifier.SplitSentences(line)...)
+
+		} else if containsChinese(line) {
+
+			units = append(units, line)
+
+		}
+
+	}
+
+	names := make([]string, 0, len(persons))
+
+	for _, person := range persons {
+
+		names = append(names, person.Item)
+
+	}
+
+	sort.Strings(names)
+
+	var nodes []networkNode
+
+	mentions := make(map[string]int)
+
+	weights := make(map[[2]string]int)
+
+	for _, text := range units {
+
+		var present []string
+
+		counts := countNames(text, names)
+
+		for _, name := range names {
+
+			if count := counts[name]; count > 0 {
+
+				mentions[name] += count
+
+				present = append(present, name)
+
+			}
+
+		}
+
+		for i := 0; i < len(present); i++ {
+
+			for j := i + 1; j < len(present); j++ {
+
+				weights[[2]string{present[i], present[j]}]++
+
+			}
+
+		}
+
+	}
+
+	for _, name := range names {
+
+		nodes = append(nodes, networkNode{Name: name, Mentions: mentions[name]})
+
+	}
+
+	var edges []networkEdge
+
+	for pair, weight := range weights {
+
+		edges = append(edges, networkEdge{Source: pair[0], Target: pair[1], Weight: weight})
+
+	}
+
+	sort.Slice(edges, func(i, j int) bool {
+
+		if edges[i].Weight != edges[j].Weight {
+
+			return edges[i].Weight > edges[j].Weight
+
+		}
+
+		if edges[i].Source != edges[j].Source {
+
+			return edges[i].Source < edges[j].Source
+
+		}
+
+		return edges[i].Target < edges[j].Target
+
+	})
+
+	return nodes, edges
+
+}
+
+// GraphML document with one weight attribute on nodes and edges
+
+type graphML struct {
+	XMLName xml.Name `xml:"graphml"`
+
+	Xmlns string `xml:"xmlns,attr"`
+
+	Keys []graphMLKey `xml:"key"`
+
+	Graph graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID string `xml:"id,attr"`
+
+	For string `xml:"for,attr"`
+
+	Name string `xml:"attr.name,attr"`
+
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	EdgeDefault string `xml:"edgedefault,attr"`
+
+	Nodes []graphMLNode `xml:"node"`
+
+	Edges []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID string `xml:"id,attr"`
+
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string `xml:"source,attr"`
+
+	Target string `xml:"target,attr"`
+
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key string `xml:"key,attr"`
+
+	Value string `xml:",chardata"`
+}
+
+// Writes the network as ChineseCharacterNetwork.graphml and as Gephi node and edge tables
+
+// (ChineseCharacterNodes.csv, ChineseCharacterEdges.csv)
+
+func writeCharacterNetwork(outputDir string, nodes []networkNode, edges []networkEdge, enc encoding.Encoding) error {
+
+	doc := graphML{
+
+		Xmlns: "http://graphml.graphdrawing.org/xmlns",
+
+		Keys: []graphMLKey{
+
+			{ID: "label", For: "node", Name: "label", Type: "string"},
+
+			{ID: "mentions", For: "node", Name: "mentions", Type: "int"},
+
+			{ID: "weight", For: "edge", Name: "weight", Type: "int"},
+		},
+
+		Graph: graphMLGraph{EdgeDefault: "undirected"},
+	}
+
+	for _, node := range nodes {
+
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{ID: node.Name, Data: []graphMLData{{Key: "label", Value: node.Name}, {Key: "mentions", Value: strconv.Itoa(node.Mentions)}}})
+
+	}
+
+	for _, edge := range edges {
+
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{Source: edge.Source, Target: edge.Target, Data: []graphMLData{{Key: "weight", Value: strconv.Itoa(edge.Weight)}}})
+
+	}
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+
+	if err != nil {
+
+		return fmt.Errorf("failed to encode GraphML: %v", err)
+
+	}
+
+	if err := os.WriteFile(filepath.Join(outputDir, "ChineseCharacterNetwork.graphml"), append([]byte(xml.Header), append(data, '\n')...), 0644); err != nil {
+
+		return fmt.Errorf("failed to write GraphML: %v", err)
+
+	}
+
+	nodeRows := [][]string{{"Id", "Label", "Weight"}}
+
+	for _, node := range nodes {
+
+		nodeRows = append(nodeRows, []string{node.Name, node.Name, strconv.Itoa(node.Mentions)})
+
+	}
+
+	if err := writeCSVRows(filepath.Join(outputDir, "ChineseCharacterNodes.csv"), nodeRows, enc); err != nil {
+
+		return err
+
+	}
+
+	edgeRows := [][]string{{"Source", "Target", "Type", "Weight"}}
+
+	for _, edge := range edges {
+
+		edgeRows = append(edgeRows, []string{edge.Source, edge.Target, "Undirected", strconv.Itoa(edge.Weight)})
+
+	}
+
+	return writeCSVRows(filepath.Join(outputDir, "ChineseCharacterEdges.csv"), edgeRows, enc)
+
+}
+
+// Writes rows to a CSV file in the output encoding
+
+func writeCSVRows(path string, rows [][]string, enc encoding.Encoding) error {
+
+	file, err := createOutputFile(path, enc)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to create %s: %v", filepath.Base(path), err)
+
+	}
+
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+
+	writer.WriteAll(rows)
+
+	return writer.Error()
+
+}
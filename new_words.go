@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bufio"
+
+	"fmt"
+
+	"io"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+
+	"golang.org/x/text/encoding"
+)
+
+// Occurrences a new-word candidate needs before it is proposed
+
+const newWordMinFrequency = 3
+
+// Default score a candidate needs to be added to a user dictionary without review
+
+const newWordMinScore = 3.0
+
+// Writes ChineseNewWords.txt: proposed out-of-dictionary words with their frequency, cohesion,
+
+// boundary entropies and score, best first
+
+func writeNewWords(path string, candidates []classifier.WordCandidate, enc encoding.Encoding) error {
+
+	file, err := createOutputFile(path, enc)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to create new word list: %v", err)
+
+	}
+
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+
+	writer.WriteString("word\tfrequency\tcohesion\tleft_entropy\tright_entropy\tscore\n")
+
+	for _, candidate := range candidates {
+
+		fmt.Fprintf(writer, "%s\t%d\t%.3f\t%.3f\t%.3f\t%.3f\n", candidate.Word, candidate.Frequency, candidate.Cohesion, candidate.LeftEntropy, candidate.RightEntropy, candidate.Score)
+
+	}
+
+	return writer.Flush()
+
+}
+
+// Adds the candidates scoring at least minScore to a user dictionary, skipping words it already has
+
+func addDiscoveredWords(path string, candidates []classifier.WordCandidate, minScore float64, messages io.Writer) error {
+
+	var entries []reviewEntry
+
+	for _, candidate := range candidates {
+
+		if candidate.Score >= minScore {
+
+			entries = append(entries, reviewEntry{Accepted: true, Word: candidate.Word, Tag: reviewDefaultTag})
+
+		}
+
+	}
+
+	added, present, err := applyDictionaryReview(path, entries)
+
+	if err != nil {
+
+		return err
+
+	}
+
+	fmt.Fprintf(messages, "Added %d new words to %s (%d already present)\n", added, path, present)
+
+	return nil
+
+}
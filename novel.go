@@ -0,0 +1,379 @@
+package main
+
+import (
+	"bufio"
+
+	"fmt"
+
+	"path/filepath"
+
+	"regexp"
+
+	"slices"
+
+	"sort"
+
+	"strings"
+
+	"unicode"
+
+	"unicode/utf8"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+
+	"golang.org/x/text/encoding"
+)
+
+// Chapter heading such as "第十二章 重逢", "第3回" or "第一卷"
+
+var chapterHeadingPattern = regexp.MustCompile(`^\s*第[0-9０-９零〇一二三四五六七八九十百千两]+[章回节卷]`)
+
+// Occurrences a name candidate needs across the whole text to be tracked
+
+const minNameOccurrences = 3
+
+// One chapter of a long text
+
+type chapter struct {
+	Title string
+
+	Text string
+}
+
+// Splits lines into chapters at chapter headings; text before the first heading becomes a prologue
+
+func splitChapters(lines []string) []chapter {
+
+	chapters := []chapter{{Title: "序"}}
+
+	for _, line := range lines {
+
+		if chapterHeadingPattern.MatchString(line) {
+
+			chapters = append(chapters, chapter{Title: strings.TrimSpace(line)})
+
+			continue
+
+		}
+
+		chapters[len(chapters)-1].Text += line + "\n"
+
+	}
+
+	if !containsChinese(chapters[0].Text) {
+
+		chapters = chapters[1:]
+
+	}
+
+	return chapters
+
+}
+
+// Dictionary tags of characters that end a phrase rather than a name: verbs, adverbs, prepositions,
+
+// conjunctions, particles and pronouns, as in 王五说, 张三不 and 李四在
+
+var nonNameEndingTags = []string{"v", "d", "p", "c", "u", "r", "y", "e", "f"}
+
+// Finds character names: the persons the entities stage recognized, and surname-initial runs of two or three
+
+// characters that recur in the text. Runs ending in a verb or function word (王五说) or forming a dictionary
+
+// word other than a name (高铁) are dropped, as are three-character runs ending in a numeral (王五一). A
+
+// three-character run replaces its two-character prefix when the prefix rarely occurs without it, and is
+
+// dropped otherwise or when the prefix is a recognized person (李明 of 李明先生).
+
+func findCharacterNames(text string, c *classifier.Classifier, persons []classifier.ItemFrequency) []string {
+
+	counts := make(map[string]int)
+
+	runes := []rune(text)
+
+	for i, r := range runes {
+
+		if !classifier.IsSurname(r) {
+
+			continue
+
+		}
+
+		for length := 2; length <= 3 && i+length <= len(runes); length++ {
+
+			candidate := runes[i : i+length]
+
+			last := string(candidate[length-1])
+
+			if !unicode.Is(unicode.Han, candidate[length-1]) {
+
+				break
+
+			}
+
+			if _, ok := classifier.FunctionWordType(last); ok {
+
+				break
+
+			}
+
+			if tag, ok := c.Tag(last); ok && (slices.ContainsFunc(nonNameEndingTags, func(prefix string) bool { return strings.HasPrefix(tag, prefix) }) || length == 3 && strings.HasPrefix(tag, "m")) {
+
+				break
+
+			}
+
+			counts[string(candidate)]++
+
+		}
+
+	}
+
+	names := make(map[string]bool)
+
+	for _, person := range persons {
+
+		if person.Frequency >= minNameOccurrences {
+
+			names[person.Item] = true
+
+		}
+
+	}
+
+	for candidate, count := range counts {
+
+		if count < minNameOccurrences || names[candidate] {
+
+			continue
+
+		}
+
+		if tag, ok := c.Tag(candidate); ok && tag != "nr" {
+
+			continue
+
+		}
+
+		runes := []rune(candidate)
+
+		if len(runes) == 2 {
+
+			longer := false
+
+			for other, otherCount := range counts {
+
+				if len([]rune(other)) == 3 && strings.HasPrefix(other, candidate) && otherCount >= minNameOccurrences && otherCount*5 >= count*4 {
+
+					longer = true
+
+				}
+
+			}
+
+			if longer {
+
+				continue
+
+			}
+
+		} else if prefix := string(runes[:2]); names[prefix] || count*5 < counts[prefix]*4 {
+
+			continue
+
+		}
+
+		names[candidate] = true
+
+	}
+
+	sorted := make([]string, 0, len(names))
+
+	for name := range names {
+
+		sorted = append(sorted, name)
+
+	}
+
+	sort.Strings(sorted)
+
+	return sorted
+
+}
+
+// Counts the mentions of each name in the text, taking the longest name at each position so that a name
+
+// within a longer one (张三 in 张三丰) is not counted again
+
+func countNames(text string, names []string) map[string]int {
+
+	longestFirst := slices.Clone(names)
+
+	sort.SliceStable(longestFirst, func(i, j int) bool { return len(longestFirst[i]) > len(longestFirst[j]) })
+
+	counts := make(map[string]int)
+
+	for i := 0; i < len(text); {
+
+		matched := ""
+
+		for _, name := range longestFirst {
+
+			if strings.HasPrefix(text[i:], name) {
+
+				matched = name
+
+				break
+
+			}
+
+		}
+
+		if matched == "" {
+
+			_, size := utf8.DecodeRuneInString(text[i:])
+
+			i += size
+
+			continue
+
+		}
+
+		counts[matched]++
+
+		i += len(matched)
+
+	}
+
+	return counts
+
+}
+
+// Writes ChineseChapters.txt, the vocabulary introduction curve (new and cumulative distinct words per chapter),
+
+// and ChineseChapterNames.txt, the frequency of each character name per chapter
+
+func writeChapterReports(outputDir string, chapters []chapter, c *classifier.Classifier, persons []classifier.ItemFrequency, enc encoding.Encoding) error {
+
+	file, err := createOutputFile(filepath.Join(outputDir, "ChineseChapters.txt"), enc)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to create chapter report: %v", err)
+
+	}
+
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+
+	writer.WriteString("chapter\ttitle\ttokens\tdistinct\tnew\tcumulative\tnew_ratio\n")
+
+	seen := make(map[string]bool)
+
+	var fullText strings.Builder
+
+	for i, ch := range chapters {
+
+		fullText.WriteString(ch.Text)
+
+		tokens, newWords := 0, 0
+
+		distinct := make(map[string]bool)
+
+		for _, word := range c.Segment(ch.Text) {
+
+			if !classifier.IsChineseText(word) {
+
+				continue
+
+			}
+
+			tokens++
+
+			distinct[word] = true
+
+			if !seen[word] {
+
+				seen[word] = true
+
+				newWords++
+
+			}
+
+		}
+
+		ratio := 0.0
+
+		if len(distinct) > 0 {
+
+			ratio = float64(newWords) / float64(len(distinct))
+
+		}
+
+		fmt.Fprintf(writer, "%d\t%s\t%d\t%d\t%d\t%d\t%.3f\n", i+1, ch.Title, tokens, len(distinct), newWords, len(seen), ratio)
+
+	}
+
+	if err := writer.Flush(); err != nil {
+
+		return err
+
+	}
+
+	names, err := createOutputFile(filepath.Join(outputDir, "ChineseChapterNames.txt"), enc)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to create chapter name report: %v", err)
+
+	}
+
+	defer names.Close()
+
+	writer = bufio.NewWriter(names)
+
+	writer.WriteString("name")
+
+	for i := range chapters {
+
+		fmt.Fprintf(writer, "\t%d", i+1)
+
+	}
+
+	writer.WriteString("\ttotal\n")
+
+	characterNames := findCharacterNames(fullText.String(), c, persons)
+
+	chapterCounts := make([]map[string]int, len(chapters))
+
+	for i, ch := range chapters {
+
+		chapterCounts[i] = countNames(ch.Text, characterNames)
+
+	}
+
+	for _, name := range characterNames {
+
+		writer.WriteString(name)
+
+		total := 0
+
+		for i := range chapters {
+
+			count := chapterCounts[i][name]
+
+			total += count
+
+			fmt.Fprintf(writer, "\t%d", count)
+
+		}
+
+		fmt.Fprintf(writer, "\t%d\n", total)
+
+	}
+
+	return writer.Flush()
+
+}
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"flag"
+
+	"fmt"
+
+	"os"
+
+	"os/exec"
+
+	"path/filepath"
+
+	"runtime"
+
+	"strings"
+)
+
+// Name under which the classifier appears in "Open with" menus
+
+const openWithName = "cwClassifier"
+
+// Label of the context menu entry and desktop entry
+
+const openWithLabel = "Classify Chinese text with cwClassifier"
+
+// Path of the macOS command registering applications with Launch Services, so Finder offers them in "Open with"
+
+const lsregister = "/System/Library/Frameworks/CoreServices.framework/Frameworks/LaunchServices.framework/Support/lsregister"
+
+// Windows registry key of the context menu entry of .txt files, for the current user only
+
+const openWithRegistryKey = `HKCU\Software\Classes\SystemFileAssociations\.txt\shell\` + openWithName
+
+// Returns the folder next to a file opened with the classifier where its results are written, e.g. novel_cwClassifier_output
+
+func openWithOutputDir(inputFile string) string {
+
+	stem := strings.TrimSuffix(filepath.Base(inputFile), filepath.Ext(inputFile))
+
+	return filepath.Join(filepath.Dir(inputFile), stem+"_cwClassifier_output")
+
+}
+
+// Runs the register subcommand, adding the classifier to the "Open with" menu of .txt files or removing it with -remove
+
+func runRegisterCommand(args []string) error {
+
+	flags := flag.NewFlagSet("register", flag.ContinueOnError)
+
+	remove := flags.Bool("remove", false, "Remove the classifier from the \"Open with\" menu instead")
+
+	if err := flags.Parse(args); err != nil {
+
+		return err
+
+	}
+
+	executable, err := os.Executable()
+
+	if err != nil {
+
+		return fmt.Errorf("failed to locate the executable: %v", err)
+
+	}
+
+	if executable, err = filepath.EvalSymlinks(executable); err != nil {
+
+		return fmt.Errorf("failed to locate the executable: %v", err)
+
+	}
+
+	switch runtime.GOOS {
+
+	case "windows":
+
+		if *remove {
+
+			return runRegistry("delete", openWithRegistryKey, "/f")
+
+		}
+
+		if err := runRegistry("add", openWithRegistryKey, "/ve", "/d", openWithLabel, "/f"); err != nil {
+
+			return err
+
+		}
+
+		if err := runRegistry("add", openWithRegistryKey+`\command`, "/ve", "/d", `"`+executable+`" "%1"`, "/f"); err != nil {
+
+			return err
+
+		}
+
+	case "darwin":
+
+		if err := registerApplet(executable, *remove); err != nil {
+
+			return err
+
+		}
+
+	default:
+
+		if err := registerDesktopEntry(executable, *remove); err != nil {
+
+			return err
+
+		}
+
+	}
+
+	if *remove {
+
+		fmt.Println("Removed", openWithName, "from the \"Open with\" menu of .txt files")
+
+	} else {
+
+		fmt.Println("Added", openWithName, "to the \"Open with\" menu of .txt files; results are written to a folder next to the opened file")
+
+	}
+
+	return nil
+
+}
+
+// Runs reg.exe with the given arguments
+
+func runRegistry(args ...string) error {
+
+	if output, err := exec.Command("reg", args...).CombinedOutput(); err != nil {
+
+		return fmt.Errorf("failed to update the registry: %v: %s", err, strings.TrimSpace(string(output)))
+
+	}
+
+	return nil
+
+}
+
+// Writes or removes the freedesktop.org desktop entry offering the classifier for plain text files
+
+func registerDesktopEntry(executable string, remove bool) error {
+
+	dataHome := os.Getenv("XDG_DATA_HOME")
+
+	if dataHome == "" {
+
+		home, err := os.UserHomeDir()
+
+		if err != nil {
+
+			return fmt.Errorf("failed to locate the home directory: %v", err)
+
+		}
+
+		dataHome = filepath.Join(home, ".local", "share")
+
+	}
+
+	applications := filepath.Join(dataHome, "applications")
+
+	path := filepath.Join(applications, openWithName+".desktop")
+
+	if remove {
+
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+
+			return fmt.Errorf("failed to remove desktop entry: %v", err)
+
+		}
+
+	} else {
+
+		if err := os.MkdirAll(applications, os.ModePerm); err != nil {
+
+			return fmt.Errorf("failed to create applications directory: %v", err)
+
+		}
+
+		// Terminal=true keeps the progress and any errors visible while the file is analyzed
+
+		entry := "[Desktop Entry]\nType=Application\nName=" + openWithLabel + "\nExec=\"" + executable + "\" %f\nMimeType=text/plain;\nTerminal=true\nNoDisplay=true\n"
+
+		if err := os.WriteFile(path, []byte(entry), 0644); err != nil {
+
+			return fmt.Errorf("failed to write desktop entry: %v", err)
+
+		}
+
+	}
+
+	// The menu cache is refreshed where the desktop offers the tool; file managers rescan the directory otherwise
+
+	if updater, err := exec.LookPath("update-desktop-database"); err == nil {
+
+		exec.Command(updater, applications).Run()
+
+	}
+
+	return nil
+
+}
+
+// Writes or removes ~/Applications/cwClassifier.app, an AppleScript applet that Finder offers in "Open with".
+
+// macOS hands documents to applications as Apple events rather than arguments, so the applet runs the
+
+// classifier on each file in Terminal, which keeps the progress and any errors visible.
+
+func registerApplet(executable string, remove bool) error {
+
+	home, err := os.UserHomeDir()
+
+	if err != nil {
+
+		return fmt.Errorf("failed to locate the home directory: %v", err)
+
+	}
+
+	applet := filepath.Join(home, "Applications", openWithName+".app")
+
+	if remove {
+
+		exec.Command(lsregister, "-u", applet).Run()
+
+		if err := os.RemoveAll(applet); err != nil {
+
+			return fmt.Errorf("failed to remove %s: %v", applet, err)
+
+		}
+
+		return nil
+
+	}
+
+	if err := os.MkdirAll(filepath.Dir(applet), os.ModePerm); err != nil {
+
+		return fmt.Errorf("failed to create applications directory: %v", err)
+
+	}
+
+	quoted := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(executable)
+
+	script := "on open droppedFiles\n" +
+		"\trepeat with droppedFile in droppedFiles\n" +
+		"\t\ttell application \"Terminal\"\n" +
+		"\t\t\tactivate\n" +
+		"\t\t\tdo script quoted form of \"" + quoted + "\" & \" \" & quoted form of POSIX path of droppedFile\n" +
+		"\t\tend tell\n" +
+		"\tend repeat\n" +
+		"end open\n"
+
+	source, err := os.CreateTemp("", "cwClassifier-*.applescript")
+
+	if err != nil {
+
+		return fmt.Errorf("failed to write applet script: %v", err)
+
+	}
+
+	defer os.Remove(source.Name())
+
+	_, err = source.WriteString(script)
+
+	if closeErr := source.Close(); err == nil {
+
+		err = closeErr
+
+	}
+
+	if err != nil {
+
+		return fmt.Errorf("failed to write applet script: %v", err)
+
+	}
+
+	os.RemoveAll(applet)
+
+	if output, err := exec.Command("osacompile", "-o", applet, source.Name()).CombinedOutput(); err != nil {
+
+		return fmt.Errorf("failed to build %s: %v: %s", applet, err, strings.TrimSpace(string(output)))
+
+	}
+
+	// Finder lists new applications in "Open with" once Launch Services knows them
+
+	exec.Command(lsregister, "-f", applet).Run()
+
+	return nil
+
+}
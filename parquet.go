@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+
+	"path/filepath"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// Token-level table row: one row per token in text order
+
+type tokenRow struct {
+	Position int64 `parquet:"position"`
+
+	Line int64 `parquet:"line"`
+
+	Token string `parquet:"token"`
+
+	Tag string `parquet:"tag"`
+
+	Categories []string `parquet:"categories,list"`
+}
+
+// Category-level table row: one row per distinct item of each category
+
+type categoryRow struct {
+	Category string `parquet:"category"`
+
+	Item string `parquet:"item"`
+
+	Frequency int64 `parquet:"frequency"`
+
+	Rank int64 `parquet:"rank"`
+
+	Per10k float64 `parquet:"per_10k"`
+
+	Range int64 `parquet:"range"`
+
+	Dispersion float64 `parquet:"dispersion"`
+
+	DP float64 `parquet:"dp"`
+}
+
+// Writes ChineseTokens.parquet and ChineseCategories.parquet for loading into pandas, Polars or Spark
+
+func writeParquetTables(outputDir string, lines []alignedLine, c *classifier.Classifier, ranked map[string][]classifier.ItemFrequency, stats map[string]map[string]itemStats) error {
+
+	var tokens []tokenRow
+
+	for lineIndex, line := range lines {
+
+		for _, aligned := range line.Tokens {
+
+			tok := aligned.Token
+
+			row := tokenRow{Position: int64(len(tokens)), Line: int64(lineIndex + 1), Token: tok.Text, Tag: tok.Tag, Categories: []string{}}
+
+			if classifier.IsChineseText(tok.Text) {
+
+				row.Categories = c.TokenCategories(tok)
+
+			}
+
+			tokens = append(tokens, row)
+
+		}
+
+	}
+
+	if err := parquet.WriteFile(filepath.Join(outputDir, "ChineseTokens.parquet"), tokens); err != nil {
+
+		return fmt.Errorf("failed to write token table: %v", err)
+
+	}
+
+	var categories []categoryRow
+
+	for _, category := range c.Categories() {
+
+		for i, entry := range ranked[category] {
+
+			s := stats[category][entry.Item]
+
+			categories = append(categories, categoryRow{
+
+				Category: category,
+
+				Item: entry.Item,
+
+				Frequency: int64(entry.Frequency),
+
+				Rank: int64(i + 1),
+
+				Per10k: s.Per10k,
+
+				Range: int64(s.Range),
+
+				Dispersion: s.Dispersion,
+
+				DP: s.DP,
+			})
+
+		}
+
+	}
+
+	if err := parquet.WriteFile(filepath.Join(outputDir, "ChineseCategories.parquet"), categories); err != nil {
+
+		return fmt.Errorf("failed to write category table: %v", err)
+
+	}
+
+	return nil
+
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"fmt"
+
+	"os"
+
+	"strconv"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// Vocabulary PDF layouts selectable with --pdf-layout
+
+var pdfLayouts = []string{"columns", "cards"}
+
+// Page geometry in millimetres (A4 portrait)
+
+const (
+	pdfPageWidth = 210.0
+
+	pdfPageHeight = 297.0
+
+	pdfMargin = 15.0
+)
+
+// Writes a printable vocabulary list, embedding the given CJK-capable TrueType font
+
+func writeVocabularyPDF(path, title, fontPath, layout string, vocabulary []vocabularyEntry) error {
+
+	if fontPath == "" {
+
+		return fmt.Errorf("PDF output needs a CJK TrueType font (--pdf-font), e.g. NotoSansSC-Regular.ttf")
+
+	}
+
+	font, err := os.ReadFile(fontPath)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to read PDF font: %v", err)
+
+	}
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+
+	pdf.SetAutoPageBreak(false, pdfMargin)
+
+	pdf.SetTitle(title, true)
+
+	pdf.AddUTF8FontFromBytes("cjk", "", font)
+
+	if err := pdf.Error(); err != nil {
+
+		return fmt.Errorf("failed to load PDF font %s: %v", fontPath, err)
+
+	}
+
+	switch layout {
+
+	case "cards":
+
+		layoutFlashcards(pdf, vocabulary)
+
+	case "columns", "":
+
+		layoutColumns(pdf, title, vocabulary)
+
+	default:
+
+		return fmt.Errorf("unknown PDF layout %q (available: columns, cards)", layout)
+
+	}
+
+	if err := pdf.OutputFileAndClose(path); err != nil {
+
+		return fmt.Errorf("failed to write vocabulary PDF: %v", err)
+
+	}
+
+	return nil
+
+}
+
+// Two-column word list: word and frequency on the first line, pinyin and gloss below
+
+func layoutColumns(pdf *fpdf.Fpdf, title string, vocabulary []vocabularyEntry) {
+
+	const rowHeight = 13.0
+
+	const gutter = 8.0
+
+	columnWidth := (pdfPageWidth - 2*pdfMargin - gutter) / 2
+
+	top := pdfMargin + 12
+
+	column := 0
+
+	y := top
+
+	newPage := func() {
+
+		pdf.AddPage()
+
+		pdf.SetFont("cjk", "", 14)
+
+		pdf.SetXY(pdfMargin, pdfMargin)
+
+		pdf.CellFormat(0, 8, title, "B", 0, "L", false, 0, "")
+
+		column, y = 0, top
+
+	}
+
+	newPage()
+
+	for _, entry := range vocabulary {
+
+		if y+rowHeight > pdfPageHeight-pdfMargin {
+
+			if column == 0 {
+
+				column, y = 1, top
+
+			} else {
+
+				newPage()
+
+			}
+
+		}
+
+		x := pdfMargin + float64(column)*(columnWidth+gutter)
+
+		pdf.SetXY(x, y)
+
+		pdf.SetFont("cjk", "", 13)
+
+		pdf.CellFormat(columnWidth-15, 6, entry.Word, "", 0, "L", false, 0, "")
+
+		pdf.SetFont("cjk", "", 8)
+
+		pdf.CellFormat(15, 6, "×"+strconv.Itoa(entry.Frequency), "", 0, "R", false, 0, "")
+
+		pdf.SetXY(x, y+6)
+
+		pdf.SetFont("cjk", "", 9)
+
+		pdf.CellFormat(columnWidth, 5, truncateToWidth(pdf, joinNonEmpty(entry.Pinyin, entry.Gloss, " — "), columnWidth), "", 0, "L", false, 0, "")
+
+		y += rowHeight
+
+	}
+
+}
+
+// Flashcard grid (3 × 5 per page) with cut lines; word large, pinyin, gloss and frequency below
+
+func layoutFlashcards(pdf *fpdf.Fpdf, vocabulary []vocabularyEntry) {
+
+	const columns, rows = 3, 5
+
+	cardWidth := (pdfPageWidth - 2*pdfMargin) / columns
+
+	cardHeight := (pdfPageHeight - 2*pdfMargin) / rows
+
+	pdf.SetDrawColor(180, 180, 180)
+
+	pdf.SetDashPattern([]float64{1, 1}, 0)
+
+	for i, entry := range vocabulary {
+
+		slot := i % (columns * rows)
+
+		if slot == 0 {
+
+			pdf.AddPage()
+
+		}
+
+		x := pdfMargin + float64(slot%columns)*cardWidth
+
+		y := pdfMargin + float64(slot/columns)*cardHeight
+
+		pdf.Rect(x, y, cardWidth, cardHeight, "D")
+
+		pdf.SetFont("cjk", "", 22)
+
+		pdf.SetXY(x, y+cardHeight*0.18)
+
+		pdf.CellFormat(cardWidth, 12, entry.Word, "", 0, "C", false, 0, "")
+
+		pdf.SetFont("cjk", "", 11)
+
+		pdf.SetXY(x, y+cardHeight*0.18+13)
+
+		pdf.CellFormat(cardWidth, 6, entry.Pinyin, "", 0, "C", false, 0, "")
+
+		pdf.SetFont("cjk", "", 9)
+
+		pdf.SetXY(x+3, y+cardHeight*0.18+21)
+
+		pdf.MultiCell(cardWidth-6, 4.5, entry.Gloss, "", "C", false)
+
+		pdf.SetXY(x, y+cardHeight-7)
+
+		pdf.SetFont("cjk", "", 7)
+
+		pdf.CellFormat(cardWidth-3, 5, "×"+strconv.Itoa(entry.Frequency), "", 0, "R", false, 0, "")
+
+	}
+
+	if len(vocabulary) == 0 {
+
+		pdf.AddPage()
+
+	}
+
+}
+
+// Shortens text with an ellipsis so it fits within the given width at the current font size
+
+func truncateToWidth(pdf *fpdf.Fpdf, text string, width float64) string {
+
+	if pdf.GetStringWidth(text) <= width {
+
+		return text
+
+	}
+
+	runes := []rune(text)
+
+	for len(runes) > 0 && pdf.GetStringWidth(string(runes)+"…") > width {
+
+		runes = runes[:len(runes)-1]
+
+	}
+
+	return string(runes) + "…"
+
+}
+
+// Joins the non-empty parts with the separator
+
+func joinNonEmpty(first, second, separator string) string {
+
+	if first == "" {
+
+		return second
+
+	}
+
+	if second == "" {
+
+		return first
+
+	}
+
+	return first + separator + second
+
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"bufio"
+
+	_ "embed"
+
+	"fmt"
+
+	"strings"
+
+	"unicode/utf8"
+
+	"github.com/jdkato/prose/v2"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+)
+
+//go:embed dict/pinyin_words.txt
+
+var pinyinWordData string
+
+func init() {
+
+	classifier.RegisterResource("pinyin_words.txt", pinyinWordData)
+
+}
+
+// Pinyin styles of --pinyin
+
+var pinyinStyles = []string{"marks", "numbers", "zhuyin", "jyutping"}
+
+// Readings of items whose category settles a polyphone better than any context, such as the measure word 只
+
+// (zhī, not zhǐ) or the particle 得
+
+var categoryReadings = map[string]map[string]string{
+
+	"ChineseMeasureWords": {"只": "zhi1", "间": "jian1", "种": "zhong3", "场": "chang3", "节": "jie2"},
+
+	"ChineseFunctionWords": {"的": "de5", "地": "de5", "得": "de5", "了": "le5", "着": "zhe5", "还": "hai2", "都": "dou1", "只": "zhi3", "便": "bian4", "哇": "wa5"},
+}
+
+// Pinyin for the items of one text. Words of the word list take their listed readings; other characters
+
+// take their most common reading, except that a character standing alone takes the reading it has most
+
+// often in the words of the text, so 行 reads háng in a text about banks.
+
+type pinyinAnnotator struct {
+	characters map[rune]characterInfo
+
+	words map[string][]string // Word → numbered syllables, for words the most common readings get wrong
+
+	maxWordLength int
+
+	context map[rune]string // Polyphonic character → its most frequent reading in the text
+
+	numbered bool // Tone numbers (zhong1) instead of tone marks (zhōng)
+
+	zhuyin bool // Zhuyin (ㄓㄨㄥ) instead of pinyin, for readers in Taiwan
+
+	jyutping bool // Cantonese readings in Jyutping (zung1) instead of Mandarin ones
+
+}
+
+// Validates a --pinyin style
+
+func parsePinyinStyle(style string) (string, error) {
+
+	style = strings.ToLower(strings.TrimSpace(style))
+
+	if style != "" && !matchesPhraseList(style, pinyinStyles) {
+
+		return "", fmt.Errorf("unknown pinyin style %q (available: %s)", style, strings.Join(pinyinStyles, ", "))
+
+	}
+
+	return style, nil
+
+}
+
+// Loads the embedded word list
+
+func loadPinyinWords() (map[string][]string, int, error) {
+
+	words := make(map[string][]string)
+
+	maxLength := 0
+
+	scanner := bufio.NewScanner(strings.NewReader(classifier.ResourceText("pinyin_words.txt")))
+
+	for scanner.Scan() {
+
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+
+			continue
+
+		}
+
+		fields := strings.Fields(line)
+
+		length := utf8.RuneCountInString(fields[0])
+
+		if length != len(fields)-1 {
+
+			return nil, 0, fmt.Errorf("invalid pinyin word list line %q", line)
+
+		}
+
+		words[fields[0]] = fields[1:]
+
+		maxLength = max(maxLength, length)
+
+	}
+
+	return words, maxLength, scanner.Err()
+
+}
+
+// Creates an annotator for a style, learning the readings of polyphonic characters from the tokens of the text
+
+func newPinyinAnnotator(style string, tokens []prose.Token) (*pinyinAnnotator, error) {
+
+	var characters map[rune]characterInfo
+
+	var words map[string][]string
+
+	var maxWordLength int
+
+	var err error
+
+	if style == "jyutping" {
+
+		if characters, err = loadJyutpingTable(); err != nil {
+
+			return nil, fmt.Errorf("failed to load Jyutping table: %v", err)
+
+		}
+
+		words, maxWordLength = loadJyutpingWords()
+
+	} else {
+
+		if characters, err = loadCharacterTable(); err != nil {
+
+			return nil, fmt.Errorf("failed to load character table: %v", err)
+
+		}
+
+		if words, maxWordLength, err = loadPinyinWords(); err != nil {
+
+			return nil, err
+
+		}
+
+	}
+
+	p := &pinyinAnnotator{characters: characters, words: words, maxWordLength: maxWordLength, numbered: style == "numbers", zhuyin: style == "zhuyin", jyutping: style == "jyutping"}
+
+	counts := make(map[rune]map[string]int)
+
+	for _, token := range tokens {
+
+		if !containsChinese(token.Text) {
+
+			continue
+
+		}
+
+		runes := []rune(token.Text)
+
+		for i, syllable := range p.syllables(runes) {
+
+			if info, ok := characters[runes[i]]; !ok || len(info.Readings) < 2 || syllable == "" {
+
+				continue
+
+			}
+
+			if counts[runes[i]] == nil {
+
+				counts[runes[i]] = make(map[string]int)
+
+			}
+
+			counts[runes[i]][syllable]++
+
+		}
+
+	}
+
+	p.context = make(map[rune]string)
+
+	for r, readings := range counts {
+
+		// Ties keep the most common reading, which comes first in the table
+
+		best := ""
+
+		for _, reading := range characters[r].Readings {
+
+			if readings[reading] > readings[best] {
+
+				best = reading
+
+			}
+
+		}
+
+		if best != "" {
+
+			p.context[r] = best
+
+		}
+
+	}
+
+	return p, nil
+
+}
+
+// Numbered syllable of each rune: words of the list by longest match, then each character's most common
+
+// reading; "" for characters without one
+
+func (p *pinyinAnnotator) syllables(runes []rune) []string {
+
+	syllables := make([]string, len(runes))
+
+	for i := 0; i < len(runes); {
+
+		matched := 0
+
+		for length := min(p.maxWordLength, len(runes)-i); length >= 2; length-- {
+
+			if readings, ok := p.words[string(runes[i:i+length])]; ok {
+
+				copy(syllables[i:], readings)
+
+				matched = length
+
+				break
+
+			}
+
+		}
+
+		if matched > 0 {
+
+			i += matched
+
+			continue
+
+		}
+
+		if info, ok := p.characters[runes[i]]; ok {
+
+			syllables[i] = info.Readings[0]
+
+		}
+
+		i++
+
+	}
+
+	return syllables
+
+}
+
+// Pinyin or Zhuyin of an item of a category, one syllable per Chinese character; unknown characters show as "?"
+
+func (p *pinyinAnnotator) annotate(category, item string) string {
+
+	var numbered []string
+
+	runes := []rune(item)
+
+	if reading, ok := categoryReadings[category][item]; ok && !p.jyutping {
+
+		numbered = []string{reading}
+
+	} else if len(runes) == 1 && p.context[runes[0]] != "" {
+
+		numbered = []string{p.context[runes[0]]}
+
+	} else {
+
+		for i, syllable := range p.syllables(runes) {
+
+			if syllable != "" {
+
+				numbered = append(numbered, syllable)
+
+			} else if containsChinese(string(runes[i])) {
+
+				numbered = append(numbered, "?")
+
+			}
+
+		}
+
+	}
+
+	if p.zhuyin {
+
+		for i, syllable := range numbered {
+
+			numbered[i] = toZhuyin(syllable)
+
+		}
+
+	} else if !p.numbered && !p.jyutping {
+
+		for i, syllable := range numbered {
+
+			numbered[i] = toneMarks(syllable)
+
+		}
+
+	}
+
+	return strings.Join(numbered, " ")
+
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bufio"
+
+	"fmt"
+
+	"strconv"
+
+	"strings"
+
+	"unicode"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+
+	"golang.org/x/text/encoding"
+)
+
+// Markers listed per kind in the politeness profile
+
+const politenessTopMarkers = 5
+
+// Writes ChinesePolitenessProfile.txt: politeness markers per kind with their rate per 1,000 characters, and
+
+// how often the polite 您 is used rather than 你
+
+func writePolitenessProfile(path string, result *classifier.Result, enc encoding.Encoding) error {
+
+	characters, polite, plain := 0, 0, 0
+
+	for _, tok := range result.Tokens {
+
+		for _, r := range tok.Text {
+
+			if unicode.Is(unicode.Han, r) {
+
+				characters++
+
+			}
+
+		}
+
+		polite += strings.Count(tok.Text, "您")
+
+		plain += strings.Count(tok.Text, "你")
+
+	}
+
+	kinds := make(map[string]map[string]int)
+
+	for _, marker := range result.Items["ChinesePoliteness"] {
+
+		kind := classifier.PolitenessKind(marker)
+
+		if kinds[kind] == nil {
+
+			kinds[kind] = make(map[string]int)
+
+		}
+
+		kinds[kind][marker]++
+
+	}
+
+	file, err := createOutputFile(path, enc)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to create politeness profile: %v", err)
+
+	}
+
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+
+	var rows [][]string
+
+	for _, kind := range classifier.PolitenessKinds {
+
+		count := 0
+
+		var markers []string
+
+		for i, entry := range classifier.RankByFrequency(kinds[kind]) {
+
+			count += entry.Frequency
+
+			if i < politenessTopMarkers {
+
+				markers = append(markers, fmt.Sprintf("%s(%d)", entry.Item, entry.Frequency))
+
+			}
+
+		}
+
+		rate := 0.0
+
+		if characters > 0 {
+
+			rate = float64(count) * 1000 / float64(characters)
+
+		}
+
+		rows = append(rows, []string{kind, strconv.Itoa(count), strconv.FormatFloat(rate, 'f', 2, 64), strings.Join(markers, " ")})
+
+	}
+
+	writeTable(writer, []string{"kind", "markers", "per 1,000 chars", "most used"}, rows, []bool{false, true, true, false})
+
+	if polite+plain > 0 {
+
+		fmt.Fprintf(writer, "\n您 in second-person address: %.0f%% (您 %d, 你 %d)\n", float64(polite)*100/float64(polite+plain), polite, plain)
+
+	}
+
+	return writer.Flush()
+
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bufio"
+
+	"fmt"
+
+	"sort"
+
+	"strconv"
+
+	"strings"
+
+	"github.com/jdkato/prose/v2"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+
+	"golang.org/x/text/encoding"
+)
+
+// Words listed per reading in the polyphone report
+
+const polyphoneReportExamples = 8
+
+// One reading of a polyphonic character as used in the text, with the words it was read in
+
+type polyphoneReading struct {
+	Reading string // Numbered pinyin
+
+	Occurrences int
+
+	Words []string // Most frequent first
+}
+
+// Readings of the polyphonic characters (多音字) of the text, each character read as in the word it occurs
+// in, and a character standing alone as its category reads it (the particle 得 as de), most used reading first
+
+func (p *pinyinAnnotator) polyphoneReadings(tokens []prose.Token, c *classifier.Classifier) map[rune][]polyphoneReading {
+
+	counts := make(map[rune]map[string]int)
+
+	words := make(map[rune]map[string]map[string]int)
+
+	for _, token := range tokens {
+
+		if !containsChinese(token.Text) {
+
+			continue
+
+		}
+
+		runes := []rune(token.Text)
+
+		syllables := p.syllables(runes)
+
+		if len(runes) == 1 {
+
+			for _, category := range c.TokenCategories(token) {
+
+				if reading, ok := categoryReadings[category][token.Text]; ok {
+
+					syllables[0] = reading
+
+					break
+
+				}
+
+			}
+
+		}
+
+		for i, syllable := range syllables {
+
+			if info, ok := p.characters[runes[i]]; !ok || len(info.Readings) < 2 || syllable == "" {
+
+				continue
+
+			}
+
+			if counts[runes[i]] == nil {
+
+				counts[runes[i]] = make(map[string]int)
+
+				words[runes[i]] = make(map[string]map[string]int)
+
+			}
+
+			if words[runes[i]][syllable] == nil {
+
+				words[runes[i]][syllable] = make(map[string]int)
+
+			}
+
+			counts[runes[i]][syllable]++
+
+			words[runes[i]][syllable][token.Text]++
+
+		}
+
+	}
+
+	polyphones := make(map[rune][]polyphoneReading)
+
+	for r, readings := range counts {
+
+		for reading, occurrences := range readings {
+
+			polyphones[r] = append(polyphones[r], polyphoneReading{Reading: reading, Occurrences: occurrences, Words: rankedKeys(words[r][reading])})
+
+		}
+
+		sort.Slice(polyphones[r], func(i, j int) bool {
+
+			if polyphones[r][i].Occurrences != polyphones[r][j].Occurrences {
+
+				return polyphones[r][i].Occurrences > polyphones[r][j].Occurrences
+
+			}
+
+			return polyphones[r][i].Reading < polyphones[r][j].Reading
+
+		})
+
+	}
+
+	return polyphones
+
+}
+
+// Keys of a count map, most counted first
+
+func rankedKeys(counts map[string]int) []string {
+
+	var keys []string
+
+	for key := range counts {
+
+		keys = append(keys, key)
+
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+
+		if counts[keys[i]] != counts[keys[j]] {
+
+			return counts[keys[i]] > counts[keys[j]]
+
+		}
+
+		return keys[i] < keys[j]
+
+	})
+
+	return keys
+
+}
+
+// Writes ChinesePolyphones.txt: each polyphonic character of the text with every reading it is used in, the
+// occurrences of that reading and the words it is read in, so pronunciation-sensitive text can be audited
+
+func writePolyphoneReport(path string, polyphones map[rune][]polyphoneReading, enc encoding.Encoding) error {
+
+	var characters []rune
+
+	total := make(map[rune]int)
+
+	for r, readings := range polyphones {
+
+		characters = append(characters, r)
+
+		for _, reading := range readings {
+
+			total[r] += reading.Occurrences
+
+		}
+
+	}
+
+	sort.Slice(characters, func(i, j int) bool {
+
+		if total[characters[i]] != total[characters[j]] {
+
+			return total[characters[i]] > total[characters[j]]
+
+		}
+
+		return characters[i] < characters[j]
+
+	})
+
+	file, err := createOutputFile(path, enc)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to create polyphone report: %v", err)
+
+	}
+
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+
+	var rows [][]string
+
+	for _, r := range characters {
+
+		for i, reading := range polyphones[r] {
+
+			character := ""
+
+			if i == 0 {
+
+				character = string(r)
+
+			}
+
+			rows = append(rows, []string{character, toneMarks(reading.Reading), strconv.Itoa(reading.Occurrences), strings.Join(reading.Words[:min(len(reading.Words), polyphoneReportExamples)], " ")})
+
+		}
+
+	}
+
+	writeTable(writer, []string{"character", "reading", "occurrences", "words"}, rows, []bool{false, false, true, false})
+
+	return writer.Flush()
+
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+
+	"io"
+
+	"strings"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+)
+
+// Lower-cased category name without the Chinese prefix and separators, so nouns, Nouns and ChineseNouns, or
+
+// noun-phrases and ChineseNounPhrases, name the same category
+
+func categoryKey(name string) string {
+
+	key := strings.ToLower(strings.TrimSpace(name))
+
+	key = strings.TrimPrefix(key, "chinese")
+
+	return strings.NewReplacer("-", "", "_", "", " ", "").Replace(key)
+
+}
+
+// Finds the category --print names among the classifier's categories
+
+func resolveCategory(name string, categories []string) (string, error) {
+
+	for _, category := range categories {
+
+		if categoryKey(category) == categoryKey(name) {
+
+			return category, nil
+
+		}
+
+	}
+
+	var names []string
+
+	for _, category := range categories {
+
+		names = append(names, strings.ToLower(strings.TrimPrefix(category, "Chinese")))
+
+	}
+
+	return "", fmt.Errorf("unknown category %q for --print (available: %s)", name, strings.Join(names, ", "))
+
+}
+
+// Writes one category's items, most frequent first, as "item<TAB>frequency" lines for shell pipelines
+
+func printCategory(w io.Writer, entries []classifier.ItemFrequency) {
+
+	for _, entry := range entries {
+
+		fmt.Fprintf(w, "%s\t%d\n", entry.Item, entry.Frequency)
+
+	}
+
+}
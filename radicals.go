@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bufio"
+
+	"fmt"
+
+	"sort"
+
+	"strconv"
+
+	"strings"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+
+	"golang.org/x/text/encoding"
+)
+
+// Characters of one radical in the radical report
+
+type radicalGroup struct {
+	radical classifier.Radical
+
+	frequency int
+
+	characters []classifier.ItemFrequency
+}
+
+// Writes ChineseRadicals.txt: the characters of ChineseCharacters grouped by Kangxi radical, radicals with the
+
+// most frequent characters first; characters without a radical in the table are grouped as "?"
+
+func writeRadicalReport(path string, characters []classifier.ItemFrequency, enc encoding.Encoding) error {
+
+	groups := make(map[int]*radicalGroup)
+
+	for _, entry := range characters {
+
+		radical, ok := classifier.LookupRadical([]rune(entry.Item)[0])
+
+		if !ok {
+
+			radical = classifier.Radical{Character: "?"}
+
+		}
+
+		group := groups[radical.Number]
+
+		if group == nil {
+
+			group = &radicalGroup{radical: radical}
+
+			groups[radical.Number] = group
+
+		}
+
+		group.frequency += entry.Frequency
+
+		group.characters = append(group.characters, entry)
+
+	}
+
+	var ordered []*radicalGroup
+
+	for _, group := range groups {
+
+		ordered = append(ordered, group)
+
+	}
+
+	sort.Slice(ordered, func(i, j int) bool {
+
+		if ordered[i].frequency != ordered[j].frequency {
+
+			return ordered[i].frequency > ordered[j].frequency
+
+		}
+
+		return ordered[i].radical.Number < ordered[j].radical.Number
+
+	})
+
+	file, err := createOutputFile(path, enc)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to create radical report: %v", err)
+
+	}
+
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+
+	var rows [][]string
+
+	for _, group := range ordered {
+
+		radical := strings.TrimSpace(group.radical.Character + " " + group.radical.Simplified)
+
+		number := ""
+
+		if group.radical.Number > 0 {
+
+			number = strconv.Itoa(group.radical.Number)
+
+		}
+
+		var listed []string
+
+		for _, entry := range group.characters {
+
+			listed = append(listed, fmt.Sprintf("%s(%d)", entry.Item, entry.Frequency))
+
+		}
+
+		rows = append(rows, []string{radical, number, group.radical.Name, strconv.Itoa(len(group.characters)), strconv.Itoa(group.frequency), strings.Join(listed, " ")})
+
+	}
+
+	writeTable(writer, []string{"radical", "number", "name", "characters", "frequency", "characters by frequency"}, rows, []bool{false, true, false, true, true, false})
+
+	return writer.Flush()
+
+}
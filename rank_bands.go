@@ -0,0 +1,66 @@
+package main
+
+import "strconv"
+
+// Rank bands of category output lists by the rank of an item within its category; items ranked past the
+
+// last band are in the tail
+
+var rankBands = []struct {
+	Name string
+
+	Rank int // Lowest rank in the band
+}{
+
+	{"top-1k", 1000},
+
+	{"1k-5k", 5000},
+}
+
+// Band of items outside every rank band
+
+const tailRankBand = "tail"
+
+// Ways of splitting category output into rank bands: sections of one file or a file per band
+
+var rankBandSplits = []string{"sections", "files"}
+
+// Returns the rank band of the item at the given zero-based index of its category list
+
+func rankBand(index int) string {
+
+	for _, band := range rankBands {
+
+		if index < band.Rank {
+
+			return band.Name
+
+		}
+
+	}
+
+	return tailRankBand
+
+}
+
+// Returns the heading of a rank band section, e.g. "# top-1k (1-1000)"
+
+func rankBandHeading(band string) string {
+
+	first := 1
+
+	for _, b := range rankBands {
+
+		if b.Name == band {
+
+			return "# " + band + " (" + strconv.Itoa(first) + "-" + strconv.Itoa(b.Rank) + ")"
+
+		}
+
+		first = b.Rank + 1
+
+	}
+
+	return "# " + band + " (" + strconv.Itoa(first) + "+)"
+
+}
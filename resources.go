@@ -0,0 +1,137 @@
+package main
+
+import (
+	"flag"
+
+	"fmt"
+
+	"os"
+
+	"path/filepath"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+)
+
+// Environment variable naming the resource override directory when --resources is not given
+
+const resourceDirVariable = "CWCLASSIFIER_RESOURCES"
+
+// Makes the files of dir replace the embedded language resources and says which ones it replaces
+
+func useResourceDir(dir string) error {
+
+	if dir == "" {
+
+		return nil
+
+	}
+
+	if err := classifier.SetResourceDir(dir); err != nil {
+
+		return err
+
+	}
+
+	for _, resource := range classifier.Resources() {
+
+		if resource.Source != "embedded" {
+
+			fmt.Printf("Using %s (version %s) from %s\n", resource.Name, resource.Version, dir)
+
+		}
+
+	}
+
+	return nil
+
+}
+
+// Lists the language resources with their versions, or exports the embedded copies as a starting point for
+
+// an override directory
+
+func runResourcesCommand(args []string) error {
+
+	flags := flag.NewFlagSet("resources", flag.ContinueOnError)
+
+	dir := flags.String("resources", os.Getenv(resourceDirVariable), "Override directory, as for analysis runs")
+
+	export := flags.String("export", "", "Write the embedded copy of every resource into this directory; existing files are kept")
+
+	if err := flags.Parse(args); err != nil {
+
+		return err
+
+	}
+
+	if *export != "" {
+
+		return exportResources(*export)
+
+	}
+
+	if *dir != "" {
+
+		if err := classifier.SetResourceDir(*dir); err != nil {
+
+			return err
+
+		}
+
+	}
+
+	var rows [][]string
+
+	for _, resource := range classifier.Resources() {
+
+		rows = append(rows, []string{resource.Name, resource.Version, resource.Source, resource.SHA256[:12]})
+
+	}
+
+	writeTable(os.Stdout, []string{"resource", "version", "source", "sha256"}, rows, make([]bool, 4))
+
+	return nil
+
+}
+
+// Writes the embedded resources into dir, leaving files already there alone so customizations survive
+
+func exportResources(dir string) error {
+
+	written, kept := 0, 0
+
+	for _, resource := range classifier.Resources() {
+
+		path := filepath.Join(dir, filepath.FromSlash(resource.Name))
+
+		if _, err := os.Stat(path); err == nil {
+
+			kept++
+
+			continue
+
+		}
+
+		data, _ := classifier.EmbeddedResource(resource.Name)
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+
+			return fmt.Errorf("failed to create resource directory: %v", err)
+
+		}
+
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+
+			return fmt.Errorf("failed to export %s: %v", resource.Name, err)
+
+		}
+
+		written++
+
+	}
+
+	fmt.Printf("Exported %d resources to %s (%d already present); edit them and pass --resources %s\n", written, dir, kept, dir)
+
+	return nil
+
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"archive/zip"
+
+	"fmt"
+
+	"html"
+
+	"os"
+
+	"strings"
+
+	"time"
+
+	"unicode"
+)
+
+// Builds ruby-annotated XHTML paragraphs, adding pinyin over characters ranked below the difficulty threshold
+
+func rubyParagraphs(lines []alignedLine, characters map[rune]characterInfo, threshold int) []string {
+
+	var paragraphs []string
+
+	for _, line := range lines {
+
+		var paragraph strings.Builder
+
+		for _, aligned := range line.Tokens {
+
+			paragraph.WriteString(html.EscapeString(aligned.Gap))
+
+			for _, r := range aligned.Token.Text {
+
+				info, known := characters[r]
+
+				if !unicode.Is(unicode.Han, r) || !known || info.Rank <= threshold {
+
+					// Characters missing from the table have no reading to show
+
+					paragraph.WriteString(html.EscapeString(string(r)))
+
+					continue
+
+				}
+
+				fmt.Fprintf(&paragraph, "<ruby>%c<rt>%s</rt></ruby>", r, toneMarks(info.Readings[0]))
+
+			}
+
+		}
+
+		paragraph.WriteString(html.EscapeString(line.Trailing))
+
+		paragraphs = append(paragraphs, paragraph.String())
+
+	}
+
+	return paragraphs
+
+}
+
+// Wraps ruby paragraphs in a complete XHTML document, usable both standalone and inside an EPUB
+
+func rubyDocument(title string, paragraphs []string) string {
+
+	var doc strings.Builder
+
+	doc.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+
+	doc.WriteString(`<!DOCTYPE html>` + "\n")
+
+	doc.WriteString(`<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="zh" xml:lang="zh">` + "\n")
+
+	fmt.Fprintf(&doc, "<head>\n<meta charset=\"utf-8\"/>\n<title>%s</title>\n", html.EscapeString(title))
+
+	doc.WriteString("<style>body { line-height: 2.4; } rt { font-size: 0.55em; color: #555; } p { margin: 0 0 0.6em; }</style>\n</head>\n<body>\n")
+
+	for _, paragraph := range paragraphs {
+
+		fmt.Fprintf(&doc, "<p>%s</p>\n", paragraph)
+
+	}
+
+	doc.WriteString("</body>\n</html>\n")
+
+	return doc.String()
+
+}
+
+// Writes the ruby-annotated text as a standalone HTML page
+
+func writeRubyHTML(path, title string, paragraphs []string) error {
+
+	if err := os.WriteFile(path, []byte(rubyDocument(title, paragraphs)), 0644); err != nil {
+
+		return fmt.Errorf("failed to write ruby HTML: %v", err)
+
+	}
+
+	return nil
+
+}
+
+// Writes the ruby-annotated text as a minimal EPUB 3 book
+
+func writeRubyEPUB(path, title string, paragraphs []string) error {
+
+	file, err := os.Create(path)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to create EPUB: %v", err)
+
+	}
+
+	defer file.Close()
+
+	archive := zip.NewWriter(file)
+
+	// The mimetype entry must come first and be stored uncompressed
+
+	mimetype, err := archive.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+
+	if err != nil {
+
+		return fmt.Errorf("failed to write EPUB: %v", err)
+
+	}
+
+	mimetype.Write([]byte("application/epub+zip"))
+
+	escapedTitle := html.EscapeString(title)
+
+	entries := []struct {
+		name string
+
+		content string
+	}{
+
+		{"META-INF/container.xml", `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+<rootfiles><rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/></rootfiles>
+</container>
+`},
+		{"OEBPS/content.opf", fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id" xml:lang="zh">
+<metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+<dc:identifier id="book-id">urn:cwclassifier:%d</dc:identifier>
+<dc:title>%s</dc:title>
+<dc:language>zh</dc:language>
+<meta property="dcterms:modified">%s</meta>
+</metadata>
+<manifest>
+<item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+<item id="text" href="text.xhtml" media-type="application/xhtml+xml"/>
+</manifest>
+<spine><itemref idref="text"/></spine>
+</package>
+`, time.Now().Unix(), escapedTitle, time.Now().UTC().Format("2006-01-02T15:04:05Z"))},
+		{"OEBPS/nav.xhtml", fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="zh" xml:lang="zh">
+<head><meta charset="utf-8"/><title>%s</title></head>
+<body><nav epub:type="toc"><ol><li><a href="text.xhtml">%s</a></li></ol></nav></body>
+</html>
+`, escapedTitle, escapedTitle)},
+		{"OEBPS/text.xhtml", rubyDocument(title, paragraphs)},
+	}
+
+	for _, entry := range entries {
+
+		writer, err := archive.Create(entry.name)
+
+		if err != nil {
+
+			return fmt.Errorf("failed to write EPUB: %v", err)
+
+		}
+
+		if _, err := writer.Write([]byte(entry.content)); err != nil {
+
+			return fmt.Errorf("failed to write EPUB: %v", err)
+
+		}
+
+	}
+
+	if err := archive.Close(); err != nil {
+
+		return fmt.Errorf("failed to finalize EPUB: %v", err)
+
+	}
+
+	return nil
+
+}
@@ -0,0 +1,435 @@
+package main
+
+import (
+	"bytes"
+
+	"crypto/hmac"
+
+	"crypto/sha256"
+
+	"encoding/hex"
+
+	"encoding/xml"
+
+	"errors"
+
+	"fmt"
+
+	"io"
+
+	"net/http"
+
+	"net/url"
+
+	"os"
+
+	"sort"
+
+	"strings"
+
+	"time"
+)
+
+// Store of one object per key in an S3 bucket, or any S3-compatible service such as MinIO. Credentials and
+
+// region come from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN and AWS_REGION; AWS_ENDPOINT_URL
+
+// points at another service. Requests are signed with AWS Signature Version 4.
+
+type s3Store struct {
+	endpoint string // Scheme and host; the bucket goes in the path
+
+	bucket string
+
+	prefix string
+
+	region string
+
+	accessKey string
+
+	secretKey string
+
+	sessionToken string
+
+	client *http.Client
+}
+
+// Configures an S3 store from an s3://bucket/prefix URL and the AWS environment variables
+
+func newS3Store(spec string) (*s3Store, error) {
+
+	location, err := url.Parse(spec)
+
+	if err != nil || location.Host == "" {
+
+		return nil, fmt.Errorf("expected s3://bucket/prefix, got %q", spec)
+
+	}
+
+	store := &s3Store{
+
+		bucket: location.Host,
+
+		prefix: strings.Trim(location.Path, "/"),
+
+		region: os.Getenv("AWS_REGION"),
+
+		accessKey: os.Getenv("AWS_ACCESS_KEY_ID"),
+
+		secretKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+
+		sessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+
+		endpoint: strings.TrimSuffix(os.Getenv("AWS_ENDPOINT_URL"), "/"),
+
+		client: &http.Client{Timeout: 60 * time.Second},
+	}
+
+	if store.accessKey == "" || store.secretKey == "" {
+
+		return nil, fmt.Errorf("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set to use an S3 job store")
+
+	}
+
+	if store.region == "" {
+
+		store.region = "us-east-1"
+
+	}
+
+	if store.endpoint == "" {
+
+		store.endpoint = "https://s3." + store.region + ".amazonaws.com"
+
+	}
+
+	if store.prefix != "" {
+
+		store.prefix += "/"
+
+	}
+
+	return store, nil
+
+}
+
+func (s *s3Store) Put(key string, data []byte) error {
+
+	response, err := s.do(http.MethodPut, s.prefix+key, nil, data)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to store %s: %v", key, err)
+
+	}
+
+	response.Body.Close()
+
+	return nil
+
+}
+
+func (s *s3Store) Get(key string) ([]byte, bool, error) {
+
+	response, err := s.do(http.MethodGet, s.prefix+key, nil, nil)
+
+	if err != nil {
+
+		var statusErr *s3Error
+
+		if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+
+			return nil, false, nil
+
+		}
+
+		return nil, false, fmt.Errorf("failed to read %s: %v", key, err)
+
+	}
+
+	defer response.Body.Close()
+
+	data, err := io.ReadAll(response.Body)
+
+	if err != nil {
+
+		return nil, false, fmt.Errorf("failed to read %s: %v", key, err)
+
+	}
+
+	return data, true, nil
+
+}
+
+func (s *s3Store) Expire(cutoff time.Time) (int, error) {
+
+	expired := 0
+
+	query := url.Values{"list-type": {"2"}, "prefix": {s.prefix}}
+
+	for {
+
+		response, err := s.do(http.MethodGet, "", query, nil)
+
+		if err != nil {
+
+			return expired, fmt.Errorf("failed to list job store: %v", err)
+
+		}
+
+		var listing struct {
+			Contents []struct {
+				Key string
+
+				LastModified time.Time
+			}
+
+			IsTruncated bool
+
+			NextContinuationToken string
+		}
+
+		err = xml.NewDecoder(response.Body).Decode(&listing)
+
+		response.Body.Close()
+
+		if err != nil {
+
+			return expired, fmt.Errorf("failed to list job store: %v", err)
+
+		}
+
+		for _, object := range listing.Contents {
+
+			if !isJobKey(strings.TrimPrefix(object.Key, s.prefix)) || !object.LastModified.Before(cutoff) {
+
+				continue
+
+			}
+
+			response, err := s.do(http.MethodDelete, object.Key, nil, nil)
+
+			if err != nil {
+
+				return expired, fmt.Errorf("failed to expire %s: %v", object.Key, err)
+
+			}
+
+			response.Body.Close()
+
+			expired++
+
+		}
+
+		if !listing.IsTruncated {
+
+			return expired, nil
+
+		}
+
+		query.Set("continuation-token", listing.NextContinuationToken)
+
+	}
+
+}
+
+// Error response of the S3 API, keeping its status code so a missing key can be told from a failure
+
+type s3Error struct {
+	StatusCode int
+
+	Message string
+}
+
+func (e *s3Error) Error() string {
+
+	return fmt.Sprintf("%d %s", e.StatusCode, e.Message)
+
+}
+
+// Sends a signed request for an object key, or for the bucket itself when key is empty. Responses other than
+
+// 2xx become *s3Error errors.
+
+func (s *s3Store) do(method, key string, query url.Values, body []byte) (*http.Response, error) {
+
+	path := "/" + s.bucket
+
+	if key != "" {
+
+		path += "/" + key
+
+	}
+
+	target := s.endpoint + s3EscapePath(path)
+
+	if len(query) > 0 {
+
+		target += "?" + s3EscapeQuery(query)
+
+	}
+
+	request, err := http.NewRequest(method, target, bytes.NewReader(body))
+
+	if err != nil {
+
+		return nil, err
+
+	}
+
+	s.sign(request, body, time.Now().UTC())
+
+	response, err := s.client.Do(request)
+
+	if err != nil {
+
+		return nil, err
+
+	}
+
+	if response.StatusCode/100 != 2 {
+
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 512))
+
+		response.Body.Close()
+
+		return nil, &s3Error{StatusCode: response.StatusCode, Message: strings.TrimSpace(string(message))}
+
+	}
+
+	return response, nil
+
+}
+
+// Adds the AWS Signature Version 4 headers to a request, signing the host, the Range header if any and
+
+// every x-amz-* header
+
+func (s *s3Store) sign(request *http.Request, body []byte, now time.Time) {
+
+	payloadHash := sha256.Sum256(body)
+
+	request.Header.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+
+	request.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(payloadHash[:]))
+
+	if s.sessionToken != "" {
+
+		request.Header.Set("X-Amz-Security-Token", s.sessionToken)
+
+	}
+
+	headers := map[string]string{"host": request.URL.Host}
+
+	for name, values := range request.Header {
+
+		name = strings.ToLower(name)
+
+		if name == "range" || strings.HasPrefix(name, "x-amz-") {
+
+			headers[name] = strings.TrimSpace(strings.Join(values, ","))
+
+		}
+
+	}
+
+	var names []string
+
+	for name := range headers {
+
+		names = append(names, name)
+
+	}
+
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+
+	for _, name := range names {
+
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+
+	}
+
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+
+		request.Method,
+
+		request.URL.EscapedPath(),
+
+		request.URL.RawQuery,
+
+		canonicalHeaders.String(),
+
+		signedHeaders,
+
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	date := now.Format("20060102")
+
+	scope := date + "/" + s.region + "/s3/aws4_request"
+
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+
+	stringToSign := "AWS4-HMAC-SHA256\n" + now.Format("20060102T150405Z") + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := []byte("AWS4" + s.secretKey)
+
+	for _, part := range []string{date, s.region, "s3", "aws4_request"} {
+
+		key = hmacSHA256(key, part)
+
+	}
+
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", s.accessKey, scope, signedHeaders, signature))
+
+}
+
+// HMAC-SHA256 of a message
+
+func hmacSHA256(key []byte, message string) []byte {
+
+	mac := hmac.New(sha256.New, key)
+
+	mac.Write([]byte(message))
+
+	return mac.Sum(nil)
+
+}
+
+// Percent-encodes a path the way Signature Version 4 expects: everything but unreserved characters and slashes
+
+func s3EscapePath(path string) string {
+
+	var out strings.Builder
+
+	for _, b := range []byte(path) {
+
+		if b == '/' || b == '-' || b == '_' || b == '.' || b == '~' || ('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z') || ('0' <= b && b <= '9') {
+
+			out.WriteByte(b)
+
+		} else {
+
+			fmt.Fprintf(&out, "%%%02X", b)
+
+		}
+
+	}
+
+	return out.String()
+
+}
+
+// Encodes a query sorted by name, with spaces as %20 as Signature Version 4 expects
+
+func s3EscapeQuery(query url.Values) string {
+
+	return strings.ReplaceAll(query.Encode(), "+", "%20")
+
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+
+	"math/rand"
+
+	"strconv"
+
+	"strings"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+)
+
+// Sample of the input for quick previews: a percentage of sentences drawn at random, or the first N sentences
+
+type sampleSpec struct {
+	Percent float64 // Share of sentences kept, 0-100; zero when First is used
+
+	First int // Number of leading sentences kept
+
+}
+
+// Parses a --sample value such as "5%" or "200"; an empty value disables sampling
+
+func parseSample(value string) (*sampleSpec, error) {
+
+	value = strings.TrimSpace(value)
+
+	if value == "" {
+
+		return nil, nil
+
+	}
+
+	if percent, ok := strings.CutSuffix(value, "%"); ok {
+
+		p, err := strconv.ParseFloat(percent, 64)
+
+		if err != nil || p <= 0 || p > 100 {
+
+			return nil, fmt.Errorf("invalid sample %q: percentage must be between 0 and 100", value)
+
+		}
+
+		return &sampleSpec{Percent: p}, nil
+
+	}
+
+	n, err := strconv.Atoi(value)
+
+	if err != nil || n <= 0 {
+
+		return nil, fmt.Errorf("invalid sample %q: use a percentage such as 5%% or a number of sentences", value)
+
+	}
+
+	return &sampleSpec{First: n}, nil
+
+}
+
+// Reduces the input to the sampled sentences, one per line and in input order. Random samples draw
+
+// from the seed, so a preview can be repeated exactly.
+
+func sampleLines(lines []string, spec *sampleSpec, seed int64) []string {
+
+	random := rand.New(rand.NewSource(seed))
+
+	var sampled []string
+
+	for _, line := range lines {
+
+		for _, sentence := range classifier.SplitSentences(line) {
+
+			if spec.First > 0 {
+
+				if len(sampled) >= spec.First {
+
+					return sampled
+
+				}
+
+				sampled = append(sampled, sentence)
+
+			} else if random.Float64()*100 < spec.Percent {
+
+				sampled = append(sampled, sentence)
+
+			}
+
+		}
+
+	}
+
+	return sampled
+
+}
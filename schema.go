@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+
+	_ "embed"
+
+	"fmt"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// Version of the JSON results schema written into every results.json
+
+const resultsSchemaVersion = "1.14.0"
+
+// Identifier of the results schema, matching its $id
+
+const resultsSchemaID = "https://github.com/ljg-cqu/txt-cwClassifier/schema/results-v1.schema.json"
+
+//go:embed schema/results-v1.schema.json
+
+var resultsSchemaSource []byte
+
+// Compiles the embedded results schema
+
+func compileResultsSchema() (*jsonschema.Schema, error) {
+
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(resultsSchemaSource))
+
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to parse results schema: %v", err)
+
+	}
+
+	compiler := jsonschema.NewCompiler()
+
+	if err := compiler.AddResource(resultsSchemaID, doc); err != nil {
+
+		return nil, fmt.Errorf("failed to load results schema: %v", err)
+
+	}
+
+	return compiler.Compile(resultsSchemaID)
+
+}
+
+// Checks an encoded results document against the published schema before it is written
+
+func validateResults(data []byte) error {
+
+	schema, err := compileResultsSchema()
+
+	if err != nil {
+
+		return err
+
+	}
+
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+
+	if err != nil {
+
+		return fmt.Errorf("failed to parse JSON results: %v", err)
+
+	}
+
+	if err := schema.Validate(doc); err != nil {
+
+		return fmt.Errorf("JSON results do not match schema v%s: %v", resultsSchemaVersion, err)
+
+	}
+
+	return nil
+
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+
+	"io"
+
+	"os"
+
+	"path/filepath"
+
+	"testing"
+)
+
+// results.json of a run over the fixture, with the optional fields filled in, matches the published schema
+
+func TestResultsMatchSchema(t *testing.T) {
+
+	dir := t.TempDir()
+
+	options := analysisOptions{OutputDir: dir, Formats: map[string]bool{"json": true}, Strokes: true, Components: true, Pinyin: "marks", Messages: io.Discard}
+
+	if err := categorizeChineseText(filepath.Join("testdata", "story.txt"), options); err != nil {
+
+		t.Fatal(err)
+
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "results.json"))
+
+	if err != nil {
+
+		t.Fatal(err)
+
+	}
+
+	if err := validateResults(data); err != nil {
+
+		t.Fatalf("results.json does not match the schema: %v", err)
+
+	}
+
+	// The schema is not so loose that anything passes
+
+	var results map[string]interface{}
+
+	if err := json.Unmarshal(data, &results); err != nil {
+
+		t.Fatal(err)
+
+	}
+
+	for _, broken := range []func(map[string]interface{}){
+
+		func(r map[string]interface{}) { delete(r, "schemaVersion") },
+
+		func(r map[string]interface{}) { r["seed"] = "one" },
+
+		func(r map[string]interface{}) { r["categories"] = []string{"nouns"} },
+	} {
+
+		copied := make(map[string]interface{})
+
+		for key, value := range results {
+
+			copied[key] = value
+
+		}
+
+		broken(copied)
+
+		data, err := json.Marshal(copied)
+
+		if err != nil {
+
+			t.Fatal(err)
+
+		}
+
+		if validateResults(data) == nil {
+
+			t.Errorf("broken results passed validation: %s", data)
+
+		}
+
+	}
+
+}
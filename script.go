@@ -0,0 +1,309 @@
+package main
+
+import (
+	"bufio"
+
+	"embed"
+
+	"fmt"
+
+	"strings"
+
+	"unicode/utf8"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+)
+
+//go:embed dict/opencc
+
+var conversionTables embed.FS
+
+func init() {
+
+	entries, _ := conversionTables.ReadDir("dict/opencc")
+
+	for _, entry := range entries {
+
+		data, _ := conversionTables.ReadFile("dict/opencc/" + entry.Name())
+
+		classifier.RegisterResource("opencc/"+entry.Name(), string(data))
+
+	}
+
+}
+
+// Scripts of --script and --dual-script: mainland simplified, standard traditional, and the traditional forms
+
+// and vocabulary of Taiwan and Hong Kong
+
+var scriptTargets = []string{"simplified", "traditional", "taiwan", "hongkong"}
+
+// Converts text to one script by longest match over the words and characters of OpenCC-style tables
+
+type scriptConverter struct {
+	table map[string]string
+
+	maxLength int
+}
+
+// Validates a --script or --dual-script target
+
+func parseScript(target string) (string, error) {
+
+	target = strings.ToLower(strings.TrimSpace(target))
+
+	if target != "" && !matchesPhraseList(target, scriptTargets) {
+
+		return "", fmt.Errorf("unknown script %q (available: %s)", target, strings.Join(scriptTargets, ", "))
+
+	}
+
+	return target, nil
+
+}
+
+// Loads an OpenCC-style table of "text<TAB>conversion [alternative ...]" lines, keeping the first conversion
+
+func loadConversionTable(name string) (map[string]string, error) {
+
+	table := make(map[string]string)
+
+	scanner := bufio.NewScanner(strings.NewReader(classifier.ResourceText("opencc/" + name)))
+
+	for scanner.Scan() {
+
+		line := scanner.Text()
+
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+
+			continue
+
+		}
+
+		text, conversions, ok := strings.Cut(line, "\t")
+
+		fields := strings.Fields(conversions)
+
+		if !ok || len(fields) == 0 {
+
+			return nil, fmt.Errorf("invalid line %q in %s", line, name)
+
+		}
+
+		table[text] = fields[0]
+
+	}
+
+	if err := scanner.Err(); err != nil {
+
+		return nil, fmt.Errorf("failed to read %s: %v", name, err)
+
+	}
+
+	return table, nil
+
+}
+
+// Creates a converter to a script. Input in any script is accepted: characters and words of other regions
+
+// are read back into standard forms first, so Taiwan and Hong Kong texts convert like mainland ones.
+
+func newScriptConverter(target string) (*scriptConverter, error) {
+
+	tables := make(map[string]map[string]string)
+
+	for _, name := range []string{"TSCharacters.txt", "STCharacters.txt", "STPhrases.txt", "TWVariants.txt", "TWVariantsRevPhrases.txt", "HKVariants.txt", "TWPhrases.txt"} {
+
+		table, err := loadConversionTable(name)
+
+		if err != nil {
+
+			return nil, err
+
+		}
+
+		tables[name] = table
+
+	}
+
+	c := &scriptConverter{table: make(map[string]string)}
+
+	// Regional variants back to the standard traditional forms, except 著, which is both Taiwan's particle 着
+
+	// and the zhù of 著名
+
+	standard := make(map[string]string)
+
+	for _, name := range []string{"TWVariants.txt", "HKVariants.txt"} {
+
+		for from, to := range tables[name] {
+
+			if target != "simplified" && to == "著" {
+
+				continue
+
+			}
+
+			standard[to] = from
+
+		}
+
+	}
+
+	switch target {
+
+	case "simplified":
+
+		for from, to := range tables["TSCharacters.txt"] {
+
+			c.add(from, to)
+
+		}
+
+		for variant, from := range standard {
+
+			c.add(variant, convertEach(from, tables["TSCharacters.txt"]))
+
+		}
+
+		for word := range tables["TWVariantsRevPhrases.txt"] {
+
+			c.add(word, convertEach(word, tables["TSCharacters.txt"]))
+
+		}
+
+		for from, to := range tables["TWPhrases.txt"] {
+
+			c.add(to, from)
+
+		}
+
+	default:
+
+		var regional map[string]string
+
+		if target == "taiwan" {
+
+			regional = tables["TWVariants.txt"]
+
+		} else if target == "hongkong" {
+
+			regional = tables["HKVariants.txt"]
+
+		}
+
+		for variant, from := range standard {
+
+			c.add(variant, convertEach(from, regional))
+
+		}
+
+		for from := range regional {
+
+			c.add(from, regional[from])
+
+		}
+
+		for from, to := range tables["STCharacters.txt"] {
+
+			c.add(from, convertEach(to, regional))
+
+		}
+
+		for from, to := range tables["STPhrases.txt"] {
+
+			c.add(from, convertEach(to, regional))
+
+		}
+
+		if target == "taiwan" {
+
+			for from, to := range tables["TWPhrases.txt"] {
+
+				c.add(from, to)
+
+			}
+
+		}
+
+	}
+
+	return c, nil
+
+}
+
+// Adds a conversion, later ones replacing earlier ones of the same text
+
+func (c *scriptConverter) add(from, to string) {
+
+	c.table[from] = to
+
+	c.maxLength = max(c.maxLength, utf8.RuneCountInString(from))
+
+}
+
+// Converts each character of text by a character table, keeping those it lacks
+
+func convertEach(text string, table map[string]string) string {
+
+	var converted strings.Builder
+
+	for _, r := range text {
+
+		if to, ok := table[string(r)]; ok {
+
+			converted.WriteString(to)
+
+		} else {
+
+			converted.WriteRune(r)
+
+		}
+
+	}
+
+	return converted.String()
+
+}
+
+// Converts text, preferring the longest word of the tables at each position
+
+func (c *scriptConverter) convert(text string) string {
+
+	var converted strings.Builder
+
+	runes := []rune(text)
+
+	for i := 0; i < len(runes); {
+
+		matched := 0
+
+		for length := min(c.maxLength, len(runes)-i); length >= 1; length-- {
+
+			if to, ok := c.table[string(runes[i:i+length])]; ok {
+
+				converted.WriteString(to)
+
+				matched = length
+
+				break
+
+			}
+
+		}
+
+		if matched == 0 {
+
+			converted.WriteRune(runes[i])
+
+			matched = 1
+
+		}
+
+		i += matched
+
+	}
+
+	return converted.String()
+
+}
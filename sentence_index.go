@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+
+	"fmt"
+
+	"os"
+
+	"sort"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+)
+
+// One sentence of the index with the distinct items found in it, by category
+
+type indexedSentence struct {
+	ID string `json:"id"`
+
+	Text string `json:"text"`
+
+	Items map[string][]string `json:"items"`
+}
+
+// Sentence index: sentences with their items, and per category the IDs of the sentences containing any of its items
+
+type sentenceIndex struct {
+	Sentences []indexedSentence `json:"sentences"`
+
+	Categories map[string][]string `json:"categories"`
+}
+
+// Classifies each sentence on its own so items map back to the sentences they occur in.
+
+// Characters are left out, as nearly every sentence would list them all.
+
+func buildSentenceIndex(c *classifier.Classifier, sentences []string) (*sentenceIndex, error) {
+
+	index := &sentenceIndex{Categories: make(map[string][]string)}
+
+	for i, sentence := range sentences {
+
+		result, err := c.Classify(sentence)
+
+		if err != nil {
+
+			return nil, fmt.Errorf("failed to classify sentence %d: %v", i+1, err)
+
+		}
+
+		entry := indexedSentence{ID: fmt.Sprintf("s%d", i+1), Text: sentence, Items: make(map[string][]string)}
+
+		for category, ranked := range result.Ranked {
+
+			if category == "ChineseCharacters" || len(ranked) == 0 {
+
+				continue
+
+			}
+
+			for _, item := range ranked {
+
+				entry.Items[category] = append(entry.Items[category], item.Item)
+
+			}
+
+			sort.Strings(entry.Items[category])
+
+			index.Categories[category] = append(index.Categories[category], entry.ID)
+
+		}
+
+		index.Sentences = append(index.Sentences, entry)
+
+	}
+
+	return index, nil
+
+}
+
+// Writes the sentence index as ChineseSentenceIndex.json
+
+func writeSentenceIndex(path string, index *sentenceIndex) error {
+
+	data, err := json.MarshalIndent(index, "", "  ")
+
+	if err != nil {
+
+		return fmt.Errorf("failed to encode sentence index: %v", err)
+
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+
+		return fmt.Errorf("failed to write sentence index: %v", err)
+
+	}
+
+	return nil
+
+}
@@ -0,0 +1,699 @@
+package main
+
+import (
+	"crypto/rand"
+
+	_ "embed"
+
+	"encoding/hex"
+
+	"encoding/json"
+
+	"flag"
+
+	"fmt"
+
+	"io"
+
+	"net/http"
+
+	"os"
+
+	"path/filepath"
+
+	"slices"
+
+	"sync"
+
+	"time"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+)
+
+// OpenAPI description of the job API, served at /openapi.json; the client package follows it
+
+//go:embed schema/openapi.json
+
+var openAPISpec []byte
+
+// Lines classified between two progress updates of a job
+
+const jobChunkLines = 200
+
+// Largest accepted upload
+
+const maxJobUpload = 100 << 20
+
+// How long jobs and their results are kept after their last update, unless --ttl says otherwise
+
+const jobRetention = time.Hour
+
+// Asynchronous analysis job of the server: its state and, once done, its results
+
+type job struct {
+	ID string `json:"id"`
+
+	Status string `json:"status"` // queued, running, done or failed
+
+	LinesDone int `json:"lines_done"`
+
+	LinesTotal int `json:"lines_total"`
+
+	Error string `json:"error,omitempty"`
+
+	Submitted time.Time `json:"submitted"`
+
+	Finished time.Time `json:"finished,omitzero"`
+
+	lines []string
+}
+
+// Jobs submitted to this server, worked off in submission order by a fixed number of workers. Job states and
+
+// results live in the store, so with a shared store any replica can report on any job.
+
+type jobQueue struct {
+	store jobStore
+
+	pending chan *job
+
+	mu sync.Mutex
+
+	watchers map[string][]chan job // Event streams of jobs, each holding the latest state it has not sent yet
+}
+
+// Handles "cwClassifier serve [--addr localhost:8090] [--workers 2] [--store memory|dir|s3://...]"
+
+func runServeCommand(args []string) error {
+
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+
+	addr := flags.String("addr", "localhost:8090", "Address to serve the job API on")
+
+	workers := flags.Int("workers", 2, "Jobs analyzed at the same time")
+
+	queueSize := flags.Int("queue", 100, "Jobs that may wait; submissions beyond it are refused with 503")
+
+	storeSpec := flags.String("store", "memory", "Where job states and results are kept: memory, a directory, or s3://bucket/prefix; replicas behind a load balancer need a shared one")
+
+	ttl := flags.Duration("ttl", jobRetention, "How long jobs and results are kept after their last update")
+
+	resources := flags.String("resources", os.Getenv(resourceDirVariable), "Directory whose files replace the embedded language resources of the same name")
+
+	if err := flags.Parse(args); err != nil {
+
+		return err
+
+	}
+
+	if err := useResourceDir(*resources); err != nil {
+
+		return err
+
+	}
+
+	if *workers < 1 || *queueSize < 1 {
+
+		return fmt.Errorf("--workers and --queue must be at least 1")
+
+	}
+
+	if *ttl <= 0 {
+
+		return fmt.Errorf("--ttl must be positive")
+
+	}
+
+	store, err := openJobStore(*storeSpec)
+
+	if err != nil {
+
+		return err
+
+	}
+
+	queue := &jobQueue{store: store, pending: make(chan *job, *queueSize)}
+
+	go queue.expire(*ttl)
+
+	for range *workers {
+
+		c, err := classifier.New()
+
+		if err != nil {
+
+			return err
+
+		}
+
+		go queue.work(c)
+
+	}
+
+	fmt.Printf("Serving the job API at http://%s/jobs with %d workers\n", *addr, *workers)
+
+	return http.ListenAndServe(*addr, newJobHandler(queue))
+
+}
+
+// Routes of the job API: POST /jobs submits a document and answers 202 with the job; GET /jobs/{id} reports
+
+// its progress and GET /jobs/{id}/events streams it as server-sent events; GET /jobs/{id}/results returns the
+
+// JSON results once the job is done. GET /openapi.json describes the API and the results schema it refers to.
+
+func newJobHandler(queue *jobQueue) http.Handler {
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /openapi.json", func(w http.ResponseWriter, r *http.Request) {
+
+		w.Header().Set("Content-Type", "application/json")
+
+		w.Write(openAPISpec)
+
+	})
+
+	mux.HandleFunc("GET /schema/results-v1.schema.json", func(w http.ResponseWriter, r *http.Request) {
+
+		w.Header().Set("Content-Type", "application/schema+json")
+
+		w.Write(resultsSchemaSource)
+
+	})
+
+	mux.HandleFunc("POST /jobs", func(w http.ResponseWriter, r *http.Request) {
+
+		lines, err := readUploadedLines(http.MaxBytesReader(w, r.Body, maxJobUpload), r.URL.Query().Get("filename"), r.URL.Query().Get("input-format"))
+
+		if err != nil {
+
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+
+		}
+
+		submitted, err := queue.submit(lines)
+
+		if err != nil {
+
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+
+			return
+
+		}
+
+		w.Header().Set("Location", "/jobs/"+submitted.ID)
+
+		writeJSON(w, http.StatusAccepted, submitted)
+
+	})
+
+	mux.HandleFunc("GET /jobs/{id}", func(w http.ResponseWriter, r *http.Request) {
+
+		snapshot, ok, err := queue.get(r.PathValue("id"))
+
+		switch {
+
+		case err != nil:
+
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		case !ok:
+
+			http.Error(w, fmt.Sprintf("unknown job %q", r.PathValue("id")), http.StatusNotFound)
+
+		default:
+
+			writeJSON(w, http.StatusOK, snapshot)
+
+		}
+
+	})
+
+	mux.HandleFunc("GET /jobs/{id}/events", func(w http.ResponseWriter, r *http.Request) {
+
+		flusher, ok := w.(http.Flusher)
+
+		if !ok {
+
+			http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+
+			return
+
+		}
+
+		// Watch before reading the stored state, so that no update falls between the two
+
+		updates, stop := queue.watch(r.PathValue("id"))
+
+		defer stop()
+
+		snapshot, ok, err := queue.get(r.PathValue("id"))
+
+		switch {
+
+		case err != nil:
+
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+
+			return
+
+		case !ok:
+
+			http.Error(w, fmt.Sprintf("unknown job %q", r.PathValue("id")), http.StatusNotFound)
+
+			return
+
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+
+		w.Header().Set("Cache-Control", "no-cache")
+
+		// Workers of other replicas sharing the store report to their own streams, so the store is polled as well
+
+		ticker := time.NewTicker(progressPollInterval)
+
+		defer ticker.Stop()
+
+		var sent job
+
+		for {
+
+			if snapshot.Status != sent.Status || snapshot.LinesDone != sent.LinesDone {
+
+				data, _ := json.Marshal(snapshot)
+
+				fmt.Fprintf(w, "data: %s\n\n", data)
+
+				flusher.Flush()
+
+				sent = snapshot
+
+			}
+
+			if snapshot.Status == "done" || snapshot.Status == "failed" {
+
+				return
+
+			}
+
+			select {
+
+			case <-r.Context().Done():
+
+				return
+
+			case snapshot = <-updates:
+
+			case <-ticker.C:
+
+				if stored, ok, err := queue.get(snapshot.ID); err == nil && ok {
+
+					snapshot = stored
+
+				}
+
+			}
+
+		}
+
+	})
+
+	mux.HandleFunc("GET /jobs/{id}/results", func(w http.ResponseWriter, r *http.Request) {
+
+		snapshot, ok, err := queue.get(r.PathValue("id"))
+
+		switch {
+
+		case err != nil:
+
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		case !ok:
+
+			http.Error(w, fmt.Sprintf("unknown job %q", r.PathValue("id")), http.StatusNotFound)
+
+		case snapshot.Status == "failed":
+
+			http.Error(w, snapshot.Error, http.StatusInternalServerError)
+
+		case snapshot.Status != "done":
+
+			http.Error(w, fmt.Sprintf("job %s is %s (%d of %d lines)", snapshot.ID, snapshot.Status, snapshot.LinesDone, snapshot.LinesTotal), http.StatusConflict)
+
+		default:
+
+			results, ok, err := queue.store.Get(snapshot.ID + ".results.json")
+
+			if err != nil || !ok {
+
+				http.Error(w, fmt.Sprintf("results of job %s are no longer available", snapshot.ID), http.StatusGone)
+
+				return
+
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+
+			w.Write(results)
+
+		}
+
+	})
+
+	return mux
+
+}
+
+// Reads an uploaded document into lines, the same way as an input file with the given name and format
+
+func readUploadedLines(body io.Reader, filename, format string) ([]string, error) {
+
+	if format == "" {
+
+		format = "auto"
+
+	}
+
+	format, err := parseInputFormat(format)
+
+	if err != nil {
+
+		return nil, err
+
+	}
+
+	dir, err := os.MkdirTemp("", "cwClassifier-job")
+
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to store upload: %v", err)
+
+	}
+
+	defer os.RemoveAll(dir)
+
+	if filename == "" {
+
+		filename = "upload.txt"
+
+	}
+
+	path := filepath.Join(dir, filepath.Base(filename))
+
+	file, err := os.Create(path)
+
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to store upload: %v", err)
+
+	}
+
+	_, err = io.Copy(file, body)
+
+	file.Close()
+
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to read upload: %v", err)
+
+	}
+
+	lines, _, _, err := readInputLines(path, format)
+
+	return lines, err
+
+}
+
+// Queues a job for the lines and records it in the store
+
+func (q *jobQueue) submit(lines []string) (job, error) {
+
+	id := make([]byte, 8)
+
+	if _, err := rand.Read(id); err != nil {
+
+		return job{}, fmt.Errorf("failed to create job ID: %v", err)
+
+	}
+
+	submitted := &job{ID: hex.EncodeToString(id), Status: "queued", LinesTotal: len(lines), Submitted: time.Now(), lines: lines}
+
+	if len(q.pending) == cap(q.pending) {
+
+		return job{}, fmt.Errorf("job queue is full, try again later")
+
+	}
+
+	// Record the job before a worker can pick it up, so its updates never race the first write
+
+	if err := q.save(submitted); err != nil {
+
+		return job{}, err
+
+	}
+
+	// The worker owns the job once it is sent, so the answer is a copy taken before
+
+	snapshot := *submitted
+
+	select {
+
+	case q.pending <- submitted:
+
+	default:
+
+		// Another submission filled the queue since the check; the saved record must not stay queued forever
+
+		submitted.Status, submitted.Error, submitted.Finished = "failed", "job queue is full", time.Now()
+
+		q.saveOrLog(submitted)
+
+		return job{}, fmt.Errorf("job queue is full, try again later")
+
+	}
+
+	return snapshot, nil
+
+}
+
+// Reports whether id has the form of the job IDs submit creates: 16 hex digits
+
+func isJobID(id string) bool {
+
+	_, err := hex.DecodeString(id)
+
+	return err == nil && len(id) == 16
+
+}
+
+// Reads a job's state from the store
+
+func (q *jobQueue) get(id string) (job, bool, error) {
+
+	// IDs become store keys, so anything but the IDs submit creates is unknown
+
+	if !isJobID(id) {
+
+		return job{}, false, nil
+
+	}
+
+	data, ok, err := q.store.Get(id + ".json")
+
+	if err != nil || !ok {
+
+		return job{}, false, err
+
+	}
+
+	var found job
+
+	if err := json.Unmarshal(data, &found); err != nil {
+
+		return job{}, false, fmt.Errorf("failed to read job %s: %v", id, err)
+
+	}
+
+	return found, true, nil
+
+}
+
+// Registers an event stream for a job; stop unregisters it
+
+func (q *jobQueue) watch(id string) (updates <-chan job, stop func()) {
+
+	latest := make(chan job, 1)
+
+	q.mu.Lock()
+
+	defer q.mu.Unlock()
+
+	if q.watchers == nil {
+
+		q.watchers = make(map[string][]chan job)
+
+	}
+
+	q.watchers[id] = append(q.watchers[id], latest)
+
+	return latest, func() {
+
+		q.mu.Lock()
+
+		defer q.mu.Unlock()
+
+		q.watchers[id] = slices.DeleteFunc(q.watchers[id], func(c chan job) bool { return c == latest })
+
+		if len(q.watchers[id]) == 0 {
+
+			delete(q.watchers, id)
+
+		}
+
+	}
+
+}
+
+// Hands a job's new state to its event streams; a stream that has not sent the previous state yet skips it
+
+func (q *jobQueue) publish(j job) {
+
+	q.mu.Lock()
+
+	defer q.mu.Unlock()
+
+	for _, latest := range q.watchers[j.ID] {
+
+		select {
+
+		case <-latest:
+
+		default:
+
+		}
+
+		latest <- j
+
+	}
+
+}
+
+// Runs queued jobs one after another, updating their progress after each chunk
+
+func (q *jobQueue) work(c *classifier.Classifier) {
+
+	for next := range q.pending {
+
+		next.Status = "running"
+
+		q.saveOrLog(next)
+
+		result, err := classifyInChunks(c, next.lines, jobChunkLines, func(done, total int, ranked map[string][]classifier.ItemFrequency) error {
+
+			next.LinesDone = done
+
+			q.saveOrLog(next)
+
+			return nil
+
+		})
+
+		next.lines = nil
+
+		if err == nil {
+
+			// Store the results before the job is marked done, so a done job always has them
+
+			var data []byte
+
+			data, err = json.Marshal(buildJSONResults(next.ID, 0, result.Ranked, c, nil, nil, nil, nil, nil, result.Forms, result.Suppressed, nil, false, false))
+
+			if err == nil {
+
+				err = q.store.Put(next.ID+".results.json", data)
+
+			}
+
+		}
+
+		next.Finished = time.Now()
+
+		if err != nil {
+
+			next.Status, next.Error = "failed", err.Error()
+
+		} else {
+
+			next.Status, next.LinesDone = "done", next.LinesTotal
+
+		}
+
+		q.saveOrLog(next)
+
+	}
+
+}
+
+// Writes a job's state to the store
+
+func (q *jobQueue) save(j *job) error {
+
+	data, err := json.Marshal(j)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to encode job %s: %v", j.ID, err)
+
+	}
+
+	return q.store.Put(j.ID+".json", data)
+
+}
+
+// Writes a job's state from a worker, which has no client to report a failure to, and hands it to the job's
+
+// event streams
+
+func (q *jobQueue) saveOrLog(j *job) {
+
+	if err := q.save(j); err != nil {
+
+		fmt.Println("Failed to update job:", err)
+
+	}
+
+	q.publish(*j)
+
+}
+
+// Removes jobs and results not updated within the TTL, checking a few times per TTL but at most once a second
+
+func (q *jobQueue) expire(ttl time.Duration) {
+
+	for range time.Tick(max(min(ttl/4, time.Minute), time.Second)) {
+
+		if _, err := q.store.Expire(time.Now().Add(-ttl)); err != nil {
+
+			fmt.Println("Failed to expire jobs:", err)
+
+		}
+
+	}
+
+}
+
+// Writes a value as a JSON response
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+
+	w.Header().Set("Content-Type", "application/json")
+
+	w.WriteHeader(status)
+
+	json.NewEncoder(w).Encode(value)
+
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bufio"
+
+	"encoding/json"
+
+	"net/http"
+
+	"net/http/httptest"
+
+	"strings"
+
+	"testing"
+
+	"time"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+)
+
+// Jobs submitted while a worker runs are worked off to the end; run with -race to check that submit and the
+
+// worker never share a job
+
+func TestJobQueueWork(t *testing.T) {
+
+	c, err := classifier.New()
+
+	if err != nil {
+
+		t.Fatal(err)
+
+	}
+
+	store, _ := openJobStore("memory")
+
+	queue := &jobQueue{store: store, pending: make(chan *job, 4)}
+
+	go queue.work(c)
+
+	defer close(queue.pending)
+
+	var ids []string
+
+	for range 3 {
+
+		submitted, err := queue.submit([]string{"老王坐高铁去北京。", "小李在车站等他。"})
+
+		if err != nil {
+
+			t.Fatal(err)
+
+		}
+
+		if submitted.Status != "queued" || submitted.LinesTotal != 2 {
+
+			t.Errorf("submit answered %+v, want a queued job of 2 lines", submitted)
+
+		}
+
+		ids = append(ids, submitted.ID)
+
+	}
+
+	for _, id := range ids {
+
+		deadline := time.Now().Add(10 * time.Second)
+
+		for {
+
+			found, ok, err := queue.get(id)
+
+			if err != nil || !ok {
+
+				t.Fatalf("get(%s) = %v, %v", id, ok, err)
+
+			}
+
+			if found.Status == "done" {
+
+				break
+
+			}
+
+			if found.Status == "failed" || time.Now().After(deadline) {
+
+				t.Fatalf("job %s is %s: %s", id, found.Status, found.Error)
+
+			}
+
+			time.Sleep(10 * time.Millisecond)
+
+		}
+
+		if _, ok, err := store.Get(id + ".results.json"); err != nil || !ok {
+
+			t.Errorf("results of job %s are missing: %v", id, err)
+
+		}
+
+	}
+
+}
+
+// Events of a job follow the worker's updates until the job is done
+
+func TestJobEvents(t *testing.T) {
+
+	store, _ := openJobStore("memory")
+
+	queue := &jobQueue{store: store, pending: make(chan *job, 1)}
+
+	submitted, err := queue.submit([]string{"一", "二", "三"})
+
+	if err != nil {
+
+		t.Fatal(err)
+
+	}
+
+	server := httptest.NewServer(newJobHandler(queue))
+
+	defer server.Close()
+
+	response, err := http.Get(server.URL + "/jobs/" + submitted.ID + "/events")
+
+	if err != nil {
+
+		t.Fatal(err)
+
+	}
+
+	defer response.Body.Close()
+
+	if contentType := response.Header.Get("Content-Type"); contentType != "text/event-stream" {
+
+		t.Fatalf("Content-Type = %q, want text/event-stream", contentType)
+
+	}
+
+	events := bufio.NewScanner(response.Body)
+
+	next := func() job {
+
+		for events.Scan() {
+
+			if data, ok := strings.CutPrefix(events.Text(), "data: "); ok {
+
+				var event job
+
+				if err := json.Unmarshal([]byte(data), &event); err != nil {
+
+					t.Fatal(err)
+
+				}
+
+				return event
+
+			}
+
+		}
+
+		t.Fatalf("event stream ended early: %v", events.Err())
+
+		return job{}
+
+	}
+
+	if event := next(); event.Status != "queued" {
+
+		t.Fatalf("first event has status %q, want queued", event.Status)
+
+	}
+
+	// Play the worker: each state is published only after the stream has sent the previous one
+
+	running := <-queue.pending
+
+	running.Status = "running"
+
+	queue.saveOrLog(running)
+
+	if event := next(); event.Status != "running" || event.LinesDone != 0 {
+
+		t.Errorf("second event = %+v, want running with 0 lines done", event)
+
+	}
+
+	running.LinesDone = 2
+
+	queue.saveOrLog(running)
+
+	if event := next(); event.LinesDone != 2 {
+
+		t.Errorf("third event reports %d lines done, want 2", event.LinesDone)
+
+	}
+
+	running.Status, running.LinesDone = "done", 3
+
+	queue.saveOrLog(running)
+
+	if event := next(); event.Status != "done" || event.LinesDone != 3 {
+
+		t.Errorf("last event = %+v, want done with 3 lines", event)
+
+	}
+
+	for events.Scan() {
+
+		if events.Text() != "" {
+
+			t.Errorf("stream went on after the job was done: %q", events.Text())
+
+		}
+
+	}
+
+	missing, err := http.Get(server.URL + "/jobs/0123456789abcdef/events")
+
+	if err != nil {
+
+		t.Fatal(err)
+
+	}
+
+	missing.Body.Close()
+
+	if missing.StatusCode != http.StatusNotFound {
+
+		t.Errorf("events of an unknown job answered %d, want 404", missing.StatusCode)
+
+	}
+
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+
+	"fmt"
+
+	"io"
+
+	"net/http"
+
+	"os"
+
+	"path/filepath"
+
+	"strings"
+
+	"time"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+)
+
+// Reads the --slang lexicon from a file or an http(s) URL. URLs are fetched on every run so published
+
+// updates apply immediately; the last good copy is kept in the user cache directory for offline runs.
+
+// In a dry run (writes non-nil) the cache is left as it is and recorded as a file the run would write.
+
+// A fallback to the cache is reported to messages.
+
+func loadSlangLexicon(source string, writes *dryRunWrites, messages io.Writer) ([]classifier.SlangTerm, error) {
+
+	if source == "" {
+
+		return nil, nil
+
+	}
+
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+
+		file, err := os.Open(source)
+
+		if err != nil {
+
+			return nil, fmt.Errorf("failed to open slang lexicon: %v", err)
+
+		}
+
+		defer file.Close()
+
+		terms, err := classifier.ParseSlang(file)
+
+		if err != nil {
+
+			return nil, fmt.Errorf("invalid slang lexicon %s: %v", source, err)
+
+		}
+
+		return terms, nil
+
+	}
+
+	cacheDir, err := os.UserCacheDir()
+
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to locate cache directory: %v", err)
+
+	}
+
+	cachePath := filepath.Join(cacheDir, "cwClassifier", "slang.txt")
+
+	data, fetchErr := fetchSlangLexicon(source)
+
+	if fetchErr != nil {
+
+		cached, err := os.ReadFile(cachePath)
+
+		if err != nil {
+
+			return nil, fetchErr
+
+		}
+
+		fmt.Fprintf(messages, "Using cached slang lexicon (%v)\n", fetchErr)
+
+		data = cached
+
+	}
+
+	terms, err := classifier.ParseSlang(bytes.NewReader(data))
+
+	if err != nil {
+
+		return nil, fmt.Errorf("invalid slang lexicon %s: %v", source, err)
+
+	}
+
+	if fetchErr == nil && writes != nil {
+
+		writes.add(cachePath)
+
+	} else if fetchErr == nil {
+
+		if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err == nil {
+
+			os.WriteFile(cachePath, data, 0644)
+
+		}
+
+	}
+
+	return terms, nil
+
+}
+
+// Downloads a slang lexicon
+
+func fetchSlangLexicon(url string) ([]byte, error) {
+
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	response, err := client.Get(url)
+
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to fetch slang lexicon: %v", err)
+
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+
+		return nil, fmt.Errorf("failed to fetch slang lexicon: %s", response.Status)
+
+	}
+
+	data, err := io.ReadAll(response.Body)
+
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to fetch slang lexicon: %v", err)
+
+	}
+
+	return data, nil
+
+}
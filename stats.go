@@ -0,0 +1,400 @@
+package main
+
+import (
+	"fmt"
+
+	"math"
+
+	"sort"
+
+	"strings"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+)
+
+// Statistics that can be added next to raw counts with --stats
+
+var frequencyStatistics = []string{"per10k", "range", "dispersion", "dp"}
+
+// Orders for category items: by raw frequency, or by frequency discounted for uneven dispersion
+
+var rankOrders = []string{"frequency", "dispersion"}
+
+// Normalized frequency of one item: rate per 10,000 tokens, and in how many sections it occurs
+
+type itemStats struct {
+	Per10k float64 // Occurrences per 10,000 Chinese tokens (characters for ChineseCharacters)
+
+	Range int // Sections containing the item
+
+	Dispersion float64 // Range as a share of all sections
+
+	DP float64 // Gries' deviation of proportions: 0 for an even spread over the sections, near 1 for one spike
+
+}
+
+// Parses a comma-separated --stats value into the set of enabled statistics
+
+func parseStatistics(value string) (map[string]bool, error) {
+
+	enabled := make(map[string]bool)
+
+	for _, name := range strings.Split(value, ",") {
+
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		if name == "" {
+
+			continue
+
+		}
+
+		if !matchesPhraseList(name, frequencyStatistics) {
+
+			return nil, fmt.Errorf("unknown statistic %q (available: %s)", name, strings.Join(frequencyStatistics, ", "))
+
+		}
+
+		enabled[name] = true
+
+	}
+
+	return enabled, nil
+
+}
+
+// Groups aligned lines into sections: the documents of a batch (sizes gives their line counts),
+
+// otherwise the non-empty lines of the input
+
+func splitSections(lines []alignedLine, sizes []int) [][]alignedLine {
+
+	total := 0
+
+	for _, size := range sizes {
+
+		total += size
+
+	}
+
+	var sections [][]alignedLine
+
+	if total == len(lines) && len(sizes) > 0 {
+
+		start := 0
+
+		for _, size := range sizes {
+
+			sections = append(sections, lines[start:start+size])
+
+			start += size
+
+		}
+
+		return sections
+
+	}
+
+	for i, line := range lines {
+
+		if len(line.Tokens) > 0 {
+
+			sections = append(sections, lines[i:i+1])
+
+		}
+
+	}
+
+	return sections
+
+}
+
+// Counts every ranked item per section. Words and characters are counted from the tokens; phrases and
+
+// abbreviations, which are not single tokens, are counted as substrings of the section text.
+
+func sectionCounts(sections [][]alignedLine, c *classifier.Classifier, ranked map[string][]classifier.ItemFrequency) []map[string]map[string]int {
+
+	counts := make([]map[string]map[string]int, len(sections))
+
+	for i, section := range sections {
+
+		counts[i] = make(map[string]map[string]int)
+
+		add := func(category, item string, n int) {
+
+			if counts[i][category] == nil {
+
+				counts[i][category] = make(map[string]int)
+
+			}
+
+			counts[i][category][item] += n
+
+		}
+
+		var text strings.Builder
+
+		for _, line := range section {
+
+			for _, aligned := range line.Tokens {
+
+				tok := aligned.Token
+
+				text.WriteString(tok.Text)
+
+				if !classifier.IsChineseText(tok.Text) {
+
+					continue
+
+				}
+
+				for _, r := range tok.Text {
+
+					add("ChineseCharacters", string(r), 1)
+
+				}
+
+				for _, category := range c.TokenCategories(tok) {
+
+					add(category, tok.Text, 1)
+
+				}
+
+			}
+
+			text.WriteString("\n")
+
+		}
+
+		for _, category := range []string{"ChineseAbbreviations", "ChineseCommonPhrases", "ChineseNounPhrases", "ChineseVerbPhrases"} {
+
+			for _, entry := range ranked[category] {
+
+				if n := strings.Count(text.String(), strings.ReplaceAll(entry.Item, " ", "")); n > 0 {
+
+					add(category, entry.Item, n)
+
+				}
+
+			}
+
+		}
+
+	}
+
+	return counts
+
+}
+
+// Computes the normalized statistics of every ranked item
+
+func computeItemStats(lines []alignedLine, sizes []int, c *classifier.Classifier, ranked map[string][]classifier.ItemFrequency) map[string]map[string]itemStats {
+
+	sections := splitSections(lines, sizes)
+
+	counts := sectionCounts(sections, c, ranked)
+
+	// Expected share of each section is its share of the tokens (characters for ChineseCharacters)
+
+	sectionTokens := make([]float64, len(sections))
+
+	sectionCharacters := make([]float64, len(sections))
+
+	for i, section := range sections {
+
+		for _, line := range section {
+
+			for _, aligned := range line.Tokens {
+
+				if classifier.IsChineseText(aligned.Token.Text) {
+
+					sectionTokens[i]++
+
+					sectionCharacters[i] += float64(len([]rune(aligned.Token.Text)))
+
+				}
+
+			}
+
+		}
+
+	}
+
+	tokens, characters := 0, 0
+
+	for _, line := range lines {
+
+		for _, aligned := range line.Tokens {
+
+			if classifier.IsChineseText(aligned.Token.Text) {
+
+				tokens++
+
+				characters += len([]rune(aligned.Token.Text))
+
+			}
+
+		}
+
+	}
+
+	stats := make(map[string]map[string]itemStats)
+
+	for category, entries := range ranked {
+
+		stats[category] = make(map[string]itemStats)
+
+		total, sizes := tokens, sectionTokens
+
+		if category == "ChineseCharacters" {
+
+			total, sizes = characters, sectionCharacters
+
+		}
+
+		for _, entry := range entries {
+
+			s := itemStats{}
+
+			if total > 0 {
+
+				s.Per10k = float64(entry.Frequency) * 10000 / float64(total)
+
+			}
+
+			for _, section := range counts {
+
+				if section[category][entry.Item] > 0 {
+
+					s.Range++
+
+				}
+
+			}
+
+			if len(sections) > 0 {
+
+				s.Dispersion = float64(s.Range) / float64(len(sections))
+
+			}
+
+			s.DP = deviationOfProportions(counts, category, entry.Item, sizes, total)
+
+			stats[category][entry.Item] = s
+
+		}
+
+	}
+
+	return stats
+
+}
+
+// Gries' DP: half the summed differences between each section's share of the item's occurrences and its
+
+// share of the corpus
+
+func deviationOfProportions(counts []map[string]map[string]int, category, item string, sizes []float64, total int) float64 {
+
+	occurrences := 0
+
+	for _, section := range counts {
+
+		occurrences += section[category][item]
+
+	}
+
+	if occurrences == 0 || total == 0 {
+
+		return 0
+
+	}
+
+	dp := 0.0
+
+	for i, section := range counts {
+
+		observed := float64(section[category][item]) / float64(occurrences)
+
+		expected := sizes[i] / float64(total)
+
+		dp += math.Abs(observed - expected)
+
+	}
+
+	return dp / 2
+
+}
+
+// Reorders every category by frequency × (1 − DP), so items spread over the corpus outrank items
+
+// concentrated in one document; ties keep the item order
+
+func rankByDispersion(ranked map[string][]classifier.ItemFrequency, stats map[string]map[string]itemStats) {
+
+	for category, entries := range ranked {
+
+		score := func(entry classifier.ItemFrequency) float64 {
+
+			return float64(entry.Frequency) * (1 - stats[category][entry.Item].DP)
+
+		}
+
+		sort.SliceStable(entries, func(i, j int) bool {
+
+			si, sj := score(entries[i]), score(entries[j])
+
+			if si != sj {
+
+				return si > sj
+
+			}
+
+			return entries[i].Item < entries[j].Item
+
+		})
+
+	}
+
+}
+
+// Formats the enabled statistics of an item as tab-separated columns, in the order of frequencyStatistics
+
+func statColumns(s itemStats, enabled map[string]bool) string {
+
+	var columns strings.Builder
+
+	for _, name := range frequencyStatistics {
+
+		if !enabled[name] {
+
+			continue
+
+		}
+
+		switch name {
+
+		case "per10k":
+
+			fmt.Fprintf(&columns, "\t%.2f", s.Per10k)
+
+		case "range":
+
+			fmt.Fprintf(&columns, "\t%d", s.Range)
+
+		case "dispersion":
+
+			fmt.Fprintf(&columns, "\t%.3f", s.Dispersion)
+
+		case "dp":
+
+			fmt.Fprintf(&columns, "\t%.3f", s.DP)
+
+		}
+
+	}
+
+	return columns.String()
+
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"flag"
+
+	"fmt"
+
+	"io"
+
+	"os"
+
+	"path/filepath"
+
+	"strconv"
+
+	"strings"
+
+	"unicode"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+)
+
+// Handles "cwClassifier stats [--profile fast|full] [--stages pos,...] file...": analyzes a text, or several as
+
+// one, without writing any files and prints its size, vocabulary and the size of every category
+
+func runStatsCommand(args []string) error {
+
+	flags := flag.NewFlagSet("stats", flag.ContinueOnError)
+
+	profile := flags.String("profile", "full", "Stage preset ("+strings.Join(classifier.Profiles(), ", ")+")")
+
+	stagesValue := flags.String("stages", "", "Comma-separated pipeline stages to run instead of the profile")
+
+	inputFormatValue := flags.String("input-format", "auto", "Input format ("+strings.Join(inputFormats, ", ")+")")
+
+	resources := flags.String("resources", os.Getenv(resourceDirVariable), "Directory whose files replace the embedded language resources of the same name")
+
+	if err := flags.Parse(args); err != nil {
+
+		return err
+
+	}
+
+	if flags.NArg() == 0 {
+
+		return fmt.Errorf("usage: cwClassifier stats [--profile fast|full] [--stages pos,...] file...")
+
+	}
+
+	if err := useResourceDir(*resources); err != nil {
+
+		return err
+
+	}
+
+	stages, err := classifier.ProfileStages(*profile)
+
+	if *stagesValue != "" && err == nil {
+
+		stages, err = classifier.ParseStages(*stagesValue)
+
+	}
+
+	if err != nil {
+
+		return err
+
+	}
+
+	inputFormat, err := parseInputFormat(*inputFormatValue)
+
+	if err != nil {
+
+		return err
+
+	}
+
+	var lines []string
+
+	for _, file := range flags.Args() {
+
+		fileLines, _, _, err := readInputLines(file, inputFormat)
+
+		if err != nil {
+
+			return fmt.Errorf("%s: %v", filepath.Base(file), err)
+
+		}
+
+		lines = append(lines, fileLines...)
+
+	}
+
+	c, err := classifier.New(classifier.WithStages(stages...))
+
+	if err != nil {
+
+		return err
+
+	}
+
+	result, err := c.Classify(strings.Join(lines, "\n"))
+
+	if err != nil {
+
+		return err
+
+	}
+
+	printTextStats(os.Stdout, result, c.Categories())
+
+	return nil
+
+}
+
+// Prints the Han characters, words and sentences of a classified text, then how many distinct items and
+
+// occurrences every category holds
+
+func printTextStats(w io.Writer, result *classifier.Result, categories []string) {
+
+	characters, words := 0, 0
+
+	distinctCharacters, distinctWords := make(map[rune]bool), make(map[string]bool)
+
+	for _, token := range result.Tokens {
+
+		han := 0
+
+		for _, r := range token.Text {
+
+			if unicode.Is(unicode.Han, r) {
+
+				han++
+
+				distinctCharacters[r] = true
+
+			}
+
+		}
+
+		if han > 0 {
+
+			characters += han
+
+			words++
+
+			distinctWords[token.Text] = true
+
+		}
+
+	}
+
+	ratio := func(a, b int) string {
+
+		if b == 0 {
+
+			return "0"
+
+		}
+
+		return strconv.FormatFloat(float64(a)/float64(b), 'f', 2, 64)
+
+	}
+
+	writeTable(w, []string{"measure", "value"}, [][]string{
+
+		{"Han characters", strconv.Itoa(characters)},
+
+		{"distinct characters", strconv.Itoa(len(distinctCharacters))},
+
+		{"words", strconv.Itoa(words)},
+
+		{"distinct words", strconv.Itoa(len(distinctWords))},
+
+		{"type-token ratio", ratio(len(distinctWords), words)},
+
+		{"sentences", strconv.Itoa(len(result.Sentences))},
+
+		{"characters per sentence", ratio(characters, len(result.Sentences))},
+	}, []bool{false, true})
+
+	fmt.Fprintln(w)
+
+	var rows [][]string
+
+	for _, category := range categories {
+
+		total := 0
+
+		for _, entry := range result.Ranked[category] {
+
+			total += entry.Frequency
+
+		}
+
+		rows = append(rows, []string{category, strconv.Itoa(len(result.Ranked[category])), strconv.Itoa(total)})
+
+	}
+
+	writeTable(w, []string{"category", "distinct", "total"}, rows, []bool{false, true, true})
+
+}
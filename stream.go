@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/json"
+
+	"fmt"
+
+	"io"
+
+	"os"
+
+	"path/filepath"
+
+	"strings"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+)
+
+// Items per category included in each progress snapshot
+
+const progressItems = 50
+
+// Snapshot of a long run, rewritten after every chunk so early results can be inspected
+
+type progressSnapshot struct {
+	LinesDone int `json:"lines_done"`
+
+	LinesTotal int `json:"lines_total"`
+
+	Done bool `json:"done"`
+
+	Categories map[string][]jsonItem `json:"categories"` // Most frequent items so far
+
+}
+
+// Classifies the lines in chunks of every lines, merging the results and writing a progress.json
+
+// snapshot to outputDir after each chunk; progress lines go to messages
+
+func classifyIncrementally(c *classifier.Classifier, lines []string, every int, outputDir string, messages io.Writer) (*classifier.Result, error) {
+
+	return classifyInChunks(c, lines, every, func(done, total int, ranked map[string][]classifier.ItemFrequency) error {
+
+		if err := writeProgress(filepath.Join(outputDir, "progress.json"), done, total, ranked); err != nil {
+
+			return err
+
+		}
+
+		fmt.Fprintf(messages, "Processed %d of %d lines\n", done, total)
+
+		return nil
+
+	})
+
+}
+
+// Classifies the lines in chunks of every lines, merging the results and reporting them after each chunk.
+
+// Chunks are joined the same way as a single pass, so the merged result only differs where a phrase would
+
+// span a chunk boundary.
+
+func classifyInChunks(c *classifier.Classifier, lines []string, every int, report func(done, total int, ranked map[string][]classifier.ItemFrequency) error) (*classifier.Result, error) {
+
+	merged := &classifier.Result{Items: make(map[string][]string), Ranked: make(map[string][]classifier.ItemFrequency)}
+
+	for start := 0; start < len(lines); start += every {
+
+		end := min(start+every, len(lines))
+
+		result, err := c.Classify(strings.Join(lines[start:end], "\n") + "\n")
+
+		if err != nil {
+
+			return nil, err
+
+		}
+
+		merged.Tokens = append(merged.Tokens, result.Tokens...)
+
+		merged.Quotes = append(merged.Quotes, result.Quotes...)
+
+		for item, count := range result.Suppressed {
+
+			if merged.Suppressed == nil {
+
+				merged.Suppressed = make(map[string]int)
+
+			}
+
+			merged.Suppressed[item] += count
+
+		}
+
+		for _, category := range c.Categories() {
+
+			merged.Items[category] = append(merged.Items[category], result.Items[category]...)
+
+			merged.Ranked[category] = classifier.RankByFrequency(classifier.CountFrequencies(merged.Items[category]))
+
+			for item, surfaces := range result.Forms[category] {
+
+				if merged.Forms == nil {
+
+					merged.Forms = make(map[string]map[string]map[string]int)
+
+				}
+
+				if merged.Forms[category] == nil {
+
+					merged.Forms[category] = make(map[string]map[string]int)
+
+				}
+
+				if merged.Forms[category][item] == nil {
+
+					merged.Forms[category][item] = make(map[string]int)
+
+				}
+
+				for surface, count := range surfaces {
+
+					merged.Forms[category][item][surface] += count
+
+				}
+
+			}
+
+		}
+
+		if err := report(end, len(lines), merged.Ranked); err != nil {
+
+			return nil, err
+
+		}
+
+	}
+
+	return merged, nil
+
+}
+
+// Writes the snapshot to a temporary file first, so readers never see a partly written one
+
+func writeProgress(path string, done, total int, ranked map[string][]classifier.ItemFrequency) error {
+
+	snapshot := progressSnapshot{LinesDone: done, LinesTotal: total, Done: done == total, Categories: make(map[string][]jsonItem)}
+
+	for category, entries := range ranked {
+
+		items := []jsonItem{}
+
+		for i, entry := range entries {
+
+			if i >= progressItems {
+
+				break
+
+			}
+
+			items = append(items, jsonItem{Item: entry.Item, Frequency: entry.Frequency})
+
+		}
+
+		snapshot.Categories[category] = items
+
+	}
+
+	data, err := json.Marshal(snapshot)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to encode progress: %v", err)
+
+	}
+
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+
+		return fmt.Errorf("failed to write progress: %v", err)
+
+	}
+
+	return os.Rename(path+".tmp", path)
+
+}
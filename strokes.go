@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bufio"
+
+	"fmt"
+
+	"sort"
+
+	"strconv"
+
+	"strings"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+
+	"golang.org/x/text/encoding"
+)
+
+// Orders ChineseCharacters accepts with --sort-characters
+
+var characterOrders = []string{"frequency", "strokes"}
+
+// Characters listed per stroke count in the distribution report
+
+const strokeReportExamples = 10
+
+// Stroke count of a single character, 0 when unknown
+
+func characterStrokes(character string) int {
+
+	runes := []rune(character)
+
+	if len(runes) != 1 {
+
+		return 0
+
+	}
+
+	strokes, _ := classifier.StrokeCount(runes[0])
+
+	return strokes
+
+}
+
+// Orders characters by stroke count, most frequent first within a count; characters of unknown stroke
+
+// count come last
+
+func sortByStrokes(characters []classifier.ItemFrequency) []classifier.ItemFrequency {
+
+	sorted := append([]classifier.ItemFrequency{}, characters...)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+
+		a, b := characterStrokes(sorted[i].Item), characterStrokes(sorted[j].Item)
+
+		if a == 0 || b == 0 {
+
+			return a != 0 && b == 0
+
+		}
+
+		return a < b
+
+	})
+
+	return sorted
+
+}
+
+// Writes ChineseStrokeCounts.txt: how many distinct characters and occurrences have each stroke count,
+
+// with the average stroke count of the text
+
+func writeStrokeReport(path string, characters []classifier.ItemFrequency, enc encoding.Encoding) error {
+
+	distinct := make(map[int]int)
+
+	occurrences := make(map[int]int)
+
+	examples := make(map[int][]string)
+
+	total, strokesTotal := 0, 0
+
+	for _, entry := range characters {
+
+		strokes := characterStrokes(entry.Item)
+
+		distinct[strokes]++
+
+		occurrences[strokes] += entry.Frequency
+
+		total += entry.Frequency
+
+		strokesTotal += strokes * entry.Frequency
+
+		if len(examples[strokes]) < strokeReportExamples {
+
+			examples[strokes] = append(examples[strokes], entry.Item)
+
+		}
+
+	}
+
+	var counts []int
+
+	for strokes := range distinct {
+
+		counts = append(counts, strokes)
+
+	}
+
+	sort.Ints(counts)
+
+	file, err := createOutputFile(path, enc)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to create stroke count report: %v", err)
+
+	}
+
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+
+	var rows [][]string
+
+	for _, strokes := range counts {
+
+		label := strconv.Itoa(strokes)
+
+		if strokes == 0 {
+
+			label = "?"
+
+		}
+
+		share := float64(occurrences[strokes]) * 100 / float64(total)
+
+		rows = append(rows, []string{label, strconv.Itoa(distinct[strokes]), strconv.Itoa(occurrences[strokes]), strconv.FormatFloat(share, 'f', 1, 64) + "%", strings.Join(examples[strokes], " ")})
+
+	}
+
+	writeTable(writer, []string{"strokes", "characters", "occurrences", "share", "most frequent"}, rows, []bool{true, true, true, true, false})
+
+	if known := total - occurrences[0]; known > 0 {
+
+		fmt.Fprintf(writer, "\nAverage strokes per character: %.2f\n", float64(strokesTotal)/float64(known))
+
+	}
+
+	return writer.Flush()
+
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"bufio"
+
+	"fmt"
+
+	"regexp"
+
+	"sort"
+
+	"strconv"
+
+	"strings"
+
+	"unicode/utf8"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+
+	"golang.org/x/text/encoding"
+)
+
+// Speaker tag leading a subtitle or script line: （老王）, (老王), [老王], 【老王】 or 老王：, after an optional dash
+
+var speakerTagPattern = regexp.MustCompile(`^[-–—]?\s*(?:（([^）]{1,12})）|\(([^)]{1,12})\)|\[([^\]]{1,12})\]|【([^】]{1,12})】|([\p{Han}\p{L}\p{N}·]{1,8})\s*[：:])\s*`)
+
+// Characteristic words listed per speaker
+
+const speakerTopWords = 10
+
+// Uses a word needs to be characteristic of a speaker
+
+const minCharacteristicUses = 2
+
+// Lines spoken by one speaker
+
+type speakerLines struct {
+	speaker string
+
+	lines []string
+}
+
+// Removes the speaker tags of lines, returning the lines without them and each tagged speaker's lines in order
+
+// of their first line; lines without a tag are grouped under "?"
+
+func splitSpeakers(lines []string) ([]string, []speakerLines) {
+
+	stripped := make([]string, len(lines))
+
+	index := make(map[string]int)
+
+	var speakers []speakerLines
+
+	for i, line := range lines {
+
+		speaker := "?"
+
+		text := line
+
+		if match := speakerTagPattern.FindStringSubmatch(line); match != nil {
+
+			for _, name := range match[1:] {
+
+				if name != "" {
+
+					speaker = strings.TrimSpace(name)
+
+				}
+
+			}
+
+			text = line[len(match[0]):]
+
+		}
+
+		stripped[i] = text
+
+		if strings.TrimSpace(text) == "" {
+
+			continue
+
+		}
+
+		if _, ok := index[speaker]; !ok {
+
+			index[speaker] = len(speakers)
+
+			speakers = append(speakers, speakerLines{speaker: speaker})
+
+		}
+
+		speakers[index[speaker]].lines = append(speakers[index[speaker]].lines, text)
+
+	}
+
+	return stripped, speakers
+
+}
+
+// Vocabulary of one speaker
+
+type speakerVocabulary struct {
+	speaker string
+
+	lines int
+
+	characters int
+
+	words map[string]int
+
+	tokens int
+}
+
+// Writes ChineseSpeakers.txt: for each speaker of a subtitle or script, their lines, words, vocabulary size and
+
+// most used words, then the words characteristic of each, used at least minCharacteristicUses times and far more
+
+// often by them than by everyone else
+
+func writeSpeakerReport(path string, c *classifier.Classifier, speakers []speakerLines, enc encoding.Encoding) error {
+
+	var vocabularies []speakerVocabulary
+
+	total := make(map[string]int)
+
+	totalTokens := 0
+
+	for _, speaker := range speakers {
+
+		// Each line is its own sentence, so no phrase runs from one into the next
+
+		spoken, err := c.Classify(strings.Join(speaker.lines, "。\n"))
+
+		if err != nil {
+
+			return fmt.Errorf("failed to classify the lines of %s: %v", speaker.speaker, err)
+
+		}
+
+		vocabulary := speakerVocabulary{speaker: speaker.speaker, lines: len(speaker.lines), words: make(map[string]int)}
+
+		for _, line := range speaker.lines {
+
+			vocabulary.characters += utf8.RuneCountInString(line)
+
+		}
+
+		for _, category := range vocabularyCategories {
+
+			for _, entry := range spoken.Ranked[category] {
+
+				if !containsChinese(entry.Item) {
+
+					continue
+
+				}
+
+				vocabulary.words[entry.Item] += entry.Frequency
+
+				vocabulary.tokens += entry.Frequency
+
+				total[entry.Item] += entry.Frequency
+
+				totalTokens += entry.Frequency
+
+			}
+
+		}
+
+		vocabularies = append(vocabularies, vocabulary)
+
+	}
+
+	sort.SliceStable(vocabularies, func(i, j int) bool { return vocabularies[i].lines > vocabularies[j].lines })
+
+	file, err := createOutputFile(path, enc)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to create speaker report: %v", err)
+
+	}
+
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+
+	fmt.Fprintln(writer, "Speakers")
+
+	var rows [][]string
+
+	for _, vocabulary := range vocabularies {
+
+		var top []string
+
+		for i, entry := range classifier.RankByFrequency(vocabulary.words) {
+
+			if i == speakerTopWords {
+
+				break
+
+			}
+
+			top = append(top, fmt.Sprintf("%s(%d)", entry.Item, entry.Frequency))
+
+		}
+
+		ratio := 0.0
+
+		if vocabulary.tokens > 0 {
+
+			ratio = float64(len(vocabulary.words)) / float64(vocabulary.tokens)
+
+		}
+
+		rows = append(rows, []string{vocabulary.speaker, strconv.Itoa(vocabulary.lines), strconv.Itoa(vocabulary.characters / max(vocabulary.lines, 1)), strconv.Itoa(vocabulary.tokens), strconv.Itoa(len(vocabulary.words)), fmt.Sprintf("%.2f", ratio), strings.Join(top, " ")})
+
+	}
+
+	writeTable(writer, []string{"speaker", "lines", "characters per line", "words", "distinct", "type-token ratio", "most used"}, rows, []bool{false, true, true, true, true, true, false})
+
+	fmt.Fprintln(writer, "\nCharacteristic words (times as frequent as in everyone else's lines; + for words no one else uses)")
+
+	rows = nil
+
+	for _, vocabulary := range vocabularies {
+
+		others := totalTokens - vocabulary.tokens
+
+		type characteristic struct {
+			word string
+
+			ratio float64
+		}
+
+		var found []characteristic
+
+		for word, uses := range vocabulary.words {
+
+			if uses < minCharacteristicUses {
+
+				continue
+
+			}
+
+			otherUses := total[word] - uses
+
+			if otherUses == 0 {
+
+				found = append(found, characteristic{word, -float64(uses)})
+
+				continue
+
+			}
+
+			if ratio := float64(uses) / float64(vocabulary.tokens) / (float64(otherUses) / float64(others)); ratio >= 2 {
+
+				found = append(found, characteristic{word, ratio})
+
+			}
+
+		}
+
+		// Words only this speaker uses come first, most used first, then the others by ratio
+
+		sort.Slice(found, func(i, j int) bool {
+
+			if (found[i].ratio < 0) != (found[j].ratio < 0) {
+
+				return found[i].ratio < 0
+
+			}
+
+			if found[i].ratio < 0 {
+
+				if found[i].ratio != found[j].ratio {
+
+					return found[i].ratio < found[j].ratio
+
+				}
+
+				return found[i].word < found[j].word
+
+			}
+
+			if found[i].ratio != found[j].ratio {
+
+				return found[i].ratio > found[j].ratio
+
+			}
+
+			return found[i].word < found[j].word
+
+		})
+
+		var listed []string
+
+		for i, entry := range found {
+
+			if i == speakerTopWords {
+
+				break
+
+			}
+
+			if entry.ratio < 0 {
+
+				listed = append(listed, fmt.Sprintf("%s(%d, +)", entry.word, vocabulary.words[entry.word]))
+
+			} else {
+
+				listed = append(listed, fmt.Sprintf("%s(%d, ×%.1f)", entry.word, vocabulary.words[entry.word], entry.ratio))
+
+			}
+
+		}
+
+		rows = append(rows, []string{vocabulary.speaker, strings.Join(listed, " ")})
+
+	}
+
+	writeTable(writer, []string{"speaker", "characteristic words"}, rows, []bool{false, false})
+
+	return writer.Flush()
+
+}
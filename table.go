@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+
+	"io"
+
+	"strconv"
+
+	"strings"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+)
+
+// Items per category shown by --format table
+
+const tableTopItems = 10
+
+// Writes rows as columns aligned by display width, so full-width characters line up; numeric columns are
+
+// right-aligned. A header, if given, gets a rule under it.
+
+func writeTable(w io.Writer, header []string, rows [][]string, numeric []bool) {
+
+	widths := make([]int, len(numeric))
+
+	for _, row := range append([][]string{header}, rows...) {
+
+		for i, cell := range row {
+
+			widths[i] = max(widths[i], classifier.DisplayWidth(cell))
+
+		}
+
+	}
+
+	writeRow := func(row []string) {
+
+		cells := make([]string, len(row))
+
+		for i, cell := range row {
+
+			if numeric[i] {
+
+				cells[i] = classifier.PadLeft(cell, widths[i])
+
+			} else {
+
+				cells[i] = classifier.PadRight(cell, widths[i])
+
+			}
+
+		}
+
+		fmt.Fprintln(w, strings.TrimRight(strings.Join(cells, "  "), " "))
+
+	}
+
+	if header != nil {
+
+		writeRow(header)
+
+		rules := make([]string, len(header))
+
+		for i := range header {
+
+			rules[i] = strings.Repeat("-", widths[i])
+
+		}
+
+		fmt.Fprintln(w, strings.Join(rules, "  "))
+
+	}
+
+	for _, row := range rows {
+
+		writeRow(row)
+
+	}
+
+}
+
+// Prints the most frequent items of every non-empty category as console tables
+
+func printCategoryTables(w io.Writer, categories []string, ranked map[string][]classifier.ItemFrequency) {
+
+	for _, category := range categories {
+
+		entries := ranked[category]
+
+		if len(entries) == 0 {
+
+			continue
+
+		}
+
+		total := 0
+
+		for _, entry := range entries {
+
+			total += entry.Frequency
+
+		}
+
+		fmt.Fprintf(w, "\n%s (%d distinct, %d total)\n", category, len(entries), total)
+
+		var rows [][]string
+
+		for i, entry := range entries[:min(len(entries), tableTopItems)] {
+
+			rows = append(rows, []string{strconv.Itoa(i + 1), entry.Item, strconv.Itoa(entry.Frequency), fmt.Sprintf("%.1f%%", float64(entry.Frequency)*100/float64(total))})
+
+		}
+
+		writeTable(w, []string{"#", "Item", "Count", "Share"}, rows, []bool{true, false, true, true})
+
+	}
+
+}
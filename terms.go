@@ -0,0 +1,310 @@
+package main
+
+import (
+	"encoding/csv"
+
+	"encoding/xml"
+
+	"fmt"
+
+	"math"
+
+	"os"
+
+	"path/filepath"
+
+	"sort"
+
+	"strconv"
+
+	"strings"
+
+	"golang.org/x/text/encoding"
+)
+
+// Candidates seen fewer times than this are too rare to score reliably
+
+const minTermFrequency = 2
+
+// Longest candidate considered, in words
+
+const maxTermWords = 5
+
+// Candidate term with its corpus frequency and C-value termhood score
+
+type termCandidate struct {
+	Term string
+
+	Words int
+
+	Frequency int
+
+	CValue float64
+}
+
+// Scores noun-phrase sub-sequences with C-value, penalizing candidates that mostly occur nested in longer ones
+
+func extractTerms(nounPhrases []string) []termCandidate {
+
+	frequencies := make(map[string]int)
+
+	lengths := make(map[string]int)
+
+	for _, phrase := range nounPhrases {
+
+		words := strings.Fields(phrase)
+
+		for start := 0; start < len(words); start++ {
+
+			for end := start + 1; end <= len(words) && end-start <= maxTermWords; end++ {
+
+				candidate := strings.Join(words[start:end], " ")
+
+				frequencies[candidate]++
+
+				lengths[candidate] = end - start
+
+			}
+
+		}
+
+	}
+
+	// For every candidate, collect the frequencies of the longer candidates that contain it
+
+	nestedIn := make(map[string][]int)
+
+	for candidate, frequency := range frequencies {
+
+		if frequency < minTermFrequency {
+
+			continue
+
+		}
+
+		words := strings.Fields(candidate)
+
+		seen := make(map[string]bool)
+
+		for start := 0; start < len(words); start++ {
+
+			for end := start + 1; end <= len(words); end++ {
+
+				nested := strings.Join(words[start:end], " ")
+
+				if end-start == len(words) || seen[nested] {
+
+					continue
+
+				}
+
+				seen[nested] = true
+
+				nestedIn[nested] = append(nestedIn[nested], frequency)
+
+			}
+
+		}
+
+	}
+
+	var terms []termCandidate
+
+	for candidate, frequency := range frequencies {
+
+		if frequency < minTermFrequency || len([]rune(strings.ReplaceAll(candidate, " ", ""))) < 2 {
+
+			continue
+
+		}
+
+		// log2(|a|+1) keeps single-word compounds, which dominate segmented Chinese, from scoring zero
+
+		weight := math.Log2(float64(lengths[candidate]) + 1)
+
+		score := float64(frequency)
+
+		if containers := nestedIn[candidate]; len(containers) > 0 {
+
+			sum := 0
+
+			for _, f := range containers {
+
+				sum += f
+
+			}
+
+			score -= float64(sum) / float64(len(containers))
+
+		}
+
+		if score <= 0 {
+
+			continue
+
+		}
+
+		terms = append(terms, termCandidate{
+
+			Term: strings.ReplaceAll(candidate, " ", ""),
+
+			Words: lengths[candidate],
+
+			Frequency: frequency,
+
+			CValue: weight * score,
+		})
+
+	}
+
+	sort.Slice(terms, func(i, j int) bool {
+
+		if terms[i].CValue != terms[j].CValue {
+
+			return terms[i].CValue > terms[j].CValue
+
+		}
+
+		return terms[i].Term < terms[j].Term
+
+	})
+
+	return terms
+
+}
+
+// Writes candidate terms as CSV for spreadsheet review
+
+func writeTermsCSV(path string, terms []termCandidate, enc encoding.Encoding) error {
+
+	file, err := createOutputFile(path, enc)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to create terms CSV: %v", err)
+
+	}
+
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+
+	writer.Write([]string{"term", "words", "frequency", "c_value"})
+
+	for _, term := range terms {
+
+		writer.Write([]string{term.Term, strconv.Itoa(term.Words), strconv.Itoa(term.Frequency), strconv.FormatFloat(term.CValue, 'f', 3, 64)})
+
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+
+}
+
+// TBX-Basic document structure, enough for termbase import in CAT tools
+
+type tbxDocument struct {
+	XMLName xml.Name `xml:"martif"`
+
+	Type string `xml:"type,attr"`
+
+	Lang string `xml:"xml:lang,attr"`
+
+	Header tbxHeader `xml:"martifHeader"`
+
+	Entries []tbxEntry `xml:"text>body>termEntry"`
+}
+
+type tbxHeader struct {
+	Source string `xml:"fileDesc>sourceDesc>p"`
+}
+
+type tbxEntry struct {
+	ID string `xml:"id,attr"`
+
+	Descrip tbxDescrip `xml:"descrip"`
+
+	LangSet tbxLangSet `xml:"langSet"`
+}
+
+type tbxDescrip struct {
+	Type string `xml:"type,attr"`
+
+	Value string `xml:",chardata"`
+}
+
+type tbxLangSet struct {
+	Lang string `xml:"xml:lang,attr"`
+
+	Term string `xml:"tig>term"`
+
+	Note tbxNote `xml:"tig>termNote"`
+}
+
+type tbxNote struct {
+	Type string `xml:"type,attr"`
+
+	Value string `xml:",chardata"`
+}
+
+// Writes candidate terms as a TBX-Basic termbase
+
+func writeTermsTBX(path string, terms []termCandidate) error {
+
+	doc := tbxDocument{
+
+		Type: "TBX-Basic",
+
+		Lang: "zh",
+
+		Header: tbxHeader{Source: "Candidate terms extracted by cwClassifier (C-value over noun phrases)"},
+	}
+
+	for i, term := range terms {
+
+		doc.Entries = append(doc.Entries, tbxEntry{
+
+			ID: "t" + strconv.Itoa(i+1),
+
+			Descrip: tbxDescrip{Type: "definition", Value: fmt.Sprintf("frequency=%d; c-value=%.3f", term.Frequency, term.CValue)},
+
+			LangSet: tbxLangSet{Lang: "zh", Term: term.Term, Note: tbxNote{Type: "partOfSpeech", Value: "noun"}},
+		})
+
+	}
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+
+	if err != nil {
+
+		return fmt.Errorf("failed to encode TBX: %v", err)
+
+	}
+
+	return os.WriteFile(path, append([]byte(xml.Header), append(data, '\n')...), 0644)
+
+}
+
+// Writes the term candidates in every supported export format
+
+// The CSV follows the output encoding; TBX is XML and stays UTF-8
+
+func writeTerms(outputDir string, terms []termCandidate, enc encoding.Encoding) error {
+
+	if err := writeTermsCSV(filepath.Join(outputDir, "ChineseTerms.csv"), terms, enc); err != nil {
+
+		return err
+
+	}
+
+	if err := writeTermsTBX(filepath.Join(outputDir, "ChineseTerms.tbx"), terms); err != nil {
+
+		return fmt.Errorf("failed to write TBX termbase: %v", err)
+
+	}
+
+	return nil
+
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bufio"
+
+	"fmt"
+
+	"strconv"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+
+	"golang.org/x/text/encoding"
+)
+
+// Writes ChineseTimeline.txt: each date and time of the text in ISO 8601 form with the sentence it occurs
+
+// in, in text order
+
+func writeTimeline(path string, result *classifier.Result, enc encoding.Encoding) error {
+
+	file, err := createOutputFile(path, enc)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to create timeline: %v", err)
+
+	}
+
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+
+	var rows [][]string
+
+	for _, entry := range classifier.Timeline(result) {
+
+		rows = append(rows, []string{strconv.Itoa(entry.Sentence.ID), entry.ISO, entry.Expression, entry.Sentence.Text})
+
+	}
+
+	writeTable(writer, []string{"sentence", "date", "expression", "text"}, rows, []bool{true, false, false, false})
+
+	return writer.Flush()
+
+}
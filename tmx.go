@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bufio"
+
+	"encoding/xml"
+
+	"fmt"
+
+	"os"
+
+	"strings"
+
+	"unicode"
+)
+
+// Source sentence paired with its translation
+
+type sentencePair struct {
+	Source string
+
+	Translation string
+}
+
+// Checks whether a sentence contains any Chinese characters
+
+func containsChinese(text string) bool {
+
+	for _, r := range text {
+
+		if unicode.Is(unicode.Han, r) {
+
+			return true
+
+		}
+
+	}
+
+	return false
+
+}
+
+// Loads a tab-separated parallel corpus of "source<TAB>translation" lines
+
+func loadParallelCorpus(path string) (map[string]string, error) {
+
+	file, err := os.Open(path)
+
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to open parallel corpus: %v", err)
+
+	}
+
+	defer file.Close()
+
+	translations := make(map[string]string)
+
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+
+		source, translation, ok := strings.Cut(scanner.Text(), "\t")
+
+		if !ok {
+
+			continue
+
+		}
+
+		source = strings.TrimSpace(source)
+
+		translation = strings.TrimSpace(translation)
+
+		if source != "" && translation != "" {
+
+			translations[source] = translation
+
+		}
+
+	}
+
+	if err := scanner.Err(); err != nil {
+
+		return nil, fmt.Errorf("error reading parallel corpus: %v", err)
+
+	}
+
+	return translations, nil
+
+}
+
+// Pairs each distinct Chinese sentence with its known translation, in document order
+
+func pairSentences(sentences []string, translations map[string]string) []sentencePair {
+
+	var pairs []sentencePair
+
+	seen := make(map[string]bool)
+
+	for _, sentence := range sentences {
+
+		if seen[sentence] || !containsChinese(sentence) {
+
+			continue
+
+		}
+
+		seen[sentence] = true
+
+		if translation, ok := translations[sentence]; ok {
+
+			pairs = append(pairs, sentencePair{Source: sentence, Translation: translation})
+
+		}
+
+	}
+
+	return pairs
+
+}
+
+// Fills in translations for Chinese sentences the parallel corpus does not cover
+
+func translateMissingSentences(t translator, sentences []string, translations map[string]string, targetLanguage string) error {
+
+	var missing []string
+
+	for _, sentence := range sentences {
+
+		if _, ok := translations[sentence]; !ok && containsChinese(sentence) {
+
+			missing = append(missing, sentence)
+
+		}
+
+	}
+
+	if len(missing) == 0 {
+
+		return nil
+
+	}
+
+	translated, err := t.Translate(missing, targetLanguage)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to translate sentences: %v", err)
+
+	}
+
+	for i, sentence := range missing {
+
+		translations[sentence] = translated[i]
+
+	}
+
+	return nil
+
+}
+
+// TMX 1.4 document structure
+
+type tmxDocument struct {
+	XMLName xml.Name `xml:"tmx"`
+
+	Version string `xml:"version,attr"`
+
+	Header tmxHeader `xml:"header"`
+
+	Units []tmxUnit `xml:"body>tu"`
+}
+
+type tmxHeader struct {
+	CreationTool string `xml:"creationtool,attr"`
+
+	CreationToolVersion string `xml:"creationtoolversion,attr"`
+
+	SegType string `xml:"segtype,attr"`
+
+	Format string `xml:"o-tmf,attr"`
+
+	AdminLang string `xml:"adminlang,attr"`
+
+	SourceLang string `xml:"srclang,attr"`
+
+	DataType string `xml:"datatype,attr"`
+}
+
+type tmxUnit struct {
+	Variants []tmxVariant `xml:"tuv"`
+}
+
+type tmxVariant struct {
+	Lang string `xml:"xml:lang,attr"`
+
+	Segment string `xml:"seg"`
+}
+
+// Writes sentence pairs as a TMX translation memory
+
+func writeTMX(path string, pairs []sentencePair, targetLanguage string) error {
+
+	doc := tmxDocument{
+
+		Version: "1.4",
+
+		Header: tmxHeader{
+
+			CreationTool: "cwClassifier",
+
+			CreationToolVersion: "1.0",
+
+			SegType: "sentence",
+
+			Format: "plaintext",
+
+			AdminLang: "en",
+
+			SourceLang: "zh",
+
+			DataType: "plaintext",
+		},
+	}
+
+	for _, pair := range pairs {
+
+		doc.Units = append(doc.Units, tmxUnit{Variants: []tmxVariant{
+
+			{Lang: "zh", Segment: pair.Source},
+
+			{Lang: targetLanguage, Segment: pair.Translation},
+		}})
+
+	}
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+
+	if err != nil {
+
+		return fmt.Errorf("failed to encode TMX: %v", err)
+
+	}
+
+	if err := os.WriteFile(path, append([]byte(xml.Header), append(data, '\n')...), 0644); err != nil {
+
+		return fmt.Errorf("failed to write TMX file: %v", err)
+
+	}
+
+	return nil
+
+}
@@ -0,0 +1,368 @@
+package main
+
+import (
+	"bufio"
+
+	"fmt"
+
+	"sort"
+
+	"strconv"
+
+	"strings"
+
+	"github.com/jdkato/prose/v2"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+
+	"golang.org/x/text/encoding"
+)
+
+// Words listed per pattern in the tone pattern report
+
+const tonePatternExamples = 8
+
+// Tone sandhi rules counted in the tone pattern report, by the character whose tone changes ("" for any third
+// tone syllable) and the tones of the next syllable that trigger the change
+
+var toneSandhiRules = []struct {
+	Name string
+
+	Character string
+
+	Before []int
+}{
+
+	{"3-3 → 2-3 (third tone)", "", []int{3}},
+
+	{"不 before a fourth tone (bù → bú)", "不", []int{4}},
+
+	{"一 before a fourth tone (yī → yí)", "一", []int{4}},
+
+	{"一 before a first, second or third tone (yī → yì)", "一", []int{1, 2, 3}},
+}
+
+// Tone sequences of a text: the tone pattern of each word, and the tone pairs and sandhi contexts of adjacent
+// syllables within each sentence, across word boundaries
+
+type toneStatistics struct {
+	Tones [6]int // Syllables by tone, 5 for the neutral tone
+
+	Patterns map[string]map[string]int // Word pattern (3-3, 2-4-1) → word → occurrences
+
+	Pairs map[string]int // Adjacent tones, e.g. 3-3
+
+	Sandhi []int // Occurrences of each rule of toneSandhiRules
+
+	SandhiSentences []int // Sentences with at least one occurrence of each rule
+
+	Sentences int
+}
+
+// Tone of a numbered syllable, 1-4 or 5 for the neutral tone; 0 for a syllable without a reading
+
+func syllableTone(syllable string) int {
+
+	if syllable == "" || syllable[len(syllable)-1] < '1' || syllable[len(syllable)-1] > '5' {
+
+		return 0
+
+	}
+
+	return int(syllable[len(syllable)-1] - '0')
+
+}
+
+// Reads the tones of the text with the annotator's citation readings. Punctuation and words without readings
+// break a sequence, and a sentence ends at sentence-final punctuation.
+
+func (p *pinyinAnnotator) toneStatistics(tokens []prose.Token) toneStatistics {
+
+	stats := toneStatistics{Patterns: make(map[string]map[string]int), Pairs: make(map[string]int), Sandhi: make([]int, len(toneSandhiRules)), SandhiSentences: make([]int, len(toneSandhiRules))}
+
+	type syllable struct {
+		Character string
+
+		Tone int
+	}
+
+	var previous *syllable
+
+	inSentence := make([]bool, len(toneSandhiRules))
+
+	read := false // Whether the sentence has a syllable with a reading
+
+	endSentence := func() {
+
+		if !read {
+
+			return
+
+		}
+
+		for i, found := range inSentence {
+
+			if found {
+
+				stats.SandhiSentences[i]++
+
+			}
+
+		}
+
+		inSentence = make([]bool, len(toneSandhiRules))
+
+		previous, read = nil, false
+
+		stats.Sentences++
+
+	}
+
+	for _, token := range tokens {
+
+		if !containsChinese(token.Text) {
+
+			previous = nil
+
+			if strings.ContainsAny(token.Text, classifier.SentenceTerminators) {
+
+				endSentence()
+
+			}
+
+			continue
+
+		}
+
+		runes := []rune(token.Text)
+
+		var pattern []string
+
+		for i, reading := range p.syllables(runes) {
+
+			tone := syllableTone(reading)
+
+			if tone == 0 {
+
+				previous, pattern = nil, nil
+
+				continue
+
+			}
+
+			stats.Tones[tone]++
+
+			read = true
+
+			pattern = append(pattern, strconv.Itoa(tone))
+
+			current := &syllable{Character: string(runes[i]), Tone: tone}
+
+			if previous != nil {
+
+				stats.Pairs[fmt.Sprintf("%d-%d", previous.Tone, current.Tone)]++
+
+				for k, rule := range toneSandhiRules {
+
+					if (rule.Character == previous.Character || rule.Character == "" && previous.Tone == 3) && containsTone(rule.Before, current.Tone) {
+
+						stats.Sandhi[k]++
+
+						inSentence[k] = true
+
+					}
+
+				}
+
+			}
+
+			previous = current
+
+		}
+
+		if len(runes) > 1 && len(pattern) == len(runes) {
+
+			key := strings.Join(pattern, "-")
+
+			if stats.Patterns[key] == nil {
+
+				stats.Patterns[key] = make(map[string]int)
+
+			}
+
+			stats.Patterns[key][token.Text]++
+
+		}
+
+	}
+
+	endSentence()
+
+	return stats
+
+}
+
+// Whether a tone is among the tones of a sandhi rule
+
+func containsTone(tones []int, tone int) bool {
+
+	for _, t := range tones {
+
+		if t == tone {
+
+			return true
+
+		}
+
+	}
+
+	return false
+
+}
+
+// Writes ChineseTonePatterns.txt: the tone distribution of the syllables, the tone patterns of multi-syllable
+// words, the pairs of adjacent tones and the tone sandhi contexts they contain, for pronunciation curricula
+
+func writeTonePatternReport(path string, stats toneStatistics, enc encoding.Encoding) error {
+
+	file, err := createOutputFile(path, enc)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to create tone pattern report: %v", err)
+
+	}
+
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+
+	syllables := 0
+
+	for _, count := range stats.Tones {
+
+		syllables += count
+
+	}
+
+	share := func(count, total int) string {
+
+		if total == 0 {
+
+			return "0.0%"
+
+		}
+
+		return strconv.FormatFloat(float64(count)*100/float64(total), 'f', 1, 64) + "%"
+
+	}
+
+	var rows [][]string
+
+	for tone := 1; tone <= 5; tone++ {
+
+		name := strconv.Itoa(tone)
+
+		if tone == 5 {
+
+			name = "neutral"
+
+		}
+
+		rows = append(rows, []string{name, strconv.Itoa(stats.Tones[tone]), share(stats.Tones[tone], syllables)})
+
+	}
+
+	fmt.Fprintln(writer, "Tones")
+
+	writeTable(writer, []string{"tone", "syllables", "share"}, rows, []bool{false, true, true})
+
+	occurrences := make(map[string]int)
+
+	var patterns []string
+
+	for pattern, words := range stats.Patterns {
+
+		patterns = append(patterns, pattern)
+
+		for _, count := range words {
+
+			occurrences[pattern] += count
+
+		}
+
+	}
+
+	sort.Slice(patterns, func(i, j int) bool {
+
+		if occurrences[patterns[i]] != occurrences[patterns[j]] {
+
+			return occurrences[patterns[i]] > occurrences[patterns[j]]
+
+		}
+
+		return patterns[i] < patterns[j]
+
+	})
+
+	rows = nil
+
+	for _, pattern := range patterns {
+
+		words := rankedKeys(stats.Patterns[pattern])
+
+		rows = append(rows, []string{pattern, strconv.Itoa(len(words)), strconv.Itoa(occurrences[pattern]), strings.Join(words[:min(len(words), tonePatternExamples)], " ")})
+
+	}
+
+	fmt.Fprintln(writer, "\nWord tone patterns")
+
+	writeTable(writer, []string{"pattern", "words", "occurrences", "most frequent"}, rows, []bool{false, true, true, false})
+
+	pairs := 0
+
+	for _, count := range stats.Pairs {
+
+		pairs += count
+
+	}
+
+	rows = nil
+
+	for first := 1; first <= 5; first++ {
+
+		for second := 1; second <= 5; second++ {
+
+			pair := fmt.Sprintf("%d-%d", first, second)
+
+			if stats.Pairs[pair] > 0 {
+
+				rows = append(rows, []string{pair, strconv.Itoa(stats.Pairs[pair]), share(stats.Pairs[pair], pairs)})
+
+			}
+
+		}
+
+	}
+
+	fmt.Fprintln(writer, "\nAdjacent tone pairs (5 is the neutral tone)")
+
+	writeTable(writer, []string{"pair", "occurrences", "share"}, rows, []bool{false, true, true})
+
+	rows = nil
+
+	for i, rule := range toneSandhiRules {
+
+		rows = append(rows, []string{rule.Name, strconv.Itoa(stats.Sandhi[i]), strconv.Itoa(stats.SandhiSentences[i]), share(stats.SandhiSentences[i], stats.Sentences)})
+
+	}
+
+	fmt.Fprintln(writer, "\nTone sandhi")
+
+	writeTable(writer, []string{"rule", "occurrences", "sentences", "share of sentences"}, rows, []bool{false, true, true, true})
+
+	fmt.Fprintf(writer, "\nSentences: %d\n", stats.Sentences)
+
+	return writer.Flush()
+
+}
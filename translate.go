@@ -0,0 +1,496 @@
+package main
+
+import (
+	"bufio"
+
+	"bytes"
+
+	"encoding/json"
+
+	"fmt"
+
+	"maps"
+
+	"net/http"
+
+	"net/url"
+
+	"os"
+
+	"os/exec"
+
+	"path/filepath"
+
+	"strings"
+
+	"sync"
+
+	"time"
+)
+
+// Maximum number of texts sent to a translation backend in one request
+
+const translationBatchSize = 50
+
+// Machine-translation backend that translates Chinese texts into a target language
+
+type translator interface {
+	Name() string
+
+	Translate(texts []string, targetLanguage string) ([]string, error)
+}
+
+// Creates the translation backend selected by --mt, wrapped in a persistent cache
+
+func newTranslator(backend, command string) (translator, error) {
+
+	var base translator
+
+	switch strings.ToLower(backend) {
+
+	case "":
+
+		return nil, nil
+
+	case "deepl":
+
+		key := os.Getenv("DEEPL_AUTH_KEY")
+
+		if key == "" {
+
+			return nil, fmt.Errorf("DEEPL_AUTH_KEY must be set to use the DeepL backend")
+
+		}
+
+		base = &deeplTranslator{authKey: key, client: &http.Client{Timeout: 60 * time.Second}}
+
+	case "google":
+
+		key := os.Getenv("GOOGLE_TRANSLATE_API_KEY")
+
+		if key == "" {
+
+			return nil, fmt.Errorf("GOOGLE_TRANSLATE_API_KEY must be set to use the Google backend")
+
+		}
+
+		base = &googleTranslator{apiKey: key, client: &http.Client{Timeout: 60 * time.Second}}
+
+	case "local":
+
+		if strings.TrimSpace(command) == "" {
+
+			return nil, fmt.Errorf("--mt-command is required for the local backend")
+
+		}
+
+		base = &commandTranslator{command: strings.Fields(command)}
+
+	default:
+
+		return nil, fmt.Errorf("unknown MT backend %q (available: deepl, google, local)", backend)
+
+	}
+
+	return newCachingTranslator(base)
+
+}
+
+// DeepL REST API backend
+
+type deeplTranslator struct {
+	authKey string
+
+	client *http.Client
+}
+
+func (t *deeplTranslator) Name() string {
+
+	return "deepl"
+
+}
+
+func (t *deeplTranslator) Translate(texts []string, targetLanguage string) ([]string, error) {
+
+	endpoint := "https://api.deepl.com/v2/translate"
+
+	if strings.HasSuffix(t.authKey, ":fx") {
+
+		endpoint = "https://api-free.deepl.com/v2/translate"
+
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+
+		"text": texts,
+
+		"source_lang": "ZH",
+
+		"target_lang": strings.ToUpper(targetLanguage),
+	})
+
+	if err != nil {
+
+		return nil, err
+
+	}
+
+	request, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+
+	if err != nil {
+
+		return nil, err
+
+	}
+
+	request.Header.Set("Authorization", "DeepL-Auth-Key "+t.authKey)
+
+	request.Header.Set("Content-Type", "application/json")
+
+	var response struct {
+		Translations []struct {
+			Text string `json:"text"`
+		} `json:"translations"`
+	}
+
+	if err := doJSONRequest(t.client, request, &response); err != nil {
+
+		return nil, fmt.Errorf("DeepL request failed: %v", err)
+
+	}
+
+	var translations []string
+
+	for _, translation := range response.Translations {
+
+		translations = append(translations, translation.Text)
+
+	}
+
+	return translations, nil
+
+}
+
+// Google Cloud Translation (v2) backend
+
+type googleTranslator struct {
+	apiKey string
+
+	client *http.Client
+}
+
+func (t *googleTranslator) Name() string {
+
+	return "google"
+
+}
+
+func (t *googleTranslator) Translate(texts []string, targetLanguage string) ([]string, error) {
+
+	body, err := json.Marshal(map[string]interface{}{
+
+		"q": texts,
+
+		"source": "zh-CN",
+
+		"target": targetLanguage,
+
+		"format": "text",
+	})
+
+	if err != nil {
+
+		return nil, err
+
+	}
+
+	endpoint := "https://translation.googleapis.com/language/translate/v2?key=" + url.QueryEscape(t.apiKey)
+
+	request, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+
+	if err != nil {
+
+		return nil, err
+
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+
+	var response struct {
+		Data struct {
+			Translations []struct {
+				TranslatedText string `json:"translatedText"`
+			} `json:"translations"`
+		} `json:"data"`
+	}
+
+	if err := doJSONRequest(t.client, request, &response); err != nil {
+
+		return nil, fmt.Errorf("Google Translate request failed: %v", err)
+
+	}
+
+	var translations []string
+
+	for _, translation := range response.Data.Translations {
+
+		translations = append(translations, translation.TranslatedText)
+
+	}
+
+	return translations, nil
+
+}
+
+// Sends a request and decodes a JSON response, treating non-2xx statuses as errors
+
+func doJSONRequest(client *http.Client, request *http.Request, target interface{}) error {
+
+	response, err := client.Do(request)
+
+	if err != nil {
+
+		return err
+
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+
+		return fmt.Errorf("unexpected status %s", response.Status)
+
+	}
+
+	return json.NewDecoder(response.Body).Decode(target)
+
+}
+
+// Local model backend: runs a command that reads one text per line on stdin and writes one translation per line
+
+type commandTranslator struct {
+	command []string
+}
+
+func (t *commandTranslator) Name() string {
+
+	return "local"
+
+}
+
+func (t *commandTranslator) Translate(texts []string, targetLanguage string) ([]string, error) {
+
+	cmd := exec.Command(t.command[0], t.command[1:]...)
+
+	cmd.Env = append(os.Environ(), "CWCLASSIFIER_TARGET_LANG="+targetLanguage)
+
+	cmd.Stdin = strings.NewReader(strings.Join(texts, "\n") + "\n")
+
+	output, err := cmd.Output()
+
+	if err != nil {
+
+		return nil, fmt.Errorf("local MT command failed: %v", err)
+
+	}
+
+	var translations []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+
+	for scanner.Scan() {
+
+		translations = append(translations, strings.TrimSpace(scanner.Text()))
+
+	}
+
+	return translations, scanner.Err()
+
+}
+
+// Wraps a backend with an on-disk cache so repeated runs don't repeat API calls
+
+type cachingTranslator struct {
+	backend translator
+
+	cachePath string
+
+	mu sync.Mutex // Guards cache for the workers of a --queue, which share one translator
+
+	cache map[string]string
+
+	dryRun *dryRunWrites // Set during a dry run: new entries stay in memory and the cache file is recorded instead of saved
+}
+
+// Creates a caching wrapper backed by a JSON file in the user cache directory
+
+func newCachingTranslator(backend translator) (*cachingTranslator, error) {
+
+	cacheDir, err := os.UserCacheDir()
+
+	if err != nil {
+
+		return nil, fmt.Errorf("failed to locate cache directory: %v", err)
+
+	}
+
+	t := &cachingTranslator{
+
+		backend: backend,
+
+		cachePath: filepath.Join(cacheDir, "cwClassifier", "mt-cache-"+backend.Name()+".json"),
+
+		cache: make(map[string]string),
+	}
+
+	if data, err := os.ReadFile(t.cachePath); err == nil {
+
+		if err := json.Unmarshal(data, &t.cache); err != nil {
+
+			return nil, fmt.Errorf("failed to read MT cache %s: %v", t.cachePath, err)
+
+		}
+
+	}
+
+	return t, nil
+
+}
+
+func (t *cachingTranslator) Name() string {
+
+	return t.backend.Name()
+
+}
+
+// Translates only the texts missing from the cache, in batches, and persists the new entries. Calls take turns,
+
+// so concurrent runs never fetch the same text twice.
+
+func (t *cachingTranslator) Translate(texts []string, targetLanguage string) ([]string, error) {
+
+	t.mu.Lock()
+
+	defer t.mu.Unlock()
+
+	var missing []string
+
+	pending := make(map[string]bool)
+
+	for _, text := range texts {
+
+		if _, ok := t.cache[cacheKey(text, targetLanguage)]; !ok && !pending[text] {
+
+			missing = append(missing, text)
+
+			pending[text] = true
+
+		}
+
+	}
+
+	for start := 0; start < len(missing); start += translationBatchSize {
+
+		end := start + translationBatchSize
+
+		if end > len(missing) {
+
+			end = len(missing)
+
+		}
+
+		batch := missing[start:end]
+
+		translations, err := t.backend.Translate(batch, targetLanguage)
+
+		if err != nil {
+
+			return nil, err
+
+		}
+
+		if len(translations) != len(batch) {
+
+			return nil, fmt.Errorf("%s backend returned %d translations for %d texts", t.backend.Name(), len(translations), len(batch))
+
+		}
+
+		for i, text := range batch {
+
+			t.cache[cacheKey(text, targetLanguage)] = translations[i]
+
+		}
+
+	}
+
+	if len(missing) > 0 && t.dryRun != nil {
+
+		t.dryRun.add(t.cachePath)
+
+	} else if len(missing) > 0 {
+
+		if err := t.save(); err != nil {
+
+			return nil, err
+
+		}
+
+	}
+
+	translations := make([]string, len(texts))
+
+	for i, text := range texts {
+
+		translations[i] = t.cache[cacheKey(text, targetLanguage)]
+
+	}
+
+	return translations, nil
+
+}
+
+// Copy for a dry run, whose new entries stay in its own cache and whose cache file is recorded in writes
+
+func (t *cachingTranslator) dryRunCopy(writes *dryRunWrites) *cachingTranslator {
+
+	t.mu.Lock()
+
+	defer t.mu.Unlock()
+
+	return &cachingTranslator{backend: t.backend, cachePath: t.cachePath, cache: maps.Clone(t.cache), dryRun: writes}
+
+}
+
+// Writes the cache file, creating its directory when needed
+
+func (t *cachingTranslator) save() error {
+
+	if err := os.MkdirAll(filepath.Dir(t.cachePath), os.ModePerm); err != nil {
+
+		return fmt.Errorf("failed to create MT cache directory: %v", err)
+
+	}
+
+	data, err := json.MarshalIndent(t.cache, "", "  ")
+
+	if err != nil {
+
+		return err
+
+	}
+
+	if err := os.WriteFile(t.cachePath, data, 0644); err != nil {
+
+		return fmt.Errorf("failed to write MT cache: %v", err)
+
+	}
+
+	return nil
+
+}
+
+func cacheKey(text, targetLanguage string) string {
+
+	return targetLanguage + "\t" + text
+
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bufio"
+
+	_ "embed"
+
+	"fmt"
+
+	"sort"
+
+	"strconv"
+
+	"strings"
+
+	"unicode"
+
+	"unicode/utf8"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+
+	"golang.org/x/text/encoding"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+//go:embed dict/variants.txt
+
+var variantData string
+
+func init() {
+
+	classifier.RegisterResource("variants.txt", variantData)
+
+}
+
+// Legacy code points of Han characters that compatibility normalization maps to the unified ideograph:
+
+// Kangxi radicals used as characters (⼈), CJK radical forms and CJK compatibility ideographs (﨑)
+
+var legacyHanRanges = &unicode.RangeTable{
+
+	R16: []unicode.Range16{
+
+		{Lo: 0x2e80, Hi: 0x2fdf, Stride: 1},
+
+		{Lo: 0xf900, Hi: 0xfaff, Stride: 1},
+	},
+
+	R32: []unicode.Range32{
+
+		{Lo: 0x2f800, Hi: 0x2fa1f, Stride: 1},
+	},
+}
+
+// A variant character and the standard form it was counted as
+
+type variantMapping struct {
+	Variant rune
+
+	Standard rune
+}
+
+// Loads the embedded variant table, variant → standard form
+
+func loadVariantTable() (map[rune]rune, error) {
+
+	table := make(map[rune]rune)
+
+	scanner := bufio.NewScanner(strings.NewReader(classifier.ResourceText("variants.txt")))
+
+	for scanner.Scan() {
+
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+
+			continue
+
+		}
+
+		fields := strings.Fields(line)
+
+		if len(fields) != 2 || utf8.RuneCountInString(fields[0]) != 1 || utf8.RuneCountInString(fields[1]) != 1 {
+
+			return nil, fmt.Errorf("invalid variant table line %q", line)
+
+		}
+
+		variant, _ := utf8.DecodeRuneInString(fields[0])
+
+		standard, _ := utf8.DecodeRuneInString(fields[1])
+
+		table[variant] = standard
+
+	}
+
+	return table, scanner.Err()
+
+}
+
+// Standard form of a character: legacy code points are normalized to the unified ideograph, and variants of
+
+// the table to their standard form
+
+func standardCharacter(r rune, table map[rune]rune) rune {
+
+	if unicode.Is(legacyHanRanges, r) {
+
+		if normalized := []rune(norm.NFKC.String(string(r))); len(normalized) == 1 && unicode.Is(unicode.Han, normalized[0]) {
+
+			r = normalized[0]
+
+		}
+
+	}
+
+	if standard, ok := table[r]; ok {
+
+		return standard
+
+	}
+
+	return r
+
+}
+
+// Replaces variant and legacy character forms with their standard forms before counting, and counts each
+
+// mapping applied
+
+func normalizeVariants(lines []string, table map[rune]rune) ([]string, map[variantMapping]int) {
+
+	found := make(map[variantMapping]int)
+
+	normalized := make([]string, len(lines))
+
+	for i, line := range lines {
+
+		normalized[i] = strings.Map(func(r rune) rune {
+
+			standard := standardCharacter(r, table)
+
+			if standard != r {
+
+				found[variantMapping{Variant: r, Standard: standard}]++
+
+			}
+
+			return standard
+
+		}, line)
+
+	}
+
+	return normalized, found
+
+}
+
+// Writes ChineseVariants.txt: the variant and legacy forms found in the text, the standard forms they were
+
+// counted as and their occurrences, most frequent first
+
+func writeVariantReport(path string, found map[variantMapping]int, enc encoding.Encoding) error {
+
+	var mappings []variantMapping
+
+	for mapping := range found {
+
+		mappings = append(mappings, mapping)
+
+	}
+
+	sort.Slice(mappings, func(i, j int) bool {
+
+		if found[mappings[i]] != found[mappings[j]] {
+
+			return found[mappings[i]] > found[mappings[j]]
+
+		}
+
+		return mappings[i].Variant < mappings[j].Variant
+
+	})
+
+	file, err := createOutputFile(path, enc)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to create variant report: %v", err)
+
+	}
+
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+
+	var rows [][]string
+
+	for _, mapping := range mappings {
+
+		rows = append(rows, []string{string(mapping.Variant), fmt.Sprintf("U+%04X", mapping.Variant), string(mapping.Standard), strconv.Itoa(found[mapping])})
+
+	}
+
+	writeTable(writer, []string{"variant", "code point", "standard", "occurrences"}, rows, []bool{false, false, false, true})
+
+	return writer.Flush()
+
+}
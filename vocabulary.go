@@ -0,0 +1,115 @@
+package main
+
+import (
+	"sort"
+
+	"strings"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+)
+
+// Categories whose items are words worth studying, as opposed to characters, phrases, or leftovers
+
+var vocabularyCategories = []string{
+
+	"ChineseNouns", "ChineseVerbs", "ChineseAdjectives", "ChineseAdverbs",
+
+	"ChineseIdioms", "ChineseSlang", "ChineseAbbreviations",
+
+	"ChineseFinanceTerms", "ChineseITTerms", "ChineseLegalTerms", "ChineseMedicalTerms",
+}
+
+// Vocabulary list entry with its reading, gloss and frequency
+
+type vocabularyEntry struct {
+	Word string
+
+	Pinyin string
+
+	Gloss string
+
+	Frequency int
+}
+
+// Merges the word categories into one vocabulary list, most frequent first
+
+func buildVocabulary(ranked map[string][]classifier.ItemFrequency, characters map[rune]characterInfo, glosses map[string]string) []vocabularyEntry {
+
+	frequencies := make(map[string]int)
+
+	for _, category := range vocabularyCategories {
+
+		for _, entry := range ranked[category] {
+
+			if !containsChinese(entry.Item) {
+
+				continue
+
+			}
+
+			if entry.Frequency > frequencies[entry.Item] {
+
+				frequencies[entry.Item] = entry.Frequency
+
+			}
+
+		}
+
+	}
+
+	var vocabulary []vocabularyEntry
+
+	for word, frequency := range frequencies {
+
+		vocabulary = append(vocabulary, vocabularyEntry{
+
+			Word: word,
+
+			Pinyin: wordPinyin(word, characters),
+
+			Gloss: glosses[word],
+
+			Frequency: frequency,
+		})
+
+	}
+
+	sort.Slice(vocabulary, func(i, j int) bool {
+
+		if vocabulary[i].Frequency != vocabulary[j].Frequency {
+
+			return vocabulary[i].Frequency > vocabulary[j].Frequency
+
+		}
+
+		return vocabulary[i].Word < vocabulary[j].Word
+
+	})
+
+	return vocabulary
+
+}
+
+// Tone-marked pinyin for a word using each character's most common reading; unknown characters show as "?"
+
+func wordPinyin(word string, characters map[rune]characterInfo) string {
+
+	var syllables []string
+
+	for _, r := range word {
+
+		if info, ok := characters[r]; ok {
+
+			syllables = append(syllables, toneMarks(info.Readings[0]))
+
+		} else if containsChinese(string(r)) {
+
+			syllables = append(syllables, "?")
+
+		}
+
+	}
+
+	return strings.Join(syllables, " ")
+
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bufio"
+
+	"fmt"
+
+	"strconv"
+
+	"strings"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+
+	"golang.org/x/text/encoding"
+)
+
+// Surface form of an item in the JSON output, with --lemmas
+
+type jsonForm struct {
+	Form string `json:"form"`
+
+	Frequency int `json:"frequency"`
+}
+
+// Surface forms of an item, most frequent first
+
+func itemForms(surfaces map[string]int) []jsonForm {
+
+	var forms []jsonForm
+
+	for _, entry := range classifier.RankByFrequency(surfaces) {
+
+		forms = append(forms, jsonForm{Form: entry.Item, Frequency: entry.Frequency})
+
+	}
+
+	return forms
+
+}
+
+// Writes ChineseWordForms.txt: each item counted under its dictionary form with the forms it was written in,
+
+// e.g. 看 with 看看, 看了 and 看一看
+
+func writeWordForms(path string, categories []string, ranked map[string][]classifier.ItemFrequency, forms map[string]map[string]map[string]int, enc encoding.Encoding) error {
+
+	file, err := createOutputFile(path, enc)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to create word form report: %v", err)
+
+	}
+
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+
+	var rows [][]string
+
+	for _, category := range categories {
+
+		for _, entry := range ranked[category] {
+
+			surfaces, ok := forms[category][entry.Item]
+
+			if !ok {
+
+				continue
+
+			}
+
+			var written []string
+
+			for _, form := range itemForms(surfaces) {
+
+				written = append(written, fmt.Sprintf("%s %d", form.Form, form.Frequency))
+
+			}
+
+			rows = append(rows, []string{category, entry.Item, strconv.Itoa(entry.Frequency), strings.Join(written, ", ")})
+
+		}
+
+	}
+
+	writeTable(writer, []string{"category", "item", "frequency", "forms"}, rows, []bool{false, false, true, false})
+
+	return writer.Flush()
+
+}
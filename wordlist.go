@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bufio"
+
+	"fmt"
+
+	"path/filepath"
+
+	"strconv"
+
+	"strings"
+
+	"github.com/jdkato/prose/v2"
+
+	"github.com/ljg-cqu/txt-cwClassifier/classifier"
+
+	"golang.org/x/text/encoding"
+)
+
+// Writes the master word list in the layouts of corpus tools: ChineseWordList.txt as AntConc saves a word
+
+// list, and ChineseWordList.tsv with a row per form, lemma and category for #LancsBox or spreadsheets
+
+func writeWordLists(outputDir string, tokens []prose.Token, categories []string, ranked map[string][]classifier.ItemFrequency, forms map[string]map[string]map[string]int, stats map[string]map[string]itemStats, enc encoding.Encoding) error {
+
+	if err := writeAntConcWordList(filepath.Join(outputDir, "ChineseWordList.txt"), tokens, enc); err != nil {
+
+		return err
+
+	}
+
+	return writeLexiconTable(filepath.Join(outputDir, "ChineseWordList.tsv"), categories, ranked, forms, stats, enc)
+
+}
+
+// Writes the Chinese word types of the text by token frequency, under the #Word Types, #Word Tokens and
+
+// #Search Hits header of an AntConc word list
+
+func writeAntConcWordList(path string, tokens []prose.Token, enc encoding.Encoding) error {
+
+	var words []string
+
+	for _, token := range tokens {
+
+		if classifier.IsChineseText(token.Text) {
+
+			words = append(words, token.Text)
+
+		}
+
+	}
+
+	types := classifier.RankByFrequency(classifier.CountFrequencies(words))
+
+	file, err := createOutputFile(path, enc)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to create word list: %v", err)
+
+	}
+
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+
+	fmt.Fprintf(writer, "#Word Types: %d\n#Word Tokens: %d\n#Search Hits: 0\n", len(types), len(words))
+
+	for i, entry := range types {
+
+		fmt.Fprintf(writer, "%d\t%d\t%s\n", i+1, entry.Frequency, entry.Item)
+
+	}
+
+	return writer.Flush()
+
+}
+
+// Writes a tab-separated table of every item but single characters with its lemma, its category as part
+
+// of speech, frequency, rate per 10,000 tokens, range and Gries' DP; items counted under a dictionary form
+
+// (--lemmas) get a row per form written
+
+func writeLexiconTable(path string, categories []string, ranked map[string][]classifier.ItemFrequency, forms map[string]map[string]map[string]int, stats map[string]map[string]itemStats, enc encoding.Encoding) error {
+
+	file, err := createOutputFile(path, enc)
+
+	if err != nil {
+
+		return fmt.Errorf("failed to create word list table: %v", err)
+
+	}
+
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+
+	writer.WriteString("Type\tLemma\tPOS\tFrequency\tPer10k\tRange\tDP\n")
+
+	for _, category := range categories {
+
+		if category == "ChineseCharacters" {
+
+			continue
+
+		}
+
+		pos := strings.TrimPrefix(category, "Chinese")
+
+		for _, entry := range ranked[category] {
+
+			s := stats[category][entry.Item]
+
+			dispersion := strconv.Itoa(s.Range) + "\t" + strconv.FormatFloat(s.DP, 'f', 3, 64)
+
+			surfaces, ok := forms[category][entry.Item]
+
+			if !ok {
+
+				fmt.Fprintf(writer, "%s\t%s\t%s\t%d\t%.2f\t%s\n", entry.Item, entry.Item, pos, entry.Frequency, s.Per10k, dispersion)
+
+				continue
+
+			}
+
+			// Range and DP are measured for the lemma, so its forms share them
+
+			for _, form := range itemForms(surfaces) {
+
+				fmt.Fprintf(writer, "%s\t%s\t%s\t%d\t%.2f\t%s\n", form.Form, entry.Item, pos, form.Frequency, s.Per10k*float64(form.Frequency)/float64(entry.Frequency), dispersion)
+
+			}
+
+		}
+
+	}
+
+	return writer.Flush()
+
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"strings"
+)
+
+// Zhuyin (bopomofo) of the pinyin initials, longest first so zh is not read as z
+
+var zhuyinInitials = []struct {
+	Pinyin string
+
+	Zhuyin string
+}{
+
+	{"zh", "ㄓ"}, {"ch", "ㄔ"}, {"sh", "ㄕ"},
+
+	{"b", "ㄅ"}, {"p", "ㄆ"}, {"m", "ㄇ"}, {"f", "ㄈ"}, {"d", "ㄉ"}, {"t", "ㄊ"}, {"n", "ㄋ"}, {"l", "ㄌ"},
+
+	{"g", "ㄍ"}, {"k", "ㄎ"}, {"h", "ㄏ"}, {"j", "ㄐ"}, {"q", "ㄑ"}, {"x", "ㄒ"}, {"r", "ㄖ"}, {"z", "ㄗ"},
+
+	{"c", "ㄘ"}, {"s", "ㄙ"},
+}
+
+// Zhuyin of the pinyin finals, written out in full: y and w syllables (you, wei) are first rewritten to the
+// finals they stand for (iou, uei), and ü is written v
+
+var zhuyinFinals = map[string]string{
+	"a": "ㄚ", "o": "ㄛ", "e": "ㄜ", "ê": "ㄝ", "ai": "ㄞ", "ei": "ㄟ", "ao": "ㄠ", "ou": "ㄡ",
+	"an": "ㄢ", "en": "ㄣ", "ang": "ㄤ", "eng": "ㄥ", "ong": "ㄨㄥ", "er": "ㄦ",
+
+	"i": "ㄧ", "ia": "ㄧㄚ", "io": "ㄧㄛ", "ie": "ㄧㄝ", "iai": "ㄧㄞ", "iao": "ㄧㄠ", "iu": "ㄧㄡ", "iou": "ㄧㄡ",
+	"ian": "ㄧㄢ", "in": "ㄧㄣ", "iang": "ㄧㄤ", "ing": "ㄧㄥ", "iong": "ㄩㄥ",
+
+	"u": "ㄨ", "ua": "ㄨㄚ", "uo": "ㄨㄛ", "uai": "ㄨㄞ", "ui": "ㄨㄟ", "uei": "ㄨㄟ", "uan": "ㄨㄢ", "un": "ㄨㄣ",
+	"uen": "ㄨㄣ", "uang": "ㄨㄤ", "ueng": "ㄨㄥ",
+
+	"v": "ㄩ", "ve": "ㄩㄝ", "ue": "ㄩㄝ", "van": "ㄩㄢ", "vn": "ㄩㄣ",
+}
+
+// Tone marks of Zhuyin; the first tone is unmarked and the neutral tone's dot goes before the syllable
+
+var zhuyinTones = map[byte]string{'2': "ˊ", '3': "ˇ", '4': "ˋ"}
+
+// Converts numbered pinyin such as "zhong1" or "lv4" to Zhuyin ("ㄓㄨㄥ", "ㄌㄩˋ"); syllables it cannot read are
+// returned unchanged
+
+func toZhuyin(numbered string) string {
+
+	syllable, tone := strings.ToLower(numbered), byte('1')
+
+	if syllable == "" {
+
+		return numbered
+
+	}
+
+	if last := syllable[len(syllable)-1]; last >= '0' && last <= '5' {
+
+		syllable, tone = syllable[:len(syllable)-1], last
+
+	}
+
+	syllable = strings.ReplaceAll(strings.ReplaceAll(syllable, "ü", "v"), "u:", "v")
+
+	switch {
+
+	case strings.HasPrefix(syllable, "yu"):
+
+		syllable = "v" + syllable[2:]
+
+	case strings.HasPrefix(syllable, "yi"):
+
+		syllable = "i" + syllable[2:]
+
+	case strings.HasPrefix(syllable, "y"):
+
+		syllable = "i" + syllable[1:]
+
+	case strings.HasPrefix(syllable, "wu"):
+
+		syllable = "u" + syllable[2:]
+
+	case strings.HasPrefix(syllable, "w"):
+
+		syllable = "u" + syllable[1:]
+
+	}
+
+	initial, final := "", syllable
+
+	for _, candidate := range zhuyinInitials {
+
+		if strings.HasPrefix(syllable, candidate.Pinyin) {
+
+			initial, final = candidate.Zhuyin, syllable[len(candidate.Pinyin):]
+
+			break
+
+		}
+
+	}
+
+	var zhuyin string
+
+	switch {
+
+	case initial != "" && final == "":
+
+		return numbered
+
+	case initial != "" && final == "i" && strings.Contains("ㄓㄔㄕㄖㄗㄘㄙ", initial):
+
+		// The i of zhi, chi, shi, ri, zi, ci and si is not written
+
+		zhuyin = initial
+
+	case initial != "" && strings.Contains("ㄐㄑㄒ", initial) && strings.HasPrefix(final, "u"):
+
+		// u after j, q and x is ü
+
+		zhuyin = initial + zhuyinFinals["v"+final[1:]]
+
+	default:
+
+		written, ok := zhuyinFinals[final]
+
+		if !ok {
+
+			return numbered
+
+		}
+
+		zhuyin = initial + written
+
+	}
+
+	if tone == '5' || tone == '0' {
+
+		return "˙" + zhuyin
+
+	}
+
+	return zhuyin + zhuyinTones[tone]
+
+}